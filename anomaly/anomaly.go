@@ -0,0 +1,194 @@
+package anomaly
+
+import (
+	"math"
+
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+)
+
+const (
+	// DefaultSeason is a default period of metric seasonality, the same time last week is used to calculate band
+	DefaultSeason int64 = 7 * 24 * 60 * 60
+	// DefaultSeasons is a default number of previous seasons used to calculate band
+	DefaultSeasons = 1
+	// DefaultWindow is a default time before and after the same time of previous season, values in window are used to calculate band
+	DefaultWindow int64 = 10 * 60
+)
+
+// ApplyDefaults sets default values of not set anomaly settings
+func ApplyDefaults(settings *moira.AnomalySettings) {
+	if settings.Season <= 0 {
+		settings.Season = DefaultSeason
+	}
+	if settings.Seasons <= 0 {
+		settings.Seasons = DefaultSeasons
+	}
+	if settings.Window <= 0 {
+		settings.Window = DefaultWindow
+	}
+	if settings.Direction == "" {
+		settings.Direction = moira.AnomalyDirectionBoth
+	}
+}
+
+// Band is an expected metric value and standard deviation of metric values at the same time of previous seasons
+type Band struct {
+	Expected  float64
+	Deviation float64
+}
+
+// Bounds returns lower and upper bounds of band extended by sensitivity standard deviations
+func (band Band) Bounds(sensitivity float64) (float64, float64) {
+	return band.Expected - sensitivity*band.Deviation, band.Expected + sensitivity*band.Deviation
+}
+
+// GetState returns ERROR or WARN state if value breaches band by error or warn sensitivity and OK otherwise
+func GetState(settings moira.AnomalySettings, value float64, band Band) moira.State {
+	if settings.ErrorSensitivity != nil && isBreached(settings.Direction, value, band, *settings.ErrorSensitivity) {
+		return moira.StateERROR
+	}
+	if settings.WarnSensitivity != nil && isBreached(settings.Direction, value, band, *settings.WarnSensitivity) {
+		return moira.StateWARN
+	}
+	return moira.StateOK
+}
+
+func isBreached(direction string, value float64, band Band, sensitivity float64) bool {
+	lower, upper := band.Bounds(sensitivity)
+	switch direction {
+	case moira.AnomalyDirectionUp:
+		return value > upper
+	case moira.AnomalyDirectionDown:
+		return value < lower
+	default:
+		return value > upper || value < lower
+	}
+}
+
+// Baseline contains metrics of target fetched for previous seasons
+type Baseline struct {
+	settings moira.AnomalySettings
+	seasons  []map[string]metricSource.MetricData
+}
+
+// FetchBaseline fetches target metrics of previous seasons which are required to calculate bands for period from-until
+func FetchBaseline(source metricSource.MetricSource, target string, from, until int64, settings moira.AnomalySettings, allowRealTimeAlerting bool) (*Baseline, error) {
+	ApplyDefaults(&settings)
+	baseline := &Baseline{
+		settings: settings,
+		seasons:  make([]map[string]metricSource.MetricData, 0, settings.Seasons),
+	}
+	for season := 1; season <= settings.Seasons; season++ {
+		shift := int64(season) * settings.Season
+		fetchResult, err := source.Fetch(target, from-shift-settings.Window, until-shift+settings.Window, allowRealTimeAlerting)
+		if err != nil {
+			return nil, err
+		}
+		metrics := make(map[string]metricSource.MetricData)
+		for _, metricData := range fetchResult.GetMetricsData() {
+			metrics[metricData.Name] = metricData
+		}
+		baseline.seasons = append(baseline.seasons, metrics)
+	}
+	return baseline, nil
+}
+
+// GetBand returns band of metric at timestamp, false is returned if metric has no values in previous seasons
+func (baseline *Baseline) GetBand(metric string, timestamp int64) (Band, bool) {
+	values := make([]float64, 0)
+	for i, metrics := range baseline.seasons {
+		metricData, ok := metrics[metric]
+		if !ok || metricData.StepTime <= 0 {
+			continue
+		}
+		seasonTimestamp := timestamp - int64(i+1)*baseline.settings.Season
+		for valueTimestamp := seasonTimestamp - baseline.settings.Window; valueTimestamp <= seasonTimestamp+baseline.settings.Window; valueTimestamp += metricData.StepTime {
+			value := metricData.GetTimestampValue(valueTimestamp)
+			if moira.IsValidFloat64(value) {
+				values = append(values, value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return Band{}, false
+	}
+
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	expected := sum / float64(len(values))
+	var squaresSum float64
+	for _, value := range values {
+		squaresSum += (value - expected) * (value - expected)
+	}
+	return Band{
+		Expected:  expected,
+		Deviation: math.Sqrt(squaresSum / float64(len(values))),
+	}, true
+}
+
+// BandSeries is a band calculated for every point of metric, points without band are NaN
+type BandSeries struct {
+	Name      string
+	StartTime int64
+	StepTime  int64
+	Expected  []float64
+	Deviation []float64
+}
+
+// GetBandSeries returns band for every point of metric data
+func (baseline *Baseline) GetBandSeries(metricData metricSource.MetricData) BandSeries {
+	series := BandSeries{
+		Name:      metricData.Name,
+		StartTime: metricData.StartTime,
+		StepTime:  metricData.StepTime,
+		Expected:  make([]float64, len(metricData.Values)),
+		Deviation: make([]float64, len(metricData.Values)),
+	}
+	for i := range metricData.Values {
+		band, ok := baseline.GetBand(metricData.Name, metricData.StartTime+int64(i)*metricData.StepTime)
+		if !ok {
+			series.Expected[i] = math.NaN()
+			series.Deviation[i] = math.NaN()
+			continue
+		}
+		series.Expected[i] = band.Expected
+		series.Deviation[i] = band.Deviation
+	}
+	return series
+}
+
+// GetExpected returns expected values as metric data
+func (series BandSeries) GetExpected() metricSource.MetricData {
+	return *metricSource.MakeMetricData(series.Name, series.Expected, series.StepTime, series.StartTime)
+}
+
+// GetBounds returns lower and upper bounds of band extended by sensitivity standard deviations as metric data
+func (series BandSeries) GetBounds(sensitivity float64) (metricSource.MetricData, metricSource.MetricData) {
+	lower := make([]float64, len(series.Expected))
+	upper := make([]float64, len(series.Expected))
+	for i := range series.Expected {
+		lower[i], upper[i] = Band{Expected: series.Expected[i], Deviation: series.Deviation[i]}.Bounds(sensitivity)
+	}
+	return *metricSource.MakeMetricData(series.Name, lower, series.StepTime, series.StartTime),
+		*metricSource.MakeMetricData(series.Name, upper, series.StepTime, series.StartTime)
+}
+
+// GetTriggerBands fetches previous seasons of main trigger target and returns bands of its metrics.
+// Nil is returned for not anomaly triggers
+func GetTriggerBands(source metricSource.MetricSource, trigger *moira.Trigger, from, until int64, metricsData []metricSource.MetricData, allowRealTimeAlerting bool) ([]BandSeries, error) {
+	if trigger.TriggerType != moira.AnomalyTrigger || trigger.Anomaly == nil || len(trigger.Targets) == 0 {
+		return nil, nil
+	}
+	baseline, err := FetchBaseline(source, trigger.Targets[0], from, until, *trigger.Anomaly, allowRealTimeAlerting)
+	if err != nil {
+		return nil, err
+	}
+	bands := make([]BandSeries, 0, len(metricsData))
+	for _, metricData := range metricsData {
+		bands = append(bands, baseline.GetBandSeries(metricData))
+	}
+	return bands, nil
+}
@@ -0,0 +1,101 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	mock_metric_source "github.com/moira-alert/moira/mock/metric_source"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetState(t *testing.T) {
+	warn := 2.0
+	errorSensitivity := 3.0
+	band := Band{Expected: 100, Deviation: 10}
+
+	Convey("Both directions", t, func() {
+		settings := moira.AnomalySettings{WarnSensitivity: &warn, ErrorSensitivity: &errorSensitivity, Direction: moira.AnomalyDirectionBoth}
+		So(GetState(settings, 100, band), ShouldEqual, moira.StateOK)
+		So(GetState(settings, 120, band), ShouldEqual, moira.StateOK)
+		So(GetState(settings, 125, band), ShouldEqual, moira.StateWARN)
+		So(GetState(settings, 75, band), ShouldEqual, moira.StateWARN)
+		So(GetState(settings, 131, band), ShouldEqual, moira.StateERROR)
+		So(GetState(settings, 69, band), ShouldEqual, moira.StateERROR)
+	})
+
+	Convey("Up direction", t, func() {
+		settings := moira.AnomalySettings{WarnSensitivity: &warn, ErrorSensitivity: &errorSensitivity, Direction: moira.AnomalyDirectionUp}
+		So(GetState(settings, 131, band), ShouldEqual, moira.StateERROR)
+		So(GetState(settings, 69, band), ShouldEqual, moira.StateOK)
+	})
+
+	Convey("Down direction", t, func() {
+		settings := moira.AnomalySettings{WarnSensitivity: &warn, ErrorSensitivity: &errorSensitivity, Direction: moira.AnomalyDirectionDown}
+		So(GetState(settings, 131, band), ShouldEqual, moira.StateOK)
+		So(GetState(settings, 69, band), ShouldEqual, moira.StateERROR)
+	})
+
+	Convey("Only error sensitivity", t, func() {
+		settings := moira.AnomalySettings{ErrorSensitivity: &errorSensitivity}
+		So(GetState(settings, 125, band), ShouldEqual, moira.StateOK)
+		So(GetState(settings, 131, band), ShouldEqual, moira.StateERROR)
+	})
+}
+
+func TestBaseline(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	source := mock_metric_source.NewMockMetricSource(mockCtrl)
+	fetchResult := mock_metric_source.NewMockFetchResult(mockCtrl)
+
+	var from, until int64 = 10000, 10600
+	settings := moira.AnomalySettings{Season: 3600, Seasons: 2, Window: 120}
+
+	Convey("Fetch baseline and calculate bands", t, func() {
+		source.EXPECT().Fetch("target", from-3600-120, until-3600+120, true).Return(fetchResult, nil)
+		fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{
+			*metricSource.MakeMetricData("metric", []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 10}, 60, from-3600-120),
+		})
+		source.EXPECT().Fetch("target", from-7200-120, until-7200+120, true).Return(fetchResult, nil)
+		fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{
+			*metricSource.MakeMetricData("metric", []float64{20, 20, 20, 20, 20, 20, 20, 20, 20, 20, 20, 20, 20, 20, 20}, 60, from-7200-120),
+		})
+
+		baseline, err := FetchBaseline(source, "target", from, until, settings, true)
+		So(err, ShouldBeNil)
+
+		band, ok := baseline.GetBand("metric", from)
+		So(ok, ShouldBeTrue)
+		So(band, ShouldResemble, Band{Expected: 15, Deviation: 5})
+
+		_, ok = baseline.GetBand("other", from)
+		So(ok, ShouldBeFalse)
+
+		series := baseline.GetBandSeries(*metricSource.MakeMetricData("metric", []float64{1, 2}, 60, from))
+		So(series.Expected, ShouldResemble, []float64{15, 15})
+		lower, upper := series.GetBounds(2)
+		So(lower.Values, ShouldResemble, []float64{5, 5})
+		So(upper.Values, ShouldResemble, []float64{25, 25})
+
+		series = baseline.GetBandSeries(*metricSource.MakeMetricData("other", []float64{1}, 60, from))
+		So(math.IsNaN(series.Expected[0]), ShouldBeTrue)
+	})
+
+	Convey("Fetch error", t, func() {
+		fetchErr := fmt.Errorf("fetch error")
+		source.EXPECT().Fetch("target", from-3600-120, until-3600+120, true).Return(nil, fetchErr)
+		baseline, err := FetchBaseline(source, "target", from, until, settings, true)
+		So(err, ShouldResemble, fetchErr)
+		So(baseline, ShouldBeNil)
+	})
+
+	Convey("Not anomaly trigger has no bands", t, func() {
+		bands, err := GetTriggerBands(source, &moira.Trigger{TriggerType: moira.RisingTrigger, Targets: []string{"target"}}, from, until, nil, true)
+		So(err, ShouldBeNil)
+		So(bands, ShouldBeNil)
+	})
+}
@@ -1,15 +1,23 @@
 package api
 
+import "time"
+
 // Config for api configuration variables
 type Config struct {
 	EnableCORS bool
 	Listen     string
+	// ThrottlingPolicies are names of throttling policies configured in notifier which subscriptions can select
+	ThrottlingPolicies []string
+	// CheckIntervals are intervals of checker ticks of metric sources by source name, empty name is used for local source.
+	// Triggers can't be checked more often than their metric source tick
+	CheckIntervals map[string]time.Duration
 }
 
 // WebConfig is container for web ui configuration parameters
 type WebConfig struct {
 	SupportEmail  string       `json:"supportEmail,omitempty"`
 	RemoteAllowed bool         `json:"remoteAllowed"`
+	RemoteSources []string     `json:"remoteSources,omitempty"`
 	Contacts      []WebContact `json:"contacts"`
 }
 
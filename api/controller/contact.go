@@ -29,10 +29,11 @@ func GetAllContacts(database moira.Database) (*dto.ContactList, *api.ErrorRespon
 // CreateContact creates new notification contact for current user
 func CreateContact(dataBase moira.Database, contact *dto.Contact, userLogin string) *api.ErrorResponse {
 	contactData := moira.ContactData{
-		ID:    contact.ID,
-		User:  userLogin,
-		Type:  contact.Type,
-		Value: contact.Value,
+		ID:           contact.ID,
+		User:         userLogin,
+		Type:         contact.Type,
+		Value:        contact.Value,
+		DigestWindow: contact.DigestWindow,
 	}
 	if contactData.ID == "" {
 		uuid4, err := uuid.NewV4()
@@ -62,6 +63,7 @@ func CreateContact(dataBase moira.Database, contact *dto.Contact, userLogin stri
 func UpdateContact(dataBase moira.Database, contactDTO dto.Contact, contactData moira.ContactData) (dto.Contact, *api.ErrorResponse) {
 	contactData.Type = contactDTO.Type
 	contactData.Value = contactDTO.Value
+	contactData.DigestWindow = contactDTO.DigestWindow
 	if err := dataBase.SaveContact(&contactData); err != nil {
 		return contactDTO, api.ErrorInternalServer(err)
 	}
@@ -88,13 +90,17 @@ func RemoveContact(database moira.Database, contactID string, userLogin string)
 		if subscription == nil {
 			continue
 		}
+		isContactUsed := subscription.Escalation.HasContact(contactID)
 		for i, contact := range subscription.Contacts {
 			if contact == contactID {
 				subscription.Contacts = append(subscription.Contacts[:i], subscription.Contacts[i+1:]...)
-				subscriptionsWithDeletingContact = append(subscriptionsWithDeletingContact, subscription)
+				isContactUsed = true
 				break
 			}
 		}
+		if isContactUsed {
+			subscriptionsWithDeletingContact = append(subscriptionsWithDeletingContact, subscription)
+		}
 	}
 
 	if len(subscriptionsWithDeletingContact) > 0 {
@@ -236,6 +236,21 @@ func TestRemoveContact(t *testing.T) {
 			err := RemoveContact(dataBase, contactID, userLogin)
 			So(err, ShouldResemble, api.ErrorInvalidRequest(expectedError))
 		})
+		Convey("Subscription escalation has contact", func() {
+			subscription := moira.SubscriptionData{
+				Contacts: []string{uuid.Must(uuid.NewV4()).String()},
+				ID:       uuid.Must(uuid.NewV4()).String(),
+				Tags:     []string{"Tag1"},
+				Escalation: &moira.EscalationPolicy{
+					Tiers: []moira.EscalationTier{{Contacts: []string{contactID}, DelayInMinutes: 10}},
+				},
+			}
+			expectedError := fmt.Errorf("this contact is being used in following subscriptions: %s (tags: Tag1)", subscription.ID)
+			dataBase.EXPECT().GetUserSubscriptionIDs(userLogin).Return([]string{subscription.ID}, nil)
+			dataBase.EXPECT().GetSubscriptions([]string{subscription.ID}).Return([]*moira.SubscriptionData{&subscription}, nil)
+			err := RemoveContact(dataBase, contactID, userLogin)
+			So(err, ShouldResemble, api.ErrorInvalidRequest(expectedError))
+		})
 	})
 }
 
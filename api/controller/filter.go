@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetPrefixOffenders gets metric prefixes with the most points dropped by filter limits
+func GetPrefixOffenders(database moira.Database, size int64) (*dto.PrefixOffendersList, *api.ErrorResponse) {
+	if size <= 0 {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("size must be positive"))
+	}
+	offenders, err := database.GetPrefixOffenders(size)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	offendersList := &dto.PrefixOffendersList{
+		List: make([]moira.PrefixOffender, 0, len(offenders)),
+	}
+	for _, offender := range offenders {
+		offendersList.List = append(offendersList.List, *offender)
+	}
+	return offendersList, nil
+}
+
+// GetRejectedLines gets recently rejected by filter metric lines with given reason or with any reason if reason is empty
+func GetRejectedLines(database moira.Database, reason string) (*dto.RejectedLinesList, *api.ErrorResponse) {
+	lines, err := database.GetRejectedLines(reason)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	linesList := &dto.RejectedLinesList{
+		List: make([]moira.RejectedLine, 0, len(lines)),
+	}
+	for _, line := range lines {
+		linesList.List = append(linesList.List, *line)
+	}
+	return linesList, nil
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetPrefixOffenders(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Success", t, func() {
+		offenders := []*moira.PrefixOffender{
+			{Prefix: "first.service", DroppedByCardinality: 10, Timestamp: 100},
+			{Prefix: "second.service", DroppedByRate: 5, Timestamp: 200},
+		}
+		dataBase.EXPECT().GetPrefixOffenders(int64(20)).Return(offenders, nil)
+		list, err := GetPrefixOffenders(dataBase, 20)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.PrefixOffendersList{List: []moira.PrefixOffender{*offenders[0], *offenders[1]}})
+	})
+
+	Convey("Empty list", t, func() {
+		dataBase.EXPECT().GetPrefixOffenders(int64(20)).Return(nil, nil)
+		list, err := GetPrefixOffenders(dataBase, 20)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.PrefixOffendersList{List: []moira.PrefixOffender{}})
+	})
+
+	Convey("Invalid size", t, func() {
+		list, err := GetPrefixOffenders(dataBase, 0)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("size must be positive")))
+		So(list, ShouldBeNil)
+	})
+
+	Convey("Error", t, func() {
+		expected := fmt.Errorf("oooops! Can not get prefix offenders")
+		dataBase.EXPECT().GetPrefixOffenders(int64(20)).Return(nil, expected)
+		list, err := GetPrefixOffenders(dataBase, 20)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
+
+func TestGetRejectedLines(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Success", t, func() {
+		lines := []*moira.RejectedLine{
+			{Line: "metric 1", Reason: "items_count", Error: "too few space-separated items", Source: "127.0.0.1:1234", Timestamp: 200},
+			{Line: "metric one 1", Reason: "invalid_value", Error: "cannot parse value", Source: "127.0.0.1:1235", Timestamp: 100},
+		}
+		dataBase.EXPECT().GetRejectedLines("").Return(lines, nil)
+		list, err := GetRejectedLines(dataBase, "")
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.RejectedLinesList{List: []moira.RejectedLine{*lines[0], *lines[1]}})
+	})
+
+	Convey("Empty list", t, func() {
+		dataBase.EXPECT().GetRejectedLines("invalid_value").Return(nil, nil)
+		list, err := GetRejectedLines(dataBase, "invalid_value")
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.RejectedLinesList{List: []moira.RejectedLine{}})
+	})
+
+	Convey("Error", t, func() {
+		expected := fmt.Errorf("oooops! Can not get rejected lines")
+		dataBase.EXPECT().GetRejectedLines("").Return(nil, expected)
+		list, err := GetRejectedLines(dataBase, "")
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
@@ -19,6 +19,20 @@ func GetNotifications(database moira.Database, start int64, end int64) (*dto.Not
 	return &notificationsList, nil
 }
 
+// GetNotificationHistory gets page of notification sending attempts in given time range filtered by contact and trigger if they are not empty
+func GetNotificationHistory(database moira.Database, contactID, triggerID string, from, to, page, size int64) (*dto.NotificationHistoryList, *api.ErrorResponse) {
+	items, total, err := database.GetNotificationHistory(contactID, triggerID, from, to, page, size)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return &dto.NotificationHistoryList{
+		Page:  page,
+		Size:  size,
+		Total: total,
+		List:  items,
+	}, nil
+}
+
 // DeleteNotification removes all notifications by notification key
 func DeleteNotification(database moira.Database, notificationKey string) (*dto.NotificationDeleteResponse, *api.ErrorResponse) {
 	result, err := database.RemoveNotification(notificationKey)
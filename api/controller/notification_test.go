@@ -38,6 +38,30 @@ func TestGetNotifications(t *testing.T) {
 	})
 }
 
+func TestGetNotificationHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	var from int64 = 100
+	var to int64 = 200
+
+	Convey("Has history", t, func() {
+		items := []*moira.NotificationHistoryItem{{Timestamp: 150, ContactID: "contact", TriggerIDs: []string{"trigger"}, Attempt: 1, Success: true}}
+		dataBase.EXPECT().GetNotificationHistory("contact", "trigger", from, to, int64(0), int64(10)).Return(items, int64(1), nil)
+		list, err := GetNotificationHistory(dataBase, "contact", "trigger", from, to, 0, 10)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.NotificationHistoryList{Page: 0, Size: 10, Total: 1, List: items})
+	})
+
+	Convey("Test error", t, func() {
+		expected := fmt.Errorf("oooops! Can not get notification history")
+		dataBase.EXPECT().GetNotificationHistory("", "", from, to, int64(0), int64(10)).Return(nil, int64(0), expected)
+		list, err := GetNotificationHistory(dataBase, "", "", from, to, 0, 10)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
+
 func TestDeleteNotification(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -24,6 +24,11 @@ func UpdateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, triggerID
 
 // saveTrigger create or update trigger data and update trigger metrics in last state
 func saveTrigger(dataBase moira.Database, trigger *moira.Trigger, triggerID string, timeSeriesNames map[string]bool) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
+	if len(trigger.Parents) > 0 {
+		if errorResponse := checkTriggerParents(dataBase, triggerID, trigger.Parents); errorResponse != nil {
+			return nil, errorResponse
+		}
+	}
 	if err := dataBase.AcquireTriggerCheckLock(triggerID, 10); err != nil {
 		return nil, api.ErrorInternalServer(err)
 	}
@@ -51,7 +56,7 @@ func saveTrigger(dataBase moira.Database, trigger *moira.Trigger, triggerID stri
 		lastCheck.UpdateScore()
 	}
 
-	if err = dataBase.SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote); err != nil {
+	if err = dataBase.SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source); err != nil {
 		return nil, api.ErrorInternalServer(err)
 	}
 
@@ -66,6 +71,50 @@ func saveTrigger(dataBase moira.Database, trigger *moira.Trigger, triggerID stri
 	return &resp, nil
 }
 
+// checkTriggerParents checks that all parent triggers exist and trigger does not become its own ancestor
+func checkTriggerParents(dataBase moira.Database, triggerID string, parents []string) *api.ErrorResponse {
+	visited := make(map[string]bool)
+	level := make([]string, 0, len(parents))
+	for _, parentID := range parents {
+		if parentID == triggerID {
+			return api.ErrorInvalidRequest(fmt.Errorf("trigger can not be parent of itself"))
+		}
+		if !visited[parentID] {
+			visited[parentID] = true
+			level = append(level, parentID)
+		}
+	}
+
+	isDirectParents := true
+	for len(level) > 0 {
+		triggers, err := dataBase.GetTriggers(level)
+		if err != nil {
+			return api.ErrorInternalServer(err)
+		}
+		nextLevel := make([]string, 0)
+		for i, trigger := range triggers {
+			if trigger == nil {
+				if isDirectParents {
+					return api.ErrorInvalidRequest(fmt.Errorf("parent trigger %s not found", level[i]))
+				}
+				continue
+			}
+			for _, parentID := range trigger.Parents {
+				if parentID == triggerID {
+					return api.ErrorInvalidRequest(fmt.Errorf("trigger %s depends on this trigger, dependencies can not be cyclic", level[i]))
+				}
+				if !visited[parentID] {
+					visited[parentID] = true
+					nextLevel = append(nextLevel, parentID)
+				}
+			}
+		}
+		level = nextLevel
+		isDirectParents = false
+	}
+	return nil
+}
+
 // GetTrigger gets trigger with his throttling - next allowed message time
 func GetTrigger(dataBase moira.Database, triggerID string) (*dto.Trigger, *api.ErrorResponse) {
 	trigger, err := dataBase.GetTrigger(triggerID)
@@ -106,9 +155,43 @@ func GetTriggerThrottling(database moira.Database, triggerID string) (*dto.Throt
 	throttling, _ := database.GetTriggerThrottling(triggerID)
 	throttlingUnix := throttling.Unix()
 	if throttlingUnix < time.Now().Unix() {
-		throttlingUnix = 0
+		return &dto.ThrottlingResponse{Throttling: 0}, nil
+	}
+	reason, err := database.GetTriggerThrottlingReason(triggerID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
 	}
-	return &dto.ThrottlingResponse{Throttling: throttlingUnix}, nil
+	return &dto.ThrottlingResponse{Throttling: throttlingUnix, Reason: reason}, nil
+}
+
+// AckTrigger acknowledges bad state of whole trigger or of given metrics and stops escalations of acknowledged metrics
+func AckTrigger(dataBase moira.Database, triggerID string, triggerAck dto.TriggerAck, userLogin string, timeCallAck int64) *api.ErrorResponse {
+	lastCheck, err := dataBase.GetTriggerLastCheck(triggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return api.ErrorInvalidRequest(fmt.Errorf("trigger has not been checked yet"))
+		}
+		return api.ErrorInternalServer(err)
+	}
+	for _, metric := range triggerAck.Metrics {
+		if _, ok := lastCheck.Metrics[metric]; !ok {
+			return api.ErrorInvalidRequest(fmt.Errorf("metric '%s' not found in last check of trigger", metric))
+		}
+	}
+
+	ack := &moira.AckData{
+		User:      userLogin,
+		Comment:   triggerAck.Comment,
+		Timestamp: timeCallAck,
+		Expiry:    triggerAck.Expiry,
+	}
+	if err = dataBase.SetTriggerCheckAck(triggerID, triggerAck.Metrics, ack); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if err = dataBase.RemoveEscalations(triggerID, triggerAck.Metrics...); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
 }
 
 // GetTriggerLastCheck gets trigger last check data
@@ -132,6 +215,18 @@ func GetTriggerLastCheck(dataBase moira.Database, triggerID string) (*dto.Trigge
 	return &triggerCheck, nil
 }
 
+// GetTriggerDiagnostics gets diagnostics of latest trigger checks
+func GetTriggerDiagnostics(dataBase moira.Database, triggerID string) (*dto.TriggerDiagnostics, *api.ErrorResponse) {
+	diagnostics, err := dataBase.GetTriggerCheckDiagnostics(triggerID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return &dto.TriggerDiagnostics{
+		TriggerID: triggerID,
+		List:      diagnostics,
+	}, nil
+}
+
 // DeleteTriggerThrottling deletes trigger throttling
 func DeleteTriggerThrottling(database moira.Database, triggerID string) *api.ErrorResponse {
 	if err := database.DeleteTriggerThrottling(triggerID); err != nil {
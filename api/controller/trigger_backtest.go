@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/checker"
+	metricSource "github.com/moira-alert/moira/metric_source"
+)
+
+// maxBacktestChecks is a maximum number of trigger checks replayed by one backtest
+const maxBacktestChecks = 10000
+
+// BacktestTrigger replays trigger checks over metrics history and returns state transitions
+// without writing check data and events
+func BacktestTrigger(dataBase moira.Database, logger moira.Logger, metricSourceProvider *metricSource.SourceProvider, trigger *dto.TriggerModel, from, to, step int64) (*dto.TriggerBacktest, *api.ErrorResponse) {
+	if step <= 0 {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("step should be greater than zero"))
+	}
+	if from >= to {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("from should be less than to"))
+	}
+	if (to-from)/step > maxBacktestChecks {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("backtest can not replay more than %d checks, increase step or decrease time range", maxBacktestChecks))
+	}
+
+	moiraTrigger := trigger.ToMoiraTrigger()
+	source, err := metricSourceProvider.GetTriggerMetricSource(moiraTrigger)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	result, err := checker.Backtest(dataBase, logger, source, moiraTrigger, from, to, step)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+
+	backtest := &dto.TriggerBacktest{
+		Checks:  result.Checks,
+		State:   result.LastCheck.State,
+		Events:  result.Events,
+		Trigger: dto.BacktestStateCounts{States: make(map[moira.State]int64)},
+		Metrics: make(map[string]dto.BacktestStateCounts),
+	}
+	for _, event := range result.Events {
+		if event.IsTriggerEvent {
+			countBacktestEvent(&backtest.Trigger, event)
+			continue
+		}
+		counts, ok := backtest.Metrics[event.Metric]
+		if !ok {
+			counts = dto.BacktestStateCounts{States: make(map[moira.State]int64)}
+		}
+		countBacktestEvent(&counts, event)
+		backtest.Metrics[event.Metric] = counts
+	}
+	return backtest, nil
+}
+
+func countBacktestEvent(counts *dto.BacktestStateCounts, event moira.NotificationEvent) {
+	counts.Transitions++
+	counts.States[event.State]++
+}
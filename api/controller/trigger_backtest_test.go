@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/local"
+	mock_metric_source "github.com/moira-alert/moira/mock/metric_source"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestBacktestTrigger(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	localSource := mock_metric_source.NewMockMetricSource(mockCtrl)
+	remoteSource := mock_metric_source.NewMockMetricSource(mockCtrl)
+	sourceProvider := metricSource.CreateMetricSourceProvider(localSource, remoteSource)
+	logger, _ := logging.GetLogger("Test")
+
+	warnValue := float64(10)
+	errorValue := float64(20)
+	pattern := "super.puper.pattern"
+	metric := "super.puper.metric"
+	trigger := &dto.TriggerModel{
+		ID:          "triggerID",
+		Name:        "Super trigger",
+		WarnValue:   &warnValue,
+		ErrorValue:  &errorValue,
+		TriggerType: moira.RisingTrigger,
+		Targets:     []string{pattern},
+		Patterns:    []string{pattern},
+	}
+	var from int64 = 6000
+	var to int64 = 6300
+
+	Convey("Invalid step", t, func() {
+		backtest, err := BacktestTrigger(dataBase, logger, sourceProvider, trigger, from, to, 0)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("step should be greater than zero")))
+		So(backtest, ShouldBeNil)
+	})
+
+	Convey("Invalid time range", t, func() {
+		backtest, err := BacktestTrigger(dataBase, logger, sourceProvider, trigger, to, from, 60)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("from should be less than to")))
+		So(backtest, ShouldBeNil)
+	})
+
+	Convey("Too many checks", t, func() {
+		backtest, err := BacktestTrigger(dataBase, logger, sourceProvider, trigger, from, from+maxBacktestChecks*60+60, 60)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("backtest can not replay more than 10000 checks, increase step or decrease time range")))
+		So(backtest, ShouldBeNil)
+	})
+
+	Convey("Count state transitions", t, func() {
+		localSource.EXPECT().IsConfigured().Return(true, nil)
+		localSource.EXPECT().Fetch(pattern, gomock.Any(), gomock.Any(), true).DoAndReturn(func(target string, fetchFrom, fetchUntil int64, allowRealTimeAlerting bool) (metricSource.FetchResult, error) {
+			startTime := moira.RoundToNearestRetention(fetchFrom, 60)
+			values := make([]float64, 0)
+			for timestamp := startTime; timestamp <= fetchUntil; timestamp += 60 {
+				values = append(values, float64(timestamp-from)/10)
+			}
+			return &local.FetchResult{
+				MetricsData: []metricSource.MetricData{*metricSource.MakeMetricData(metric, values, 60, startTime)},
+				Patterns:    []string{pattern},
+				Metrics:     []string{metric},
+			}, nil
+		}).AnyTimes()
+		dataBase.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+
+		backtest, err := BacktestTrigger(dataBase, logger, sourceProvider, trigger, from, to, 60)
+		So(err, ShouldBeNil)
+		So(backtest.Checks, ShouldEqual, 5)
+		So(backtest.State, ShouldEqual, moira.StateOK)
+		So(backtest.Events, ShouldHaveLength, 3)
+		So(backtest.Trigger, ShouldResemble, dto.BacktestStateCounts{States: map[moira.State]int64{}})
+		So(backtest.Metrics, ShouldResemble, map[string]dto.BacktestStateCounts{
+			metric: {
+				Transitions: 3,
+				States:      map[moira.State]int64{moira.StateOK: 1, moira.StateWARN: 1, moira.StateERROR: 1},
+			},
+		})
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/dto"
 	"github.com/moira-alert/moira/database"
@@ -36,6 +37,18 @@ func GetTriggerEvaluationResult(dataBase moira.Database, metricSourceProvider *m
 	return triggerMetrics, &trigger, nil
 }
 
+// GetTriggerAnomalyBands returns bands of main target metrics of anomaly trigger, nil is returned for other triggers
+func GetTriggerAnomalyBands(metricSourceProvider *metricSource.SourceProvider, trigger *moira.Trigger, from, to int64, metricsData []metricSource.MetricData) ([]anomaly.BandSeries, error) {
+	if trigger.TriggerType != moira.AnomalyTrigger {
+		return nil, nil
+	}
+	metricsSource, err := metricSourceProvider.GetTriggerMetricSource(trigger)
+	if err != nil {
+		return nil, err
+	}
+	return anomaly.GetTriggerBands(metricsSource, trigger, from, to, metricsData, false)
+}
+
 // DeleteTriggerMetric deletes metric from last check and all trigger patterns metrics
 func DeleteTriggerMetric(dataBase moira.Database, metricName string, triggerID string) *api.ErrorResponse {
 	return deleteTriggerMetrics(dataBase, metricName, triggerID, false)
@@ -49,7 +62,7 @@ func DeleteTriggerNodataMetrics(dataBase moira.Database, triggerID string) *api.
 
 // GetTriggerMetrics gets all trigger metrics values, default values from: now - 10min, to: now
 func GetTriggerMetrics(dataBase moira.Database, metricSourceProvider *metricSource.SourceProvider, from, to int64, triggerID string) (*dto.TriggerMetrics, *api.ErrorResponse) {
-	tts, _, err := GetTriggerEvaluationResult(dataBase, metricSourceProvider, from, to, triggerID, false)
+	tts, trigger, err := GetTriggerEvaluationResult(dataBase, metricSourceProvider, from, to, triggerID, false)
 	if err != nil {
 		if err == database.ErrNil {
 			return nil, api.ErrorInvalidRequest(fmt.Errorf("trigger not found"))
@@ -61,21 +74,60 @@ func GetTriggerMetrics(dataBase moira.Database, metricSourceProvider *metricSour
 	for targetName, target := range tts {
 		targetMetrics := make(map[string][]moira.MetricValue)
 		for _, timeSeries := range target {
-			values := make([]moira.MetricValue, 0)
-			for i, l := 0, len(timeSeries.Values); i < l; i++ {
-				timestamp := timeSeries.StartTime + int64(i)*timeSeries.StepTime
-				value := timeSeries.GetTimestampValue(timestamp)
-				if moira.IsValidFloat64(value) {
-					values = append(values, moira.MetricValue{Value: value, Timestamp: timestamp})
-				}
-			}
-			targetMetrics[timeSeries.Name] = values
+			targetMetrics[timeSeries.Name] = getMetricValues(timeSeries)
 		}
 		triggerMetrics[targetName] = targetMetrics
 	}
+
+	bands, err := GetTriggerAnomalyBands(metricSourceProvider, trigger, from, to, tts["t1"])
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	addAnomalyBands(triggerMetrics, trigger, bands)
 	return &triggerMetrics, nil
 }
 
+// addAnomalyBands adds expected values and bounds of anomaly band as pseudo targets
+func addAnomalyBands(triggerMetrics dto.TriggerMetrics, trigger *moira.Trigger, bands []anomaly.BandSeries) {
+	if len(bands) == 0 {
+		return
+	}
+	expected := make(map[string][]moira.MetricValue)
+	for _, band := range bands {
+		expected[band.Name] = getMetricValues(band.GetExpected())
+	}
+	triggerMetrics[dto.AnomalyExpectedTarget] = expected
+
+	addBounds := func(sensitivity *float64, lowerTarget, upperTarget string) {
+		if sensitivity == nil {
+			return
+		}
+		lowerMetrics := make(map[string][]moira.MetricValue)
+		upperMetrics := make(map[string][]moira.MetricValue)
+		for _, band := range bands {
+			lower, upper := band.GetBounds(*sensitivity)
+			lowerMetrics[band.Name] = getMetricValues(lower)
+			upperMetrics[band.Name] = getMetricValues(upper)
+		}
+		triggerMetrics[lowerTarget] = lowerMetrics
+		triggerMetrics[upperTarget] = upperMetrics
+	}
+	addBounds(trigger.Anomaly.WarnSensitivity, dto.AnomalyWarnLowerTarget, dto.AnomalyWarnUpperTarget)
+	addBounds(trigger.Anomaly.ErrorSensitivity, dto.AnomalyErrorLowerTarget, dto.AnomalyErrorUpperTarget)
+}
+
+func getMetricValues(timeSeries metricSource.MetricData) []moira.MetricValue {
+	values := make([]moira.MetricValue, 0)
+	for i, l := 0, len(timeSeries.Values); i < l; i++ {
+		timestamp := timeSeries.StartTime + int64(i)*timeSeries.StepTime
+		value := timeSeries.GetTimestampValue(timestamp)
+		if moira.IsValidFloat64(value) {
+			values = append(values, moira.MetricValue{Value: value, Timestamp: timestamp})
+		}
+	}
+	return values
+}
+
 func deleteTriggerMetrics(dataBase moira.Database, metricName string, triggerID string, removeAllNodataMetrics bool) *api.ErrorResponse {
 	trigger, err := dataBase.GetTrigger(triggerID)
 	if err != nil {
@@ -113,7 +165,7 @@ func deleteTriggerMetrics(dataBase moira.Database, metricName string, triggerID
 	if err = dataBase.RemovePatternsMetrics(trigger.Patterns); err != nil {
 		return api.ErrorInternalServer(err)
 	}
-	if err = dataBase.SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote); err != nil {
+	if err = dataBase.SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source); err != nil {
 		return api.ErrorInternalServer(err)
 	}
 	return nil
@@ -40,7 +40,7 @@ func TestDeleteTriggerMetric(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.Source)
 		err := DeleteTriggerMetric(dataBase, "super.metric1", triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
@@ -53,7 +53,7 @@ func TestDeleteTriggerMetric(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.Source)
 		err := DeleteTriggerMetric(dataBase, "super.metric1", triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
@@ -117,7 +117,7 @@ func TestDeleteTriggerMetric(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(expected)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(expected)
 		err := DeleteTriggerMetric(dataBase, "super.metric1", triggerID)
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
@@ -175,7 +175,7 @@ func TestDeleteTriggerNodataMetrics(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.Source)
 		err := DeleteTriggerNodataMetrics(dataBase, triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
@@ -188,7 +188,7 @@ func TestDeleteTriggerNodataMetrics(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.Source)
 		err := DeleteTriggerNodataMetrics(dataBase, triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
@@ -201,7 +201,7 @@ func TestDeleteTriggerNodataMetrics(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheckWithoutNodata, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheckWithoutNodata, trigger.Source)
 		err := DeleteTriggerNodataMetrics(dataBase, triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, lastCheckWithoutNodata)
@@ -214,7 +214,7 @@ func TestDeleteTriggerNodataMetrics(t *testing.T) {
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
 		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.IsRemote)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck, trigger.Source)
 		err := DeleteTriggerNodataMetrics(dataBase, triggerID)
 		So(err, ShouldBeNil)
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
@@ -303,6 +303,26 @@ func TestGetTriggerMetrics(t *testing.T) {
 		So(*triggerMetrics, ShouldResemble, dto.TriggerMetrics{"t1": map[string][]moira.MetricValue{metric: {{Value: 0, Timestamp: 17}, {Value: 1, Timestamp: 27}, {Value: 2, Timestamp: 37}, {Value: 3, Timestamp: 47}, {Value: 4, Timestamp: 57}}}})
 	})
 
+	Convey("Anomaly trigger has band pseudo targets", t, func() {
+		errorSensitivity := float64(2)
+		anomalySettings := &moira.AnomalySettings{Season: 3600, Seasons: 1, Window: 600, ErrorSensitivity: &errorSensitivity}
+		dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{ID: triggerID, Targets: []string{pattern}, TriggerType: moira.AnomalyTrigger, Anomaly: anomalySettings}, nil)
+		localSource.EXPECT().IsConfigured().Return(true, nil).Times(2)
+		localSource.EXPECT().Fetch(pattern, from, until, false).Return(fetchResult, nil)
+		fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData(metric, []float64{0, 1}, retention, from)})
+		seasonFetchResult := mock_metric_source.NewMockFetchResult(mockCtrl)
+		localSource.EXPECT().Fetch(pattern, from-3600-600, until-3600+600, false).Return(seasonFetchResult, nil)
+		seasonFetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData(metric, []float64{5}, retention, from-3600)})
+		triggerMetrics, err := GetTriggerMetrics(dataBase, sourceProvider, from, until, triggerID)
+		So(err, ShouldBeNil)
+		So(*triggerMetrics, ShouldResemble, dto.TriggerMetrics{
+			"t1":                        map[string][]moira.MetricValue{metric: {{Value: 0, Timestamp: 17}, {Value: 1, Timestamp: 27}}},
+			dto.AnomalyExpectedTarget:   map[string][]moira.MetricValue{metric: {{Value: 5, Timestamp: 17}, {Value: 5, Timestamp: 27}}},
+			dto.AnomalyErrorLowerTarget: map[string][]moira.MetricValue{metric: {{Value: 5, Timestamp: 17}, {Value: 5, Timestamp: 27}}},
+			dto.AnomalyErrorUpperTarget: map[string][]moira.MetricValue{metric: {{Value: 5, Timestamp: 17}, {Value: 5, Timestamp: 27}}},
+		})
+	})
+
 	Convey("GetTrigger error", t, func() {
 		expected := fmt.Errorf("get trigger error")
 		dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{}, expected)
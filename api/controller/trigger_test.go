@@ -27,7 +27,7 @@ func TestUpdateTrigger(t *testing.T) {
 		dataBase.EXPECT().AcquireTriggerCheckLock(gomock.Any(), 10)
 		dataBase.EXPECT().DeleteTriggerCheckLock(gomock.Any())
 		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
-		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any(), trigger.IsRemote).Return(nil)
+		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any(), trigger.Source).Return(nil)
 		dataBase.EXPECT().SaveTrigger(gomock.Any(), trigger).Return(nil)
 		resp, err := UpdateTrigger(dataBase, &triggerModel, triggerModel.ID, make(map[string]bool))
 		So(err, ShouldBeNil)
@@ -73,7 +73,7 @@ func TestSaveTrigger(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -84,7 +84,7 @@ func TestSaveTrigger(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(actualLastCheck, nil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &actualLastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &actualLastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -98,7 +98,7 @@ func TestSaveTrigger(t *testing.T) {
 		dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-		dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.IsRemote).Return(nil)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.Source).Return(nil)
 		dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 		resp, err := saveTrigger(dataBase, &trigger, triggerID, map[string]bool{"super.metric1": true, "super.metric2": true})
 		So(err, ShouldBeNil)
@@ -130,7 +130,7 @@ func TestSaveTrigger(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.IsRemote).Return(expected)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.Source).Return(expected)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldResemble, api.ErrorInternalServer(expected))
 			So(resp, ShouldBeNil)
@@ -141,7 +141,7 @@ func TestSaveTrigger(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any(), trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(expected)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldResemble, api.ErrorInternalServer(expected))
@@ -150,6 +150,48 @@ func TestSaveTrigger(t *testing.T) {
 	})
 }
 
+func TestCheckTriggerParents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	triggerID := "child"
+
+	Convey("Parents without cycles", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"parent1", "parent2"}).Return([]*moira.Trigger{
+			{ID: "parent1", Parents: []string{"grandparent"}},
+			{ID: "parent2", Parents: []string{"grandparent", "removed"}},
+		}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"grandparent", "removed"}).Return([]*moira.Trigger{{ID: "grandparent"}, nil}, nil)
+		err := checkTriggerParents(dataBase, triggerID, []string{"parent1", "parent2", "parent1"})
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Trigger is parent of itself", t, func() {
+		err := checkTriggerParents(dataBase, triggerID, []string{"parent1", triggerID})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("trigger can not be parent of itself")))
+	})
+
+	Convey("Parent does not exist", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"parent1"}).Return([]*moira.Trigger{nil}, nil)
+		err := checkTriggerParents(dataBase, triggerID, []string{"parent1"})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("parent trigger parent1 not found")))
+	})
+
+	Convey("Cyclic dependencies", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"parent1"}).Return([]*moira.Trigger{{ID: "parent1", Parents: []string{"grandparent"}}}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"grandparent"}).Return([]*moira.Trigger{{ID: "grandparent", Parents: []string{triggerID}}}, nil)
+		err := checkTriggerParents(dataBase, triggerID, []string{"parent1"})
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("trigger grandparent depends on this trigger, dependencies can not be cyclic")))
+	})
+
+	Convey("GetTriggers error", t, func() {
+		expected := fmt.Errorf("getTriggers error")
+		dataBase.EXPECT().GetTriggers([]string{"parent1"}).Return(nil, expected)
+		err := checkTriggerParents(dataBase, triggerID, []string{"parent1"})
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
+
 func TestVariousTtlState(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -172,7 +214,7 @@ func TestVariousTtlState(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -187,7 +229,7 @@ func TestVariousTtlState(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -202,7 +244,7 @@ func TestVariousTtlState(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -217,7 +259,7 @@ func TestVariousTtlState(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -232,7 +274,7 @@ func TestVariousTtlState(t *testing.T) {
 			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10)
 			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
 			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, &lastCheck, trigger.Source).Return(nil)
 			dataBase.EXPECT().SaveTrigger(triggerID, &trigger).Return(nil)
 			resp, err := saveTrigger(dataBase, &trigger, triggerID, make(map[string]bool))
 			So(err, ShouldBeNil)
@@ -340,10 +382,21 @@ func TestGetTriggerThrottling(t *testing.T) {
 	})
 
 	Convey("has throttling", t, func() {
+		reason := "trigger switched 10 times in last 1h0m0s, notifications are delayed for 30m0s by throttling policy 'default'"
 		dataBase.EXPECT().GetTriggerThrottling(triggerID).Return(tomorrow, begging)
+		dataBase.EXPECT().GetTriggerThrottlingReason(triggerID).Return(reason, nil)
 		actual, err := GetTriggerThrottling(dataBase, triggerID)
 		So(err, ShouldBeNil)
-		So(actual, ShouldResemble, &dto.ThrottlingResponse{Throttling: tomorrow.Unix()})
+		So(actual, ShouldResemble, &dto.ThrottlingResponse{Throttling: tomorrow.Unix(), Reason: reason})
+	})
+
+	Convey("get throttling reason error", t, func() {
+		expected := fmt.Errorf("get throttling reason error")
+		dataBase.EXPECT().GetTriggerThrottling(triggerID).Return(tomorrow, begging)
+		dataBase.EXPECT().GetTriggerThrottlingReason(triggerID).Return("", expected)
+		actual, err := GetTriggerThrottling(dataBase, triggerID)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(actual, ShouldBeNil)
 	})
 
 	Convey("has old throttling", t, func() {
@@ -354,6 +407,29 @@ func TestGetTriggerThrottling(t *testing.T) {
 	})
 }
 
+func TestGetTriggerDiagnostics(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	triggerID := uuid.Must(uuid.NewV4()).String()
+
+	Convey("Success", t, func() {
+		diagnostics := []*moira.CheckDiagnostics{{Timestamp: 100, State: moira.StateEXCEPTION, Error: "remote server unavailable"}}
+		dataBase.EXPECT().GetTriggerCheckDiagnostics(triggerID).Return(diagnostics, nil)
+		actual, err := GetTriggerDiagnostics(dataBase, triggerID)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, &dto.TriggerDiagnostics{TriggerID: triggerID, List: diagnostics})
+	})
+
+	Convey("Error", t, func() {
+		expected := fmt.Errorf("oooops! Error get")
+		dataBase.EXPECT().GetTriggerCheckDiagnostics(triggerID).Return(nil, expected)
+		actual, err := GetTriggerDiagnostics(dataBase, triggerID)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(actual, ShouldBeNil)
+	})
+}
+
 func TestGetTriggerLastCheck(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -445,3 +521,55 @@ func TestSetTriggerMaintenance(t *testing.T) {
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
 }
+
+func TestAckTrigger(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	triggerID := uuid.Must(uuid.NewV4()).String()
+	lastCheck := moira.CheckData{
+		Metrics: map[string]moira.MetricState{
+			"Metric1": {State: moira.StateERROR},
+		},
+	}
+	ack := &moira.AckData{User: "user", Comment: "working on it", Timestamp: 12345, Expiry: 12445}
+
+	Convey("Success acknowledgement of trigger", t, func() {
+		triggerAck := dto.TriggerAck{Comment: ack.Comment, Expiry: ack.Expiry}
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		dataBase.EXPECT().SetTriggerCheckAck(triggerID, nil, ack).Return(nil)
+		dataBase.EXPECT().RemoveEscalations(triggerID).Return(nil)
+		err := AckTrigger(dataBase, triggerID, triggerAck, ack.User, ack.Timestamp)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Success acknowledgement of metrics", t, func() {
+		triggerAck := dto.TriggerAck{Metrics: []string{"Metric1"}, Comment: ack.Comment, Expiry: ack.Expiry}
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		dataBase.EXPECT().SetTriggerCheckAck(triggerID, triggerAck.Metrics, ack).Return(nil)
+		dataBase.EXPECT().RemoveEscalations(triggerID, "Metric1").Return(nil)
+		err := AckTrigger(dataBase, triggerID, triggerAck, ack.User, ack.Timestamp)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Unknown metric", t, func() {
+		triggerAck := dto.TriggerAck{Metrics: []string{"Metric2"}}
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		err := AckTrigger(dataBase, triggerID, triggerAck, ack.User, ack.Timestamp)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("metric 'Metric2' not found in last check of trigger")))
+	})
+
+	Convey("Trigger without last check", t, func() {
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
+		err := AckTrigger(dataBase, triggerID, dto.TriggerAck{}, ack.User, ack.Timestamp)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("trigger has not been checked yet")))
+	})
+
+	Convey("Error", t, func() {
+		expected := fmt.Errorf("oooops! Error set")
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		dataBase.EXPECT().SetTriggerCheckAck(triggerID, nil, ack).Return(expected)
+		err := AckTrigger(dataBase, triggerID, dto.TriggerAck{Comment: ack.Comment, Expiry: ack.Expiry}, ack.User, ack.Timestamp)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
@@ -21,8 +21,13 @@ type Contact struct {
 	Value string `json:"value"`
 	ID    string `json:"id,omitempty"`
 	User  string `json:"user,omitempty"`
+	// Duration in seconds notifications to contact are held and combined in one message for, zero disables digests
+	DigestWindow int64 `json:"digest_window,omitempty"`
 }
 
+// maxDigestWindow limits duration notifications can be held for to combine them into digest
+const maxDigestWindow int64 = 3600
+
 func (*Contact) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
@@ -34,5 +39,12 @@ func (contact *Contact) Bind(r *http.Request) error {
 	if contact.Value == "" {
 		return fmt.Errorf("contact value of type %s can not be empty", contact.Type)
 	}
+	return checkDigestWindow(contact.DigestWindow)
+}
+
+func checkDigestWindow(window int64) error {
+	if window < 0 || window > maxDigestWindow {
+		return fmt.Errorf("digest_window should be from 0 to %d seconds", maxDigestWindow)
+	}
 	return nil
 }
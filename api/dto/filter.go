@@ -0,0 +1,24 @@
+// nolint
+package dto
+
+import (
+	"net/http"
+
+	"github.com/moira-alert/moira"
+)
+
+type PrefixOffendersList struct {
+	List []moira.PrefixOffender `json:"list"`
+}
+
+func (*PrefixOffendersList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type RejectedLinesList struct {
+	List []moira.RejectedLine `json:"list"`
+}
+
+func (*RejectedLinesList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -23,3 +23,14 @@ type NotificationDeleteResponse struct {
 func (*NotificationDeleteResponse) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+type NotificationHistoryList struct {
+	Page  int64                            `json:"page"`
+	Size  int64                            `json:"size"`
+	Total int64                            `json:"total"`
+	List  []*moira.NotificationHistoryItem `json:"list"`
+}
+
+func (*NotificationHistoryList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
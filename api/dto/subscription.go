@@ -8,6 +8,7 @@ import (
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api/middleware"
+	"github.com/moira-alert/moira/notifier"
 )
 
 // ErrProvidedContactsForbidden used when user try to save subscription with another users contacts
@@ -55,9 +56,71 @@ func (subscription *Subscription) Bind(request *http.Request) error {
 	if len(subscription.Contacts) == 0 {
 		return fmt.Errorf("subscription must have contacts")
 	}
+	if err := checkDigestWindow(subscription.DigestWindow); err != nil {
+		return err
+	}
+	subscription.ThrottlingPolicy = strings.TrimSpace(subscription.ThrottlingPolicy)
+	if subscription.ThrottlingPolicy != "" && !subscription.ThrottlingEnabled {
+		return fmt.Errorf("throttling_policy can be set only if throttling is enabled")
+	}
+	if err := checkThrottlingPolicy(subscription.ThrottlingPolicy, middleware.GetThrottlingPolicies(request)); err != nil {
+		return err
+	}
+	if err := checkEscalation(subscription.Escalation); err != nil {
+		return err
+	}
 	return subscription.checkContacts(request)
 }
 
+// checkThrottlingPolicy checks that subscription selects default throttling policy or one of policies configured in notifier
+func checkThrottlingPolicy(policy string, configuredPolicies []string) error {
+	if policy == "" || policy == notifier.DefaultThrottlingPolicy {
+		return nil
+	}
+	for _, configuredPolicy := range configuredPolicies {
+		if policy == configuredPolicy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown throttling_policy '%s'", policy)
+}
+
+func checkEscalation(escalation *moira.EscalationPolicy) error {
+	if escalation == nil {
+		return nil
+	}
+	for i, tier := range escalation.Tiers {
+		if len(tier.Contacts) == 0 {
+			return fmt.Errorf("escalation tier %d must have contacts", i+1)
+		}
+		if tier.DelayInMinutes <= 0 {
+			return fmt.Errorf("escalation tier %d delay_in_minutes should be greater than 0", i+1)
+		}
+	}
+	return nil
+}
+
+// getContactIDs returns unique ids of subscription contacts and contacts of its escalation tiers
+func (subscription *Subscription) getContactIDs() []string {
+	contactIDs := make([]string, 0, len(subscription.Contacts))
+	added := make(map[string]bool)
+	addContacts := func(ids []string) {
+		for _, id := range ids {
+			if !added[id] {
+				added[id] = true
+				contactIDs = append(contactIDs, id)
+			}
+		}
+	}
+	addContacts(subscription.Contacts)
+	if subscription.Escalation != nil {
+		for _, tier := range subscription.Escalation.Tiers {
+			addContacts(tier.Contacts)
+		}
+	}
+	return contactIDs
+}
+
 func (subscription *Subscription) checkContacts(request *http.Request) error {
 	database := middleware.GetDatabase(request)
 	userLogin := middleware.GetLogin(request)
@@ -72,7 +135,7 @@ func (subscription *Subscription) checkContacts(request *http.Request) error {
 	}
 
 	anotherUserContactIds := make([]string, 0)
-	for _, subContactId := range subscription.Contacts {
+	for _, subContactId := range subscription.getContactIDs() {
 		if _, ok := userContactIdsHash[subContactId]; !ok {
 			anotherUserContactIds = append(anotherUserContactIds, subContactId)
 		}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/moira-alert/moira"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckThrottlingPolicy(t *testing.T) {
+	Convey("Default and configured throttling policies can be selected", t, func() {
+		configured := []string{"strict", "soft"}
+		So(checkThrottlingPolicy("", configured), ShouldBeNil)
+		So(checkThrottlingPolicy("default", nil), ShouldBeNil)
+		So(checkThrottlingPolicy("soft", configured), ShouldBeNil)
+		So(checkThrottlingPolicy("unknown", configured), ShouldResemble, fmt.Errorf("unknown throttling_policy 'unknown'"))
+		So(checkThrottlingPolicy("strict", nil), ShouldResemble, fmt.Errorf("unknown throttling_policy 'strict'"))
+	})
+}
+
+func TestSubscriptionEscalation(t *testing.T) {
+	Convey("Test escalation validation", t, func() {
+		So(checkEscalation(nil), ShouldBeNil)
+		So(checkEscalation(&moira.EscalationPolicy{}), ShouldBeNil)
+
+		escalation := &moira.EscalationPolicy{
+			Tiers: []moira.EscalationTier{
+				{Contacts: []string{"lead"}, DelayInMinutes: 10},
+				{Contacts: []string{"manager"}, DelayInMinutes: 30},
+			},
+		}
+		So(checkEscalation(escalation), ShouldBeNil)
+
+		escalation.Tiers[1].Contacts = nil
+		So(checkEscalation(escalation), ShouldResemble, fmt.Errorf("escalation tier 2 must have contacts"))
+
+		escalation.Tiers[1].Contacts = []string{"manager"}
+		escalation.Tiers[0].DelayInMinutes = 0
+		So(checkEscalation(escalation), ShouldResemble, fmt.Errorf("escalation tier 1 delay_in_minutes should be greater than 0"))
+	})
+
+	Convey("Contacts of escalation tiers are checked with subscription contacts", t, func() {
+		subscription := Subscription{
+			Contacts: []string{"first", "lead"},
+			Escalation: &moira.EscalationPolicy{
+				Tiers: []moira.EscalationTier{
+					{Contacts: []string{"lead"}, DelayInMinutes: 10},
+					{Contacts: []string{"manager", "lead"}, DelayInMinutes: 30},
+				},
+			},
+		}
+		So(subscription.getContactIDs(), ShouldResemble, []string{"first", "lead", "manager"})
+	})
+}
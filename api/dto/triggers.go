@@ -11,10 +11,12 @@ import (
 	"github.com/moira-alert/moira/templating"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/middleware"
 	"github.com/moira-alert/moira/expression"
 	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/prometheus"
 )
 
 var targetNameRegex = regexp.MustCompile("t(\\d+)")
@@ -53,7 +55,7 @@ type TriggerModel struct {
 	WarnValue *float64 `json:"warn_value"`
 	// ERROR threshold
 	ErrorValue *float64 `json:"error_value"`
-	// Could be: rising, falling, expression
+	// Could be: rising, falling, expression, anomaly
 	TriggerType string `json:"trigger_type"`
 	// Set of tags to manipulate subscriptions
 	Tags []string `json:"tags"`
@@ -73,6 +75,22 @@ type TriggerModel struct {
 	MuteNewMetrics bool `json:"mute_new_metrics"`
 	// A list of targets that have only alone metrics
 	AloneMetrics map[string]bool `json:"alone_metrics"`
+	// Determines how long new metric state must persist before event is emitted
+	Hysteresis *moira.Hysteresis `json:"hysteresis,omitempty"`
+	// Seasonal baseline settings of anomaly trigger
+	Anomaly *moira.AnomalySettings `json:"anomaly,omitempty"`
+	// IDs of triggers whose bad state suppresses events of this trigger
+	Parents []string `json:"parents,omitempty"`
+	// Minimal interval in seconds between trigger checks, zero means checker default
+	CheckInterval int64 `json:"check_interval,omitempty"`
+	// Order in which trigger is taken from checks queue: low, normal or high
+	Priority moira.TriggerPriority `json:"priority,omitempty"`
+	// Name of remote metric source of remote trigger, is_remote triggers without it use default remote source
+	Source string `json:"source,omitempty"`
+	// Intervals in seconds between reminders about WARN, ERROR, NODATA or EXCEPTION states, zero disables reminders about state
+	Reminders moira.ReminderIntervals `json:"reminders,omitempty"`
+	// Computes trigger state from share or count of metrics in WARN and ERROR states
+	Aggregation *moira.AggregationSettings `json:"aggregation,omitempty"`
 }
 
 // ToMoiraTrigger transforms TriggerModel to moira.Trigger
@@ -94,6 +112,14 @@ func (model *TriggerModel) ToMoiraTrigger() *moira.Trigger {
 		IsRemote:       model.IsRemote,
 		MuteNewMetrics: model.MuteNewMetrics,
 		AloneMetrics:   model.AloneMetrics,
+		Hysteresis:     model.Hysteresis,
+		Anomaly:        model.Anomaly,
+		Parents:        model.Parents,
+		CheckInterval:  model.CheckInterval,
+		Priority:       model.Priority,
+		Source:         model.Source,
+		Reminders:      model.Reminders,
+		Aggregation:    model.Aggregation,
 	}
 }
 
@@ -116,6 +142,14 @@ func CreateTriggerModel(trigger *moira.Trigger) TriggerModel {
 		IsRemote:       trigger.IsRemote,
 		MuteNewMetrics: trigger.MuteNewMetrics,
 		AloneMetrics:   trigger.AloneMetrics,
+		Hysteresis:     trigger.Hysteresis,
+		Anomaly:        trigger.Anomaly,
+		Parents:        trigger.Parents,
+		CheckInterval:  trigger.CheckInterval,
+		Priority:       trigger.Priority,
+		Source:         trigger.Source,
+		Reminders:      trigger.Reminders,
+		Aggregation:    trigger.Aggregation,
 	}
 }
 
@@ -133,6 +167,19 @@ func (trigger *Trigger) Bind(request *http.Request) error {
 	if err := checkWarnErrorExpression(trigger); err != nil {
 		return api.ErrInvalidRequestContent{ValidationError: err}
 	}
+	if trigger.TriggerType == moira.AnomalyTrigger {
+		// Defaults are saved with trigger, so anomaly history is checked and shown for actual settings
+		anomaly.ApplyDefaults(trigger.Anomaly)
+	}
+	if err := checkHysteresis(trigger.Hysteresis); err != nil {
+		return api.ErrInvalidRequestContent{ValidationError: err}
+	}
+	if err := trigger.Reminders.Validate(); err != nil {
+		return api.ErrInvalidRequestContent{ValidationError: err}
+	}
+	if err := checkAggregation(trigger.Aggregation); err != nil {
+		return api.ErrInvalidRequestContent{ValidationError: err}
+	}
 	for targetName := range trigger.AloneMetrics {
 		if !targetNameRegex.MatchString(targetName) {
 			return api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("alone metrics target name should be in pattern: t\\d+")}
@@ -156,18 +203,34 @@ func (trigger *Trigger) Bind(request *http.Request) error {
 		Expression:              &trigger.Expression,
 	}
 
+	if !trigger.IsRemote && trigger.Source != "" {
+		return api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("source can be set only for remote trigger, set is_remote to true")}
+	}
+	trigger.Source = moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source)
+	if err := checkCheckSettings(trigger, middleware.GetCheckIntervals(request)[trigger.Source]); err != nil {
+		return api.ErrInvalidRequestContent{ValidationError: err}
+	}
 	metricsSourceProvider := middleware.GetTriggerTargetsSourceProvider(request)
-	metricsSource, err := metricsSourceProvider.GetMetricSource(trigger.IsRemote)
+	metricsSource, err := metricsSourceProvider.GetMetricSource(trigger.Source)
 	if err != nil {
+		if _, ok := err.(metricSource.ErrUnknownMetricSource); ok {
+			return api.ErrInvalidRequestContent{ValidationError: err}
+		}
 		return err
 	}
 
 	if err := checkTTLSanity(trigger, metricsSource); err != nil {
 		return api.ErrInvalidRequestContent{ValidationError: err}
 	}
+	if err := checkAnomalyHistory(trigger, metricsSource); err != nil {
+		return api.ErrInvalidRequestContent{ValidationError: err}
+	}
 
 	metricsDataNames, err := resolvePatterns(trigger, &triggerExpression, metricsSource)
 	if err != nil {
+		if _, ok := err.(prometheus.ErrInvalidQuery); ok {
+			return api.ErrInvalidRequestContent{ValidationError: err}
+		}
 		return err
 	}
 	// TODO(litleleprikon): Remove after https://github.com/moira-alert/moira/issues/550 will be resolved
@@ -178,6 +241,9 @@ func (trigger *Trigger) Bind(request *http.Request) error {
 	}
 
 	middleware.SetTimeSeriesNames(request, metricsDataNames)
+	if trigger.TriggerType == moira.AnomalyTrigger {
+		return nil
+	}
 	if _, err := triggerExpression.Evaluate(); err != nil {
 		return err
 	}
@@ -198,6 +264,23 @@ func checkTTLSanity(trigger *Trigger, metricsSource metricSource.MetricSource) e
 	return nil
 }
 
+// checkAnomalyHistory checks that metrics source keeps metrics of all seasons used to calculate anomaly band
+func checkAnomalyHistory(trigger *Trigger, metricsSource metricSource.MetricSource) error {
+	if trigger.TriggerType != moira.AnomalyTrigger {
+		return nil
+	}
+	maximumAllowedHistory := metricsSource.GetMetricsTTLSeconds()
+	history := trigger.Anomaly.Season*int64(trigger.Anomaly.Seasons) + trigger.Anomaly.Window
+	if history > maximumAllowedHistory {
+		triggerType := "local"
+		if trigger.IsRemote {
+			triggerType = "remote"
+		}
+		return fmt.Errorf("anomaly history for %s trigger can't be more than %d seconds, got %d seconds", triggerType, maximumAllowedHistory, history)
+	}
+	return nil
+}
+
 func resolvePatterns(trigger *Trigger, expressionValues *expression.TriggerExpression, metricsSource metricSource.MetricSource) (map[string]bool, error) {
 	now := time.Now().Unix()
 	targetNum := 1
@@ -229,6 +312,13 @@ func resolvePatterns(trigger *Trigger, expressionValues *expression.TriggerExpre
 }
 
 func checkWarnErrorExpression(trigger *Trigger) error {
+	if trigger.TriggerType == moira.AnomalyTrigger {
+		return checkAnomalySettings(trigger)
+	}
+	if trigger.Anomaly != nil {
+		return fmt.Errorf("can't use 'anomaly' on trigger_type: '%v'", trigger.TriggerType)
+	}
+
 	if trigger.WarnValue == nil && trigger.ErrorValue == nil && trigger.Expression == "" {
 		return fmt.Errorf("at least one of error_value, warn_value or expression is required")
 	}
@@ -295,13 +385,106 @@ func checkWarnErrorExpression(trigger *Trigger) error {
 		}
 
 	default:
-		return fmt.Errorf("wrong trigger_type: %v, allowable values: '%v', '%v', '%v'",
-			trigger.TriggerType, moira.RisingTrigger, moira.FallingTrigger, moira.ExpressionTrigger)
+		return fmt.Errorf("wrong trigger_type: %v, allowable values: '%v', '%v', '%v', '%v'",
+			trigger.TriggerType, moira.RisingTrigger, moira.FallingTrigger, moira.ExpressionTrigger, moira.AnomalyTrigger)
 	}
 
 	return nil
 }
 
+func checkAnomalySettings(trigger *Trigger) error {
+	if trigger.Anomaly == nil {
+		return fmt.Errorf("trigger_type set to anomaly, but no anomaly settings provided")
+	}
+	if trigger.WarnValue != nil || trigger.ErrorValue != nil {
+		return fmt.Errorf("can't use 'warn_value' and 'error_value' on trigger_type: '%v'", moira.AnomalyTrigger)
+	}
+	if err := checkSimpleModeFields(trigger); err != nil {
+		return err
+	}
+
+	settings := trigger.Anomaly
+	if settings.WarnSensitivity == nil && settings.ErrorSensitivity == nil {
+		return fmt.Errorf("at least one of anomaly warn_sensitivity or error_sensitivity is required")
+	}
+	if (settings.WarnSensitivity != nil && *settings.WarnSensitivity <= 0) || (settings.ErrorSensitivity != nil && *settings.ErrorSensitivity <= 0) {
+		return fmt.Errorf("anomaly sensitivity should be greater than zero")
+	}
+	if settings.WarnSensitivity != nil && settings.ErrorSensitivity != nil && *settings.WarnSensitivity >= *settings.ErrorSensitivity {
+		return fmt.Errorf("anomaly error_sensitivity should be greater than warn_sensitivity")
+	}
+	if settings.Season < 0 || settings.Seasons < 0 || settings.Window < 0 {
+		return fmt.Errorf("anomaly season, seasons and window can not be negative")
+	}
+	switch settings.Direction {
+	case "", moira.AnomalyDirectionBoth, moira.AnomalyDirectionUp, moira.AnomalyDirectionDown:
+	default:
+		return fmt.Errorf("wrong anomaly direction: %v, allowable values: '%v', '%v', '%v'",
+			settings.Direction, moira.AnomalyDirectionBoth, moira.AnomalyDirectionUp, moira.AnomalyDirectionDown)
+	}
+	return nil
+}
+
+func checkHysteresis(hysteresis *moira.Hysteresis) error {
+	if hysteresis == nil {
+		return nil
+	}
+	if hysteresis.Worsening.Seconds < 0 || hysteresis.Worsening.Checks < 0 {
+		return fmt.Errorf("hysteresis worsening seconds and checks can not be negative")
+	}
+	if hysteresis.Recovering.Seconds < 0 || hysteresis.Recovering.Checks < 0 {
+		return fmt.Errorf("hysteresis recovering seconds and checks can not be negative")
+	}
+	return nil
+}
+
+// checkCheckSettings checks trigger check interval and priority. Trigger can't be checked more often than
+// checker ticks of its metric source, so nonzero check interval less than source check interval is rejected
+func checkCheckSettings(trigger *Trigger, sourceCheckInterval time.Duration) error {
+	if trigger.CheckInterval < 0 {
+		return fmt.Errorf("check_interval can not be negative")
+	}
+	if trigger.CheckInterval != 0 && time.Duration(trigger.CheckInterval)*time.Second < sourceCheckInterval {
+		return fmt.Errorf("check_interval can not be less than %d seconds, metric source is checked once in this period", int64(sourceCheckInterval.Seconds()))
+	}
+	switch trigger.Priority {
+	case "", moira.TriggerPriorityLow, moira.TriggerPriorityNormal, moira.TriggerPriorityHigh:
+		return nil
+	default:
+		return fmt.Errorf("priority can be only '%s', '%s' or '%s'", moira.TriggerPriorityLow, moira.TriggerPriorityNormal, moira.TriggerPriorityHigh)
+	}
+}
+
+func checkAggregation(aggregation *moira.AggregationSettings) error {
+	if aggregation == nil {
+		return nil
+	}
+	if aggregation.Mode != moira.AggregationModeShare && aggregation.Mode != moira.AggregationModeCount {
+		return fmt.Errorf("aggregation mode can be only '%s' or '%s'", moira.AggregationModeShare, moira.AggregationModeCount)
+	}
+	if aggregation.WarnValue == nil && aggregation.ErrorValue == nil {
+		return fmt.Errorf("aggregation warn_value or error_value is required")
+	}
+	for _, value := range []*float64{aggregation.WarnValue, aggregation.ErrorValue} {
+		if value == nil {
+			continue
+		}
+		if *value < 0 {
+			return fmt.Errorf("aggregation warn_value and error_value can not be negative")
+		}
+		if aggregation.Mode == moira.AggregationModeShare && *value >= 100 {
+			return fmt.Errorf("aggregation warn_value and error_value should be less than 100 percents")
+		}
+	}
+	if aggregation.WarnValue != nil && aggregation.ErrorValue != nil && *aggregation.WarnValue >= *aggregation.ErrorValue {
+		return fmt.Errorf("aggregation error_value should be greater than warn_value")
+	}
+	if aggregation.TopOffenders < moira.AggregationTopOffendersDisabled {
+		return fmt.Errorf("aggregation top_offenders can not be less than %d", moira.AggregationTopOffendersDisabled)
+	}
+	return nil
+}
+
 func checkSimpleModeFields(trigger *Trigger) error {
 	if len(trigger.Targets) > 1 {
 		return fmt.Errorf("can't use trigger_type not '%v' for with multiple targets", trigger.TriggerType)
@@ -356,8 +539,33 @@ func (*TriggerMaintenance) Bind(r *http.Request) error {
 	return nil
 }
 
+// TriggerAck is a request to acknowledge bad state of whole trigger or of given metrics
+type TriggerAck struct {
+	Metrics []string `json:"metrics,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+	// Expiry is a timestamp acknowledgement expires at, zero means acknowledgement is kept until recovery
+	Expiry int64 `json:"expiry,omitempty"`
+}
+
+func (ack *TriggerAck) Bind(r *http.Request) error {
+	if ack.Expiry != 0 && ack.Expiry <= time.Now().Unix() {
+		return fmt.Errorf("expiry should be in the future")
+	}
+	return nil
+}
+
+type TriggerDiagnostics struct {
+	TriggerID string                    `json:"trigger_id"`
+	List      []*moira.CheckDiagnostics `json:"list"`
+}
+
+func (*TriggerDiagnostics) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
 type ThrottlingResponse struct {
-	Throttling int64 `json:"throttling"`
+	Throttling int64  `json:"throttling"`
+	Reason     string `json:"reason,omitempty"`
 }
 
 func (*ThrottlingResponse) Render(w http.ResponseWriter, r *http.Request) error {
@@ -373,8 +581,38 @@ func (*SaveTriggerResponse) Render(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+// Pseudo targets of anomaly trigger metrics with expected values and bounds of band
+const (
+	AnomalyExpectedTarget   = "anomaly_expected"
+	AnomalyWarnLowerTarget  = "anomaly_warn_lower"
+	AnomalyWarnUpperTarget  = "anomaly_warn_upper"
+	AnomalyErrorLowerTarget = "anomaly_error_lower"
+	AnomalyErrorUpperTarget = "anomaly_error_upper"
+)
+
 type TriggerMetrics map[string]map[string][]moira.MetricValue
 
 func (*TriggerMetrics) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+// BacktestStateCounts is a number of state transitions and number of transitions to every state
+type BacktestStateCounts struct {
+	Transitions int64                 `json:"transitions"`
+	States      map[moira.State]int64 `json:"states"`
+}
+
+type TriggerBacktest struct {
+	// Number of replayed trigger checks
+	Checks int64 `json:"checks"`
+	// Trigger state after the last replayed check
+	State moira.State `json:"state"`
+	// State transitions which would have been sent
+	Events  []moira.NotificationEvent      `json:"events"`
+	Trigger BacktestStateCounts            `json:"trigger"`
+	Metrics map[string]BacktestStateCounts `json:"metrics"`
+}
+
+func (*TriggerBacktest) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
@@ -30,6 +31,7 @@ func TestTriggerValidation(t *testing.T) {
 		request.Header.Set("Content-Type", "application/json")
 		ctx := request.Context()
 		ctx = context.WithValue(ctx, middleware.ContextKey("metricSourceProvider"), sourceProvider)
+		ctx = context.WithValue(ctx, middleware.ContextKey("checkIntervals"), map[string]time.Duration{"": 5 * time.Second, moira.DefaultRemoteSource: time.Minute})
 		request = request.WithContext(ctx)
 
 		desc := "Graphite ClickHouse"
@@ -171,6 +173,70 @@ func TestTriggerValidation(t *testing.T) {
 			})
 		})
 
+		Convey("Test AnomalyTrigger", func() {
+			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(7 * 24 * 3600)).AnyTimes()
+			localSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			warnSensitivity := float64(2)
+			errorSensitivity := float64(3)
+			trigger.TriggerType = moira.AnomalyTrigger
+			trigger.Targets = []string{"test target"}
+
+			Convey("without anomaly settings", func() {
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("trigger_type set to anomaly, but no anomaly settings provided")})
+			})
+			Convey("with valid settings defaults are filled", func() {
+				trigger.Anomaly = &moira.AnomalySettings{Season: 3600, WarnSensitivity: &warnSensitivity, ErrorSensitivity: &errorSensitivity}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+				So(tr.Anomaly, ShouldResemble, &moira.AnomalySettings{
+					Season:           3600,
+					Seasons:          1,
+					Window:           600,
+					WarnSensitivity:  &warnSensitivity,
+					ErrorSensitivity: &errorSensitivity,
+					Direction:        moira.AnomalyDirectionBoth,
+				})
+			})
+			Convey("without sensitivity", func() {
+				trigger.Anomaly = &moira.AnomalySettings{}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("at least one of anomaly warn_sensitivity or error_sensitivity is required")})
+			})
+			Convey("with warn sensitivity greater than error sensitivity", func() {
+				trigger.Anomaly = &moira.AnomalySettings{WarnSensitivity: &errorSensitivity, ErrorSensitivity: &warnSensitivity}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("anomaly error_sensitivity should be greater than warn_sensitivity")})
+			})
+			Convey("with warn_value", func() {
+				trigger.Anomaly = &moira.AnomalySettings{WarnSensitivity: &warnSensitivity}
+				trigger.WarnValue = &warnValue
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("can't use 'warn_value' and 'error_value' on trigger_type: 'anomaly'")})
+			})
+			Convey("with wrong direction", func() {
+				trigger.Anomaly = &moira.AnomalySettings{WarnSensitivity: &warnSensitivity, Direction: "left"}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("wrong anomaly direction: left, allowable values: 'both', 'up', 'down'")})
+			})
+			Convey("with history longer than metrics TTL", func() {
+				trigger.Anomaly = &moira.AnomalySettings{WarnSensitivity: &warnSensitivity, Seasons: 2}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("anomaly history for local trigger can't be more than 604800 seconds, got 1210200 seconds")})
+			})
+		})
+
 		Convey("Test alone metrics", func() {
 			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
 			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
@@ -206,6 +272,179 @@ func TestTriggerValidation(t *testing.T) {
 			})
 		})
 
+		Convey("Test hysteresis", func() {
+			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+			localSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			trigger.Targets = []string{"test target"}
+			trigger.Expression = "OK"
+			Convey("is valid", func() {
+				trigger.Hysteresis = &moira.Hysteresis{
+					Worsening:  moira.PendingCondition{Checks: 3},
+					Recovering: moira.PendingCondition{Seconds: 600},
+				}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+			})
+			Convey("has negative value", func() {
+				trigger.Hysteresis = &moira.Hysteresis{Recovering: moira.PendingCondition{Seconds: -1}}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("hysteresis recovering seconds and checks can not be negative")})
+			})
+		})
+
+		Convey("Test check settings", func() {
+			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+			localSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			trigger.Targets = []string{"test target"}
+			trigger.Expression = "OK"
+			Convey("are valid", func() {
+				trigger.CheckInterval = 10
+				trigger.Priority = moira.TriggerPriorityHigh
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+			})
+			Convey("have negative check interval", func() {
+				trigger.CheckInterval = -1
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("check_interval can not be negative")})
+			})
+			Convey("have check interval less than local check interval", func() {
+				trigger.CheckInterval = 1
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("check_interval can not be less than 5 seconds, metric source is checked once in this period")})
+			})
+			Convey("have check interval less than remote check interval", func() {
+				remoteSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+				trigger.IsRemote = true
+				trigger.CheckInterval = 30
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("check_interval can not be less than 60 seconds, metric source is checked once in this period")})
+			})
+			Convey("have unknown priority", func() {
+				trigger.Priority = "urgent"
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("priority can be only 'low', 'normal' or 'high'")})
+			})
+		})
+
+		Convey("Test reminders", func() {
+			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+			localSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			trigger.Targets = []string{"test target"}
+			trigger.Expression = "OK"
+			Convey("are valid", func() {
+				trigger.Reminders = moira.ReminderIntervals{moira.StateWARN: 3600, moira.StateNODATA: 0}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+			})
+			Convey("have not bad state", func() {
+				trigger.Reminders = moira.ReminderIntervals{moira.StateOK: 3600}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("reminders can be set only for WARN, ERROR, NODATA and EXCEPTION states")})
+			})
+			Convey("have too short interval", func() {
+				trigger.Reminders = moira.ReminderIntervals{moira.StateERROR: 600}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("reminder interval should be zero or whole number of hours (multiple of 3600 seconds)")})
+			})
+		})
+
+		Convey("Test aggregation", func() {
+			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+			localSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			trigger.Targets = []string{"test target"}
+			trigger.Expression = "OK"
+			warnValue, errorValue := float64(10), float64(50)
+			Convey("is valid", func() {
+				trigger.Aggregation = &moira.AggregationSettings{Mode: moira.AggregationModeShare, WarnValue: &warnValue, ErrorValue: &errorValue, MuteMetrics: true}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+			})
+			Convey("has unknown mode", func() {
+				trigger.Aggregation = &moira.AggregationSettings{Mode: "sum", WarnValue: &warnValue}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("aggregation mode can be only 'share' or 'count'")})
+			})
+			Convey("has no thresholds", func() {
+				trigger.Aggregation = &moira.AggregationSettings{Mode: moira.AggregationModeCount}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("aggregation warn_value or error_value is required")})
+			})
+			Convey("has share threshold above 100 percents", func() {
+				share := float64(100)
+				trigger.Aggregation = &moira.AggregationSettings{Mode: moira.AggregationModeShare, ErrorValue: &share}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("aggregation warn_value and error_value should be less than 100 percents")})
+			})
+			Convey("has warn threshold greater than error one", func() {
+				trigger.Aggregation = &moira.AggregationSettings{Mode: moira.AggregationModeCount, WarnValue: &errorValue, ErrorValue: &warnValue}
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("aggregation error_value should be greater than warn_value")})
+			})
+		})
+
+		Convey("Test remote source", func() {
+			remoteSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
+			remoteSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+			remoteSource.EXPECT().Fetch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(fetchResult, nil).AnyTimes()
+			fetchResult.EXPECT().GetPatterns().Return(make([]string, 0), nil).AnyTimes()
+			fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData("", []float64{}, 0, 0)}).AnyTimes()
+
+			trigger.Targets = []string{"test target"}
+			trigger.Expression = "OK"
+			Convey("is default for remote trigger without source", func() {
+				trigger.IsRemote = true
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldBeNil)
+				So(tr.Source, ShouldEqual, moira.DefaultRemoteSource)
+			})
+			Convey("is not allowed for local trigger", func() {
+				trigger.Source = "prometheus"
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: fmt.Errorf("source can be set only for remote trigger, set is_remote to true")})
+			})
+			Convey("is unknown", func() {
+				trigger.IsRemote = true
+				trigger.Source = "prometheus"
+				tr := Trigger{trigger, throttling}
+				err := tr.Bind(request)
+				So(err, ShouldResemble, api.ErrInvalidRequestContent{ValidationError: metricSource.ErrUnknownMetricSource{Name: "prometheus"}})
+			})
+		})
+
 		Convey("Test patterns", func() {
 			localSource.EXPECT().IsConfigured().Return(true, nil).AnyTimes()
 			localSource.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/middleware"
+)
+
+func filter(router chi.Router) {
+	router.With(middleware.Paginate(0, 20)).Get("/offenders", getPrefixOffenders)
+	router.Get("/rejected", getRejectedLines)
+}
+
+func getPrefixOffenders(writer http.ResponseWriter, request *http.Request) {
+	size := middleware.GetSize(request)
+	offendersList, err := controller.GetPrefixOffenders(database, size)
+	if err != nil {
+		render.Render(writer, request, err) //nolint
+		return
+	}
+	if err := render.Render(writer, request, offendersList); err != nil {
+		render.Render(writer, request, api.ErrorRender(err)) //nolint
+	}
+}
+
+func getRejectedLines(writer http.ResponseWriter, request *http.Request) {
+	reason := request.URL.Query().Get("reason")
+	linesList, err := controller.GetRejectedLines(database, reason)
+	if err != nil {
+		render.Render(writer, request, err) //nolint
+		return
+	}
+	if err := render.Render(writer, request, linesList); err != nil {
+		render.Render(writer, request, api.ErrorRender(err)) //nolint
+	}
+}
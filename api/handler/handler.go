@@ -37,14 +37,15 @@ func NewHandler(db moira.Database, log moira.Logger, index moira.Searcher, confi
 		router.Use(moiramiddle.DatabaseContext(database))
 		router.Get("/config", getWebConfig(webConfigContent))
 		router.Route("/user", user)
-		router.Route("/trigger", triggers(metricSourceProvider, searchIndex))
+		router.With(moiramiddle.CheckIntervalsContext(config.CheckIntervals)).Route("/trigger", triggers(metricSourceProvider, searchIndex))
 		router.Route("/tag", tag)
 		router.Route("/pattern", pattern)
 		router.Route("/event", event)
 		router.Route("/contact", contact)
-		router.Route("/subscription", subscription)
+		router.With(moiramiddle.ThrottlingPoliciesContext(config.ThrottlingPolicies)).Route("/subscription", subscription)
 		router.Route("/notification", notification)
 		router.Route("/health", health)
+		router.Route("/filter", filter)
 	})
 	if config.EnableCORS {
 		return cors.AllowAll().Handler(router)
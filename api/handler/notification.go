@@ -4,15 +4,19 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
+	"github.com/go-graphite/carbonapi/date"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/middleware"
 )
 
 func notification(router chi.Router) {
 	router.Get("/", getNotification)
+	router.With(middleware.DateRange("-1day", "now"), middleware.Paginate(0, 100)).Get("/history", getNotificationHistory)
 	router.Delete("/", deleteNotification)
 	router.Delete("/all", deleteAllNotifications)
 }
@@ -37,6 +41,34 @@ func getNotification(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func getNotificationHistory(writer http.ResponseWriter, request *http.Request) {
+	fromStr := middleware.GetFromStr(request)
+	toStr := middleware.GetToStr(request)
+	from := date.DateParamToEpoch(fromStr, "UTC", 0, time.UTC)
+	if from == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("can not parse from: %s", fromStr))) //nolint
+		return
+	}
+	to := date.DateParamToEpoch(toStr, "UTC", 0, time.UTC)
+	if to == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("can not parse to: %s", toStr))) //nolint
+		return
+	}
+	contactID := request.URL.Query().Get("contact")
+	triggerID := request.URL.Query().Get("trigger")
+	page := middleware.GetPage(request)
+	size := middleware.GetSize(request)
+
+	history, errorResponse := controller.GetNotificationHistory(database, contactID, triggerID, from, to, page, size)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse) //nolint
+		return
+	}
+	if err := render.Render(writer, request, history); err != nil {
+		render.Render(writer, request, api.ErrorRender(err)) //nolint
+	}
+}
+
 func deleteNotification(writer http.ResponseWriter, request *http.Request) {
 	notificationKey := request.URL.Query().Get("id")
 	if notificationKey == "" {
@@ -24,12 +24,14 @@ func trigger(router chi.Router) {
 		middleware.Populate(false)).Get("/", getTrigger)
 	router.Delete("/", removeTrigger)
 	router.Get("/state", getTriggerState)
+	router.Get("/diagnostics", getTriggerDiagnostics)
 	router.Route("/throttling", func(router chi.Router) {
 		router.Get("/", getTriggerThrottling)
 		router.Delete("/", deleteThrottling)
 	})
 	router.Route("/metrics", triggerMetrics)
 	router.Put("/setMaintenance", setTriggerMaintenance)
+	router.Post("/ack", ackTrigger)
 	router.With(middleware.DateRange("-1hour", "now")).With(middleware.TargetName("t1")).Get("/render", renderTrigger)
 }
 
@@ -131,6 +133,18 @@ func getTriggerState(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func getTriggerDiagnostics(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	diagnostics, err := controller.GetTriggerDiagnostics(database, triggerID)
+	if err != nil {
+		render.Render(writer, request, err) //nolint
+		return
+	}
+	if err := render.Render(writer, request, diagnostics); err != nil {
+		render.Render(writer, request, api.ErrorRender(err)) //nolint
+	}
+}
+
 func getTriggerThrottling(writer http.ResponseWriter, request *http.Request) {
 	triggerID := middleware.GetTriggerID(request)
 	triggerState, err := controller.GetTriggerThrottling(database, triggerID)
@@ -166,3 +180,19 @@ func setTriggerMaintenance(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, err) //nolint
 	}
 }
+
+func ackTrigger(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	triggerAck := dto.TriggerAck{}
+	if err := render.Bind(request, &triggerAck); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err)) //nolint
+		return
+	}
+	userLogin := middleware.GetLogin(request)
+	timeCallAck := time.Now().Unix()
+
+	err := controller.AckTrigger(database, triggerID, triggerAck, userLogin, timeCallAck)
+	if err != nil {
+		render.Render(writer, request, err) //nolint
+	}
+}
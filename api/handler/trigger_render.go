@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/render"
 	"github.com/go-graphite/carbonapi/date"
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/controller"
 	"github.com/moira-alert/moira/api/middleware"
@@ -38,7 +39,16 @@ func renderTrigger(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, api.ErrorNotFound(fmt.Sprintf("Cannot find target %s", targetName))) //nolint
 	}
 
-	renderable, err := buildRenderable(request, trigger, targetMetrics, targetName)
+	var bands []anomaly.BandSeries
+	if targetName == "t1" {
+		bands, err = controller.GetTriggerAnomalyBands(sourceProvider, trigger, from, to, targetMetrics)
+		if err != nil {
+			render.Render(writer, request, api.ErrorInternalServer(err)) //nolint
+			return
+		}
+	}
+
+	renderable, err := buildRenderable(request, trigger, targetMetrics, bands, targetName)
 	if err != nil {
 		render.Render(writer, request, api.ErrorInternalServer(err)) //nolint
 		return
@@ -86,7 +96,7 @@ func evaluateTargetMetrics(metricSourceProvider *metricSource.SourceProvider, fr
 	return tts, trigger, err
 }
 
-func buildRenderable(request *http.Request, trigger *moira.Trigger, metricsData []metricSource.MetricData, targetName string) (*chart.Chart, error) {
+func buildRenderable(request *http.Request, trigger *moira.Trigger, metricsData []metricSource.MetricData, bands []anomaly.BandSeries, targetName string) (*chart.Chart, error) {
 	timezone := request.URL.Query().Get("timezone")
 	location, err := time.LoadLocation(timezone)
 	if err != nil {
@@ -97,7 +107,7 @@ func buildRenderable(request *http.Request, trigger *moira.Trigger, metricsData
 	if err != nil {
 		return nil, fmt.Errorf("can not initialize plot theme %s", err.Error())
 	}
-	renderable, err := plotTemplate.GetRenderable(targetName, trigger, metricsData)
+	renderable, err := plotTemplate.GetRenderable(targetName, trigger, metricsData, bands)
 	if err != nil {
 		return nil, err
 	}
@@ -6,9 +6,11 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
+	"github.com/go-graphite/carbonapi/date"
 	"github.com/moira-alert/moira"
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
@@ -27,6 +29,7 @@ func triggers(metricSourceProvider *metricSource.SourceProvider, searcher moira.
 		router.Use(middleware.SearchIndexContext(searcher))
 		router.Get("/", getAllTriggers)
 		router.Put("/", createTrigger)
+		router.With(middleware.DateRange("-1day", "now")).Post("/backtest", backtestTrigger)
 		router.Route("/{triggerId}", trigger)
 		router.With(middleware.Paginate(0, 10)).With(middleware.Pager(false, "")).Get("/search", searchTriggers)
 		// ToDo: DEPRECATED method. Remove in Moira 2.6
@@ -86,6 +89,59 @@ func createTrigger(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
+func backtestTrigger(writer http.ResponseWriter, request *http.Request) {
+	trigger := &dto.Trigger{}
+	if err := render.Bind(request, trigger); err != nil {
+		switch err.(type) {
+		case local.ErrParseExpr, local.ErrEvalExpr, local.ErrUnknownFunction:
+			render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("invalid graphite targets: %s", err.Error()))) //nolint
+		case expression.ErrInvalidExpression:
+			render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("invalid expression: %s", err.Error()))) //nolint
+		case api.ErrInvalidRequestContent:
+			render.Render(writer, request, api.ErrorInvalidRequest(err)) //nolint
+		case remote.ErrRemoteTriggerResponse:
+			render.Render(writer, request, api.ErrorRemoteServerUnavailable(err)) //nolint
+		default:
+			render.Render(writer, request, api.ErrorInternalServer(err)) //nolint
+		}
+		return
+	}
+
+	fromStr := middleware.GetFromStr(request)
+	toStr := middleware.GetToStr(request)
+	from := date.DateParamToEpoch(fromStr, "UTC", 0, time.UTC)
+	if from == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("can not parse from: %s", fromStr))) //nolint
+		return
+	}
+	to := date.DateParamToEpoch(toStr, "UTC", 0, time.UTC)
+	if to == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("can not parse to: %s", toStr))) //nolint
+		return
+	}
+	step := int64(60) //nolint
+	if stepStr := request.URL.Query().Get("step"); stepStr != "" {
+		var err error
+		if step, err = strconv.ParseInt(stepStr, 10, 64); err != nil {
+			render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("can not parse step: %s", stepStr))) //nolint
+			return
+		}
+	}
+
+	metricSourceProvider := middleware.GetTriggerTargetsSourceProvider(request)
+	logger := middleware.GetLoggerEntry(request)
+	backtest, errorResponse := controller.BacktestTrigger(database, logger, metricSourceProvider, &trigger.TriggerModel, from, to, step)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse) //nolint
+		return
+	}
+
+	if err := render.Render(writer, request, backtest); err != nil {
+		render.Render(writer, request, api.ErrorRender(err)) //nolint
+		return
+	}
+}
+
 func searchTriggers(writer http.ResponseWriter, request *http.Request) {
 	request.ParseForm() //nolint
 	onlyErrors := getOnlyProblemsFlag(request)
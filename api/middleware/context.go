@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
@@ -104,6 +105,26 @@ func MetricSourceProvider(sourceProvider *metricSource.SourceProvider) func(next
 	}
 }
 
+// ThrottlingPoliciesContext sets to requests context names of throttling policies configured in notifier
+func ThrottlingPoliciesContext(policies []string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := context.WithValue(request.Context(), policiesKey, policies)
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
+// CheckIntervalsContext sets to requests context intervals of checker ticks of metric sources
+func CheckIntervalsContext(checkIntervals map[string]time.Duration) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			ctx := context.WithValue(request.Context(), checkIntervalsKey, checkIntervals)
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
+}
+
 // Paginate gets page and size values from URI query and set it to request context. If query has not values sets given values
 func Paginate(defaultPage, defaultSize int64) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/moira-alert/moira"
 	metricSource "github.com/moira-alert/moira/metric_source"
@@ -33,6 +34,8 @@ var (
 	timeSeriesNamesKey   ContextKey = "timeSeriesNames"
 	metricSourceProvider ContextKey = "metricSourceProvider"
 	targetNameKey        ContextKey = "target"
+	policiesKey          ContextKey = "throttlingPolicies"
+	checkIntervalsKey    ContextKey = "checkIntervals"
 )
 
 // GetDatabase gets moira.Database realization from request context
@@ -120,3 +123,15 @@ func GetTriggerTargetsSourceProvider(request *http.Request) *metricSource.Source
 func GetTargetName(request *http.Request) string {
 	return request.Context().Value(targetNameKey).(string)
 }
+
+// GetThrottlingPolicies gets names of throttling policies configured in notifier
+func GetThrottlingPolicies(request *http.Request) []string {
+	policies, _ := request.Context().Value(policiesKey).([]string)
+	return policies
+}
+
+// GetCheckIntervals gets intervals of checker ticks of metric sources by source name
+func GetCheckIntervals(request *http.Request) map[string]time.Duration {
+	checkIntervals, _ := request.Context().Value(checkIntervalsKey).(map[string]time.Duration)
+	return checkIntervals
+}
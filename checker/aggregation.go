@@ -0,0 +1,70 @@
+package checker
+
+import (
+	"sort"
+
+	"github.com/moira-alert/moira"
+)
+
+// aggregateMetricStates computes state of trigger with aggregation from states of its metrics.
+// Trigger without aggregation is always OK
+func (triggerChecker *TriggerChecker) aggregateMetricStates(metrics map[string]moira.MetricState) (moira.State, *moira.AggregationInfo) {
+	aggregation := triggerChecker.trigger.Aggregation
+	if aggregation == nil {
+		return moira.StateOK, nil
+	}
+	var warnCount, errorCount int
+	badMetrics := make([]string, 0)
+	for metricName, metricState := range metrics {
+		switch metricState.State {
+		case moira.StateWARN:
+			warnCount++
+		case moira.StateERROR:
+			errorCount++
+		default:
+			continue
+		}
+		badMetrics = append(badMetrics, metricName)
+	}
+	triggerChecker.sortOffenders(badMetrics, metrics)
+	if topOffenders := aggregation.GetTopOffenders(); len(badMetrics) > topOffenders {
+		badMetrics = badMetrics[:topOffenders]
+	}
+	return aggregation.GetState(warnCount, errorCount, len(metrics)), &moira.AggregationInfo{
+		BadMetrics:   warnCount + errorCount,
+		TotalMetrics: len(metrics),
+		TopOffenders: badMetrics,
+	}
+}
+
+// sortOffenders sorts metrics from the worst one: ERROR goes before WARN,
+// then metrics of rising and falling triggers are ordered by how far their values went in the bad direction
+func (triggerChecker *TriggerChecker) sortOffenders(metricNames []string, metrics map[string]moira.MetricState) {
+	sort.Slice(metricNames, func(i, j int) bool {
+		first, second := metrics[metricNames[i]], metrics[metricNames[j]]
+		if first.State != second.State {
+			return first.State.IsWorseThan(second.State)
+		}
+		firstValue, firstOk := getFirstTargetValue(first)
+		secondValue, secondOk := getFirstTargetValue(second)
+		if firstOk && secondOk && firstValue != secondValue {
+			switch triggerChecker.trigger.TriggerType {
+			case moira.RisingTrigger:
+				return firstValue > secondValue
+			case moira.FallingTrigger:
+				return firstValue < secondValue
+			}
+		}
+		return metricNames[i] < metricNames[j]
+	})
+}
+
+func getFirstTargetValue(metricState moira.MetricState) (float64, bool) {
+	if value, ok := metricState.Values["t1"]; ok {
+		return value, true
+	}
+	if metricState.Value != nil {
+		return *metricState.Value, true
+	}
+	return 0, false
+}
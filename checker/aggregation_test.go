@@ -0,0 +1,121 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/moira-alert/moira"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAggregateMetricStates(t *testing.T) {
+	errorValue := float64(30)
+	metrics := map[string]moira.MetricState{
+		"host1": {State: moira.StateOK, Values: map[string]float64{"t1": 1}},
+		"host2": {State: moira.StateWARN, Values: map[string]float64{"t1": 15}},
+		"host3": {State: moira.StateERROR, Values: map[string]float64{"t1": 25}},
+		"host4": {State: moira.StateERROR, Values: map[string]float64{"t1": 30}},
+		"host5": {State: moira.StateNODATA},
+	}
+
+	Convey("Trigger without aggregation is OK", t, func() {
+		triggerChecker := TriggerChecker{trigger: &moira.Trigger{}}
+		state, info := triggerChecker.aggregateMetricStates(metrics)
+		So(state, ShouldEqual, moira.StateOK)
+		So(info, ShouldBeNil)
+	})
+
+	Convey("Trigger with aggregation", t, func() {
+		triggerChecker := TriggerChecker{trigger: &moira.Trigger{
+			TriggerType: moira.RisingTrigger,
+			Aggregation: &moira.AggregationSettings{Mode: moira.AggregationModeShare, ErrorValue: &errorValue, TopOffenders: 2},
+		}}
+
+		Convey("switches to ERROR and lists worst metrics", func() {
+			state, info := triggerChecker.aggregateMetricStates(metrics)
+			So(state, ShouldEqual, moira.StateERROR)
+			So(info, ShouldResemble, &moira.AggregationInfo{
+				BadMetrics:   3,
+				TotalMetrics: 5,
+				TopOffenders: []string{"host4", "host3"},
+			})
+		})
+
+		Convey("stays OK below threshold", func() {
+			triggerChecker.trigger.Aggregation.TopOffenders = 0
+			state, info := triggerChecker.aggregateMetricStates(map[string]moira.MetricState{
+				"host1": {State: moira.StateOK},
+				"host2": {State: moira.StateERROR},
+				"host3": {State: moira.StateOK},
+				"host4": {State: moira.StateOK},
+			})
+			So(state, ShouldEqual, moira.StateOK)
+			So(info.TopOffenders, ShouldResemble, []string{"host2"})
+		})
+
+		Convey("orders falling trigger metrics by lowest value", func() {
+			triggerChecker.trigger.TriggerType = moira.FallingTrigger
+			_, info := triggerChecker.aggregateMetricStates(metrics)
+			So(info.TopOffenders, ShouldResemble, []string{"host3", "host4"})
+		})
+
+		Convey("does not list metrics if top offenders are disabled", func() {
+			triggerChecker.trigger.Aggregation.TopOffenders = moira.AggregationTopOffendersDisabled
+			_, info := triggerChecker.aggregateMetricStates(metrics)
+			So(info.TopOffenders, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestCompareStatesWithAggregation(t *testing.T) {
+	Convey("Test compare states of trigger with aggregation", t, func() {
+		dataBase, mockCtrl := newMocks(t)
+		defer mockCtrl.Finish()
+		logger, _ := logging.GetLogger("Test")
+		errorValue := float64(0)
+
+		triggerChecker := TriggerChecker{
+			triggerID: "SuperId",
+			database:  dataBase,
+			logger:    logger,
+			trigger: &moira.Trigger{
+				Name:        "Super trigger",
+				Aggregation: &moira.AggregationSettings{Mode: moira.AggregationModeCount, ErrorValue: &errorValue, MuteMetrics: true},
+			},
+			lastCheck: &moira.CheckData{State: moira.StateOK, Timestamp: 100},
+		}
+
+		Convey("Metric events are muted", func() {
+			lastState := moira.MetricState{State: moira.StateOK, Timestamp: 100, EventTimestamp: 100}
+			currentState := moira.MetricState{State: moira.StateERROR, Timestamp: 160}
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: "SuperId",
+				State:     moira.StateERROR,
+				OldState:  moira.StateOK,
+				Timestamp: 160,
+				Metric:    "host1",
+				IsMuted:   true,
+			}, true).Return(nil)
+			actual, err := triggerChecker.compareMetricStates("host1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.State, ShouldEqual, moira.StateERROR)
+			So(actual.EventTimestamp, ShouldEqual, 160)
+		})
+
+		Convey("Trigger event contains aggregation info", func() {
+			triggerChecker.aggregation = &moira.AggregationInfo{BadMetrics: 1, TotalMetrics: 2, TopOffenders: []string{"host1"}}
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				IsTriggerEvent:   true,
+				TriggerID:        "SuperId",
+				State:            moira.StateERROR,
+				OldState:         moira.StateOK,
+				Timestamp:        160,
+				Metric:           "Super trigger",
+				MessageEventInfo: &moira.EventInfo{Aggregation: triggerChecker.aggregation},
+			}, true).Return(nil)
+			actual, err := triggerChecker.compareTriggerStates(moira.CheckData{State: moira.StateERROR, Timestamp: 160})
+			So(err, ShouldBeNil)
+			So(actual.EventTimestamp, ShouldEqual, 160)
+		})
+	})
+}
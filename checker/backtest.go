@@ -0,0 +1,91 @@
+package checker
+
+import (
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metrics"
+)
+
+// BacktestResult is a result of trigger checks replayed over history
+type BacktestResult struct {
+	// Checks is a number of performed trigger checks
+	Checks int64
+	// Events are trigger and metric state transitions which would have been sent
+	Events []moira.NotificationEvent
+	// LastCheck is a check data of the last trigger check
+	LastCheck moira.CheckData
+}
+
+// backtestDatabase keeps check data and events in memory instead of writing them to database.
+// Other methods are passed to underlying database
+type backtestDatabase struct {
+	moira.Database
+	lastCheck moira.CheckData
+	events    []moira.NotificationEvent
+}
+
+// SetTriggerLastCheck keeps check data to use it as last check of the next trigger check
+func (db *backtestDatabase) SetTriggerLastCheck(triggerID string, checkData *moira.CheckData, source string) error {
+	db.lastCheck = *checkData
+	return nil
+}
+
+// PushNotificationEvent collects event instead of sending it to notifier
+func (db *backtestDatabase) PushNotificationEvent(event *moira.NotificationEvent, ui bool) error {
+	db.events = append(db.events, *event)
+	return nil
+}
+
+// RemovePatternsMetrics does nothing, stored metrics are not changed by backtest
+func (db *backtestDatabase) RemovePatternsMetrics(pattern []string) error {
+	return nil
+}
+
+// RemoveMetricsValues does nothing, stored metrics are not changed by backtest
+func (db *backtestDatabase) RemoveMetricsValues(metrics []string, toTime int64) error {
+	return nil
+}
+
+// Backtest replays trigger checks from 'from' to 'until' with given step using historical metrics of source.
+// Check data and events are not written to database, they are returned as result
+func Backtest(dataBase moira.Database, logger moira.Logger, source metricSource.MetricSource, trigger *moira.Trigger, from, until, step int64) (*BacktestResult, error) {
+	backtestDataBase := &backtestDatabase{
+		Database: dataBase,
+		lastCheck: moira.CheckData{
+			Metrics:   make(map[string]moira.MetricState),
+			State:     moira.StateOK,
+			Timestamp: from,
+		},
+	}
+	var remoteSources []string
+	if trigger.IsRemote {
+		remoteSources = []string{moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source)}
+	}
+	checkMetrics, _ := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), remoteSources).GetCheckMetrics(trigger)
+	triggerChecker := &TriggerChecker{
+		database:  backtestDataBase,
+		logger:    logger,
+		config:    &Config{},
+		metrics:   checkMetrics,
+		source:    source,
+		triggerID: trigger.ID,
+		trigger:   trigger,
+		ttl:       trigger.TTL,
+		ttlState:  getTTLState(trigger.TTLState),
+	}
+
+	result := &BacktestResult{}
+	for checkTimestamp := from + step; checkTimestamp <= until; checkTimestamp += step {
+		lastCheck := backtestDataBase.lastCheck
+		triggerChecker.lastCheck = &lastCheck
+		triggerChecker.from = calculateFrom(lastCheck.Timestamp, trigger.TTL)
+		triggerChecker.until = checkTimestamp
+		if err := triggerChecker.Check(); err != nil {
+			return nil, err
+		}
+		result.Checks++
+	}
+	result.Events = backtestDataBase.events
+	result.LastCheck = backtestDataBase.lastCheck
+	return result, nil
+}
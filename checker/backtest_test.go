@@ -0,0 +1,83 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/local"
+	mock_metric_source "github.com/moira-alert/moira/mock/metric_source"
+	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBacktest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	source := mock_metric_source.NewMockMetricSource(mockCtrl)
+	logger, _ := logging.GetLogger("Test")
+
+	var warnValue float64 = 10
+	var errorValue float64 = 20
+	var retention int64 = 60
+	pattern := "super.puper.pattern"
+	metric := "super.puper.metric"
+	trigger := &moira.Trigger{
+		ID:          "triggerID",
+		Name:        "Super trigger",
+		WarnValue:   &warnValue,
+		ErrorValue:  &errorValue,
+		TriggerType: moira.RisingTrigger,
+		Targets:     []string{pattern},
+		Patterns:    []string{pattern},
+	}
+
+	// Metric is OK for the first 10 minutes, then WARN, ERROR and OK again
+	var from int64 = 6000
+	var until int64 = from + 2400
+	getValue := func(timestamp int64) float64 {
+		switch {
+		case timestamp < from+600:
+			return 5
+		case timestamp < from+1200:
+			return 15
+		case timestamp < from+1800:
+			return 25
+		default:
+			return 5
+		}
+	}
+	source.EXPECT().Fetch(pattern, gomock.Any(), gomock.Any(), true).DoAndReturn(func(target string, fetchFrom, fetchUntil int64, allowRealTimeAlerting bool) (metricSource.FetchResult, error) {
+		startTime := moira.RoundToNearestRetention(fetchFrom, retention)
+		values := make([]float64, 0)
+		for timestamp := startTime; timestamp <= fetchUntil; timestamp += retention {
+			values = append(values, getValue(timestamp))
+		}
+		return &local.FetchResult{
+			MetricsData: []metricSource.MetricData{*metricSource.MakeMetricData(metric, values, retention, startTime)},
+			Patterns:    []string{pattern},
+			Metrics:     []string{metric},
+		}, nil
+	}).AnyTimes()
+	dataBase.EXPECT().GetMetricsTTLSeconds().Return(int64(3600)).AnyTimes()
+
+	Convey("Replay trigger checks without writing to database", t, func() {
+		result, err := Backtest(dataBase, logger, source, trigger, from, until, 60)
+		So(err, ShouldBeNil)
+		So(result.Checks, ShouldEqual, 40)
+		So(result.LastCheck.State, ShouldEqual, moira.StateOK)
+		So(result.LastCheck.Metrics[metric].State, ShouldEqual, moira.StateOK)
+
+		states := make([]moira.State, 0)
+		for _, event := range result.Events {
+			So(event.Metric, ShouldEqual, metric)
+			states = append(states, event.State)
+		}
+		So(states, ShouldResemble, []moira.State{moira.StateOK, moira.StateWARN, moira.StateERROR, moira.StateOK})
+		So(result.Events[1].Timestamp, ShouldEqual, from+600)
+		So(result.Events[2].Timestamp, ShouldEqual, from+1200)
+	})
+}
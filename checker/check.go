@@ -2,12 +2,15 @@ package checker
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/checker/metrics/conversion"
 	"github.com/moira-alert/moira/expression"
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
+	"github.com/moira-alert/moira/metric_source/prometheus"
 	"github.com/moira-alert/moira/metric_source/remote"
 )
 
@@ -18,7 +21,16 @@ const (
 
 // Check handle trigger and last check and write new state of trigger, if state were change then write new NotificationEvent
 func (triggerChecker *TriggerChecker) Check() error {
+	startTime := time.Now()
+	triggerChecker.startDiagnostics()
+	err := triggerChecker.checkTrigger()
+	triggerChecker.saveDiagnostics(time.Since(startTime))
+	return err
+}
+
+func (triggerChecker *TriggerChecker) checkTrigger() error {
 	passError := false
+	triggerChecker.aggregation = nil
 	triggerChecker.logger.Debugf("Checking trigger %s", triggerChecker.triggerID)
 	checkData := newCheckData(triggerChecker.lastCheck, triggerChecker.until)
 	triggerMetricsData, err := triggerChecker.fetchTriggerMetrics()
@@ -41,7 +53,7 @@ func (triggerChecker *TriggerChecker) Check() error {
 	}
 
 	if !passError {
-		checkData.State = moira.StateOK
+		checkData.State, triggerChecker.aggregation = triggerChecker.aggregateMetricStates(checkData.Metrics)
 	}
 	checkData.LastSuccessfulCheckTimestamp = checkData.Timestamp
 	if checkData.LastSuccessfulCheckTimestamp != 0 {
@@ -51,13 +63,14 @@ func (triggerChecker *TriggerChecker) Check() error {
 		}
 	}
 	checkData.UpdateScore()
-	return triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, &checkData, triggerChecker.trigger.IsRemote)
+	return triggerChecker.setLastCheck(&checkData)
 }
 
 // handlePrepareError is a function that checks error returned from prepareMetrics function. If error
 // is not serious and check process can be continued first return value became true and Filled CheckData returned.
 // in the other case first return value became true and error passed to this function is handled.
 func (triggerChecker *TriggerChecker) handlePrepareError(checkData moira.CheckData, err error) (bool, moira.CheckData, error) {
+	triggerChecker.setErrorDiagnostics(err)
 	switch err.(type) {
 	case ErrTriggerHasSameMetricNames:
 		checkData.State = moira.StateERROR
@@ -76,11 +89,12 @@ func (triggerChecker *TriggerChecker) handlePrepareError(checkData moira.CheckDa
 		return false, checkData, err
 	}
 	checkData.UpdateScore()
-	return false, checkData, triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, &checkData, triggerChecker.trigger.IsRemote)
+	return false, checkData, triggerChecker.setLastCheck(&checkData)
 }
 
 // handleFetchError is a function that checks error returned from fetchTriggerMetrics function.
 func (triggerChecker *TriggerChecker) handleFetchError(checkData moira.CheckData, err error) error {
+	triggerChecker.setErrorDiagnostics(err)
 	switch err.(type) {
 	case ErrTriggerHasEmptyTargets, ErrTriggerHasOnlyWildcards:
 		triggerChecker.logger.Debugf("Trigger %s: %s", triggerChecker.triggerID, err.Error())
@@ -91,7 +105,7 @@ func (triggerChecker *TriggerChecker) handleFetchError(checkData moira.CheckData
 			// Do not alert when user don't wanna receive
 			// NODATA state alerts, but change trigger status
 			checkData.UpdateScore()
-			return triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, &checkData, triggerChecker.trigger.IsRemote)
+			return triggerChecker.setLastCheck(&checkData)
 		}
 	case remote.ErrRemoteTriggerResponse:
 		timeSinceLastSuccessfulCheck := checkData.Timestamp - checkData.LastSuccessfulCheckTimestamp
@@ -101,7 +115,7 @@ func (triggerChecker *TriggerChecker) handleFetchError(checkData moira.CheckData
 			checkData, err = triggerChecker.compareTriggerStates(checkData)
 		}
 		triggerChecker.logger.Warning(formatTriggerCheckException(triggerChecker.triggerID, err))
-	case local.ErrUnknownFunction, local.ErrEvalExpr:
+	case local.ErrUnknownFunction, local.ErrEvalExpr, prometheus.ErrInvalidQuery:
 		checkData.State = moira.StateEXCEPTION
 		checkData.Message = err.Error()
 		triggerChecker.logger.Warning(formatTriggerCheckException(triggerChecker.triggerID, err))
@@ -113,11 +127,12 @@ func (triggerChecker *TriggerChecker) handleFetchError(checkData moira.CheckData
 		return err
 	}
 	checkData.UpdateScore()
-	return triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, &checkData, triggerChecker.trigger.IsRemote)
+	return triggerChecker.setLastCheck(&checkData)
 }
 
 // handleUndefinedError is a function that check error with undefined type.
 func (triggerChecker *TriggerChecker) handleUndefinedError(checkData moira.CheckData, err error) error {
+	triggerChecker.setErrorDiagnostics(err)
 	triggerChecker.metrics.CheckError.Mark(1)
 	triggerChecker.logger.Errorf("Trigger %s check failed: %s", triggerChecker.triggerID, err.Error())
 	checkData, err = triggerChecker.compareTriggerStates(checkData)
@@ -125,7 +140,13 @@ func (triggerChecker *TriggerChecker) handleUndefinedError(checkData moira.Check
 		return err
 	}
 	checkData.UpdateScore()
-	return triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, &checkData, triggerChecker.trigger.IsRemote)
+	return triggerChecker.setLastCheck(&checkData)
+}
+
+// setLastCheck saves check data as trigger last check
+func (triggerChecker *TriggerChecker) setLastCheck(checkData *moira.CheckData) error {
+	triggerChecker.setCheckDataDiagnostics(checkData)
+	return triggerChecker.database.SetTriggerLastCheck(triggerChecker.triggerID, checkData, triggerChecker.trigger.Source)
 }
 
 func formatTriggerCheckException(triggerID string, err error) string {
@@ -195,6 +216,7 @@ func (triggerChecker *TriggerChecker) prepareMetrics(fetchedMetrics map[string][
 	populated := preparedPatternMetrics.Populate(*triggerChecker.lastCheck, triggerChecker.from, triggerChecker.until)
 
 	multiMetricTargets, aloneMetrics := populated.FilterAloneMetrics()
+	triggerChecker.setPrepareDiagnostics(conversion.GetRelations(aloneMetrics), duplicates)
 
 	if err := triggerChecker.validateAloneMetrics(aloneMetrics); err != nil {
 		return nil, nil, err
@@ -357,6 +379,17 @@ func (triggerChecker *TriggerChecker) getMetricDataState(metricName *string, met
 	}
 	triggerChecker.logger.Debugf("[TriggerID:%s][MetricName:%s] Values for ts %v: MainTargetValue: %v, additionalTargetValues: %v", triggerChecker.triggerID, metricName, valueTimestamp, triggerExpression.MainTargetValue, triggerExpression.AdditionalTargetsValues)
 
+	if triggerChecker.trigger.TriggerType == moira.AnomalyTrigger {
+		anomalyState := triggerChecker.getAnomalyState(*metrics, triggerExpression.MainTargetValue, *valueTimestamp)
+		triggerChecker.setMetricDiagnostics(*metricName, *valueTimestamp, values, anomalyState, nil)
+		return newMetricState(
+			*lastState,
+			anomalyState,
+			*valueTimestamp,
+			values,
+		), nil
+	}
+
 	triggerExpression.WarnValue = triggerChecker.trigger.WarnValue
 	triggerExpression.ErrorValue = triggerChecker.trigger.ErrorValue
 	triggerExpression.TriggerType = triggerChecker.trigger.TriggerType
@@ -364,6 +397,7 @@ func (triggerChecker *TriggerChecker) getMetricDataState(metricName *string, met
 	triggerExpression.Expression = triggerChecker.trigger.Expression
 
 	expressionState, err := triggerExpression.Evaluate()
+	triggerChecker.setMetricDiagnostics(*metricName, *valueTimestamp, values, expressionState, err)
 	if err != nil {
 		return nil, err
 	}
@@ -376,6 +410,18 @@ func (triggerChecker *TriggerChecker) getMetricDataState(metricName *string, met
 	), nil
 }
 
+// getAnomalyState compares main target value with band of previous seasons, metric without band is considered OK
+func (triggerChecker *TriggerChecker) getAnomalyState(metrics map[string]metricSource.MetricData, value float64, valueTimestamp int64) moira.State {
+	if triggerChecker.baseline == nil || triggerChecker.trigger.Anomaly == nil {
+		return moira.StateOK
+	}
+	band, ok := triggerChecker.baseline.GetBand(metrics["t1"].Name, valueTimestamp)
+	if !ok {
+		return moira.StateOK
+	}
+	return anomaly.GetState(*triggerChecker.trigger.Anomaly, value, band)
+}
+
 func getExpressionValues(metrics *map[string]metricSource.MetricData, valueTimestamp *int64) (*expression.TriggerExpression, map[string]float64, bool) {
 	expression := &expression.TriggerExpression{
 		AdditionalTargetsValues: make(map[string]float64, len(*metrics)-1),
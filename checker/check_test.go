@@ -8,6 +8,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/checker/metrics/conversion"
 	"github.com/moira-alert/moira/expression"
 	metricSource "github.com/moira-alert/moira/metric_source"
@@ -23,7 +24,7 @@ func TestGetMetricDataState(t *testing.T) {
 	logger, _ := logging.GetLogger("Test")
 	var warnValue float64 = 10
 	var errValue float64 = 20
-	checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), false)
+	checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil)
 	triggerChecker := TriggerChecker{
 		logger:  logger,
 		metrics: checkerMetrics.LocalMetrics,
@@ -119,6 +120,66 @@ func TestGetMetricDataState(t *testing.T) {
 	})
 }
 
+func TestGetMetricDataStateAnomaly(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	source := mock_metric_source.NewMockMetricSource(mockCtrl)
+	fetchResult := mock_metric_source.NewMockFetchResult(mockCtrl)
+	logger, _ := logging.GetLogger("Test")
+
+	warnSensitivity := 2.0
+	errorSensitivity := 4.0
+	settings := moira.AnomalySettings{Season: 3600, Seasons: 1, Window: 20, WarnSensitivity: &warnSensitivity, ErrorSensitivity: &errorSensitivity}
+	triggerChecker := TriggerChecker{
+		logger: logger,
+		until:  67,
+		from:   17,
+		trigger: &moira.Trigger{
+			TriggerType: moira.AnomalyTrigger,
+			Targets:     []string{"pattern"},
+			Anomaly:     &settings,
+		},
+	}
+
+	source.EXPECT().Fetch("pattern", triggerChecker.from-3600-20, triggerChecker.until-3600+20, false).Return(fetchResult, nil)
+	fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{
+		*metricSource.MakeMetricData("main.metric", []float64{8, 12, 8, 12, 8, 12, 8, 12, 8, 12}, 10, triggerChecker.from-3600-20),
+	})
+	baseline, err := anomaly.FetchBaseline(source, "pattern", triggerChecker.from, triggerChecker.until, settings, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triggerChecker.baseline = baseline
+
+	metricName := "main.metric"
+	metrics := map[string]metricSource.MetricData{
+		"t1": *metricSource.MakeMetricData(metricName, []float64{10, 15, 20, 3}, 10, triggerChecker.from),
+	}
+	metricLastState := moira.MetricState{State: moira.StateOK}
+	var checkPoint int64
+
+	Convey("Anomaly trigger compares values with band of previous season", t, func() {
+		expectedStates := []moira.State{moira.StateOK, moira.StateWARN, moira.StateERROR, moira.StateWARN}
+		for i, expectedState := range expectedStates {
+			valueTimestamp := triggerChecker.from + int64(i)*10
+			metricState, err := triggerChecker.getMetricDataState(&metricName, &metrics, &metricLastState, &valueTimestamp, &checkPoint)
+			So(err, ShouldBeNil)
+			So(metricState.State, ShouldEqual, expectedState)
+		}
+	})
+
+	Convey("Metric without band is OK", t, func() {
+		otherMetricName := "other.metric"
+		otherMetrics := map[string]metricSource.MetricData{
+			"t1": *metricSource.MakeMetricData(otherMetricName, []float64{100}, 10, triggerChecker.from),
+		}
+		valueTimestamp := triggerChecker.from
+		metricState, err := triggerChecker.getMetricDataState(&otherMetricName, &otherMetrics, &metricLastState, &valueTimestamp, &checkPoint)
+		So(err, ShouldBeNil)
+		So(metricState.State, ShouldEqual, moira.StateOK)
+	})
+}
+
 func TestTriggerChecker_PrepareMetrics(t *testing.T) {
 	logger, _ := logging.GetLogger("Test")
 	Convey("Prepare metrics for check:", t, func() {
@@ -519,7 +580,7 @@ func TestCheckForNODATA(t *testing.T) {
 
 	var ttl int64 = 600
 
-	checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), false)
+	checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil)
 	triggerChecker := TriggerChecker{
 		metrics: checkerMetrics.LocalMetrics,
 		logger:  logger,
@@ -618,7 +679,7 @@ func TestCheck(t *testing.T) {
 
 		var ttl int64 = 30
 
-		checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), false)
+		checkerMetrics := metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil)
 		triggerChecker := TriggerChecker{
 			triggerID: "SuperId",
 			database:  dataBase,
@@ -664,7 +725,7 @@ func TestCheck(t *testing.T) {
 
 			gomock.InOrder(
 				source.EXPECT().Fetch(pattern, triggerChecker.from, triggerChecker.until, true).Return(nil, metricErr),
-				dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil),
+				dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil),
 			)
 			err := triggerChecker.Check()
 			So(err, ShouldBeNil)
@@ -695,7 +756,7 @@ func TestCheck(t *testing.T) {
 				gomock.InOrder(
 					source.EXPECT().Fetch(pattern, triggerChecker.from, triggerChecker.until, true).Return(nil, unknownFunctionExc),
 					dataBase.EXPECT().PushNotificationEvent(&event, true).Return(nil),
-					dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil),
+					dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil),
 				)
 				err := triggerChecker.Check()
 				So(err, ShouldBeNil)
@@ -740,7 +801,7 @@ func TestCheck(t *testing.T) {
 					dataBase.EXPECT().GetMetricsTTLSeconds().Return(metricsTTL),
 					dataBase.EXPECT().RemoveMetricsValues([]string{metric}, triggerChecker.until-metricsTTL).Return(nil),
 					dataBase.EXPECT().PushNotificationEvent(&event, true).Return(nil),
-					dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil),
+					dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil),
 				)
 				err := triggerChecker.Check()
 				So(err, ShouldBeNil)
@@ -784,7 +845,7 @@ func TestCheck(t *testing.T) {
 				dataBase.EXPECT().GetMetricsTTLSeconds().Return(metricsTTL),
 				dataBase.EXPECT().RemoveMetricsValues([]string{metric}, triggerChecker.until-metricsTTL).Return(nil),
 				dataBase.EXPECT().PushNotificationEvent(&event, true).Return(nil),
-				dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil),
+				dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil),
 			)
 			err := triggerChecker.Check()
 			So(err, ShouldBeNil)
@@ -826,7 +887,7 @@ func TestCheck(t *testing.T) {
 			})
 			fetchResult.EXPECT().GetPatternMetrics().Return([]string{metric}, nil)
 			dataBase.EXPECT().PushNotificationEvent(&event, true).Return(nil)
-			dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil)
 			err := triggerChecker.Check()
 			So(err, ShouldBeNil)
 		})
@@ -1088,7 +1149,7 @@ func TestTriggerChecker_Check(t *testing.T) {
 		source:    source,
 		logger:    logger,
 		config:    &Config{},
-		metrics:   metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), false).LocalMetrics,
+		metrics:   metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil).LocalMetrics,
 		from:      17,
 		until:     67,
 		ttl:       ttl,
@@ -1139,7 +1200,7 @@ func TestTriggerChecker_Check(t *testing.T) {
 	source.EXPECT().Fetch(pattern, triggerChecker.from, triggerChecker.until, true).Return(fetchResult, nil)
 	fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData(metric, []float64{0, 1, 2, 3, 4}, retention, triggerChecker.from)})
 	fetchResult.EXPECT().GetPatternMetrics().Return([]string{metric}, nil)
-	dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil)
+	dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil)
 	_ = triggerChecker.Check()
 }
 
@@ -1167,7 +1228,7 @@ func BenchmarkTriggerChecker_Check(b *testing.B) {
 		source:    source,
 		logger:    logger,
 		config:    &Config{},
-		metrics:   metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), false).LocalMetrics,
+		metrics:   metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil).LocalMetrics,
 		from:      17,
 		until:     67,
 		ttl:       ttl,
@@ -1218,7 +1279,7 @@ func BenchmarkTriggerChecker_Check(b *testing.B) {
 	source.EXPECT().Fetch(pattern, triggerChecker.from, triggerChecker.until, true).Return(fetchResult, nil).AnyTimes()
 	fetchResult.EXPECT().GetMetricsData().Return([]metricSource.MetricData{*metricSource.MakeMetricData(metric, []float64{0, 1, 2, 3, 4}, retention, triggerChecker.from)}).AnyTimes()
 	fetchResult.EXPECT().GetPatternMetrics().Return([]string{metric}, nil).AnyTimes()
-	dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.IsRemote).Return(nil).AnyTimes()
+	dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, &lastCheck, triggerChecker.trigger.Source).Return(nil).AnyTimes()
 	for n := 0; n < b.N; n++ {
 		err := triggerChecker.Check()
 		if err != nil {
@@ -1457,7 +1518,7 @@ func TestTriggerChecker_handlePrepareError(t *testing.T) {
 				Metric:           triggerChecker.trigger.Name,
 				MessageEventInfo: nil,
 			}, true)
-			dataBase.EXPECT().SetTriggerLastCheck("test trigger", &expectedCheckData, false)
+			dataBase.EXPECT().SetTriggerLastCheck("test trigger", &expectedCheckData, "")
 			pass, checkDataReturn, errReturn := triggerChecker.handlePrepareError(checkData, err)
 			So(errReturn, ShouldBeNil)
 			So(pass, ShouldBeFalse)
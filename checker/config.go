@@ -2,6 +2,8 @@ package checker
 
 import (
 	"time"
+
+	"github.com/moira-alert/moira"
 )
 
 // Config represent checker config
@@ -15,4 +17,9 @@ type Config struct {
 	MaxParallelRemoteChecks     int
 	LogFile                     string
 	LogLevel                    string
+	// BadStateReminder are default intervals between reminders about bad states, they are used if trigger does not set its own
+	BadStateReminder moira.ReminderIntervals
+	// DiagnosticsCount is a number of latest trigger checks which diagnostics are stored, zero disables diagnostics
+	DiagnosticsCount int
+	DiagnosticsTTL   time.Duration
 }
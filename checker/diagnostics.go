@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"time"
+
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+)
+
+// maxFailedMetricsDiagnostics limits number of failed metrics in check diagnostics to keep it compact
+const maxFailedMetricsDiagnostics = 20
+
+// startDiagnostics starts recording diagnostics of current check if it is enabled in checker config
+func (triggerChecker *TriggerChecker) startDiagnostics() {
+	if triggerChecker.config == nil || triggerChecker.config.DiagnosticsCount <= 0 {
+		return
+	}
+	triggerChecker.diagnostics = &moira.CheckDiagnostics{
+		Timestamp: triggerChecker.until,
+		Targets:   make([]moira.TargetDiagnostics, 0, len(triggerChecker.trigger.Targets)),
+	}
+}
+
+// saveDiagnostics saves diagnostics of current check, failure to save them does not fail the check
+func (triggerChecker *TriggerChecker) saveDiagnostics(checkDuration time.Duration) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	triggerChecker.diagnostics.Duration = checkDuration.Milliseconds()
+	err := triggerChecker.database.SaveTriggerCheckDiagnostics(triggerChecker.triggerID, triggerChecker.diagnostics,
+		triggerChecker.config.DiagnosticsCount, triggerChecker.config.DiagnosticsTTL)
+	if err != nil {
+		triggerChecker.logger.Errorf("Failed to save diagnostics of trigger %s check: %s", triggerChecker.triggerID, err.Error())
+	}
+}
+
+func (triggerChecker *TriggerChecker) addTargetDiagnostics(targetName, target string, fetchDuration time.Duration, metricsData []metricSource.MetricData) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	targetDiagnostics := moira.TargetDiagnostics{
+		Name:          targetName,
+		Target:        target,
+		FetchDuration: fetchDuration.Milliseconds(),
+		Series:        len(metricsData),
+	}
+	for _, metricData := range metricsData {
+		targetDiagnostics.Points += len(metricData.Values)
+		if metricData.Wildcard {
+			targetDiagnostics.Wildcards++
+		}
+	}
+	triggerChecker.diagnostics.Targets = append(triggerChecker.diagnostics.Targets, targetDiagnostics)
+}
+
+func (triggerChecker *TriggerChecker) setPrepareDiagnostics(aloneMetrics map[string]string, duplicates map[string][]string) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	if len(aloneMetrics) > 0 {
+		triggerChecker.diagnostics.AloneMetrics = aloneMetrics
+	}
+	if len(duplicates) > 0 {
+		triggerChecker.diagnostics.Duplicates = duplicates
+	}
+}
+
+// setMetricDiagnostics keeps the last expression inputs and result of metric which is not in OK state
+func (triggerChecker *TriggerChecker) setMetricDiagnostics(metricName string, timestamp int64, values map[string]float64, state moira.State, err error) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	failedMetrics := triggerChecker.diagnostics.FailedMetrics
+	if state == moira.StateOK && err == nil {
+		delete(failedMetrics, metricName)
+		return
+	}
+	if failedMetrics == nil {
+		failedMetrics = make(map[string]moira.MetricDiagnostics)
+		triggerChecker.diagnostics.FailedMetrics = failedMetrics
+	}
+	if _, ok := failedMetrics[metricName]; !ok && len(failedMetrics) >= maxFailedMetricsDiagnostics {
+		return
+	}
+	metricDiagnostics := moira.MetricDiagnostics{
+		Timestamp: timestamp,
+		Values:    values,
+		State:     state,
+	}
+	if err != nil {
+		metricDiagnostics.Error = err.Error()
+	}
+	failedMetrics[metricName] = metricDiagnostics
+}
+
+func (triggerChecker *TriggerChecker) setCheckDataDiagnostics(checkData *moira.CheckData) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	triggerChecker.diagnostics.State = checkData.State
+	triggerChecker.diagnostics.Message = checkData.Message
+}
+
+func (triggerChecker *TriggerChecker) setErrorDiagnostics(err error) {
+	if triggerChecker.diagnostics == nil {
+		return
+	}
+	triggerChecker.diagnostics.Error = err.Error()
+}
@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/metrics"
+	mock_metric_source "github.com/moira-alert/moira/mock/metric_source"
+	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckDiagnostics(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	source := mock_metric_source.NewMockMetricSource(mockCtrl)
+	logger, _ := logging.GetLogger("Test")
+
+	var warnValue float64 = 10
+	pattern := "super.puper.pattern"
+	fetchErr := fmt.Errorf("ooops, metric error")
+
+	Convey("Diagnostics are saved with fetch error", t, func() {
+		triggerChecker := TriggerChecker{
+			triggerID: "SuperId",
+			database:  dataBase,
+			source:    source,
+			logger:    logger,
+			config:    &Config{DiagnosticsCount: 5, DiagnosticsTTL: time.Hour},
+			metrics:   metrics.ConfigureCheckerMetrics(metrics.NewDummyRegistry(), nil).LocalMetrics,
+			from:      17,
+			until:     67,
+			ttlState:  moira.TTLStateNODATA,
+			trigger: &moira.Trigger{
+				WarnValue:   &warnValue,
+				TriggerType: moira.RisingTrigger,
+				Targets:     []string{pattern},
+				Patterns:    []string{pattern},
+			},
+			lastCheck: &moira.CheckData{
+				State:     moira.StateOK,
+				Timestamp: 57,
+				Metrics:   map[string]moira.MetricState{},
+			},
+		}
+
+		source.EXPECT().Fetch(pattern, triggerChecker.from, triggerChecker.until, true).Return(nil, fetchErr)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerChecker.triggerID, gomock.Any(), triggerChecker.trigger.Source).Return(nil)
+		dataBase.EXPECT().SaveTriggerCheckDiagnostics(triggerChecker.triggerID, gomock.Any(), 5, time.Hour).
+			DoAndReturn(func(triggerID string, diagnostics *moira.CheckDiagnostics, maxCount int, ttl time.Duration) error {
+				So(diagnostics.Timestamp, ShouldEqual, triggerChecker.until)
+				So(diagnostics.State, ShouldEqual, moira.StateOK)
+				So(diagnostics.Error, ShouldEqual, fetchErr.Error())
+				So(diagnostics.Targets, ShouldHaveLength, 1)
+				So(diagnostics.Targets[0].Name, ShouldEqual, "t1")
+				So(diagnostics.Targets[0].Target, ShouldEqual, pattern)
+				So(diagnostics.Targets[0].Series, ShouldEqual, 0)
+				return nil
+			})
+
+		err := triggerChecker.Check()
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Diagnostics are not saved if they are disabled", t, func() {
+		triggerChecker := TriggerChecker{config: &Config{}, trigger: &moira.Trigger{}}
+		triggerChecker.startDiagnostics()
+		So(triggerChecker.diagnostics, ShouldBeNil)
+		triggerChecker.setErrorDiagnostics(fetchErr)
+		triggerChecker.saveDiagnostics(time.Second)
+	})
+}
+
+func TestSetMetricDiagnostics(t *testing.T) {
+	Convey("Only metrics which are not OK are kept", t, func() {
+		triggerChecker := TriggerChecker{diagnostics: &moira.CheckDiagnostics{}}
+		values := map[string]float64{"t1": 15}
+
+		triggerChecker.setMetricDiagnostics("metric", 100, values, moira.StateWARN, nil)
+		So(triggerChecker.diagnostics.FailedMetrics, ShouldResemble, map[string]moira.MetricDiagnostics{
+			"metric": {Timestamp: 100, Values: values, State: moira.StateWARN},
+		})
+
+		triggerChecker.setMetricDiagnostics("metric", 160, values, moira.StateOK, nil)
+		So(triggerChecker.diagnostics.FailedMetrics, ShouldBeEmpty)
+
+		triggerChecker.setMetricDiagnostics("metric", 220, values, "", fmt.Errorf("expression error"))
+		So(triggerChecker.diagnostics.FailedMetrics, ShouldResemble, map[string]moira.MetricDiagnostics{
+			"metric": {Timestamp: 220, Values: values, Error: "expression error"},
+		})
+	})
+
+	Convey("Number of failed metrics is limited", t, func() {
+		triggerChecker := TriggerChecker{diagnostics: &moira.CheckDiagnostics{}}
+		for i := 0; i < maxFailedMetricsDiagnostics+5; i++ {
+			triggerChecker.setMetricDiagnostics(fmt.Sprintf("metric%d", i), 100, nil, moira.StateERROR, nil)
+		}
+		So(triggerChecker.diagnostics.FailedMetrics, ShouldHaveLength, maxFailedMetricsDiagnostics)
+	})
+}
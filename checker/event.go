@@ -4,11 +4,6 @@ import (
 	"github.com/moira-alert/moira"
 )
 
-var badStateReminder = map[moira.State]int64{
-	moira.StateERROR:  86400, //nolint
-	moira.StateNODATA: 86400, //nolint
-}
-
 func (triggerChecker *TriggerChecker) compareTriggerStates(currentCheck moira.CheckData) (moira.CheckData, error) {
 	lastCheck := triggerChecker.lastCheck
 
@@ -30,13 +25,21 @@ func (triggerChecker *TriggerChecker) compareTriggerStates(currentCheck moira.Ch
 		lastStateSuppressedValue = lastStateValue
 	}
 	currentCheck.SuppressedState = lastStateSuppressedValue
+	currentCheck.SuppressedByParents = lastCheck.SuppressedByParents
+
+	currentCheck.Ack = lastCheck.Ack
+	if currentCheck.IsRecovered() || !currentCheck.Ack.IsActive(currentCheckTimestamp) {
+		currentCheck.Ack = nil
+	}
+	ack := getActiveAck(currentCheck.Ack, currentStateValue, currentCheckTimestamp)
 
 	maintenanceInfo, maintenanceTimestamp := getMaintenanceInfo(lastCheck, nil)
-	eventInfo, needSend := isStateChanged(currentStateValue, lastStateValue, currentCheckTimestamp, lastCheck.GetEventTimestamp(), lastStateSuppressed, lastStateSuppressedValue, maintenanceInfo)
+	eventInfo, needSend := isStateChanged(currentStateValue, lastStateValue, currentCheckTimestamp, lastCheck.GetEventTimestamp(), lastStateSuppressed, lastStateSuppressedValue, maintenanceInfo, triggerChecker.getReminderInterval(currentStateValue, ack))
 	if !needSend {
 		if maintenanceTimestamp < currentCheckTimestamp {
 			currentCheck.Suppressed = false
 			currentCheck.SuppressedState = ""
+			currentCheck.SuppressedByParents = nil
 		}
 		return currentCheck, nil
 	}
@@ -48,11 +51,18 @@ func (triggerChecker *TriggerChecker) compareTriggerStates(currentCheck moira.Ch
 		if !lastStateSuppressed {
 			currentCheck.SuppressedState = lastStateValue
 		}
+		if len(triggerChecker.badParents) > 0 {
+			currentCheck.SuppressedByParents = triggerChecker.badParents
+		}
 		return currentCheck, nil
 	}
 
 	currentCheck.Suppressed = false
 	currentCheck.SuppressedState = ""
+	currentCheck.SuppressedByParents = nil
+	setSuppressedByParents(eventInfo, lastCheck.SuppressedByParents)
+	eventInfo = setAggregationInfo(eventInfo, triggerChecker.aggregation)
+	eventInfo = setAckInfo(eventInfo, ack)
 
 	err := triggerChecker.database.PushNotificationEvent(&moira.NotificationEvent{
 		IsTriggerEvent:   true,
@@ -80,13 +90,24 @@ func (triggerChecker *TriggerChecker) compareMetricStates(metric string, current
 		lastState.SuppressedState = lastState.State
 	}
 	currentState.SuppressedState = lastState.SuppressedState
+	currentState.SuppressedByParents = lastState.SuppressedByParents
+
+	currentState = triggerChecker.applyHysteresis(currentState, lastState)
+
+	// Acknowledgement is taken from last state as intermediate states are built before previous one is compared
+	currentState.Ack = getActiveAck(lastState.Ack, currentState.State, currentState.Timestamp)
+	ack := currentState.Ack
+	if ack == nil {
+		ack = getActiveAck(triggerChecker.lastCheck.Ack, currentState.State, currentState.Timestamp)
+	}
 
 	maintenanceInfo, maintenanceTimestamp := getMaintenanceInfo(triggerChecker.lastCheck, &currentState)
-	eventInfo, needSend := isStateChanged(currentState.State, lastState.State, currentState.Timestamp, lastState.GetEventTimestamp(), lastState.Suppressed, lastState.SuppressedState, maintenanceInfo)
+	eventInfo, needSend := isStateChanged(currentState.State, lastState.State, currentState.Timestamp, lastState.GetEventTimestamp(), lastState.Suppressed, lastState.SuppressedState, maintenanceInfo, triggerChecker.getReminderInterval(currentState.State, ack))
 	if !needSend {
 		if maintenanceTimestamp < currentState.Timestamp {
 			currentState.Suppressed = false
 			currentState.SuppressedState = ""
+			currentState.SuppressedByParents = nil
 		}
 		return currentState, nil
 	}
@@ -99,11 +120,17 @@ func (triggerChecker *TriggerChecker) compareMetricStates(metric string, current
 		if !lastState.Suppressed {
 			currentState.SuppressedState = lastState.State
 		}
+		if len(triggerChecker.badParents) > 0 {
+			currentState.SuppressedByParents = triggerChecker.badParents
+		}
 		return currentState, nil
 	}
 
 	currentState.Suppressed = false
 	currentState.SuppressedState = ""
+	currentState.SuppressedByParents = nil
+	setSuppressedByParents(eventInfo, lastState.SuppressedByParents)
+	eventInfo = setAckInfo(eventInfo, ack)
 
 	err := triggerChecker.database.PushNotificationEvent(&moira.NotificationEvent{
 		TriggerID:        triggerChecker.triggerID,
@@ -113,10 +140,44 @@ func (triggerChecker *TriggerChecker) compareMetricStates(metric string, current
 		Metric:           metric,
 		MessageEventInfo: eventInfo,
 		Values:           currentState.Values,
+		IsMuted:          triggerChecker.trigger.Aggregation.IsMetricsMuted(),
 	}, true)
 	return currentState, err
 }
 
+// applyHysteresis keeps last metric state and marks new state as pending
+// until new state persists for duration and number of checks configured in trigger hysteresis
+func (triggerChecker *TriggerChecker) applyHysteresis(currentState moira.MetricState, lastState moira.MetricState) moira.MetricState {
+	currentState.PendingState = ""
+	currentState.PendingSince = 0
+	currentState.PendingChecks = 0
+	currentState.PendingCheck = 0
+	if currentState.State == lastState.State {
+		return currentState
+	}
+
+	pendingSince, pendingChecks := currentState.Timestamp, int64(1)
+	if lastState.PendingState == currentState.State {
+		pendingSince = lastState.PendingSince
+		pendingChecks = lastState.PendingChecks
+		// One trigger check can compare several points of metric, count it only once
+		if lastState.PendingCheck != triggerChecker.until {
+			pendingChecks++
+		}
+	}
+	condition := triggerChecker.trigger.Hysteresis.GetPendingCondition(currentState.State, lastState.State)
+	if condition.IsSatisfied(currentState.Timestamp-pendingSince, pendingChecks) {
+		return currentState
+	}
+
+	currentState.PendingState = currentState.State
+	currentState.PendingSince = pendingSince
+	currentState.PendingChecks = pendingChecks
+	currentState.PendingCheck = triggerChecker.until
+	currentState.State = lastState.State
+	return currentState
+}
+
 func getEventOldState(lastCheckState moira.State, lastCheckSuppressedState moira.State, isSuppressed bool) moira.State {
 	if isSuppressed {
 		return lastCheckSuppressedState
@@ -125,10 +186,63 @@ func getEventOldState(lastCheckState moira.State, lastCheckSuppressedState moira
 }
 
 func (triggerChecker *TriggerChecker) isTriggerSuppressed(timestamp int64, maintenanceTimestamp int64) bool {
-	return !triggerChecker.trigger.Schedule.IsScheduleAllows(timestamp) || maintenanceTimestamp >= timestamp
+	return !triggerChecker.trigger.Schedule.IsScheduleAllows(timestamp) || maintenanceTimestamp >= timestamp || len(triggerChecker.badParents) > 0
+}
+
+// setSuppressedByParents adds parent triggers to info of event which is sent after suppression if they suppressed it
+func setSuppressedByParents(eventInfo *moira.EventInfo, suppressedByParents []string) {
+	if eventInfo == nil || eventInfo.Maintenance == nil || len(suppressedByParents) == 0 {
+		return
+	}
+	eventInfo.SuppressedByParents = suppressedByParents
+}
+
+// setAggregationInfo adds distribution of metric states to info of event about aggregated trigger state
+func setAggregationInfo(eventInfo *moira.EventInfo, aggregationInfo *moira.AggregationInfo) *moira.EventInfo {
+	if aggregationInfo == nil {
+		return eventInfo
+	}
+	if eventInfo == nil {
+		eventInfo = &moira.EventInfo{}
+	}
+	eventInfo.Aggregation = aggregationInfo
+	return eventInfo
+}
+
+// setAckInfo adds active acknowledgement of bad state to info of event
+func setAckInfo(eventInfo *moira.EventInfo, ack *moira.AckData) *moira.EventInfo {
+	if ack == nil {
+		return eventInfo
+	}
+	if eventInfo == nil {
+		eventInfo = &moira.EventInfo{}
+	}
+	eventInfo.Ack = ack
+	return eventInfo
+}
+
+// getActiveAck returns acknowledgement if it is not expired and acknowledged bad state is not recovered to OK
+func getActiveAck(ack *moira.AckData, state moira.State, timestamp int64) *moira.AckData {
+	if state == moira.StateOK || !ack.IsActive(timestamp) {
+		return nil
+	}
+	return ack
+}
+
+// getReminderInterval returns interval in seconds between reminders about given state configured in trigger or checker config,
+// zero means reminders are disabled or bad state is acknowledged
+func (triggerChecker *TriggerChecker) getReminderInterval(state moira.State, ack *moira.AckData) int64 {
+	if ack != nil {
+		return 0
+	}
+	defaults := moira.DefaultReminderIntervals
+	if triggerChecker.config != nil && triggerChecker.config.BadStateReminder != nil {
+		defaults = triggerChecker.config.BadStateReminder
+	}
+	return triggerChecker.trigger.Reminders.GetInterval(state, defaults)
 }
 
-func isStateChanged(currentStateValue moira.State, lastStateValue moira.State, currentStateTimestamp int64, lastStateEventTimestamp int64, isLastCheckSuppressed bool, lastStateSuppressedValue moira.State, maintenanceInfo moira.MaintenanceInfo) (*moira.EventInfo, bool) {
+func isStateChanged(currentStateValue moira.State, lastStateValue moira.State, currentStateTimestamp int64, lastStateEventTimestamp int64, isLastCheckSuppressed bool, lastStateSuppressedValue moira.State, maintenanceInfo moira.MaintenanceInfo, remindInterval int64) (*moira.EventInfo, bool) {
 	if !isLastCheckSuppressed && currentStateValue != lastStateValue {
 		return nil, true
 	}
@@ -137,9 +251,8 @@ func isStateChanged(currentStateValue moira.State, lastStateValue moira.State, c
 		return &moira.EventInfo{Maintenance: &maintenanceInfo}, true
 	}
 
-	remindInterval, ok := badStateReminder[currentStateValue]
-	if ok && needRemindAgain(currentStateTimestamp, lastStateEventTimestamp, remindInterval) {
-		interval := remindInterval / 3600 //nolint
+	if remindInterval > 0 && needRemindAgain(currentStateTimestamp, lastStateEventTimestamp, remindInterval) {
+		interval := remindInterval / moira.ReminderIntervalUnit
 		return &moira.EventInfo{Interval: &interval}, true
 	}
 	return nil, false
@@ -187,6 +187,100 @@ func TestCompareMetricStates(t *testing.T) {
 	})
 }
 
+func TestCompareMetricStatesWithAck(t *testing.T) {
+	Convey("Test compare acknowledged metric states", t, func() {
+		logger, _ := logging.GetLogger("Test")
+		dataBase, mockCtrl := newMocks(t)
+		defer mockCtrl.Finish()
+
+		triggerChecker := TriggerChecker{
+			triggerID: "SuperId",
+			logger:    logger,
+			database:  dataBase,
+			trigger:   &moira.Trigger{},
+			lastCheck: &moira.CheckData{},
+		}
+		ack := &moira.AckData{User: "user", Comment: "working on it", Timestamp: 1502712000}
+		lastState := moira.MetricState{
+			Timestamp:      1502712000,
+			EventTimestamp: 1502708400,
+			State:          moira.StateERROR,
+			Ack:            ack,
+		}
+		currentState := moira.MetricState{
+			Timestamp: 1502809200,
+			State:     moira.StateERROR,
+			Values:    map[string]float64{"t1": 0},
+		}
+
+		Convey("Acknowledged state is not reminded", func() {
+			actual, err := triggerChecker.compareMetricStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldEqual, ack)
+			So(actual.EventTimestamp, ShouldEqual, lastState.EventTimestamp)
+		})
+
+		Convey("Trigger acknowledgement stops reminders of metric", func() {
+			lastState.Ack = nil
+			triggerChecker.lastCheck.Ack = ack
+			actual, err := triggerChecker.compareMetricStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldBeNil)
+			So(actual.EventTimestamp, ShouldEqual, lastState.EventTimestamp)
+		})
+
+		Convey("Expired acknowledgement is removed", func() {
+			expired := *ack
+			expired.Expiry = 1502719200
+			lastState.Ack = &expired
+			var interval int64 = 24
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID:        triggerChecker.triggerID,
+				Timestamp:        currentState.Timestamp,
+				State:            moira.StateERROR,
+				OldState:         moira.StateERROR,
+				Metric:           "m1",
+				Values:           currentState.Values,
+				MessageEventInfo: &moira.EventInfo{Interval: &interval},
+			}, true).Return(nil)
+			actual, err := triggerChecker.compareMetricStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldBeNil)
+		})
+
+		Convey("Event of acknowledged metric has acknowledgement info", func() {
+			currentState.State = moira.StateNODATA
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID:        triggerChecker.triggerID,
+				Timestamp:        currentState.Timestamp,
+				State:            moira.StateNODATA,
+				OldState:         moira.StateERROR,
+				Metric:           "m1",
+				Values:           currentState.Values,
+				MessageEventInfo: &moira.EventInfo{Ack: ack},
+			}, true).Return(nil)
+			actual, err := triggerChecker.compareMetricStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldEqual, ack)
+		})
+
+		Convey("Acknowledgement is removed when metric is recovered", func() {
+			currentState.State = moira.StateOK
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.triggerID,
+				Timestamp: currentState.Timestamp,
+				State:     moira.StateOK,
+				OldState:  moira.StateERROR,
+				Metric:    "m1",
+				Values:    currentState.Values,
+			}, true).Return(nil)
+			actual, err := triggerChecker.compareMetricStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldBeNil)
+		})
+	})
+}
+
 func TestCompareTriggerStates(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
@@ -223,6 +317,24 @@ func TestCompareTriggerStates(t *testing.T) {
 			currentCheck.EventTimestamp = lastCheck.EventTimestamp
 			So(actual, ShouldResemble, currentCheck)
 		})
+
+		Convey("Trigger acknowledgement is kept while any metric is in bad state", func() {
+			lastCheck := lastCheckExample
+			currentCheck := currentCheckExample
+			triggerChecker.lastCheck = &lastCheck
+			lastCheck.State = moira.StateOK
+			lastCheck.Ack = &moira.AckData{User: "user", Timestamp: 1502712000}
+			currentCheck.State = moira.StateOK
+			currentCheck.Metrics = map[string]moira.MetricState{"m1": {State: moira.StateERROR}}
+			actual, err := triggerChecker.compareTriggerStates(currentCheck)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldEqual, lastCheck.Ack)
+
+			currentCheck.Metrics = map[string]moira.MetricState{"m1": {State: moira.StateOK}}
+			actual, err = triggerChecker.compareTriggerStates(currentCheck)
+			So(err, ShouldBeNil)
+			So(actual.Ack, ShouldBeNil)
+		})
 	})
 
 	triggerChecker.trigger.Schedule = &moira.ScheduleData{
@@ -279,6 +391,212 @@ func TestCompareTriggerStates(t *testing.T) {
 	})
 }
 
+func TestCompareMetricStatesWithHysteresis(t *testing.T) {
+	Convey("Test compare metric states with hysteresis", t, func() {
+		dataBase, mockCtrl := newMocks(t)
+		defer mockCtrl.Finish()
+		logger, _ := logging.GetLogger("Test")
+
+		triggerChecker := TriggerChecker{
+			triggerID: "SuperId",
+			database:  dataBase,
+			logger:    logger,
+			trigger: &moira.Trigger{
+				Hysteresis: &moira.Hysteresis{
+					Worsening:  moira.PendingCondition{Checks: 3},
+					Recovering: moira.PendingCondition{Seconds: 120},
+				},
+			},
+			lastCheck: &moira.CheckData{},
+		}
+		lastState := moira.MetricState{
+			State:          moira.StateOK,
+			Timestamp:      1000,
+			EventTimestamp: 100,
+		}
+
+		Convey("Worsening state should be pending until it persists for configured checks", func() {
+			state := lastState
+			for i := int64(1); i < 3; i++ {
+				triggerChecker.until = 1000 + i*60
+				current := newMetricState(state, moira.StateERROR, 1000+i*60, nil)
+				var err error
+				state, err = triggerChecker.compareMetricStates("m1", *current, state)
+				So(err, ShouldBeNil)
+				So(state.State, ShouldEqual, moira.StateOK)
+				So(state.PendingState, ShouldEqual, moira.StateERROR)
+				So(state.PendingSince, ShouldEqual, 1060)
+				So(state.PendingChecks, ShouldEqual, i)
+				So(state.EventTimestamp, ShouldEqual, 100)
+			}
+
+			triggerChecker.until = 1180
+			current := newMetricState(state, moira.StateERROR, 1180, nil)
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.triggerID,
+				Timestamp: 1180,
+				State:     moira.StateERROR,
+				OldState:  moira.StateOK,
+				Metric:    "m1",
+			}, true).Return(nil)
+			state, err := triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateERROR)
+			So(state.PendingState, ShouldBeEmpty)
+			So(state.PendingSince, ShouldBeZeroValue)
+			So(state.PendingChecks, ShouldBeZeroValue)
+			So(state.EventTimestamp, ShouldEqual, 1180)
+		})
+
+		Convey("Several points of one check should be counted as one pending check", func() {
+			triggerChecker.until = 1180
+			state := lastState
+			for _, timestamp := range []int64{1060, 1120, 1180} {
+				current := newMetricState(state, moira.StateERROR, timestamp, nil)
+				var err error
+				state, err = triggerChecker.compareMetricStates("m1", *current, state)
+				So(err, ShouldBeNil)
+			}
+			So(state.State, ShouldEqual, moira.StateOK)
+			So(state.PendingState, ShouldEqual, moira.StateERROR)
+			So(state.PendingSince, ShouldEqual, 1060)
+			So(state.PendingChecks, ShouldEqual, 1)
+			So(state.PendingCheck, ShouldEqual, 1180)
+		})
+
+		Convey("Pending state should be reset if metric returns to last state or switches to another state", func() {
+			triggerChecker.until = 1060
+			current := newMetricState(lastState, moira.StateERROR, 1060, nil)
+			state, err := triggerChecker.compareMetricStates("m1", *current, lastState)
+			So(err, ShouldBeNil)
+			So(state.PendingState, ShouldEqual, moira.StateERROR)
+
+			triggerChecker.until = 1120
+			current = newMetricState(state, moira.StateWARN, 1120, nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateOK)
+			So(state.PendingState, ShouldEqual, moira.StateWARN)
+			So(state.PendingSince, ShouldEqual, 1120)
+			So(state.PendingChecks, ShouldEqual, 1)
+
+			triggerChecker.until = 1180
+			current = newMetricState(state, moira.StateOK, 1180, nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateOK)
+			So(state.PendingState, ShouldBeEmpty)
+			So(state.PendingChecks, ShouldBeZeroValue)
+		})
+
+		Convey("Recovering state should be pending until it persists for configured seconds", func() {
+			lastState.State = moira.StateERROR
+			triggerChecker.until = 1060
+			current := newMetricState(lastState, moira.StateOK, 1060, nil)
+			state, err := triggerChecker.compareMetricStates("m1", *current, lastState)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateERROR)
+			So(state.PendingState, ShouldEqual, moira.StateOK)
+
+			triggerChecker.until = 1120
+			current = newMetricState(state, moira.StateOK, 1120, nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateERROR)
+			So(state.PendingChecks, ShouldEqual, 2)
+
+			triggerChecker.until = 1180
+			current = newMetricState(state, moira.StateOK, 1180, nil)
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.triggerID,
+				Timestamp: 1180,
+				State:     moira.StateOK,
+				OldState:  moira.StateERROR,
+				Metric:    "m1",
+			}, true).Return(nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateOK)
+			So(state.PendingState, ShouldBeEmpty)
+		})
+	})
+}
+
+func TestCompareStatesWithBadParents(t *testing.T) {
+	Convey("Test compare states while parent triggers are in bad state", t, func() {
+		dataBase, mockCtrl := newMocks(t)
+		defer mockCtrl.Finish()
+		logger, _ := logging.GetLogger("Test")
+
+		triggerChecker := TriggerChecker{
+			triggerID:  "SuperId",
+			database:   dataBase,
+			logger:     logger,
+			trigger:    &moira.Trigger{Name: "Super trigger", Parents: []string{"parent1", "parent2"}},
+			lastCheck:  &moira.CheckData{State: moira.StateOK, Timestamp: 1000},
+			badParents: []string{"parent1"},
+		}
+		lastState := moira.MetricState{
+			State:          moira.StateOK,
+			Timestamp:      1000,
+			EventTimestamp: 100,
+		}
+
+		Convey("Metric event should be suppressed and sent with parents after they recover", func() {
+			current := newMetricState(lastState, moira.StateERROR, 1060, nil)
+			state, err := triggerChecker.compareMetricStates("m1", *current, lastState)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateERROR)
+			So(state.Suppressed, ShouldBeTrue)
+			So(state.SuppressedState, ShouldEqual, moira.StateOK)
+			So(state.SuppressedByParents, ShouldResemble, []string{"parent1"})
+			So(state.EventTimestamp, ShouldEqual, 1060)
+
+			triggerChecker.badParents = nil
+			current = newMetricState(state, moira.StateERROR, 1120, nil)
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.triggerID,
+				Timestamp: 1120,
+				State:     moira.StateERROR,
+				OldState:  moira.StateOK,
+				Metric:    "m1",
+				MessageEventInfo: &moira.EventInfo{
+					Maintenance:         &moira.MaintenanceInfo{},
+					SuppressedByParents: []string{"parent1"},
+				},
+			}, true).Return(nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.Suppressed, ShouldBeFalse)
+			So(state.SuppressedState, ShouldBeEmpty)
+			So(state.SuppressedByParents, ShouldBeNil)
+		})
+
+		Convey("Metric recovered before parents should not send event", func() {
+			current := newMetricState(lastState, moira.StateERROR, 1060, nil)
+			state, err := triggerChecker.compareMetricStates("m1", *current, lastState)
+			So(err, ShouldBeNil)
+
+			triggerChecker.badParents = nil
+			current = newMetricState(state, moira.StateOK, 1120, nil)
+			state, err = triggerChecker.compareMetricStates("m1", *current, state)
+			So(err, ShouldBeNil)
+			So(state.State, ShouldEqual, moira.StateOK)
+			So(state.Suppressed, ShouldBeFalse)
+			So(state.SuppressedByParents, ShouldBeNil)
+		})
+
+		Convey("Trigger event should be suppressed", func() {
+			currentCheck := moira.CheckData{State: moira.StateEXCEPTION, Timestamp: 1060}
+			checkData, err := triggerChecker.compareTriggerStates(currentCheck)
+			So(err, ShouldBeNil)
+			So(checkData.Suppressed, ShouldBeTrue)
+			So(checkData.SuppressedState, ShouldEqual, moira.StateOK)
+			So(checkData.SuppressedByParents, ShouldResemble, []string{"parent1"})
+		})
+	})
+}
+
 func TestCheckMetricStateWithLastStateSuppressed(t *testing.T) {
 	triggerChecker := TriggerChecker{
 		trigger:   &moira.Trigger{},
@@ -664,14 +982,14 @@ func TestIsStateChanged(t *testing.T) {
 		Convey("Test is state changed", func() {
 			Convey("If is last check suppressed and current state not equal last state", func() {
 				lastCheckTest.Suppressed = false
-				eventInfo, needSend := isStateChanged(currentCheckTest.State, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp()-1, lastCheckTest.Suppressed, lastCheckTest.SuppressedState, moira.MaintenanceInfo{})
+				eventInfo, needSend := isStateChanged(currentCheckTest.State, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp()-1, lastCheckTest.Suppressed, lastCheckTest.SuppressedState, moira.MaintenanceInfo{}, 0)
 				So(eventInfo, ShouldBeNil)
 				So(needSend, ShouldBeTrue)
 			})
 
 			Convey("Create EventInfo with MaintenanceInfo", func() {
 				maintenanceInfo := moira.MaintenanceInfo{}
-				eventInfo, needSend := isStateChanged(currentCheckTest.State, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp(), lastCheckTest.Suppressed, lastCheckTest.SuppressedState, maintenanceInfo)
+				eventInfo, needSend := isStateChanged(currentCheckTest.State, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp(), lastCheckTest.Suppressed, lastCheckTest.SuppressedState, maintenanceInfo, 0)
 				So(eventInfo, ShouldNotBeNil)
 				So(eventInfo, ShouldResemble, &moira.EventInfo{Maintenance: &maintenanceInfo})
 				So(needSend, ShouldBeTrue)
@@ -679,17 +997,68 @@ func TestIsStateChanged(t *testing.T) {
 
 			Convey("Create EventInfo with interval", func() {
 				var interval int64 = 24
-				eventInfo, needSend := isStateChanged(moira.StateNODATA, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp()-100000, lastCheckTest.Suppressed, moira.StateNODATA, moira.MaintenanceInfo{})
+				eventInfo, needSend := isStateChanged(moira.StateNODATA, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp()-100000, lastCheckTest.Suppressed, moira.StateNODATA, moira.MaintenanceInfo{}, 86400)
 				So(eventInfo, ShouldNotBeNil)
 				So(eventInfo, ShouldResemble, &moira.EventInfo{Interval: &interval})
 				So(needSend, ShouldBeTrue)
 			})
 
+			Convey("Do not remind if reminders are disabled", func() {
+				eventInfo, needSend := isStateChanged(moira.StateNODATA, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp()-100000, lastCheckTest.Suppressed, moira.StateNODATA, moira.MaintenanceInfo{}, 0)
+				So(eventInfo, ShouldBeNil)
+				So(needSend, ShouldBeFalse)
+			})
+
 			Convey("No send message", func() {
-				eventInfo, needSend := isStateChanged(moira.StateNODATA, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp(), lastCheckTest.Suppressed, moira.StateNODATA, moira.MaintenanceInfo{})
+				eventInfo, needSend := isStateChanged(moira.StateNODATA, lastCheckTest.State, currentCheckTest.Timestamp, lastCheckTest.GetEventTimestamp(), lastCheckTest.Suppressed, moira.StateNODATA, moira.MaintenanceInfo{}, 86400)
 				So(eventInfo, ShouldBeNil)
 				So(needSend, ShouldBeFalse)
 			})
 		})
 	})
 }
+
+func TestGetReminderInterval(t *testing.T) {
+	Convey("Reminder intervals", t, func() {
+		triggerChecker := TriggerChecker{
+			config:  &Config{},
+			trigger: &moira.Trigger{},
+		}
+
+		Convey("Default intervals are used without checker config and trigger reminders", func() {
+			So(triggerChecker.getReminderInterval(moira.StateERROR, nil), ShouldEqual, 86400)
+			So(triggerChecker.getReminderInterval(moira.StateNODATA, nil), ShouldEqual, 86400)
+			So(triggerChecker.getReminderInterval(moira.StateWARN, nil), ShouldEqual, 0)
+		})
+
+		Convey("Checker config intervals are used without trigger reminders", func() {
+			triggerChecker.config.BadStateReminder = moira.ReminderIntervals{moira.StateWARN: 7200}
+			So(triggerChecker.getReminderInterval(moira.StateERROR, nil), ShouldEqual, 0)
+			So(triggerChecker.getReminderInterval(moira.StateWARN, nil), ShouldEqual, 7200)
+		})
+
+		Convey("Trigger reminders override checker config", func() {
+			triggerChecker.trigger.Reminders = moira.ReminderIntervals{moira.StateERROR: 3600, moira.StateNODATA: 0}
+			So(triggerChecker.getReminderInterval(moira.StateERROR, nil), ShouldEqual, 3600)
+			So(triggerChecker.getReminderInterval(moira.StateNODATA, nil), ShouldEqual, 0)
+			So(triggerChecker.getReminderInterval(moira.StateWARN, nil), ShouldEqual, 0)
+		})
+
+		Convey("Acknowledged state is not reminded", func() {
+			So(triggerChecker.getReminderInterval(moira.StateERROR, &moira.AckData{User: "user"}), ShouldEqual, 0)
+		})
+	})
+}
+
+func TestGetActiveAck(t *testing.T) {
+	Convey("Acknowledgement is active till expiry or recovery", t, func() {
+		ack := &moira.AckData{User: "user", Timestamp: 100}
+		So(getActiveAck(nil, moira.StateERROR, 200), ShouldBeNil)
+		So(getActiveAck(ack, moira.StateERROR, 200), ShouldEqual, ack)
+		So(getActiveAck(ack, moira.StateOK, 200), ShouldBeNil)
+
+		ack.Expiry = 300
+		So(getActiveAck(ack, moira.StateNODATA, 200), ShouldEqual, ack)
+		So(getActiveAck(ack, moira.StateNODATA, 300), ShouldBeNil)
+	})
+}
@@ -2,7 +2,10 @@ package checker
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/checker/metrics/conversion"
 	metricSource "github.com/moira-alert/moira/metric_source"
 )
@@ -33,11 +36,15 @@ func (triggerChecker *TriggerChecker) fetch() (map[string][]metricSource.MetricD
 	isSimpleTrigger := triggerChecker.trigger.IsSimple()
 	for targetIndex, target := range triggerChecker.trigger.Targets {
 		targetIndex++ // increasing target index to have target names started from 1 instead of 0
+		targetName := fmt.Sprintf("t%d", targetIndex)
+		fetchStartTime := time.Now()
 		fetchResult, err := triggerChecker.source.Fetch(target, triggerChecker.from, triggerChecker.until, isSimpleTrigger)
 		if err != nil {
+			triggerChecker.addTargetDiagnostics(targetName, target, time.Since(fetchStartTime), nil)
 			return nil, nil, err
 		}
 		metricsData := fetchResult.GetMetricsData()
+		triggerChecker.addTargetDiagnostics(targetName, target, time.Since(fetchStartTime), metricsData)
 
 		metricsFetchResult, metricsErr := fetchResult.GetPatternMetrics()
 
@@ -45,9 +52,17 @@ func (triggerChecker *TriggerChecker) fetch() (map[string][]metricSource.MetricD
 			metricsArr = append(metricsArr, metricsFetchResult...)
 		}
 
-		targetName := fmt.Sprintf("t%d", targetIndex)
 		triggerMetricsData[targetName] = metricsData
 	}
+
+	if triggerChecker.trigger.TriggerType == moira.AnomalyTrigger && triggerChecker.trigger.Anomaly != nil {
+		// Previous seasons are already completed, so real time alerting is not required to fetch them
+		baseline, err := anomaly.FetchBaseline(triggerChecker.source, triggerChecker.trigger.Targets[0], triggerChecker.from, triggerChecker.until, *triggerChecker.trigger.Anomaly, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		triggerChecker.baseline = baseline
+	}
 	return triggerMetricsData, metricsArr, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	"github.com/moira-alert/moira/database"
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metrics"
@@ -26,6 +27,15 @@ type TriggerChecker struct {
 
 	ttl      int64
 	ttlState moira.TTLState
+
+	// baseline is fetched for anomaly triggers only
+	baseline *anomaly.Baseline
+	// badParents are IDs of parent triggers which are in bad state, events are suppressed while they are not empty
+	badParents []string
+	// diagnostics of current check, it is nil if diagnostics are disabled
+	diagnostics *moira.CheckDiagnostics
+	// aggregation describes metric states which trigger state of current check was aggregated from
+	aggregation *moira.AggregationInfo
 }
 
 // MakeTriggerChecker initialize new triggerChecker data
@@ -46,16 +56,26 @@ func MakeTriggerChecker(triggerID string, dataBase moira.Database, logger moira.
 		return nil, err
 	}
 
+	checkMetrics, ok := metrics.GetCheckMetrics(&trigger)
+	if !ok {
+		return nil, metricSource.ErrUnknownMetricSource{Name: moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source)}
+	}
+
 	lastCheck, err := getLastCheck(dataBase, triggerID, until-3600) //nolint
 	if err != nil {
 		return nil, err
 	}
 
+	badParents, err := getBadParents(dataBase, trigger.Parents)
+	if err != nil {
+		return nil, err
+	}
+
 	triggerChecker := &TriggerChecker{
 		database: dataBase,
 		logger:   logger,
 		config:   config,
-		metrics:  metrics.GetCheckMetrics(&trigger),
+		metrics:  checkMetrics,
 		source:   source,
 
 		from:  calculateFrom(lastCheck.Timestamp, trigger.TTL),
@@ -67,6 +87,8 @@ func MakeTriggerChecker(triggerID string, dataBase moira.Database, logger moira.
 
 		ttl:      trigger.TTL,
 		ttlState: getTTLState(trigger.TTLState),
+
+		badParents: badParents,
 	}
 	return triggerChecker, nil
 }
@@ -92,6 +114,37 @@ func getLastCheck(dataBase moira.Database, triggerID string, emptyLastCheckTimes
 	return &lastCheck, nil
 }
 
+// getBadParents returns IDs of parent triggers which trigger itself or any metric of is not in OK state.
+// Removed and not checked yet parent triggers are ignored
+func getBadParents(dataBase moira.Database, parents []string) ([]string, error) {
+	var badParents []string
+	for _, parentID := range parents {
+		parentCheck, err := dataBase.GetTriggerLastCheck(parentID)
+		if err != nil {
+			if err == database.ErrNil {
+				continue
+			}
+			return nil, err
+		}
+		if isCheckBad(parentCheck) {
+			badParents = append(badParents, parentID)
+		}
+	}
+	return badParents, nil
+}
+
+func isCheckBad(checkData moira.CheckData) bool {
+	if checkData.State != moira.StateOK {
+		return true
+	}
+	for _, metricState := range checkData.Metrics {
+		if metricState.State != moira.StateOK {
+			return true
+		}
+	}
+	return false
+}
+
 func getTTLState(triggerTTLState *moira.TTLState) moira.TTLState {
 	if triggerTTLState != nil {
 		return *triggerTTLState
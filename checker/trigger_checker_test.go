@@ -48,6 +48,14 @@ func TestInitTriggerChecker(t *testing.T) {
 			So(err, ShouldBeError)
 			So(err, ShouldResemble, readLastCheckError)
 		})
+
+		Convey("Unknown remote source metrics error", func() {
+			dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{TriggerType: moira.RisingTrigger, IsRemote: true, Source: "graphite2"}, nil)
+			sourceProvider := metricSource.CreateMetricSourceProviderWithRemotes(localSource, map[string]metricSource.MetricSource{"graphite2": localSource})
+			_, err := MakeTriggerChecker(triggerID, dataBase, logger, config, sourceProvider, &metrics.CheckerMetrics{})
+			So(err, ShouldBeError)
+			So(err, ShouldResemble, metricSource.ErrUnknownMetricSource{Name: "graphite2"})
+		})
 	})
 
 	var warnValue float64 = 10000
@@ -196,3 +204,33 @@ func TestInitTriggerChecker(t *testing.T) {
 		So(*actual, ShouldResemble, expected)
 	})
 }
+
+func TestGetBadParents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	defer mockCtrl.Finish()
+
+	Convey("Trigger without parents has no bad parents", t, func() {
+		badParents, err := getBadParents(dataBase, nil)
+		So(err, ShouldBeNil)
+		So(badParents, ShouldBeNil)
+	})
+
+	Convey("Parents with bad trigger or metric state are bad", t, func() {
+		dataBase.EXPECT().GetTriggerLastCheck("ok").Return(moira.CheckData{State: moira.StateOK, Metrics: map[string]moira.MetricState{"m": {State: moira.StateOK}}}, nil)
+		dataBase.EXPECT().GetTriggerLastCheck("error").Return(moira.CheckData{State: moira.StateERROR}, nil)
+		dataBase.EXPECT().GetTriggerLastCheck("warn").Return(moira.CheckData{State: moira.StateOK, Metrics: map[string]moira.MetricState{"m": {State: moira.StateWARN}}}, nil)
+		dataBase.EXPECT().GetTriggerLastCheck("removed").Return(moira.CheckData{}, database.ErrNil)
+		badParents, err := getBadParents(dataBase, []string{"ok", "error", "warn", "removed"})
+		So(err, ShouldBeNil)
+		So(badParents, ShouldResemble, []string{"error", "warn"})
+	})
+
+	Convey("Read last check error", t, func() {
+		readLastCheckError := fmt.Errorf("Oppps! Can't read last check")
+		dataBase.EXPECT().GetTriggerLastCheck("parent").Return(moira.CheckData{}, readLastCheckError)
+		badParents, err := getBadParents(dataBase, []string{"parent"})
+		So(err, ShouldResemble, readLastCheckError)
+		So(badParents, ShouldBeNil)
+	})
+}
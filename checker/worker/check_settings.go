@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"time"
+)
+
+const (
+	checkSettingsWorkerTicker = time.Second * 10
+)
+
+// checkSettingsWorker periodically updates check intervals and priorities of triggers
+// which are checked not with checker defaults
+func (worker *Checker) checkSettingsWorker() error {
+	checkTicker := time.NewTicker(checkSettingsWorkerTicker)
+	worker.Logger.Infof("Start triggers check settings worker. Update triggers check settings every %v", checkSettingsWorkerTicker)
+	for {
+		select {
+		case <-worker.tomb.Dying():
+			checkTicker.Stop()
+			worker.Logger.Info("Triggers check settings worker stopped")
+			return nil
+		case <-checkTicker.C:
+			if err := worker.fillTriggersCheckSettings(); err != nil {
+				worker.Logger.Errorf("Failed to get triggers check settings: %s", err.Error())
+			}
+		}
+	}
+}
+
+func (worker *Checker) fillTriggersCheckSettings() error {
+	settings, err := worker.Database.GetTriggersCheckSettings()
+	if err != nil {
+		return err
+	}
+	worker.triggersCheckSettings.Store(settings)
+	return nil
+}
@@ -46,31 +46,38 @@ func (worker *Checker) handleMetricEvent(pattern string) error {
 }
 
 func (worker *Checker) addTriggerIDsIfNeeded(triggerIDs []string) {
-	needToCheckTriggerIDs := worker.getTriggerIDsToCheck(triggerIDs)
-	if len(needToCheckTriggerIDs) > 0 {
-		worker.Database.AddLocalTriggersToCheck(needToCheckTriggerIDs) //nolint
+	for priority, needToCheckTriggerIDs := range worker.getTriggerIDsToCheck(triggerIDs) {
+		worker.Database.AddLocalTriggersToCheck(needToCheckTriggerIDs, priority) //nolint
 	}
 }
 
-func (worker *Checker) addRemoteTriggerIDsIfNeeded(triggerIDs []string) {
-	needToCheckRemoteTriggerIDs := worker.getTriggerIDsToCheck(triggerIDs)
-	if len(needToCheckRemoteTriggerIDs) > 0 {
-		worker.Database.AddRemoteTriggersToCheck(needToCheckRemoteTriggerIDs) //nolint
+func (worker *Checker) addRemoteTriggerIDsIfNeeded(source string, triggerIDs []string) {
+	for priority, needToCheckRemoteTriggerIDs := range worker.getTriggerIDsToCheck(triggerIDs) {
+		worker.Database.AddRemoteTriggersToCheck(source, needToCheckRemoteTriggerIDs, priority) //nolint
 	}
 }
 
-func (worker *Checker) getTriggerIDsToCheck(triggerIDs []string) []string {
+// getTriggerIDsToCheck returns triggers which were not checked during their check interval grouped by priority.
+// Own trigger check interval takes precedence over lazy triggers interval. Triggers are taken here only on checker ticks,
+// so check interval less than tick of metric source acts like the tick, API rejects such intervals
+func (worker *Checker) getTriggerIDsToCheck(triggerIDs []string) map[moira.TriggerPriority][]string {
 	lazyTriggerIDs := worker.lazyTriggerIDs.Load().(map[string]bool)
-	triggerIDsToCheck := make([]string, len(triggerIDs))
+	checkSettings := worker.triggersCheckSettings.Load().(map[string]moira.TriggerCheckSettings)
+	triggerIDsToCheck := make(map[moira.TriggerPriority][]string)
 	for _, triggerID := range triggerIDs {
-		if _, ok := lazyTriggerIDs[triggerID]; ok {
+		settings := checkSettings[triggerID]
+		checkInterval := cache.DefaultExpiration
+		if settings.CheckInterval > 0 {
+			checkInterval = time.Duration(settings.CheckInterval) * time.Second
+		} else if _, ok := lazyTriggerIDs[triggerID]; ok {
 			randomDuration := worker.getRandomLazyCacheDuration()
 			if err := worker.LazyTriggersCache.Add(triggerID, true, randomDuration); err != nil {
 				continue
 			}
 		}
-		if err := worker.TriggerCache.Add(triggerID, true, cache.DefaultExpiration); err == nil {
-			triggerIDsToCheck = append(triggerIDsToCheck, triggerID)
+		if err := worker.TriggerCache.Add(triggerID, true, checkInterval); err == nil {
+			priority := settings.GetPriority()
+			triggerIDsToCheck[priority] = append(triggerIDsToCheck[priority], triggerID)
 		}
 	}
 	return triggerIDsToCheck
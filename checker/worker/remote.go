@@ -1,60 +1,106 @@
 package worker
 
 import (
+	"fmt"
+	"runtime"
 	"time"
 
-	"github.com/moira-alert/moira/metric_source/remote"
+	"github.com/moira-alert/moira"
 	w "github.com/moira-alert/moira/worker"
 )
 
+// remoteAvailabilityChecker is implemented by remote metric sources which API availability can be checked
+type remoteAvailabilityChecker interface {
+	IsRemoteAvailable() (bool, error)
+}
+
 const (
 	remoteTriggerLockName = "moira-remote-checker"
 	remoteTriggerName     = "Remote checker"
 )
 
-func (worker *Checker) remoteTriggerGetter() error {
+// startRemoteCheckers starts remote triggers getter and pool of remote checkers for every configured remote source
+func (worker *Checker) startRemoteCheckers() {
+	if worker.Config.MaxParallelRemoteChecks == 0 {
+		worker.Config.MaxParallelRemoteChecks = runtime.NumCPU()
+		worker.Logger.Infof("MaxParallelRemoteChecks is not configured, set it to the number of CPU - %d", worker.Config.MaxParallelRemoteChecks)
+	}
+	for _, source := range worker.remoteSources {
+		source := source
+		worker.tomb.Go(func() error { return worker.remoteTriggerGetter(source) })
+		worker.Logger.Infof("%s started", getRemoteCheckerName(source))
+
+		worker.Logger.Infof("Start %v parallel remote checker(s) of %s remote source", worker.Config.MaxParallelRemoteChecks, source)
+		remoteTriggerIdsToCheckChan := worker.startTriggerToCheckGetter(func(count int) ([]string, error) {
+			return worker.Database.GetRemoteTriggersToCheck(source, count)
+		}, worker.Config.MaxParallelRemoteChecks)
+		for i := 0; i < worker.Config.MaxParallelRemoteChecks; i++ {
+			worker.tomb.Go(func() error {
+				return worker.startTriggerHandler(remoteTriggerIdsToCheckChan, worker.Metrics.RemoteMetrics[source])
+			})
+		}
+	}
+}
+
+func (worker *Checker) remoteTriggerGetter(source string) error {
+	lockName := remoteTriggerLockName
+	if source != moira.DefaultRemoteSource {
+		lockName = fmt.Sprintf("%s:%s", remoteTriggerLockName, source)
+	}
 	w.NewWorker(
-		remoteTriggerName,
+		getRemoteCheckerName(source),
 		worker.Logger,
-		worker.Database.NewLock(remoteTriggerLockName, nodataCheckerLockTTL),
-		worker.remoteTriggerChecker,
+		worker.Database.NewLock(lockName, nodataCheckerLockTTL),
+		func(stop <-chan struct{}) error { return worker.remoteTriggerChecker(source, stop) },
 	).Run(worker.tomb.Dying())
 
 	return nil
 }
 
-func (worker *Checker) remoteTriggerChecker(stop <-chan struct{}) error {
-	checkTicker := time.NewTicker(worker.RemoteConfig.CheckInterval)
-	worker.Logger.Info(remoteTriggerName + " started")
+func (worker *Checker) remoteTriggerChecker(source string, stop <-chan struct{}) error {
+	checkerName := getRemoteCheckerName(source)
+	checkTicker := time.NewTicker(worker.RemoteConfigs[source].CheckInterval)
+	worker.Logger.Info(checkerName + " started")
 	for {
 		select {
 		case <-stop:
-			worker.Logger.Info(remoteTriggerName + " stopped")
+			worker.Logger.Info(checkerName + " stopped")
 			checkTicker.Stop()
 			return nil
 		case <-checkTicker.C:
-			if err := worker.checkRemote(); err != nil {
-				worker.Logger.Errorf(remoteTriggerName+" failed: %s", err.Error())
+			if err := worker.checkRemote(source); err != nil {
+				worker.Logger.Errorf(checkerName+" failed: %s", err.Error())
 			}
 		}
 	}
 }
 
-func (worker *Checker) checkRemote() error {
-	source, err := worker.SourceProvider.GetRemote()
+func (worker *Checker) checkRemote(source string) error {
+	metricSource, err := worker.SourceProvider.GetRemoteSource(source)
 	if err != nil {
 		return err
 	}
-	remoteAvailable, err := source.(*remote.Remote).IsRemoteAvailable()
+	availabilityChecker, ok := metricSource.(remoteAvailabilityChecker)
+	if !ok {
+		return fmt.Errorf("availability of %s remote source can not be checked", source)
+	}
+	remoteAvailable, err := availabilityChecker.IsRemoteAvailable()
 	if !remoteAvailable {
-		worker.Logger.Infof("Remote API is unavailable. Stop checking remote triggers. Error: %s", err.Error())
+		worker.Logger.Infof("Remote API of %s remote source is unavailable. Stop checking remote triggers. Error: %s", source, err.Error())
 	} else {
-		worker.Logger.Debug("Checking remote triggers")
-		triggerIds, err := worker.Database.GetRemoteTriggerIDs()
+		worker.Logger.Debugf("Checking remote triggers of %s remote source", source)
+		triggerIds, err := worker.Database.GetRemoteTriggerIDs(source)
 		if err != nil {
 			return err
 		}
-		worker.addRemoteTriggerIDsIfNeeded(triggerIds)
+		worker.addRemoteTriggerIDsIfNeeded(source, triggerIds)
 	}
 	return nil
 }
+
+func getRemoteCheckerName(source string) string {
+	if source == moira.DefaultRemoteSource {
+		return remoteTriggerName
+	}
+	return fmt.Sprintf("%s of %s remote source", remoteTriggerName, source)
+}
@@ -18,19 +18,20 @@ import (
 
 // Checker represents workers for periodically triggers checking based by new events
 type Checker struct {
-	Logger            moira.Logger
-	Database          moira.Database
-	Config            *checker.Config
-	RemoteConfig      *remote.Config
-	SourceProvider    *metricSource.SourceProvider
-	Metrics           *metrics.CheckerMetrics
-	TriggerCache      *cache.Cache
-	LazyTriggersCache *cache.Cache
-	PatternCache      *cache.Cache
-	lazyTriggerIDs    atomic.Value
-	lastData          int64
-	tomb              tomb.Tomb
-	remoteEnabled     bool
+	Logger                moira.Logger
+	Database              moira.Database
+	Config                *checker.Config
+	RemoteConfigs         map[string]*remote.Config
+	SourceProvider        *metricSource.SourceProvider
+	Metrics               *metrics.CheckerMetrics
+	TriggerCache          *cache.Cache
+	LazyTriggersCache     *cache.Cache
+	PatternCache          *cache.Cache
+	lazyTriggerIDs        atomic.Value
+	triggersCheckSettings atomic.Value
+	lastData              int64
+	tomb                  tomb.Tomb
+	remoteSources         []string
 }
 
 // Start start schedule new MetricEvents and check for NODATA triggers
@@ -50,22 +51,13 @@ func (worker *Checker) Start() error {
 	worker.lazyTriggerIDs.Store(make(map[string]bool))
 	worker.tomb.Go(worker.lazyTriggersWorker)
 
-	worker.tomb.Go(worker.localTriggerGetter)
-
-	_, err = worker.SourceProvider.GetRemote()
-	worker.remoteEnabled = err == nil
-
-	if worker.remoteEnabled && worker.Config.MaxParallelRemoteChecks == 0 {
-		worker.Config.MaxParallelRemoteChecks = runtime.NumCPU()
-		worker.Logger.Infof("MaxParallelRemoteChecks is not configured, set it to the number of CPU - %d", worker.Config.MaxParallelRemoteChecks)
+	worker.triggersCheckSettings.Store(make(map[string]moira.TriggerCheckSettings))
+	if err := worker.fillTriggersCheckSettings(); err != nil {
+		worker.Logger.Errorf("Failed to get triggers check settings: %s", err.Error())
 	}
+	worker.tomb.Go(worker.checkSettingsWorker)
 
-	if worker.remoteEnabled {
-		worker.tomb.Go(worker.remoteTriggerGetter)
-		worker.Logger.Info("Remote checker started")
-	} else {
-		worker.Logger.Info("Remote checker disabled")
-	}
+	worker.tomb.Go(worker.localTriggerGetter)
 
 	worker.Logger.Infof("Start %v parallel local checker(s)", worker.Config.MaxParallelChecks)
 	localTriggerIdsToCheckChan := worker.startTriggerToCheckGetter(worker.Database.GetLocalTriggersToCheck, worker.Config.MaxParallelChecks)
@@ -78,14 +70,11 @@ func (worker *Checker) Start() error {
 		})
 	}
 
-	if worker.remoteEnabled {
-		worker.Logger.Infof("Start %v parallel remote checker(s)", worker.Config.MaxParallelRemoteChecks)
-		remoteTriggerIdsToCheckChan := worker.startTriggerToCheckGetter(worker.Database.GetRemoteTriggersToCheck, worker.Config.MaxParallelRemoteChecks)
-		for i := 0; i < worker.Config.MaxParallelRemoteChecks; i++ {
-			worker.tomb.Go(func() error {
-				return worker.startTriggerHandler(remoteTriggerIdsToCheckChan, worker.Metrics.RemoteMetrics)
-			})
-		}
+	worker.remoteSources = worker.SourceProvider.GetRemoteSourceNames()
+	if len(worker.remoteSources) > 0 {
+		worker.startRemoteCheckers()
+	} else {
+		worker.Logger.Info("Remote checker disabled")
 	}
 	worker.Logger.Info("Checking new events started")
 
@@ -101,7 +90,7 @@ func (worker *Checker) Start() error {
 
 func (worker *Checker) checkTriggersToCheckCount() error {
 	checkTicker := time.NewTicker(time.Millisecond * 100) //nolint
-	var triggersToCheckCount, remoteTriggersToCheckCount int64
+	var triggersToCheckCount int64
 	var err error
 	for {
 		select {
@@ -112,10 +101,10 @@ func (worker *Checker) checkTriggersToCheckCount() error {
 			if err == nil {
 				worker.Metrics.LocalMetrics.TriggersToCheckCount.Update(triggersToCheckCount)
 			}
-			if worker.remoteEnabled {
-				remoteTriggersToCheckCount, err = worker.Database.GetRemoteTriggersToCheckCount()
+			for _, source := range worker.remoteSources {
+				remoteTriggersToCheckCount, err := worker.Database.GetRemoteTriggersToCheckCount(source)
 				if err == nil {
-					worker.Metrics.RemoteMetrics.TriggersToCheckCount.Update(remoteTriggersToCheckCount)
+					worker.Metrics.RemoteMetrics[source].TriggersToCheckCount.Update(remoteTriggersToCheckCount)
 				}
 			}
 		}
@@ -3,9 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/cmd"
+	"github.com/xiam/to"
 )
 
 type config struct {
@@ -15,6 +17,7 @@ type config struct {
 	Web       webConfig           `yaml:"web"`
 	Telemetry cmd.TelemetryConfig `yaml:"telemetry"`
 	Remote    cmd.RemoteConfig    `yaml:"remote"`
+	Remotes   cmd.RemotesConfig   `yaml:"remotes"`
 }
 
 type apiConfig struct {
@@ -22,6 +25,10 @@ type apiConfig struct {
 	Listen string `yaml:"listen"`
 	// If true, CORS for cross-domain requests will be enabled. This option can be used only for debugging purposes.
 	EnableCORS bool `yaml:"enable_cors"`
+	// Names of throttling policies configured in notifier. Subscriptions can select only these policies and policy named "default".
+	ThrottlingPolicies []string `yaml:"throttling_policies"`
+	// Period of local triggers check, should be equal to check_interval of checker. Triggers can't have own check_interval less than it.
+	LocalCheckInterval string `yaml:"local_check_interval"`
 }
 
 type webConfig struct {
@@ -47,14 +54,22 @@ type webContact struct {
 	Help string `yaml:"help"`
 }
 
-func (config *apiConfig) getSettings() *api.Config {
+func (config *apiConfig) getSettings(remote cmd.RemoteConfig, remotes cmd.RemotesConfig) *api.Config {
+	checkIntervals := map[string]time.Duration{
+		"": to.Duration(config.LocalCheckInterval),
+	}
+	for name, settings := range remotes.GetRemoteSourcesSettings(remote) {
+		checkIntervals[name] = settings.CheckInterval
+	}
 	return &api.Config{
-		Listen:     config.Listen,
-		EnableCORS: config.EnableCORS,
+		Listen:             config.Listen,
+		EnableCORS:         config.EnableCORS,
+		ThrottlingPolicies: config.ThrottlingPolicies,
+		CheckIntervals:     checkIntervals,
 	}
 }
 
-func (config *webConfig) getSettings(isRemoteEnabled bool) ([]byte, error) {
+func (config *webConfig) getSettings(remoteSources []string) ([]byte, error) {
 	webContacts := make([]api.WebContact, 0, len(config.Contacts))
 	for _, configContact := range config.Contacts {
 		contact := api.WebContact{
@@ -68,7 +83,8 @@ func (config *webConfig) getSettings(isRemoteEnabled bool) ([]byte, error) {
 	}
 	configContent, err := json.Marshal(api.WebConfig{
 		SupportEmail:  config.SupportEmail,
-		RemoteAllowed: isRemoteEnabled,
+		RemoteAllowed: len(remoteSources) > 0,
+		RemoteSources: remoteSources,
 		Contacts:      webContacts,
 	})
 	if err != nil {
@@ -90,8 +106,9 @@ func getDefault() config {
 			LogLevel: "info",
 		},
 		API: apiConfig{
-			Listen:     ":8081",
-			EnableCORS: false,
+			Listen:             ":8081",
+			EnableCORS:         false,
+			LocalCheckInterval: "5s",
 		},
 		Web: webConfig{
 			RemoteAllowed: false,
@@ -108,8 +125,9 @@ func getDefault() config {
 			Pprof: cmd.ProfilerConfig{Enabled: false},
 		},
 		Remote: cmd.RemoteConfig{
-			Timeout:    "60s",
-			MetricsTTL: "7d",
+			CheckInterval: "60s",
+			Timeout:       "60s",
+			MetricsTTL:    "7d",
 		},
 	}
 }
@@ -19,7 +19,6 @@ import (
 	"github.com/moira-alert/moira/logging/go-logging"
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
-	"github.com/moira-alert/moira/metric_source/remote"
 )
 
 const serviceName = "api"
@@ -59,7 +58,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	apiConfig := config.API.getSettings()
+	apiConfig := config.API.getSettings(config.Remote, config.Remotes)
 
 	logger, err := logging.ConfigureLog(config.Logger.LogFile, config.Logger.LogLevel, serviceName)
 	if err != nil {
@@ -102,11 +101,13 @@ func main() {
 	logger.Infof("Start listening by address: [%s]", apiConfig.Listen)
 
 	localSource := local.Create(database)
-	remoteConfig := config.Remote.GetRemoteSourceSettings()
-	remoteSource := remote.Create(remoteConfig)
-	metricSourceProvider := metricSource.CreateMetricSourceProvider(localSource, remoteSource)
+	remoteSources, err := config.Remotes.CreateRemoteSources(config.Remote)
+	if err != nil {
+		logger.Fatalf("Can not configure remote sources: %s", err.Error())
+	}
+	metricSourceProvider := metricSource.CreateMetricSourceProviderWithRemotes(localSource, remoteSources)
 
-	webConfigContent, err := config.Web.getSettings(remoteConfig.Enabled)
+	webConfigContent, err := config.Web.getSettings(metricSourceProvider.GetRemoteSourceNames())
 	if err != nil {
 		logger.Fatal(err)
 	}
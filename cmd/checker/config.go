@@ -1,6 +1,10 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/checker"
 	"github.com/moira-alert/moira/cmd"
 	"github.com/xiam/to"
@@ -12,6 +16,7 @@ type config struct {
 	Checker   checkerConfig       `yaml:"checker"`
 	Telemetry cmd.TelemetryConfig `yaml:"telemetry"`
 	Remote    cmd.RemoteConfig    `yaml:"remote"`
+	Remotes   cmd.RemotesConfig   `yaml:"remotes"`
 }
 
 type checkerConfig struct {
@@ -28,9 +33,20 @@ type checkerConfig struct {
 	MaxParallelChecks int `yaml:"max_parallel_checks"`
 	// Max concurrent remote checkers to run. Equals to the number of processor cores found on Moira host by default or when variable is defined as 0.
 	MaxParallelRemoteChecks int `yaml:"max_parallel_remote_checks"`
+	// Default intervals between reminders about trigger or metric staying in WARN, ERROR, NODATA or EXCEPTION state.
+	// Intervals should be whole number of hours, zero interval disables reminders about state. Triggers can override these intervals
+	BadStateReminder map[string]string `yaml:"bad_state_reminder"`
+	// Number of latest checks of every trigger which diagnostics are stored and shown in API. Zero disables diagnostics
+	DiagnosticsCount int `yaml:"diagnostics_count"`
+	// Diagnostics of trigger checks are removed if trigger is not checked during this period. Zero keeps diagnostics forever
+	DiagnosticsTTL string `yaml:"diagnostics_ttl"`
 }
 
-func (config *checkerConfig) getSettings() *checker.Config {
+func (config *checkerConfig) getSettings() (*checker.Config, error) {
+	badStateReminder, err := config.getBadStateReminder()
+	if err != nil {
+		return nil, err
+	}
 	return &checker.Config{
 		CheckInterval:               to.Duration(config.CheckInterval),
 		LazyTriggersCheckInterval:   to.Duration(config.LazyTriggersCheckInterval),
@@ -38,7 +54,21 @@ func (config *checkerConfig) getSettings() *checker.Config {
 		StopCheckingIntervalSeconds: int64(to.Duration(config.StopCheckingInterval).Seconds()),
 		MaxParallelChecks:           config.MaxParallelChecks,
 		MaxParallelRemoteChecks:     config.MaxParallelRemoteChecks,
+		BadStateReminder:            badStateReminder,
+		DiagnosticsCount:            config.DiagnosticsCount,
+		DiagnosticsTTL:              to.Duration(config.DiagnosticsTTL),
+	}, nil
+}
+
+func (config *checkerConfig) getBadStateReminder() (moira.ReminderIntervals, error) {
+	reminders := make(moira.ReminderIntervals, len(config.BadStateReminder))
+	for state, interval := range config.BadStateReminder {
+		reminders[moira.State(strings.ToUpper(state))] = int64(to.Duration(interval).Seconds())
+	}
+	if err := reminders.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bad_state_reminder: %s", err.Error())
 	}
+	return reminders, nil
 }
 
 func getDefault() config {
@@ -60,6 +90,12 @@ func getDefault() config {
 			StopCheckingInterval:      "30s",
 			MaxParallelChecks:         0,
 			MaxParallelRemoteChecks:   0,
+			BadStateReminder: map[string]string{
+				string(moira.StateERROR):  "24h",
+				string(moira.StateNODATA): "24h",
+			},
+			DiagnosticsCount: 10, //nolint
+			DiagnosticsTTL:   "24h",
 		},
 		Telemetry: cmd.TelemetryConfig{
 			Listen: ":8092",
@@ -10,7 +10,6 @@ import (
 
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
-	"github.com/moira-alert/moira/metric_source/remote"
 	"github.com/patrickmn/go-cache"
 
 	"github.com/moira-alert/moira"
@@ -77,14 +76,19 @@ func main() {
 	databaseSettings := config.Redis.GetSettings()
 	database := redis.NewDatabase(logger, databaseSettings, redis.Checker)
 
-	remoteConfig := config.Remote.GetRemoteSourceSettings()
+	remoteConfigs := config.Remotes.GetRemoteSourcesSettings(config.Remote)
 	localSource := local.Create(database)
-	remoteSource := remote.Create(remoteConfig)
-	metricSourceProvider := metricSource.CreateMetricSourceProvider(localSource, remoteSource)
+	remoteSources, err := config.Remotes.CreateRemoteSources(config.Remote)
+	if err != nil {
+		logger.Fatalf("Can not configure remote sources: %s", err.Error())
+	}
+	metricSourceProvider := metricSource.CreateMetricSourceProviderWithRemotes(localSource, remoteSources)
 
-	isConfigured, _ := remoteSource.IsConfigured()
-	checkerMetrics := metrics.ConfigureCheckerMetrics(telemetry.Metrics, isConfigured)
-	checkerSettings := config.Checker.getSettings()
+	checkerMetrics := metrics.ConfigureCheckerMetrics(telemetry.Metrics, metricSourceProvider.GetRemoteSourceNames())
+	checkerSettings, err := config.Checker.getSettings()
+	if err != nil {
+		logger.Fatalf("Can not configure checker: %s", err.Error())
+	}
 	if triggerID != nil && *triggerID != "" {
 		checkSingleTrigger(database, checkerMetrics, checkerSettings, metricSourceProvider)
 	}
@@ -93,7 +97,7 @@ func main() {
 		Logger:            logger,
 		Database:          database,
 		Config:            checkerSettings,
-		RemoteConfig:      remoteConfig,
+		RemoteConfigs:     remoteConfigs,
 		SourceProvider:    metricSourceProvider,
 		Metrics:           checkerMetrics,
 		TriggerCache:      cache.New(checkerSettings.CheckInterval, time.Minute*60), //nolint
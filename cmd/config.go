@@ -7,7 +7,10 @@ import (
 
 	"github.com/moira-alert/moira/metrics"
 
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/image_store/s3"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/prometheus"
 	remoteSource "github.com/moira-alert/moira/metric_source/remote"
 	"github.com/xiam/to"
 	"gopkg.in/yaml.v2"
@@ -93,9 +96,11 @@ type ProfilerConfig struct {
 	Enabled bool `yaml:"enabled"`
 }
 
-// RemoteConfig is remote graphite settings structure
+// RemoteConfig is remote graphite or prometheus settings structure
 type RemoteConfig struct {
-	// graphite url e.g http://graphite/render
+	// Type of remote storage API: graphite (default) or prometheus
+	Type string `yaml:"type"`
+	// graphite url e.g http://graphite/render or prometheus url e.g http://prometheus:9090
 	URL string `yaml:"url"`
 	// Min period to perform triggers re-check. Note: Reducing of this value leads to increasing of CPU and memory usage values
 	CheckInterval string `yaml:"check_interval"`
@@ -109,10 +114,18 @@ type RemoteConfig struct {
 	User string `yaml:"user"`
 	// Password for basic auth
 	Password string `yaml:"password"`
+	// Query resolution step of prometheus range queries, one minute by default
+	Step string `yaml:"step"`
 	// If true, remote worker will be enabled.
 	Enabled bool `yaml:"enabled"`
 }
 
+// Remote storage API types
+const (
+	RemoteTypeGraphite   = "graphite"
+	RemoteTypePrometheus = "prometheus"
+)
+
 // ImageStoreConfig defines the configuration for all the image stores to be initialized by InitImageStores
 type ImageStoreConfig struct {
 	S3 s3.Config `yaml:"s3"`
@@ -131,6 +144,64 @@ func (config *RemoteConfig) GetRemoteSourceSettings() *remoteSource.Config {
 	}
 }
 
+// GetPrometheusSourceSettings returns prometheus config parsed from moira config files
+func (config *RemoteConfig) GetPrometheusSourceSettings() *prometheus.Config {
+	return &prometheus.Config{
+		URL:        config.URL,
+		MetricsTTL: to.Duration(config.MetricsTTL),
+		Timeout:    to.Duration(config.Timeout),
+		Step:       to.Duration(config.Step),
+		User:       config.User,
+		Password:   config.Password,
+		Enabled:    config.Enabled,
+	}
+}
+
+// CreateMetricSource creates remote metric source of configured type
+func (config *RemoteConfig) CreateMetricSource() (metricSource.MetricSource, error) {
+	switch config.Type {
+	case "", RemoteTypeGraphite:
+		return remoteSource.Create(config.GetRemoteSourceSettings()), nil
+	case RemoteTypePrometheus:
+		return prometheus.Create(config.GetPrometheusSourceSettings()), nil
+	default:
+		return nil, fmt.Errorf("unknown remote type '%s', it can be only '%s' or '%s'", config.Type, RemoteTypeGraphite, RemoteTypePrometheus)
+	}
+}
+
+// RemotesConfig is a collection of named remote graphite or prometheus settings
+type RemotesConfig map[string]RemoteConfig
+
+// GetRemoteSourcesSettings returns settings of named remote sources parsed from moira config files.
+// Remote config is used as settings of default remote source
+func (config RemotesConfig) GetRemoteSourcesSettings(defaultRemote RemoteConfig) map[string]*remoteSource.Config {
+	settings := map[string]*remoteSource.Config{
+		moira.DefaultRemoteSource: defaultRemote.GetRemoteSourceSettings(),
+	}
+	for name, remote := range config {
+		settings[name] = remote.GetRemoteSourceSettings()
+	}
+	return settings
+}
+
+// CreateRemoteSources creates named remote metric sources of configured types.
+// Remote config is used to create default remote source
+func (config RemotesConfig) CreateRemoteSources(defaultRemote RemoteConfig) (map[string]metricSource.MetricSource, error) {
+	sources := make(map[string]metricSource.MetricSource, len(config)+1)
+	remotes := map[string]RemoteConfig{moira.DefaultRemoteSource: defaultRemote}
+	for name, remote := range config {
+		remotes[name] = remote
+	}
+	for name, remote := range remotes {
+		source, err := remote.CreateMetricSource()
+		if err != nil {
+			return nil, fmt.Errorf("can not create %s remote source: %s", name, err.Error())
+		}
+		sources[name] = source
+	}
+	return sources, nil
+}
+
 // ReadConfig parses config file by the given path into Moira-used type
 func ReadConfig(configFileName string, config interface{}) error {
 	configYaml, err := ioutil.ReadFile(configFileName)
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"github.com/xiam/to"
+
 	"github.com/moira-alert/moira/cmd"
+	"github.com/moira-alert/moira/filter"
 )
 
 type config struct {
@@ -14,9 +17,22 @@ type config struct {
 type filterConfig struct {
 	// Metrics listener uri
 	Listen string `yaml:"listen"`
+	// Additional metrics listeners uris. Supported formats are tcp://host:port, udp://host:port, unix:///path/to/socket
+	// pickle://host:port for carbon pickle protocol over tcp, influx://host:port for influx line protocol over tcp
+	// and http://host:port for http write endpoints (prometheus remote_write on /api/v1/write and influx write on /write)
+	Listeners []string `yaml:"listeners"`
+	// Rules to convert influx line protocol points to metrics
+	Influx influxConfig `yaml:"influx"`
 	// Retentions config file path.
 	// Simply use your original storage-schemas.conf or create new if you're using Moira without existing Graphite installation.
 	RetentionConfig string `yaml:"retention_config"`
+	// Period in which retentions config file is checked for changes and reloaded. It is also reloaded on SIGHUP.
+	RetentionConfigUpdatePeriod string `yaml:"retention_config_update_period"`
+	// Aggregations config file path in storage-aggregation.conf format. Optional.
+	// Points which fall into the same retention bucket are aggregated by average, sum, min, max or last method of first matched pattern,
+	// so local triggers see the same values as Graphite stores. Without config or matched pattern points are saved as is.
+	// Points which come later than the latest 3 retention buckets of metric are ignored for aggregated metrics.
+	AggregationConfig string `yaml:"aggregation_config"`
 	// Number of metrics to cache before checking them.
 	// Note: As this value increases, Redis CPU usage decreases.
 	// Normally, this value must be an order of magnitude less than graphite.prefix.filter.recevied.matching.count | nonNegativeDerivative() | scaleToSeconds(1)
@@ -26,6 +42,90 @@ type filterConfig struct {
 	MaxParallelMatches int `yaml:"max_parallel_matches"`
 	// Period in which patterns will be reloaded from Redis.
 	PatternsUpdatePeriod string `yaml:"patterns_update_period"`
+	// Limits of matched metrics grouped by metric name prefix, protect storage from services which send too many metrics.
+	PrefixLimits prefixLimitsConfig `yaml:"prefix_limits"`
+	// Sampled lines rejected by parser are kept per reason for inspection via API.
+	RejectedLines rejectedLinesConfig `yaml:"rejected_lines"`
+	// Rewrite rules file path. Optional.
+	// Yaml list of rules which rename, drop, keep only or retag metrics in order before pattern matching.
+	RewriteRules string `yaml:"rewrite_rules"`
+	// Period in which rewrite rules file is checked for changes and reloaded. It is also reloaded on SIGHUP.
+	RewriteRulesUpdatePeriod string `yaml:"rewrite_rules_update_period"`
+}
+
+type prefixLimitsConfig struct {
+	// Number of first dot-separated metric name parts used as prefix
+	PrefixDepth int `yaml:"prefix_depth"`
+	// Max count of metrics per prefix, points of new metrics over the limit are not saved. 0 disables limit
+	MaxMetrics int `yaml:"max_metrics"`
+	// Max count of points per second per prefix, points over the limit are not saved. 0 disables limit
+	MaxPointsPerSecond int `yaml:"max_points_per_second"`
+	// Metric is not counted in prefix metrics after it was not received for this period
+	MetricTTL string `yaml:"metric_ttl"`
+}
+
+func (config *prefixLimitsConfig) getSettings() filter.PrefixGuardConfig {
+	return filter.PrefixGuardConfig{
+		PrefixDepth:        config.PrefixDepth,
+		MaxMetrics:         config.MaxMetrics,
+		MaxPointsPerSecond: config.MaxPointsPerSecond,
+		MetricTTL:          to.Duration(config.MetricTTL),
+	}
+}
+
+type rejectedLinesConfig struct {
+	// Every sample_rate-th rejected line of each reason is kept
+	SampleRate int `yaml:"sample_rate"`
+	// Max count of kept lines per reason. 0 disables recording of rejected lines
+	MaxLines int `yaml:"max_lines"`
+}
+
+func (config *rejectedLinesConfig) getSettings() filter.RejectedLinesConfig {
+	return filter.RejectedLinesConfig{
+		SampleRate: config.SampleRate,
+		MaxLines:   config.MaxLines,
+	}
+}
+
+type influxConfig struct {
+	// Rules are checked in order, first rule with measurement matching the point measurement is applied.
+	// If no rule matches, metric is named as "measurement.field"
+	Rules []influxRuleConfig `yaml:"rules"`
+	// If true, field named "value" is not added to metric name
+	SkipValueField bool `yaml:"skip_value_field"`
+	// If true, tags which are not used in template are dropped, otherwise they are added to metric as graphite tags
+	DropTags bool `yaml:"drop_tags"`
+}
+
+type influxRuleConfig struct {
+	// Regular expression to match point measurement, empty pattern matches any measurement
+	Measurement string `yaml:"measurement"`
+	// Dot-separated metric name template which consists of "measurement", "field" and tag names, e.g. "host.measurement.field"
+	Template string `yaml:"template"`
+}
+
+func (config *influxConfig) getSettings() filter.InfluxConfig {
+	rules := make([]filter.InfluxRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		rules = append(rules, filter.InfluxRule{
+			Measurement: rule.Measurement,
+			Template:    rule.Template,
+		})
+	}
+	return filter.InfluxConfig{
+		Rules:          rules,
+		SkipValueField: config.SkipValueField,
+		DropTags:       config.DropTags,
+	}
+}
+
+// getListenAddresses returns all configured metrics listeners uris
+func (config *filterConfig) getListenAddresses() []string {
+	addresses := make([]string, 0, len(config.Listeners)+1)
+	if config.Listen != "" {
+		addresses = append(addresses, config.Listen)
+	}
+	return append(addresses, config.Listeners...)
 }
 
 func getDefault() config {
@@ -41,11 +141,21 @@ func getDefault() config {
 			LogLevel: "info",
 		},
 		Filter: filterConfig{
-			Listen:               ":2003",
-			RetentionConfig:      "/etc/moira/storage-schemas.conf",
-			CacheCapacity:        10, //nolint
-			MaxParallelMatches:   0,
-			PatternsUpdatePeriod: "1s",
+			Listen:                      ":2003",
+			RetentionConfig:             "/etc/moira/storage-schemas.conf",
+			RetentionConfigUpdatePeriod: "10s",
+			CacheCapacity:               10, //nolint
+			MaxParallelMatches:          0,
+			PatternsUpdatePeriod:        "1s",
+			RewriteRulesUpdatePeriod:    "10s",
+			PrefixLimits: prefixLimitsConfig{
+				PrefixDepth: 2, //nolint
+				MetricTTL:   "1h",
+			},
+			RejectedLines: rejectedLinesConfig{
+				SampleRate: 10,  //nolint
+				MaxLines:   100, //nolint
+			},
 		},
 		Telemetry: cmd.TelemetryConfig{
 			Listen: ":8094",
@@ -89,12 +89,37 @@ func main() {
 	if err != nil {
 		logger.Fatalf("Failed to initialize cache storage with config [%s]: %s", config.Filter.RetentionConfig, err.Error())
 	}
+	retentionConfigFile.Close()
+
+	// Start retentions updater
+	retentionsWorker := patterns.NewRetentionsWorker(logger, cacheStorage, config.Filter.RetentionConfig, to.Duration(config.Filter.RetentionConfigUpdatePeriod))
+	if err = retentionsWorker.Start(); err != nil {
+		logger.Fatalf("Failed to start retentions updater with config [%s]: %s", config.Filter.RetentionConfig, err.Error())
+	}
+	defer stopRetentionsWorker(retentionsWorker)
+
+	if config.Filter.AggregationConfig != "" {
+		aggregationConfigFile, err := os.Open(config.Filter.AggregationConfig)
+		if err != nil {
+			logger.Fatalf("Error open aggregations file [%s]: %s", config.Filter.AggregationConfig, err.Error())
+		}
+		if err = cacheStorage.LoadAggregations(aggregationConfigFile); err != nil {
+			logger.Fatalf("Failed to load aggregations with config [%s]: %s", config.Filter.AggregationConfig, err.Error())
+		}
+		aggregationConfigFile.Close()
+	}
 
 	patternStorage, err := filter.NewPatternStorage(database, filterMetrics, logger)
 	if err != nil {
 		logger.Fatalf("Failed to refresh pattern storage: %s", err.Error())
 	}
 
+	// Start rejected lines recorder
+	rejectedLinesRecorder := filter.NewRejectedLinesRecorder(database, logger, config.Filter.RejectedLines.getSettings())
+	patternStorage.SetRejectedLinesRecorder(rejectedLinesRecorder)
+	rejectedLinesRecorder.Start()
+	defer stopRejectedLinesRecorder(rejectedLinesRecorder)
+
 	// Refresh Patterns on first init
 	refreshPatternWorker := patterns.NewRefreshPatternWorker(database, filterMetrics, logger, patternStorage, to.Duration(config.Filter.PatternsUpdatePeriod))
 
@@ -105,24 +130,39 @@ func main() {
 	}
 	defer stopRefreshPatternWorker(refreshPatternWorker)
 
+	// Start rewrite rules updater
+	if config.Filter.RewriteRules != "" {
+		rewriteRulesWorker := patterns.NewRewriteRulesWorker(filterMetrics, logger, patternStorage, config.Filter.RewriteRules, to.Duration(config.Filter.RewriteRulesUpdatePeriod))
+		if err = rewriteRulesWorker.Start(); err != nil {
+			logger.Fatalf("Failed to load rewrite rules [%s]: %s", config.Filter.RewriteRules, err.Error())
+		}
+		defer stopRewriteRulesWorker(rewriteRulesWorker)
+	}
+
 	// Start Filter heartbeat
 	heartbeatWorker := heartbeat.NewHeartbeatWorker(database, filterMetrics, logger)
 	heartbeatWorker.Start()
 	defer stopHeartbeatWorker(heartbeatWorker)
 
+	influxConverter, err := filter.NewInfluxConverter(config.Filter.Influx.getSettings())
+	if err != nil {
+		logger.Fatalf("Failed to initialize influx converter: %s", err.Error())
+	}
+
 	// Start metrics listener
-	listener, err := connection.NewListener(config.Filter.Listen, logger, filterMetrics)
+	listener, err := connection.NewListener(config.Filter.getListenAddresses(), influxConverter, logger, filterMetrics)
 	if err != nil {
 		logger.Fatalf("Failed to start listen: %s", err.Error())
 	}
-	lineChan := listener.Listen()
+	lineChan, parsedMetricsChan := listener.Listen()
 
 	patternMatcher := patterns.NewMatcher(logger, filterMetrics, patternStorage)
-	metricsChan := patternMatcher.Start(config.Filter.MaxParallelMatches, lineChan)
+	metricsChan := patternMatcher.Start(config.Filter.MaxParallelMatches, lineChan, parsedMetricsChan)
 
 	// Start metrics matcher
 	cacheCapacity := config.Filter.CacheCapacity
-	metricsMatcher := matchedmetrics.NewMetricsMatcher(filterMetrics, logger, database, cacheStorage, cacheCapacity)
+	prefixGuard := filter.NewPrefixGuard(config.Filter.PrefixLimits.getSettings(), filterMetrics)
+	metricsMatcher := matchedmetrics.NewMetricsMatcher(filterMetrics, logger, database, cacheStorage, prefixGuard, cacheCapacity)
 	metricsMatcher.Start(metricsChan)
 	defer metricsMatcher.Wait()  // First stop listener
 	defer stopListener(listener) // Then waiting for metrics matcher handle all received events
@@ -146,6 +186,24 @@ func stopHeartbeatWorker(heartbeatWorker *heartbeat.Worker) {
 	}
 }
 
+func stopRejectedLinesRecorder(rejectedLinesRecorder *filter.RejectedLinesRecorder) {
+	if err := rejectedLinesRecorder.Stop(); err != nil {
+		logger.Errorf("Failed to stop rejected lines recorder: %v", err)
+	}
+}
+
+func stopRetentionsWorker(retentionsWorker *patterns.ReloadableFileWorker) {
+	if err := retentionsWorker.Stop(); err != nil {
+		logger.Errorf("Failed to stop retentions worker: %v", err)
+	}
+}
+
+func stopRewriteRulesWorker(rewriteRulesWorker *patterns.ReloadableFileWorker) {
+	if err := rewriteRulesWorker.Stop(); err != nil {
+		logger.Errorf("Failed to stop rewrite rules worker: %v", err)
+	}
+}
+
 func stopRefreshPatternWorker(refreshPatternWorker *patterns.RefreshPatternWorker) {
 	if err := refreshPatternWorker.Stop(); err != nil {
 		logger.Errorf("Failed to stop refresh pattern worker: %v", err)
@@ -18,6 +18,7 @@ type config struct {
 	Notifier    notifierConfig       `yaml:"notifier"`
 	Telemetry   cmd.TelemetryConfig  `yaml:"telemetry"`
 	Remote      cmd.RemoteConfig     `yaml:"remote"`
+	Remotes     cmd.RemotesConfig    `yaml:"remotes"`
 	ImageStores cmd.ImageStoreConfig `yaml:"image_store"`
 }
 
@@ -38,6 +39,19 @@ type notifierConfig struct {
 	DateTimeFormat string `yaml:"date_time_format"`
 	// Amount of messages notifier reads from Redis per iteration. Use notifier.NotificationsLimitUnlimited for unlimited.
 	ReadBatchSize int `yaml:"read_batch_size"`
+	// Named throttling policies which subscriptions can select. Policy named "default" overrides built-in throttling levels.
+	ThrottlingPolicies map[string][]throttlingLevelConfig `yaml:"throttling_policies"`
+	// Time to keep history of notification sending attempts. Empty value disables history
+	NotificationHistoryTTL string `yaml:"notification_history_ttl"`
+}
+
+type throttlingLevelConfig struct {
+	// Period in which trigger events are counted
+	Window string `yaml:"window"`
+	// Amount of trigger events in window to start throttling
+	Count int64 `yaml:"count"`
+	// Delay of notifications when throttling is started
+	Delay string `yaml:"delay"`
 }
 
 type selfStateConfig struct {
@@ -82,9 +96,10 @@ func getDefault() config {
 				LastCheckDelay:          "60s",
 				NoticeInterval:          "300s",
 			},
-			FrontURI:      "http://localhost",
-			Timezone:      "UTC",
-			ReadBatchSize: int(notifier.NotificationsLimitUnlimited),
+			FrontURI:               "http://localhost",
+			Timezone:               "UTC",
+			ReadBatchSize:          int(notifier.NotificationsLimitUnlimited),
+			NotificationHistoryTTL: "48h",
 		},
 		Telemetry: cmd.TelemetryConfig{
 			Listen: ":8093",
@@ -132,16 +147,37 @@ func (config *notifierConfig) getSettings(logger moira.Logger) notifier.Config {
 	}
 	logger.Infof("Current read_batch_size is %d", readBatchSize)
 
+	throttlingPolicies := make(map[string]notifier.ThrottlingPolicy, len(config.ThrottlingPolicies))
+	for name, levels := range config.ThrottlingPolicies {
+		policy := make(notifier.ThrottlingPolicy, 0, len(levels))
+		for _, levelConfig := range levels {
+			level := notifier.ThrottlingLevel{
+				Window: to.Duration(levelConfig.Window),
+				Count:  levelConfig.Count,
+				Delay:  to.Duration(levelConfig.Delay),
+			}
+			if level.Window <= 0 || level.Count <= 0 {
+				logger.Warningf("Throttling policy '%s' has invalid level with window '%s' and count %d, level ignored",
+					name, levelConfig.Window, levelConfig.Count)
+				continue
+			}
+			policy = append(policy, level)
+		}
+		throttlingPolicies[name] = policy
+	}
+
 	return notifier.Config{
-		SelfStateEnabled:  config.SelfState.Enabled,
-		SelfStateContacts: config.SelfState.Contacts,
-		SendingTimeout:    to.Duration(config.SenderTimeout),
-		ResendingTimeout:  to.Duration(config.ResendingTimeout),
-		Senders:           config.Senders,
-		FrontURL:          config.FrontURI,
-		Location:          location,
-		DateTimeFormat:    format,
-		ReadBatchSize:     readBatchSize,
+		SelfStateEnabled:       config.SelfState.Enabled,
+		SelfStateContacts:      config.SelfState.Contacts,
+		SendingTimeout:         to.Duration(config.SenderTimeout),
+		ResendingTimeout:       to.Duration(config.ResendingTimeout),
+		Senders:                config.Senders,
+		FrontURL:               config.FrontURI,
+		Location:               location,
+		DateTimeFormat:         format,
+		ReadBatchSize:          readBatchSize,
+		ThrottlingPolicies:     throttlingPolicies,
+		NotificationHistoryTTL: to.Duration(config.NotificationHistoryTTL),
 	}
 }
 
@@ -154,13 +190,14 @@ func checkDateTimeFormat(format string) error {
 	return nil
 }
 
-func (config *selfStateConfig) getSettings() selfstate.Config {
+func (config *selfStateConfig) getSettings(remoteSources []string) selfstate.Config {
 	return selfstate.Config{
 		Enabled:                        config.Enabled,
 		RedisDisconnectDelaySeconds:    int64(to.Duration(config.RedisDisconnectDelay).Seconds()),
 		LastMetricReceivedDelaySeconds: int64(to.Duration(config.LastMetricReceivedDelay).Seconds()),
 		LastCheckDelaySeconds:          int64(to.Duration(config.LastCheckDelay).Seconds()),
 		LastRemoteCheckDelaySeconds:    int64(to.Duration(config.LastRemoteCheckDelay).Seconds()),
+		RemoteSources:                  remoteSources,
 		Contacts:                       config.Contacts,
 		NoticeIntervalSeconds:          int64(to.Duration(config.NoticeInterval).Seconds()),
 	}
@@ -9,7 +9,6 @@ import (
 
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
-	"github.com/moira-alert/moira/metric_source/remote"
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/cmd"
@@ -78,9 +77,11 @@ func main() {
 	database := redis.NewDatabase(logger, databaseSettings, redis.Notifier)
 
 	localSource := local.Create(database)
-	remoteConfig := config.Remote.GetRemoteSourceSettings()
-	remoteSource := remote.Create(remoteConfig)
-	metricSourceProvider := metricSource.CreateMetricSourceProvider(localSource, remoteSource)
+	remoteSources, err := config.Remotes.CreateRemoteSources(config.Remote)
+	if err != nil {
+		logger.Fatalf("Can not configure remote sources: %s", err.Error())
+	}
+	metricSourceProvider := metricSource.CreateMetricSourceProviderWithRemotes(localSource, remoteSources)
 
 	// Initialize the image store
 	imageStoreMap := cmd.InitImageStores(config.ImageStores, logger)
@@ -98,7 +99,7 @@ func main() {
 	selfState := &selfstate.SelfCheckWorker{
 		Logger:   logger,
 		Database: database,
-		Config:   config.Notifier.SelfState.getSettings(),
+		Config:   config.Notifier.SelfState.getSettings(metricSourceProvider.GetRemoteSourceNames()),
 		Notifier: sender,
 	}
 	if err := selfState.Start(); err != nil {
@@ -119,7 +120,7 @@ func main() {
 	fetchEventsWorker := &events.FetchEventsWorker{
 		Logger:    logger,
 		Database:  database,
-		Scheduler: notifier.NewScheduler(database, logger, notifierMetrics),
+		Scheduler: notifier.NewSchedulerWithPolicies(database, logger, notifierMetrics, notifierConfig.ThrottlingPolicies),
 		Metrics:   notifierMetrics,
 	}
 	fetchEventsWorker.Start()
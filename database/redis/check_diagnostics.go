@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// SaveTriggerCheckDiagnostics adds diagnostics of trigger check to the head of trigger diagnostics list.
+// Only given count of latest diagnostics is kept, whole list expires after given ttl since the last check.
+// Diagnostics don't expire if ttl is less than a second
+func (connector *DbConnector) SaveTriggerCheckDiagnostics(triggerID string, diagnostics *moira.CheckDiagnostics, maxCount int, ttl time.Duration) error {
+	bytes, err := reply.GetCheckDiagnosticsBytes(*diagnostics)
+	if err != nil {
+		return err
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	key := triggerCheckDiagnosticsKey(triggerID)
+	c.Send("MULTI")                     //nolint
+	c.Send("LPUSH", key, bytes)         //nolint
+	c.Send("LTRIM", key, 0, maxCount-1) //nolint
+	if ttlSeconds := int64(ttl.Seconds()); ttlSeconds > 0 {
+		c.Send("EXPIRE", key, ttlSeconds) //nolint
+	}
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetTriggerCheckDiagnostics returns diagnostics of latest trigger checks starting from the latest one
+func (connector *DbConnector) GetTriggerCheckDiagnostics(triggerID string) ([]*moira.CheckDiagnostics, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	return reply.CheckDiagnosticsList(c.Do("LRANGE", triggerCheckDiagnosticsKey(triggerID), 0, -1))
+}
+
+func triggerCheckDiagnosticsKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-check-diagnostics:%s", triggerID)
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheckDiagnostics(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	triggerID := "triggerID-0000000000001"
+
+	Convey("Check diagnostics manipulation", t, func() {
+		Convey("Empty list for trigger without diagnostics", func() {
+			actual, err := dataBase.GetTriggerCheckDiagnostics(triggerID)
+			So(err, ShouldBeNil)
+			So(actual, ShouldBeEmpty)
+		})
+
+		Convey("Only latest diagnostics are kept", func() {
+			for timestamp := int64(1); timestamp <= 3; timestamp++ {
+				err := dataBase.SaveTriggerCheckDiagnostics(triggerID, &moira.CheckDiagnostics{
+					Timestamp: timestamp,
+					State:     moira.StateOK,
+					Targets:   []moira.TargetDiagnostics{{Name: "t1", Target: "my.metric", Series: 1, Points: 10}},
+				}, 2, time.Hour)
+				So(err, ShouldBeNil)
+			}
+
+			actual, err := dataBase.GetTriggerCheckDiagnostics(triggerID)
+			So(err, ShouldBeNil)
+			So(actual, ShouldHaveLength, 2)
+			So(actual[0].Timestamp, ShouldEqual, 3)
+			So(actual[1].Timestamp, ShouldEqual, 2)
+			So(actual[0].Targets, ShouldResemble, []moira.TargetDiagnostics{{Name: "t1", Target: "my.metric", Series: 1, Points: 10}})
+		})
+
+		Convey("Diagnostics are kept without ttl", func() {
+			err := dataBase.SaveTriggerCheckDiagnostics(triggerID, &moira.CheckDiagnostics{Timestamp: 4, State: moira.StateOK}, 2, 0)
+			So(err, ShouldBeNil)
+
+			actual, err := dataBase.GetTriggerCheckDiagnostics(triggerID)
+			So(err, ShouldBeNil)
+			So(actual, ShouldNotBeEmpty)
+			So(actual[0].Timestamp, ShouldEqual, 4)
+		})
+	})
+}
+
+func TestCheckDiagnosticsErrorConnection(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Should throw error when no connection", t, func() {
+		err := dataBase.SaveTriggerCheckDiagnostics("123", &moira.CheckDiagnostics{}, 1, time.Hour)
+		So(err, ShouldNotBeNil)
+
+		actual, err := dataBase.GetTriggerCheckDiagnostics("123")
+		So(err, ShouldNotBeNil)
+		So(actual, ShouldBeNil)
+	})
+}
@@ -0,0 +1,103 @@
+package redis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// AddEscalation starts escalation of trigger metric for subscription if it is not started yet.
+// Returns false if escalation is already in progress
+func (connector *DbConnector) AddEscalation(escalation *moira.EscalationData) (bool, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	added, err := redis.Bool(c.Do("HSETNX", triggerEscalationsKey(escalation.TriggerID), escalationField(escalation), escalation.Started))
+	if err != nil {
+		return false, fmt.Errorf("failed to add escalation: %s", err.Error())
+	}
+	return added, nil
+}
+
+// IsEscalationActive returns true if escalation is not stopped by acknowledgement or recovery of trigger metric
+// and is not replaced by escalation of later ERROR event
+func (connector *DbConnector) IsEscalationActive(escalation *moira.EscalationData) (bool, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	started, err := redis.Int64(c.Do("HGET", triggerEscalationsKey(escalation.TriggerID), escalationField(escalation)))
+	if err != nil {
+		if err == redis.ErrNil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get escalation: %s", err.Error())
+	}
+	return started == escalation.Started, nil
+}
+
+// RemoveEscalation stops escalation of trigger metric started by subscription
+func (connector *DbConnector) RemoveEscalation(escalation *moira.EscalationData) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	if _, err := c.Do("HDEL", triggerEscalationsKey(escalation.TriggerID), escalationField(escalation)); err != nil {
+		return fmt.Errorf("failed to remove escalation: %s", err.Error())
+	}
+	return nil
+}
+
+// RemoveEscalations stops escalations of given trigger metrics for all subscriptions, all escalations of trigger are stopped if no metrics given
+func (connector *DbConnector) RemoveEscalations(triggerID string, metrics ...string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	key := triggerEscalationsKey(triggerID)
+	if len(metrics) == 0 {
+		if _, err := c.Do("DEL", key); err != nil {
+			return fmt.Errorf("failed to remove escalations: %s", err.Error())
+		}
+		return nil
+	}
+
+	fields, err := redis.Strings(c.Do("HKEYS", key))
+	if err != nil {
+		return fmt.Errorf("failed to get escalations: %s", err.Error())
+	}
+	removedMetrics := make(map[string]bool, len(metrics))
+	for _, metric := range metrics {
+		removedMetrics[metric] = true
+	}
+	args := redis.Args{}.Add(key)
+	for _, field := range fields {
+		if removedMetrics[escalationFieldMetric(field)] {
+			args = args.Add(field)
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+	if _, err = c.Do("HDEL", args...); err != nil {
+		return fmt.Errorf("failed to remove escalations: %s", err.Error())
+	}
+	return nil
+}
+
+// escalationField returns hash field of escalation, subscription id never contains separator so metric is everything after it
+func escalationField(escalation *moira.EscalationData) string {
+	return fmt.Sprintf("%s:%s", escalation.SubscriptionID, escalation.Metric)
+}
+
+func escalationFieldMetric(field string) string {
+	parts := strings.SplitN(field, ":", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+func triggerEscalationsKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-escalations:%s", triggerID)
+}
@@ -0,0 +1,119 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/moira-alert/moira"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEscalations(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	triggerID := "triggerID-0000000000001"
+
+	Convey("Escalations manipulation", t, func() {
+		escalation := &moira.EscalationData{TriggerID: triggerID, Metric: "my.metric:1", SubscriptionID: "subscriptionID-00000000000001", Started: 100}
+		otherMetric := &moira.EscalationData{TriggerID: triggerID, Metric: "my.metric:2", SubscriptionID: "subscriptionID-00000000000001", Started: 100}
+
+		Convey("Not started escalation is not active", func() {
+			active, err := dataBase.IsEscalationActive(escalation)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeFalse)
+		})
+
+		Convey("Escalation is started only once", func() {
+			added, err := dataBase.AddEscalation(escalation)
+			So(err, ShouldBeNil)
+			So(added, ShouldBeTrue)
+
+			later := *escalation
+			later.Started = 200
+			added, err = dataBase.AddEscalation(&later)
+			So(err, ShouldBeNil)
+			So(added, ShouldBeFalse)
+
+			active, err := dataBase.IsEscalationActive(escalation)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeTrue)
+
+			active, err = dataBase.IsEscalationActive(&later)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeFalse)
+		})
+
+		Convey("Escalations are removed by metric", func() {
+			_, err := dataBase.AddEscalation(otherMetric)
+			So(err, ShouldBeNil)
+
+			err = dataBase.RemoveEscalations(triggerID, escalation.Metric)
+			So(err, ShouldBeNil)
+
+			active, err := dataBase.IsEscalationActive(escalation)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeFalse)
+
+			active, err = dataBase.IsEscalationActive(otherMetric)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeTrue)
+		})
+
+		Convey("Escalation is removed for subscription", func() {
+			otherSubscription := *escalation
+			otherSubscription.SubscriptionID = "subscriptionID-00000000000002"
+			_, err := dataBase.AddEscalation(escalation)
+			So(err, ShouldBeNil)
+			_, err = dataBase.AddEscalation(&otherSubscription)
+			So(err, ShouldBeNil)
+
+			err = dataBase.RemoveEscalation(escalation)
+			So(err, ShouldBeNil)
+
+			active, err := dataBase.IsEscalationActive(escalation)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeFalse)
+
+			active, err = dataBase.IsEscalationActive(&otherSubscription)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeTrue)
+		})
+
+		Convey("All escalations of trigger are removed without metrics", func() {
+			err := dataBase.RemoveEscalations(triggerID)
+			So(err, ShouldBeNil)
+
+			active, err := dataBase.IsEscalationActive(otherMetric)
+			So(err, ShouldBeNil)
+			So(active, ShouldBeFalse)
+		})
+	})
+}
+
+func TestEscalationsErrorConnection(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Should throw error when no connection", t, func() {
+		escalation := &moira.EscalationData{TriggerID: "123", SubscriptionID: "456", Started: 100}
+		added, err := dataBase.AddEscalation(escalation)
+		So(err, ShouldNotBeNil)
+		So(added, ShouldBeFalse)
+
+		active, err := dataBase.IsEscalationActive(escalation)
+		So(err, ShouldNotBeNil)
+		So(active, ShouldBeFalse)
+
+		err = dataBase.RemoveEscalation(escalation)
+		So(err, ShouldNotBeNil)
+
+		err = dataBase.RemoveEscalations("123")
+		So(err, ShouldNotBeNil)
+
+		err = dataBase.RemoveEscalations("123", "my.metric")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -22,9 +22,9 @@ func (connector *DbConnector) GetTriggerLastCheck(triggerID string) (moira.Check
 	return lastCheck, nil
 }
 
-// SetTriggerLastCheck sets trigger last check data
-func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *moira.CheckData, isRemote bool) error {
-	selfStateCheckCountKey := connector.getSelfStateCheckCountKey(isRemote)
+// SetTriggerLastCheck sets trigger last check data, source is a name of trigger remote metric source or empty string for local trigger
+func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *moira.CheckData, source string) error {
+	selfStateCheckCountKey := connector.getSelfStateCheckCountKey(source)
 	bytes, err := reply.GetCheckBytes(*checkData)
 	if err != nil {
 		return err
@@ -55,12 +55,12 @@ func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *m
 	return nil
 }
 
-func (connector *DbConnector) getSelfStateCheckCountKey(isRemote bool) string {
+func (connector *DbConnector) getSelfStateCheckCountKey(source string) string {
 	if connector.source != Checker {
 		return ""
 	}
-	if isRemote {
-		return selfStateRemoteChecksCounterKey
+	if source != "" {
+		return selfStateRemoteChecksCounterKey(source)
 	}
 	return selfStateChecksCounterKey
 }
@@ -86,6 +86,46 @@ func (connector *DbConnector) RemoveTriggerLastCheck(triggerID string) error {
 // If during the update lastCheck was updated from another place, try update again
 // If CheckData does not contain one of given metrics it will ignore this metric
 func (connector *DbConnector) SetTriggerCheckMaintenance(triggerID string, metrics map[string]int64, triggerMaintenance *int64, userLogin string, timeCallMaintenance int64) error {
+	return connector.updateTriggerLastCheck(triggerID, func(lastCheck *moira.CheckData) {
+		metricsCheck := lastCheck.Metrics
+		if len(metricsCheck) > 0 {
+			for metric, value := range metrics {
+				data, ok := metricsCheck[metric]
+				if !ok {
+					continue
+				}
+				moira.SetMaintenanceUserAndTime(&data, value, userLogin, timeCallMaintenance)
+				metricsCheck[metric] = data
+			}
+		}
+		if triggerMaintenance != nil {
+			moira.SetMaintenanceUserAndTime(lastCheck, *triggerMaintenance, userLogin, timeCallMaintenance)
+		}
+	})
+}
+
+// SetTriggerCheckAck sets acknowledgement to given metrics or to whole trigger if no metrics given,
+// If CheckData does not contain one of given metrics it will ignore this metric
+func (connector *DbConnector) SetTriggerCheckAck(triggerID string, metrics []string, ack *moira.AckData) error {
+	return connector.updateTriggerLastCheck(triggerID, func(lastCheck *moira.CheckData) {
+		if len(metrics) == 0 {
+			lastCheck.Ack = ack
+			return
+		}
+		for _, metric := range metrics {
+			data, ok := lastCheck.Metrics[metric]
+			if !ok {
+				continue
+			}
+			data.Ack = ack
+			lastCheck.Metrics[metric] = data
+		}
+	})
+}
+
+// updateTriggerLastCheck applies update to trigger last check if it exists,
+// If during the update lastCheck was updated from another place, try update again
+func (connector *DbConnector) updateTriggerLastCheck(triggerID string, update func(lastCheck *moira.CheckData)) error {
 	c := connector.pool.Get()
 	defer c.Close()
 	var readingErr error
@@ -100,20 +140,7 @@ func (connector *DbConnector) SetTriggerCheckMaintenance(triggerID string, metri
 		if err != nil {
 			return fmt.Errorf("failed to parse lastCheck json %s: %s", lastCheckString, err.Error())
 		}
-		metricsCheck := lastCheck.Metrics
-		if len(metricsCheck) > 0 {
-			for metric, value := range metrics {
-				data, ok := metricsCheck[metric]
-				if !ok {
-					continue
-				}
-				moira.SetMaintenanceUserAndTime(&data, value, userLogin, timeCallMaintenance)
-				metricsCheck[metric] = data
-			}
-		}
-		if triggerMaintenance != nil {
-			moira.SetMaintenanceUserAndTime(&lastCheck, *triggerMaintenance, userLogin, timeCallMaintenance)
-		}
+		update(&lastCheck)
 		newLastCheck, err := json.Marshal(lastCheck)
 		if err != nil {
 			return err
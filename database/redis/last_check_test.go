@@ -22,7 +22,7 @@ func TestLastCheck(t *testing.T) {
 	Convey("LastCheck manipulation", t, func() {
 		Convey("Test read write delete", func() {
 			triggerID := uuid.Must(uuid.NewV4()).String()
-			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 			So(err, ShouldBeNil)
 
 			actual, err := dataBase.GetTriggerLastCheck(triggerID)
@@ -54,7 +54,7 @@ func TestLastCheck(t *testing.T) {
 
 			Convey("While no metrics", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -67,7 +67,7 @@ func TestLastCheck(t *testing.T) {
 
 			Convey("While no metrics to change", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric11": 1, "metric55": 5}, nil, "", 0)
@@ -81,7 +81,7 @@ func TestLastCheck(t *testing.T) {
 			Convey("Has metrics to change", func() {
 				checkData := lastCheckTest
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -99,6 +99,50 @@ func TestLastCheck(t *testing.T) {
 			})
 		})
 
+		Convey("Test set check acknowledgement", func() {
+			ack := &moira.AckData{User: "user", Comment: "working on it", Timestamp: 100, Expiry: 200}
+
+			Convey("While no check", func() {
+				triggerID := uuid.Must(uuid.NewV4()).String()
+				err := dataBase.SetTriggerCheckAck(triggerID, nil, ack)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("Set metrics acknowledgement", func() {
+				checkData := lastCheckWithNoMetrics
+				checkData.Metrics = map[string]moira.MetricState{
+					"metric1": {State: moira.StateERROR, Timestamp: 100},
+					"metric2": {State: moira.StateERROR, Timestamp: 100},
+				}
+				triggerID := uuid.Must(uuid.NewV4()).String()
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
+				So(err, ShouldBeNil)
+
+				err = dataBase.SetTriggerCheckAck(triggerID, []string{"metric1", "metric11"}, ack)
+				So(err, ShouldBeNil)
+
+				actual, err := dataBase.GetTriggerLastCheck(triggerID)
+				So(err, ShouldBeNil)
+				So(actual.Ack, ShouldBeNil)
+				So(actual.Metrics["metric1"].Ack, ShouldResemble, ack)
+				So(actual.Metrics["metric2"].Ack, ShouldBeNil)
+				So(actual.Metrics, ShouldHaveLength, 2)
+			})
+
+			Convey("Set trigger acknowledgement", func() {
+				triggerID := uuid.Must(uuid.NewV4()).String()
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, "")
+				So(err, ShouldBeNil)
+
+				err = dataBase.SetTriggerCheckAck(triggerID, nil, ack)
+				So(err, ShouldBeNil)
+
+				actual, err := dataBase.GetTriggerLastCheck(triggerID)
+				So(err, ShouldBeNil)
+				So(actual.Ack, ShouldResemble, ack)
+			})
+		})
+
 		Convey("Test set Trigger and metrics check maintenance", func() {
 			Convey("While no check", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
@@ -108,7 +152,7 @@ func TestLastCheck(t *testing.T) {
 
 			Convey("Set metrics maintenance while no metrics", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -121,7 +165,7 @@ func TestLastCheck(t *testing.T) {
 
 			Convey("Set trigger maintenance while no metrics", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, "")
 				So(err, ShouldBeNil)
 
 				triggerMaintenanceTS = 1000
@@ -136,7 +180,7 @@ func TestLastCheck(t *testing.T) {
 
 			Convey("Set metrics maintenance while no metrics to change", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric11": 1, "metric55": 5}, nil, "", 0)
@@ -151,7 +195,7 @@ func TestLastCheck(t *testing.T) {
 				newLastCheckTest := lastCheckTest
 				newLastCheckTest.Maintenance = 1000
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 				So(err, ShouldBeNil)
 
 				triggerMaintenanceTS = 1000
@@ -166,7 +210,7 @@ func TestLastCheck(t *testing.T) {
 			Convey("Set metrics maintenance while has metrics to change", func() {
 				checkData := lastCheckTest
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -186,7 +230,7 @@ func TestLastCheck(t *testing.T) {
 			Convey("Set trigger and metrics maintenance while has metrics to change", func() {
 				checkData := lastCheckTest
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
 				So(err, ShouldBeNil)
 
 				triggerMaintenanceTS = 1000
@@ -208,7 +252,7 @@ func TestLastCheck(t *testing.T) {
 			Convey("Set trigger maintenance to 0 and metrics maintenance", func() {
 				checkData := lastCheckTest
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, false)
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
 				So(err, ShouldBeNil)
 
 				triggerMaintenanceTS = 0
@@ -230,7 +274,7 @@ func TestLastCheck(t *testing.T) {
 			So(dataBase.checkDataScoreChanged(triggerID, &lastCheckWithNoMetrics), ShouldBeTrue)
 
 			// set new last check. Should add a trigger to a reindex set
-			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, false)
+			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, "")
 			So(err, ShouldBeNil)
 
 			So(dataBase.checkDataScoreChanged(triggerID, &lastCheckWithNoMetrics), ShouldBeFalse)
@@ -243,7 +287,7 @@ func TestLastCheck(t *testing.T) {
 
 			time.Sleep(time.Second)
 
-			err = dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+			err = dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 			So(err, ShouldBeNil)
 
 			actual, err = dataBase.FetchTriggersToReindex(time.Now().Unix() - 10)
@@ -282,7 +326,7 @@ func TestLastCheck(t *testing.T) {
 						Value:          &value,
 					},
 				},
-			}, false)
+			}, "")
 			So(err, ShouldBeNil)
 
 			actual, err := dataBase.GetTriggerLastCheck(triggerID)
@@ -316,7 +360,7 @@ func TestRemoteLastCheck(t *testing.T) {
 	Convey("LastCheck manipulation", t, func() {
 		Convey("Test read write delete", func() {
 			triggerID := uuid.Must(uuid.NewV4()).String()
-			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, true)
+			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, moira.DefaultRemoteSource)
 			So(err, ShouldBeNil)
 
 			actual, err := dataBase.GetTriggerLastCheck(triggerID)
@@ -348,7 +392,7 @@ func TestRemoteLastCheck(t *testing.T) {
 
 			Convey("While no metrics", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, true)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckWithNoMetrics, moira.DefaultRemoteSource)
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -361,7 +405,7 @@ func TestRemoteLastCheck(t *testing.T) {
 
 			Convey("While no metrics to change", func() {
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, true)
+				err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, moira.DefaultRemoteSource)
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric11": 1, "metric55": 5}, nil, "", 0)
@@ -375,7 +419,7 @@ func TestRemoteLastCheck(t *testing.T) {
 			Convey("Has metrics to change", func() {
 				checkData := lastCheckTest
 				triggerID := uuid.Must(uuid.NewV4()).String()
-				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, true)
+				err := dataBase.SetTriggerLastCheck(triggerID, &checkData, moira.DefaultRemoteSource)
 				So(err, ShouldBeNil)
 
 				err = dataBase.SetTriggerCheckMaintenance(triggerID, map[string]int64{"metric1": 1, "metric5": 5}, nil, "", 0)
@@ -405,7 +449,7 @@ func TestLastCheckErrorConnection(t *testing.T) {
 		So(actual1, ShouldResemble, moira.CheckData{})
 		So(err, ShouldNotBeNil)
 
-		err = dataBase.SetTriggerLastCheck("123", &lastCheckTest, false)
+		err = dataBase.SetTriggerLastCheck("123", &lastCheckTest, "")
 		So(err, ShouldNotBeNil)
 
 		err = dataBase.RemoveTriggerLastCheck("123")
@@ -415,6 +459,9 @@ func TestLastCheckErrorConnection(t *testing.T) {
 		err = dataBase.SetTriggerCheckMaintenance("123", map[string]int64{}, &triggerMaintenanceTS, "", 0)
 		So(err, ShouldNotBeNil)
 
+		err = dataBase.SetTriggerCheckAck("123", nil, &moira.AckData{User: "user"})
+		So(err, ShouldNotBeNil)
+
 		actual2, err := dataBase.GetTriggerLastCheck("123")
 		So(actual2, ShouldResemble, moira.CheckData{})
 		So(err, ShouldNotBeNil)
@@ -438,7 +485,7 @@ func TestMaintenanceUserSave(t *testing.T) {
 			newLastCheckTest.MaintenanceInfo.StartUser = &userLogin
 			newLastCheckTest.MaintenanceInfo.StartTime = &startTime
 			triggerID := uuid.Must(uuid.NewV4()).String()
-			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 			So(err, ShouldBeNil)
 
 			triggerMaintenanceTS = 1000
@@ -456,7 +503,7 @@ func TestMaintenanceUserSave(t *testing.T) {
 			newLastCheckTest.MaintenanceInfo.StopUser = &userLogin
 			newLastCheckTest.MaintenanceInfo.StopTime = &startTime
 			triggerID := uuid.Must(uuid.NewV4()).String()
-			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, false)
+			err := dataBase.SetTriggerLastCheck(triggerID, &lastCheckTest, "")
 			So(err, ShouldBeNil)
 
 			triggerMaintenanceTS = 1000
@@ -475,7 +522,7 @@ func TestMaintenanceUserSave(t *testing.T) {
 		checkData.MaintenanceInfo = moira.MaintenanceInfo{}
 		userLogin := "test"
 		var timeCallMaintenance = int64(3)
-		err := dataBase.SetTriggerLastCheck(triggerID, &checkData, false)
+		err := dataBase.SetTriggerLastCheck(triggerID, &checkData, "")
 		So(err, ShouldBeNil)
 
 		triggerMaintenanceTS = 1000
@@ -100,6 +100,10 @@ func (connector *DbConnector) SaveMetrics(metrics map[string]*moira.MatchedMetri
 	defer c.Close()
 	for _, metric := range metrics {
 		metricValue := fmt.Sprintf("%v %v", metric.Timestamp, metric.Value)
+		if metric.Aggregated {
+			// Aggregated value of retention bucket replaces previously saved one
+			c.Send("ZREMRANGEBYSCORE", metricDataKey(metric.Metric), metric.RetentionTimestamp, metric.RetentionTimestamp) //nolint
+		}
 		c.Send("ZADD", metricDataKey(metric.Metric), metric.RetentionTimestamp, metricValue) //nolint
 
 		if err := connector.retentionSavingCache.Add(metric.Metric, true, cache.DefaultExpiration); err == nil {
@@ -181,6 +181,21 @@ func TestMetricsStoring(t *testing.T) {
 		So(err, ShouldBeNil)
 		So(actualRet, ShouldEqual, 10)
 	})
+
+	Convey("Aggregated value replaces saved value of retention bucket", t, func() {
+		metric := "my.test.super.metric.aggregated"
+		point := &moira.MatchedMetric{Metric: metric, Retention: 10, RetentionTimestamp: 10, Timestamp: 11, Value: 1}
+		err := dataBase.SaveMetrics(map[string]*moira.MatchedMetric{metric: point})
+		So(err, ShouldBeNil)
+
+		aggregated := &moira.MatchedMetric{Metric: metric, Retention: 10, RetentionTimestamp: 10, Timestamp: 12, Value: 3, Aggregated: true}
+		err = dataBase.SaveMetrics(map[string]*moira.MatchedMetric{metric: aggregated})
+		So(err, ShouldBeNil)
+
+		actualValues, err := dataBase.GetMetricsValues([]string{metric}, 0, 10)
+		So(err, ShouldBeNil)
+		So(actualValues, ShouldResemble, map[string][]*moira.MetricValue{metric: {&moira.MetricValue{Timestamp: 12, RetentionTimestamp: 10, Value: 3}}})
+	})
 }
 
 func TestRemoveMetricValues(t *testing.T) {
@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// AddNotificationHistory saves attempt to send notification package to the history of contact and of its triggers.
+// Items older than given ttl are removed from history
+func (connector *DbConnector) AddNotificationHistory(item *moira.NotificationHistoryItem, ttl time.Duration) error {
+	bytes, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification history item: %s", err.Error())
+	}
+
+	keys := []string{notificationHistoryKey, notificationHistoryContactKey(item.ContactID)}
+	for _, triggerID := range item.TriggerIDs {
+		keys = append(keys, notificationHistoryTriggerKey(triggerID))
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	expired := item.Timestamp - int64(ttl.Seconds())
+	c.Send("MULTI") //nolint
+	for _, key := range keys {
+		c.Send("ZADD", key, item.Timestamp, bytes)                           //nolint
+		c.Send("ZREMRANGEBYSCORE", key, "-inf", fmt.Sprintf("(%d", expired)) //nolint
+		if key != notificationHistoryKey {
+			c.Send("EXPIRE", key, int64(ttl.Seconds())) //nolint
+		}
+	}
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetNotificationHistory returns page of notification history items sent in given time range from the latest to the oldest
+// and total count of items. Empty contactID or triggerID means history of all contacts or triggers
+func (connector *DbConnector) GetNotificationHistory(contactID, triggerID string, from, to, page, size int64) ([]*moira.NotificationHistoryItem, int64, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	key := notificationHistoryKey
+	switch {
+	case contactID != "":
+		key = notificationHistoryContactKey(contactID)
+	case triggerID != "":
+		key = notificationHistoryTriggerKey(triggerID)
+	}
+
+	if contactID == "" || triggerID == "" {
+		total, err := redis.Int64(c.Do("ZCOUNT", key, from, to))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to count notification history items: %s", err.Error())
+		}
+		items, err := getNotificationHistoryItems(c.Do("ZREVRANGEBYSCORE", key, to, from, "LIMIT", page*size, size))
+		return items, total, err
+	}
+
+	// History of contact is filtered by trigger, so whole time range is fetched
+	items, err := getNotificationHistoryItems(c.Do("ZREVRANGEBYSCORE", key, to, from))
+	if err != nil {
+		return nil, 0, err
+	}
+	filtered := make([]*moira.NotificationHistoryItem, 0, len(items))
+	for _, item := range items {
+		if item.HasTrigger(triggerID) {
+			filtered = append(filtered, item)
+		}
+	}
+	total := int64(len(filtered))
+	if size < 0 {
+		return filtered, total, nil
+	}
+	start := page * size
+	if start > total {
+		start = total
+	}
+	end := start + size
+	if end > total {
+		end = total
+	}
+	return filtered[start:end], total, nil
+}
+
+func getNotificationHistoryItems(rawResponse interface{}, err error) ([]*moira.NotificationHistoryItem, error) {
+	values, err := redis.ByteSlices(rawResponse, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification history: %s", err.Error())
+	}
+	items := make([]*moira.NotificationHistoryItem, 0, len(values))
+	for _, value := range values {
+		item := &moira.NotificationHistoryItem{}
+		if err := json.Unmarshal(value, item); err != nil {
+			return nil, fmt.Errorf("failed to parse notification history item json %s: %s", value, err.Error())
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+const notificationHistoryKey = "moira-notification-history"
+
+func notificationHistoryContactKey(contactID string) string {
+	return "moira-notification-history-contact:" + contactID
+}
+
+func notificationHistoryTriggerKey(triggerID string) string {
+	return "moira-notification-history-trigger:" + triggerID
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/logging/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNotificationHistory(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Notification history manipulation", t, func() {
+		first := &moira.NotificationHistoryItem{Timestamp: 100, ContactID: "contact-1", SenderType: "mail",
+			TriggerIDs: []string{"trigger-1"}, Attempt: 1, Error: "timeout"}
+		second := &moira.NotificationHistoryItem{Timestamp: 110, ContactID: "contact-1", SenderType: "mail",
+			TriggerIDs: []string{"trigger-1"}, Attempt: 2, Success: true}
+		digest := &moira.NotificationHistoryItem{Timestamp: 120, ContactID: "contact-2", SenderType: "slack",
+			TriggerIDs: []string{"trigger-1", "trigger-2"}, Attempt: 1, Success: true}
+		other := &moira.NotificationHistoryItem{Timestamp: 130, ContactID: "contact-1", SenderType: "mail",
+			TriggerIDs: []string{"trigger-2"}, Attempt: 1, Success: true}
+		for _, item := range []*moira.NotificationHistoryItem{first, second, digest, other} {
+			So(dataBase.AddNotificationHistory(item, time.Hour), ShouldBeNil)
+		}
+
+		Convey("Get all history", func() {
+			items, total, err := dataBase.GetNotificationHistory("", "", 0, 200, 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 4)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{other, digest, second, first})
+		})
+
+		Convey("Get history in time range by pages", func() {
+			items, total, err := dataBase.GetNotificationHistory("", "", 105, 125, 0, 1)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{digest})
+
+			items, total, err = dataBase.GetNotificationHistory("", "", 105, 125, 1, 1)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{second})
+		})
+
+		Convey("Get history of contact", func() {
+			items, total, err := dataBase.GetNotificationHistory("contact-1", "", 0, 200, 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{other, second, first})
+		})
+
+		Convey("Get history of trigger", func() {
+			items, total, err := dataBase.GetNotificationHistory("", "trigger-2", 0, 200, 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{other, digest})
+		})
+
+		Convey("Get history of contact and trigger", func() {
+			items, total, err := dataBase.GetNotificationHistory("contact-1", "trigger-1", 0, 200, 0, 1)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{second})
+
+			items, total, err = dataBase.GetNotificationHistory("contact-1", "trigger-1", 0, 200, 5, 1)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(items, ShouldBeEmpty)
+		})
+
+		Convey("Expired items are removed", func() {
+			latest := &moira.NotificationHistoryItem{Timestamp: 100 + 3600*2, ContactID: "contact-1", SenderType: "mail",
+				TriggerIDs: []string{"trigger-1"}, Attempt: 1, Success: true}
+			So(dataBase.AddNotificationHistory(latest, time.Hour), ShouldBeNil)
+
+			items, total, err := dataBase.GetNotificationHistory("contact-1", "", 0, latest.Timestamp, 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 1)
+			So(items, ShouldResemble, []*moira.NotificationHistoryItem{latest})
+		})
+	})
+}
+
+func TestNotificationHistoryErrorConnection(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Should throw error when no connection", t, func() {
+		items, total, err := dataBase.GetNotificationHistory("", "", 0, 100, 0, 100)
+		So(err, ShouldNotBeNil)
+		So(total, ShouldEqual, 0)
+		So(items, ShouldBeNil)
+
+		err = dataBase.AddNotificationHistory(&moira.NotificationHistoryItem{ContactID: "contact"}, time.Hour)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// prefixOffendersTTL is a time to keep prefix offenders since last dropped point
+const prefixOffendersTTL = 24 * time.Hour
+
+// maxPrefixOffenders is a max count of stored prefix offenders, prefixes with less dropped points are removed
+const maxPrefixOffenders = 1000
+
+const (
+	prefixOffenderCardinalityField = "cardinality"
+	prefixOffenderRateField        = "rate"
+	prefixOffenderTimestampField   = "timestamp"
+)
+
+// AddPrefixOffenders increments dropped points counters of metric prefixes which exceeded filter limits
+func (connector *DbConnector) AddPrefixOffenders(offenders []*moira.PrefixOffender) error {
+	if len(offenders) == 0 {
+		return nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	ttl := int64(prefixOffendersTTL.Seconds())
+	c.Send("MULTI") //nolint
+	for _, offender := range offenders {
+		key := prefixOffenderKey(offender.Prefix)
+		c.Send("HINCRBY", key, prefixOffenderCardinalityField, offender.DroppedByCardinality)                        //nolint
+		c.Send("HINCRBY", key, prefixOffenderRateField, offender.DroppedByRate)                                      //nolint
+		c.Send("HSET", key, prefixOffenderTimestampField, offender.Timestamp)                                        //nolint
+		c.Send("EXPIRE", key, ttl)                                                                                   //nolint
+		c.Send("ZINCRBY", prefixOffendersKey, offender.DroppedByCardinality+offender.DroppedByRate, offender.Prefix) //nolint
+	}
+	c.Send("ZREMRANGEBYRANK", prefixOffendersKey, 0, -maxPrefixOffenders-1) //nolint
+	c.Send("EXPIRE", prefixOffendersKey, ttl)                               //nolint
+	_, err := c.Do("EXEC")
+	if err != nil {
+		return fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetPrefixOffenders returns metric prefixes with the most dropped points
+func (connector *DbConnector) GetPrefixOffenders(count int64) ([]*moira.PrefixOffender, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	prefixes, err := redis.Strings(c.Do("ZREVRANGE", prefixOffendersKey, 0, count-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prefix offenders: %s", err.Error())
+	}
+
+	c.Send("MULTI") //nolint
+	for _, prefix := range prefixes {
+		c.Send("HGETALL", prefixOffenderKey(prefix)) //nolint
+	}
+	rawResponse, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+
+	offenders := make([]*moira.PrefixOffender, 0, len(prefixes))
+	for i, prefix := range prefixes {
+		fields, err := redis.Int64Map(rawResponse[i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get prefix offender %s: %s", prefix, err.Error())
+		}
+		if len(fields) == 0 {
+			// Prefix offender is expired, it is removed to keep top of actual offenders
+			c.Do("ZREM", prefixOffendersKey, prefix) //nolint
+			continue
+		}
+		offenders = append(offenders, &moira.PrefixOffender{
+			Prefix:               prefix,
+			DroppedByCardinality: fields[prefixOffenderCardinalityField],
+			DroppedByRate:        fields[prefixOffenderRateField],
+			Timestamp:            fields[prefixOffenderTimestampField],
+		})
+	}
+	return offenders, nil
+}
+
+const prefixOffendersKey = "moira-filter-prefix-offenders"
+
+func prefixOffenderKey(prefix string) string {
+	return "moira-filter-prefix-offender:" + prefix
+}
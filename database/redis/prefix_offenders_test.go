@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/logging/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrefixOffenders(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Test on empty DB", t, func() {
+		offenders, err := dataBase.GetPrefixOffenders(10)
+		So(err, ShouldBeNil)
+		So(offenders, ShouldBeEmpty)
+
+		err = dataBase.AddPrefixOffenders(nil)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Offenders counters should be summed and sorted by dropped points", t, func() {
+		err := dataBase.AddPrefixOffenders([]*moira.PrefixOffender{
+			{Prefix: "first.service", DroppedByCardinality: 10, Timestamp: 100},
+			{Prefix: "second.service", DroppedByRate: 5, Timestamp: 100},
+		})
+		So(err, ShouldBeNil)
+		err = dataBase.AddPrefixOffenders([]*moira.PrefixOffender{
+			{Prefix: "second.service", DroppedByCardinality: 3, DroppedByRate: 5, Timestamp: 200},
+		})
+		So(err, ShouldBeNil)
+
+		offenders, err := dataBase.GetPrefixOffenders(10)
+		So(err, ShouldBeNil)
+		So(offenders, ShouldResemble, []*moira.PrefixOffender{
+			{Prefix: "second.service", DroppedByCardinality: 3, DroppedByRate: 10, Timestamp: 200},
+			{Prefix: "first.service", DroppedByCardinality: 10, Timestamp: 100},
+		})
+
+		offenders, err = dataBase.GetPrefixOffenders(1)
+		So(err, ShouldBeNil)
+		So(offenders, ShouldHaveLength, 1)
+	})
+
+	Convey("Expired offenders should be skipped", t, func() {
+		c := dataBase.pool.Get()
+		defer c.Close()
+		_, err := c.Do("DEL", prefixOffenderKey("first.service"))
+		So(err, ShouldBeNil)
+
+		offenders, err := dataBase.GetPrefixOffenders(10)
+		So(err, ShouldBeNil)
+		So(offenders, ShouldHaveLength, 1)
+		So(offenders[0].Prefix, ShouldEqual, "second.service")
+	})
+}
+
+func TestPrefixOffendersErrorConnection(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Should throw error when no connection", t, func() {
+		offenders, err := dataBase.GetPrefixOffenders(10)
+		So(err, ShouldNotBeNil)
+		So(offenders, ShouldBeNil)
+
+		err = dataBase.AddPrefixOffenders([]*moira.PrefixOffender{{Prefix: "prefix"}})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// rejectedLinesTTL is a time to keep rejected lines of reason since last rejected line
+const rejectedLinesTTL = 24 * time.Hour
+
+// AddRejectedLines adds lines rejected by filter, only maxCount latest lines are kept for every reason
+func (connector *DbConnector) AddRejectedLines(lines []*moira.RejectedLine, maxCount int64) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	ttl := int64(rejectedLinesTTL.Seconds())
+	reasons := make(map[string]bool)
+	c.Send("MULTI") //nolint
+	for _, line := range lines {
+		bytes, err := json.Marshal(line)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rejected line: %s", err.Error())
+		}
+		c.Send("LPUSH", rejectedLinesKey(line.Reason), bytes) //nolint
+		reasons[line.Reason] = true
+	}
+	for reason := range reasons {
+		c.Send("LTRIM", rejectedLinesKey(reason), 0, maxCount-1) //nolint
+		c.Send("EXPIRE", rejectedLinesKey(reason), ttl)          //nolint
+		c.Send("SADD", rejectedLinesReasonsKey, reason)          //nolint
+	}
+	_, err := c.Do("EXEC")
+	if err != nil {
+		return fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetRejectedLines returns lines rejected by filter with given reason or with any reason if reason is empty.
+// Lines are sorted from the latest to the oldest
+func (connector *DbConnector) GetRejectedLines(reason string) ([]*moira.RejectedLine, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	reasons := []string{reason}
+	if reason == "" {
+		var err error
+		reasons, err = redis.Strings(c.Do("SMEMBERS", rejectedLinesReasonsKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rejected lines reasons: %s", err.Error())
+		}
+	}
+
+	c.Send("MULTI") //nolint
+	for _, reason := range reasons {
+		c.Send("LRANGE", rejectedLinesKey(reason), 0, -1) //nolint
+	}
+	rawResponse, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+
+	lines := make([]*moira.RejectedLine, 0)
+	for i := range reasons {
+		values, err := redis.ByteSlices(rawResponse[i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rejected lines: %s", err.Error())
+		}
+		for _, value := range values {
+			line := &moira.RejectedLine{}
+			if err := json.Unmarshal(value, line); err != nil {
+				return nil, fmt.Errorf("failed to parse rejected line json %s: %s", value, err.Error())
+			}
+			lines = append(lines, line)
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Timestamp > lines[j].Timestamp
+	})
+	return lines, nil
+}
+
+const rejectedLinesReasonsKey = "moira-filter-rejected-reasons"
+
+func rejectedLinesKey(reason string) string {
+	return "moira-filter-rejected-lines:" + reason
+}
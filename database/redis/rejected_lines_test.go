@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/logging/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRejectedLines(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Test on empty DB", t, func() {
+		lines, err := dataBase.GetRejectedLines("")
+		So(err, ShouldBeNil)
+		So(lines, ShouldBeEmpty)
+
+		err = dataBase.AddRejectedLines(nil, 2)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Only latest lines should be kept for every reason", t, func() {
+		first := &moira.RejectedLine{Line: "first", Reason: "invalid_value", Error: "error", Source: "127.0.0.1:1234", Timestamp: 100}
+		second := &moira.RejectedLine{Line: "second", Reason: "invalid_value", Timestamp: 200}
+		third := &moira.RejectedLine{Line: "third", Reason: "invalid_value", Timestamp: 300}
+		other := &moira.RejectedLine{Line: "other", Reason: "items_count", Timestamp: 250}
+
+		err := dataBase.AddRejectedLines([]*moira.RejectedLine{first, second}, 2)
+		So(err, ShouldBeNil)
+		err = dataBase.AddRejectedLines([]*moira.RejectedLine{third, other}, 2)
+		So(err, ShouldBeNil)
+
+		lines, err := dataBase.GetRejectedLines("invalid_value")
+		So(err, ShouldBeNil)
+		So(lines, ShouldResemble, []*moira.RejectedLine{third, second})
+
+		lines, err = dataBase.GetRejectedLines("")
+		So(err, ShouldBeNil)
+		So(lines, ShouldResemble, []*moira.RejectedLine{third, other, second})
+
+		lines, err = dataBase.GetRejectedLines("unknown")
+		So(err, ShouldBeNil)
+		So(lines, ShouldBeEmpty)
+	})
+}
+
+func TestRejectedLinesErrorConnection(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Should throw error when no connection", t, func() {
+		lines, err := dataBase.GetRejectedLines("")
+		So(err, ShouldNotBeNil)
+		So(lines, ShouldBeNil)
+
+		err = dataBase.AddRejectedLines([]*moira.RejectedLine{{Line: "line"}}, 1)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -27,6 +27,8 @@ type checkDataStorageElement struct {
 	Suppressed                   bool                         `json:"suppressed,omitempty"`
 	SuppressedState              moira.State                  `json:"suppressed_state,omitempty"`
 	Message                      string                       `json:"msg,omitempty"`
+	SuppressedByParents          []string                     `json:"suppressed_by_parents,omitempty"`
+	Ack                          *moira.AckData               `json:"ack,omitempty"`
 }
 
 func toCheckDataStorageElement(check moira.CheckData) checkDataStorageElement {
@@ -53,6 +55,8 @@ func toCheckDataStorageElement(check moira.CheckData) checkDataStorageElement {
 		Suppressed:                   check.Suppressed,
 		SuppressedState:              check.SuppressedState,
 		Message:                      check.Message,
+		SuppressedByParents:          check.SuppressedByParents,
+		Ack:                          check.Ack,
 	}
 }
 
@@ -85,6 +89,8 @@ func (d checkDataStorageElement) toCheckData() moira.CheckData {
 		Suppressed:                   d.Suppressed,
 		SuppressedState:              d.SuppressedState,
 		Message:                      d.Message,
+		SuppressedByParents:          d.SuppressedByParents,
+		Ack:                          d.Ack,
 	}
 }
 
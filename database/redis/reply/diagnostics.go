@@ -0,0 +1,38 @@
+package reply
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/moira-alert/moira"
+)
+
+// GetCheckDiagnosticsBytes is a function that takes moira.CheckDiagnostics and turns it to bytes that will be saved in redis.
+func GetCheckDiagnosticsBytes(diagnostics moira.CheckDiagnostics) ([]byte, error) {
+	bytes, err := json.Marshal(diagnostics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal check diagnostics: %s", err.Error())
+	}
+	return bytes, nil
+}
+
+// CheckDiagnosticsList converts redis DB reply to moira.CheckDiagnostics objects array
+func CheckDiagnosticsList(rep interface{}, err error) ([]*moira.CheckDiagnostics, error) {
+	values, err := redis.ByteSlices(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.CheckDiagnostics, 0), nil
+		}
+		return nil, fmt.Errorf("failed to read check diagnostics: %s", err.Error())
+	}
+	diagnosticsList := make([]*moira.CheckDiagnostics, 0, len(values))
+	for _, value := range values {
+		diagnostics := &moira.CheckDiagnostics{}
+		if err := json.Unmarshal(value, diagnostics); err != nil {
+			return nil, fmt.Errorf("failed to parse check diagnostics json %s: %s", string(value), err.Error())
+		}
+		diagnosticsList = append(diagnosticsList, diagnostics)
+	}
+	return diagnosticsList, nil
+}
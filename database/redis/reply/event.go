@@ -22,6 +22,7 @@ type notificationEventStorageElement struct {
 	OldState         moira.State        `json:"old_state"`
 	Message          *string            `json:"msg,omitempty"`
 	MessageEventInfo *moira.EventInfo   `json:"event_message"`
+	IsMuted          bool               `json:"muted,omitempty"`
 }
 
 func toNotificationEventStorageElement(event moira.NotificationEvent) notificationEventStorageElement {
@@ -45,6 +46,7 @@ func toNotificationEventStorageElement(event moira.NotificationEvent) notificati
 		OldState:         event.OldState,
 		Message:          event.Message,
 		MessageEventInfo: event.MessageEventInfo,
+		IsMuted:          event.IsMuted,
 	}
 }
 
@@ -71,6 +73,7 @@ func (e notificationEventStorageElement) toNotificationEvent() moira.Notificatio
 		OldState:         e.OldState,
 		Message:          e.Message,
 		MessageEventInfo: e.MessageEventInfo,
+		IsMuted:          e.IsMuted,
 	}
 }
 
@@ -11,36 +11,42 @@ import (
 
 // scheduledNotificationStorageElement represent notification object
 type scheduledNotificationStorageElement struct {
-	Event     moira.NotificationEvent `json:"event"`
-	Trigger   moira.TriggerData       `json:"trigger"`
-	Contact   moira.ContactData       `json:"contact"`
-	Plotting  moira.PlottingData      `json:"plotting"`
-	Throttled bool                    `json:"throttled"`
-	SendFail  int                     `json:"send_fail"`
-	Timestamp int64                   `json:"timestamp"`
+	Event      moira.NotificationEvent `json:"event"`
+	Trigger    moira.TriggerData       `json:"trigger"`
+	Contact    moira.ContactData       `json:"contact"`
+	Plotting   moira.PlottingData      `json:"plotting"`
+	Throttled  bool                    `json:"throttled"`
+	SendFail   int                     `json:"send_fail"`
+	Timestamp  int64                   `json:"timestamp"`
+	Digest     string                  `json:"digest,omitempty"`
+	Escalation *moira.EscalationData   `json:"escalation,omitempty"`
 }
 
 func toScheduledNotificationStorageElement(notification moira.ScheduledNotification) scheduledNotificationStorageElement {
 	return scheduledNotificationStorageElement{
-		Event:     notification.Event,
-		Trigger:   notification.Trigger,
-		Contact:   notification.Contact,
-		Plotting:  notification.Plotting,
-		Throttled: notification.Throttled,
-		SendFail:  notification.SendFail,
-		Timestamp: notification.Timestamp,
+		Event:      notification.Event,
+		Trigger:    notification.Trigger,
+		Contact:    notification.Contact,
+		Plotting:   notification.Plotting,
+		Throttled:  notification.Throttled,
+		SendFail:   notification.SendFail,
+		Timestamp:  notification.Timestamp,
+		Digest:     notification.Digest,
+		Escalation: notification.Escalation,
 	}
 }
 
 func (n scheduledNotificationStorageElement) toScheduledNotification() moira.ScheduledNotification {
 	return moira.ScheduledNotification{
-		Event:     n.Event,
-		Trigger:   n.Trigger,
-		Contact:   n.Contact,
-		Plotting:  n.Plotting,
-		Throttled: n.Throttled,
-		SendFail:  n.SendFail,
-		Timestamp: n.Timestamp,
+		Event:      n.Event,
+		Trigger:    n.Trigger,
+		Contact:    n.Contact,
+		Plotting:   n.Plotting,
+		Throttled:  n.Throttled,
+		SendFail:   n.SendFail,
+		Timestamp:  n.Timestamp,
+		Digest:     n.Digest,
+		Escalation: n.Escalation,
 	}
 }
 
@@ -12,23 +12,31 @@ import (
 
 // Duty hack for moira.Trigger TTL int64 and stored trigger TTL string compatibility
 type triggerStorageElement struct {
-	ID               string              `json:"id"`
-	Name             string              `json:"name"`
-	Desc             *string             `json:"desc,omitempty"`
-	Targets          []string            `json:"targets"`
-	WarnValue        *float64            `json:"warn_value"`
-	ErrorValue       *float64            `json:"error_value"`
-	TriggerType      string              `json:"trigger_type,omitempty"`
-	Tags             []string            `json:"tags"`
-	TTLState         *moira.TTLState     `json:"ttl_state,omitempty"`
-	Schedule         *moira.ScheduleData `json:"sched,omitempty"`
-	Expression       *string             `json:"expr,omitempty"`
-	PythonExpression *string             `json:"expression,omitempty"`
-	Patterns         []string            `json:"patterns"`
-	TTL              string              `json:"ttl,omitempty"`
-	IsRemote         bool                `json:"is_remote"`
-	MuteNewMetrics   bool                `json:"mute_new_metrics,omitempty"`
-	AloneMetrics     map[string]bool     `json:"alone_metrics"`
+	ID               string                     `json:"id"`
+	Name             string                     `json:"name"`
+	Desc             *string                    `json:"desc,omitempty"`
+	Targets          []string                   `json:"targets"`
+	WarnValue        *float64                   `json:"warn_value"`
+	ErrorValue       *float64                   `json:"error_value"`
+	TriggerType      string                     `json:"trigger_type,omitempty"`
+	Tags             []string                   `json:"tags"`
+	TTLState         *moira.TTLState            `json:"ttl_state,omitempty"`
+	Schedule         *moira.ScheduleData        `json:"sched,omitempty"`
+	Expression       *string                    `json:"expr,omitempty"`
+	PythonExpression *string                    `json:"expression,omitempty"`
+	Patterns         []string                   `json:"patterns"`
+	TTL              string                     `json:"ttl,omitempty"`
+	IsRemote         bool                       `json:"is_remote"`
+	MuteNewMetrics   bool                       `json:"mute_new_metrics,omitempty"`
+	AloneMetrics     map[string]bool            `json:"alone_metrics"`
+	Hysteresis       *moira.Hysteresis          `json:"hysteresis,omitempty"`
+	Anomaly          *moira.AnomalySettings     `json:"anomaly,omitempty"`
+	Parents          []string                   `json:"parents,omitempty"`
+	CheckInterval    int64                      `json:"check_interval,omitempty"`
+	Priority         moira.TriggerPriority      `json:"priority,omitempty"`
+	Source           string                     `json:"source,omitempty"`
+	Reminders        moira.ReminderIntervals    `json:"reminders,omitempty"`
+	Aggregation      *moira.AggregationSettings `json:"aggregation,omitempty"`
 }
 
 func (storageElement *triggerStorageElement) toTrigger() moira.Trigger {
@@ -60,6 +68,14 @@ func (storageElement *triggerStorageElement) toTrigger() moira.Trigger {
 		IsRemote:         storageElement.IsRemote,
 		MuteNewMetrics:   storageElement.MuteNewMetrics,
 		AloneMetrics:     storageElement.AloneMetrics,
+		Hysteresis:       storageElement.Hysteresis,
+		Anomaly:          storageElement.Anomaly,
+		Parents:          storageElement.Parents,
+		CheckInterval:    storageElement.CheckInterval,
+		Priority:         storageElement.Priority,
+		Source:           moira.GetRemoteSourceName(storageElement.IsRemote, storageElement.Source),
+		Reminders:        storageElement.Reminders,
+		Aggregation:      storageElement.Aggregation,
 	}
 }
 
@@ -82,6 +98,14 @@ func toTriggerStorageElement(trigger *moira.Trigger, triggerID string) *triggerS
 		IsRemote:         trigger.IsRemote,
 		MuteNewMetrics:   trigger.MuteNewMetrics,
 		AloneMetrics:     trigger.AloneMetrics,
+		Hysteresis:       trigger.Hysteresis,
+		Anomaly:          trigger.Anomaly,
+		Parents:          trigger.Parents,
+		CheckInterval:    trigger.CheckInterval,
+		Priority:         trigger.Priority,
+		Source:           trigger.Source,
+		Reminders:        trigger.Reminders,
+		Aggregation:      trigger.Aggregation,
 	}
 }
 
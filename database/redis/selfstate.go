@@ -35,11 +35,11 @@ func (connector *DbConnector) GetChecksUpdatesCount() (int64, error) {
 	return ts, err
 }
 
-// GetRemoteChecksUpdatesCount return remote checks count by Moira-Checker
-func (connector *DbConnector) GetRemoteChecksUpdatesCount() (int64, error) {
+// GetRemoteChecksUpdatesCount return checks count of given remote source triggers by Moira-Checker
+func (connector *DbConnector) GetRemoteChecksUpdatesCount(source string) (int64, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	ts, err := redis.Int64(c.Do("GET", selfStateRemoteChecksCounterKey))
+	ts, err := redis.Int64(c.Do("GET", selfStateRemoteChecksCounterKey(source)))
 	if err == redis.ErrNil {
 		return 0, nil
 	}
@@ -70,5 +70,11 @@ func (connector *DbConnector) SetNotifierState(health string) error {
 
 var selfStateMetricsHeartbeatKey = "moira-selfstate:metrics-heartbeat"
 var selfStateChecksCounterKey = "moira-selfstate:checks-counter"
-var selfStateRemoteChecksCounterKey = "moira-selfstate:remote-checks-counter"
 var selfStateNotifierHealth = "moira-selfstate:notifier-health"
+
+func selfStateRemoteChecksCounterKey(source string) string {
+	if source == moira.DefaultRemoteSource {
+		return "moira-selfstate:remote-checks-counter"
+	}
+	return "moira-selfstate:remote-source-checks-counter:" + source
+}
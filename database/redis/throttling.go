@@ -7,7 +7,7 @@ import (
 	"github.com/gomodule/redigo/redis"
 )
 
-// GetTriggerThrottling get throttling or scheduled notifications delay for given triggerID
+// GetTriggerThrottling get the latest throttling or scheduled notifications delay for given triggerID set by any throttling policy
 func (connector *DbConnector) GetTriggerThrottling(triggerID string) (time.Time, time.Time) {
 	c := connector.pool.Get()
 	defer c.Close()
@@ -18,23 +18,78 @@ func (connector *DbConnector) GetTriggerThrottling(triggerID string) (time.Time,
 	return time.Unix(next, 0), time.Unix(beginning, 0)
 }
 
-// SetTriggerThrottling store throttling or scheduled notifications delay for given triggerID
-func (connector *DbConnector) SetTriggerThrottling(triggerID string, next time.Time) error {
+// GetTriggerPolicyThrottling gets throttling set for given triggerID by given throttling policy and beginning of throttling
+func (connector *DbConnector) GetTriggerPolicyThrottling(triggerID, policy string) (time.Time, time.Time) {
 	c := connector.pool.Get()
 	defer c.Close()
-	_, err := c.Do("SET", notifierNextKey(triggerID), next.Unix())
-	return err
+
+	next, _ := redis.Int64(c.Do("GET", notifierPolicyNextKey(triggerID, policy)))
+	beginning, _ := redis.Int64(c.Do("GET", notifierThrottlingBeginningKey(triggerID)))
+
+	return time.Unix(next, 0), time.Unix(beginning, 0)
+}
+
+// SetTriggerThrottling store throttling or scheduled notifications delay for given triggerID set by given throttling policy
+// and reason of throttling. The latest throttling of all policies is stored as throttling of whole trigger
+func (connector *DbConnector) SetTriggerThrottling(triggerID, policy string, next time.Time, reason string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	latest, err := redis.Int64(c.Do("GET", notifierNextKey(triggerID)))
+	if err != nil && err != redis.ErrNil {
+		return fmt.Errorf("failed to get trigger throttling: %s", err.Error())
+	}
+
+	c.Send("MULTI") //nolint
+	c.Send("SET", notifierPolicyNextKey(triggerID, policy), next.Unix()) //nolint
+	c.Send("SET", notifierPolicyThrottlingReasonKey(triggerID, policy), reason) //nolint
+	c.Send("SADD", notifierThrottlingPoliciesKey(triggerID), policy) //nolint
+	if next.Unix() >= latest {
+		c.Send("SET", notifierNextKey(triggerID), next.Unix()) //nolint
+		c.Send("SET", notifierThrottlingReasonKey(triggerID), reason) //nolint
+	}
+	_, err = c.Do("EXEC")
+	if err != nil {
+		return fmt.Errorf("failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetTriggerThrottlingReason gets reason of last throttling of given triggerID, it is empty if trigger was not throttled
+func (connector *DbConnector) GetTriggerThrottlingReason(triggerID string) (string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	reason, err := redis.String(c.Do("GET", notifierThrottlingReasonKey(triggerID)))
+	if err != nil {
+		if err == redis.ErrNil {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get trigger throttling reason: %s", err.Error())
+	}
+	return reason, nil
 }
 
-// DeleteTriggerThrottling deletes throttling and scheduled notifications delay for given triggerID
+// DeleteTriggerThrottling deletes throttling and scheduled notifications delay for given triggerID set by all throttling policies
 func (connector *DbConnector) DeleteTriggerThrottling(triggerID string) error {
 	c := connector.pool.Get()
 	defer c.Close()
 
+	policies, err := redis.Strings(c.Do("SMEMBERS", notifierThrottlingPoliciesKey(triggerID)))
+	if err != nil {
+		return fmt.Errorf("failed to get trigger throttling policies: %s", err.Error())
+	}
+
 	c.Send("MULTI") //nolint
 	c.Send("SET", notifierThrottlingBeginningKey(triggerID), time.Now().Unix()) //nolint
 	c.Send("DEL", notifierNextKey(triggerID)) //nolint
-	_, err := c.Do("EXEC")
+	c.Send("DEL", notifierThrottlingReasonKey(triggerID)) //nolint
+	for _, policy := range policies {
+		c.Send("DEL", notifierPolicyNextKey(triggerID, policy)) //nolint
+		c.Send("DEL", notifierPolicyThrottlingReasonKey(triggerID, policy)) //nolint
+	}
+	c.Send("DEL", notifierThrottlingPoliciesKey(triggerID)) //nolint
+	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("failed to EXEC: %s", err.Error())
 	}
@@ -45,6 +100,22 @@ func notifierThrottlingBeginningKey(triggerID string) string {
 	return "moira-notifier-throttling-beginning:" + triggerID
 }
 
+func notifierThrottlingReasonKey(triggerID string) string {
+	return "moira-notifier-throttling-reason:" + triggerID
+}
+
 func notifierNextKey(triggerID string) string {
 	return "moira-notifier-next:" + triggerID
 }
+
+func notifierPolicyNextKey(triggerID, policy string) string {
+	return fmt.Sprintf("moira-notifier-next:%s:%s", triggerID, policy)
+}
+
+func notifierPolicyThrottlingReasonKey(triggerID, policy string) string {
+	return fmt.Sprintf("moira-notifier-throttling-reason:%s:%s", triggerID, policy)
+}
+
+func notifierThrottlingPoliciesKey(triggerID string) string {
+	return "moira-notifier-throttling-policies:" + triggerID
+}
@@ -9,6 +9,68 @@ import (
 	"time"
 )
 
+func TestThrottling(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Throttling reason should be saved and deleted with throttling", t, func() {
+		reason, err := dataBase.GetTriggerThrottlingReason("trigger")
+		So(err, ShouldBeNil)
+		So(reason, ShouldBeEmpty)
+
+		next := time.Unix(time.Now().Unix()+3600, 0)
+		err = dataBase.SetTriggerThrottling("trigger", "default", next, "some reason")
+		So(err, ShouldBeNil)
+
+		actual, _ := dataBase.GetTriggerThrottling("trigger")
+		So(actual, ShouldResemble, next)
+		reason, err = dataBase.GetTriggerThrottlingReason("trigger")
+		So(err, ShouldBeNil)
+		So(reason, ShouldEqual, "some reason")
+
+		err = dataBase.DeleteTriggerThrottling("trigger")
+		So(err, ShouldBeNil)
+		reason, err = dataBase.GetTriggerThrottlingReason("trigger")
+		So(err, ShouldBeNil)
+		So(reason, ShouldBeEmpty)
+	})
+
+	Convey("Throttling should be stored per policy", t, func() {
+		now := time.Now().Unix()
+		strict := time.Unix(now+7200, 0)
+		soft := time.Unix(now+600, 0)
+		err := dataBase.SetTriggerThrottling("trigger", "strict", strict, "strict reason")
+		So(err, ShouldBeNil)
+		err = dataBase.SetTriggerThrottling("trigger", "soft", soft, "soft reason")
+		So(err, ShouldBeNil)
+
+		actual, _ := dataBase.GetTriggerPolicyThrottling("trigger", "strict")
+		So(actual, ShouldResemble, strict)
+		actual, _ = dataBase.GetTriggerPolicyThrottling("trigger", "soft")
+		So(actual, ShouldResemble, soft)
+		actual, _ = dataBase.GetTriggerPolicyThrottling("trigger", "default")
+		So(actual, ShouldResemble, time.Unix(0, 0))
+
+		// the latest throttling of all policies is throttling of trigger
+		actual, _ = dataBase.GetTriggerThrottling("trigger")
+		So(actual, ShouldResemble, strict)
+		reason, err := dataBase.GetTriggerThrottlingReason("trigger")
+		So(err, ShouldBeNil)
+		So(reason, ShouldEqual, "strict reason")
+
+		err = dataBase.DeleteTriggerThrottling("trigger")
+		So(err, ShouldBeNil)
+		actual, beginning := dataBase.GetTriggerPolicyThrottling("trigger", "strict")
+		So(actual, ShouldResemble, time.Unix(0, 0))
+		So(beginning.Unix(), ShouldBeGreaterThanOrEqualTo, now)
+		actual, _ = dataBase.GetTriggerPolicyThrottling("trigger", "soft")
+		So(actual, ShouldResemble, time.Unix(0, 0))
+		actual, _ = dataBase.GetTriggerThrottling("trigger")
+		So(actual, ShouldResemble, time.Unix(0, 0))
+	})
+}
+
 func TestThrottlingErrorConnection(t *testing.T) {
 	logger, _ := logging.GetLogger("dataBase")
 	dataBase := newTestDatabase(logger, emptyConfig)
@@ -19,7 +81,14 @@ func TestThrottlingErrorConnection(t *testing.T) {
 		So(t1, ShouldResemble, time.Unix(0, 0))
 		So(t2, ShouldResemble, time.Unix(0, 0))
 
-		err := dataBase.SetTriggerThrottling("", time.Now())
+		t1, t2 = dataBase.GetTriggerPolicyThrottling("", "")
+		So(t1, ShouldResemble, time.Unix(0, 0))
+		So(t2, ShouldResemble, time.Unix(0, 0))
+
+		err := dataBase.SetTriggerThrottling("", "", time.Now(), "")
+		So(err, ShouldNotBeNil)
+
+		_, err = dataBase.GetTriggerThrottlingReason("")
 		So(err, ShouldNotBeNil)
 
 		err = dataBase.DeleteTriggerThrottling("")
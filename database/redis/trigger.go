@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -33,17 +34,56 @@ func (connector *DbConnector) GetLocalTriggerIDs() ([]string, error) {
 	return triggerIds, nil
 }
 
-// GetRemoteTriggerIDs gets moira remote triggerIDs
-func (connector *DbConnector) GetRemoteTriggerIDs() ([]string, error) {
+// GetRemoteTriggerIDs gets moira remote triggerIDs of given remote metric source.
+// Default remote source triggers are remote triggers which do not belong to any named remote source
+func (connector *DbConnector) GetRemoteTriggerIDs(source string) ([]string, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	triggerIds, err := redis.Strings(c.Do("SMEMBERS", remoteTriggersListKey))
+	if source != moira.DefaultRemoteSource {
+		triggerIds, err := redis.Strings(c.Do("SMEMBERS", remoteSourceTriggersListKey(source)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get remote source %s triggers-list: %s", source, err.Error())
+		}
+		return triggerIds, nil
+	}
+
+	sources, err := redis.Strings(c.Do("SMEMBERS", remoteSourcesListKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote sources-list: %s", err.Error())
+	}
+	args := redis.Args{}.Add(remoteTriggersListKey)
+	for _, namedSource := range sources {
+		if namedSource != moira.DefaultRemoteSource {
+			args = args.Add(remoteSourceTriggersListKey(namedSource))
+		}
+	}
+	triggerIds, err := redis.Strings(c.Do("SDIFF", args...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get remote triggers-list: %s", err.Error())
 	}
 	return triggerIds, nil
 }
 
+// GetTriggersCheckSettings gets check settings of triggers which are checked not with checker defaults
+func (connector *DbConnector) GetTriggersCheckSettings() (map[string]moira.TriggerCheckSettings, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	values, err := redis.StringMap(c.Do("HGETALL", triggersCheckSettingsKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get triggers check settings: %s", err.Error())
+	}
+	settings := make(map[string]moira.TriggerCheckSettings, len(values))
+	for triggerID, value := range values {
+		var triggerSettings moira.TriggerCheckSettings
+		if err := json.Unmarshal([]byte(value), &triggerSettings); err != nil {
+			connector.logger.Warningf("Failed to parse check settings of trigger %s: %s", triggerID, err.Error())
+			continue
+		}
+		settings[triggerID] = triggerSettings
+	}
+	return settings, nil
+}
+
 // GetTrigger gets trigger and trigger tags by given ID and return it in merged object
 func (connector *DbConnector) GetTrigger(triggerID string) (moira.Trigger, error) {
 	c := connector.pool.Get()
@@ -120,6 +160,7 @@ func (connector *DbConnector) RemovePatternTriggerIDs(pattern string) error {
 // If given trigger contains new tags then create it.
 // If given trigger has no subscription on it, add it to triggers-without-subscriptions
 func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigger) error {
+	trigger.Source = moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source)
 	if trigger.IsRemote {
 		trigger.Patterns = make([]string, 0)
 	}
@@ -162,6 +203,11 @@ func (connector *DbConnector) updateTrigger(triggerID string, newTrigger *moira.
 	if err != nil {
 		return err
 	}
+	checkSettings := newTrigger.GetCheckSettings()
+	checkSettingsBytes, err := json.Marshal(checkSettings)
+	if err != nil {
+		return err
+	}
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI") //nolint
@@ -172,6 +218,9 @@ func (connector *DbConnector) updateTrigger(triggerID string, newTrigger *moira.
 		if oldTrigger.IsRemote && !newTrigger.IsRemote {
 			c.Send("SREM", remoteTriggersListKey, triggerID) //nolint
 		}
+		if oldTrigger.Source != newTrigger.Source && oldTrigger.Source != "" {
+			c.Send("SREM", remoteSourceTriggersListKey(oldTrigger.Source), triggerID) //nolint
+		}
 
 		for _, tag := range moira.GetStringListsDiff(oldTrigger.Tags, newTrigger.Tags) {
 			c.Send("SREM", triggerTagsKey(triggerID), tag) //nolint
@@ -180,8 +229,17 @@ func (connector *DbConnector) updateTrigger(triggerID string, newTrigger *moira.
 	}
 	c.Send("SET", triggerKey(triggerID), bytes) //nolint
 	c.Send("SADD", triggersListKey, triggerID) //nolint
+	if checkSettings.IsDefault() {
+		c.Send("HDEL", triggersCheckSettingsKey, triggerID) //nolint
+	} else {
+		c.Send("HSET", triggersCheckSettingsKey, triggerID, checkSettingsBytes) //nolint
+	}
 	if newTrigger.IsRemote {
 		c.Send("SADD", remoteTriggersListKey, triggerID) //nolint
+		if newTrigger.Source != moira.DefaultRemoteSource {
+			c.Send("SADD", remoteSourcesListKey, newTrigger.Source) //nolint
+			c.Send("SADD", remoteSourceTriggersListKey(newTrigger.Source), triggerID) //nolint
+		}
 	} else {
 		for _, pattern := range newTrigger.Patterns {
 			c.Send("SADD", patternsListKey, pattern) //nolint
@@ -229,9 +287,15 @@ func (connector *DbConnector) removeTrigger(triggerID string, trigger *moira.Tri
 	c.Send("DEL", triggerKey(triggerID)) //nolint
 	c.Send("DEL", triggerTagsKey(triggerID)) //nolint
 	c.Send("DEL", triggerEventsKey(triggerID)) //nolint
+	c.Send("DEL", triggerCheckDiagnosticsKey(triggerID)) //nolint
+	c.Send("DEL", triggerEscalationsKey(triggerID)) //nolint
 	c.Send("SREM", triggersListKey, triggerID) //nolint
 	c.Send("SREM", remoteTriggersListKey, triggerID) //nolint
+	if trigger.Source != "" {
+		c.Send("SREM", remoteSourceTriggersListKey(trigger.Source), triggerID) //nolint
+	}
 	c.Send("SREM", unusedTriggersKey, triggerID) //nolint
+	c.Send("HDEL", triggersCheckSettingsKey, triggerID) //nolint
 	for _, tag := range trigger.Tags {
 		c.Send("SREM", tagTriggersKey(tag), triggerID) //nolint
 	}
@@ -352,6 +416,12 @@ func (connector *DbConnector) triggerHasSubscriptions(trigger *moira.Trigger) (b
 
 var triggersListKey = "moira-triggers-list"
 var remoteTriggersListKey = "moira-remote-triggers-list"
+var triggersCheckSettingsKey = "moira-triggers-check-settings"
+var remoteSourcesListKey = "moira-remote-sources-list"
+
+func remoteSourceTriggersListKey(source string) string {
+	return "moira-remote-source-triggers-list:" + source
+}
 
 func triggerKey(triggerID string) string {
 	return "moira-trigger:" + triggerID
@@ -242,7 +242,7 @@ func TestTriggerStoring(t *testing.T) {
 			So(actualTriggerChecks, ShouldResemble, []*moira.TriggerCheck{triggerCheck})
 
 			//Add check data
-			err = dataBase.SetTriggerLastCheck(trigger.ID, &lastCheckTest, false)
+			err = dataBase.SetTriggerLastCheck(trigger.ID, &lastCheckTest, "")
 			So(err, ShouldBeNil)
 
 			triggerCheck.LastCheck = lastCheckTest
@@ -251,7 +251,7 @@ func TestTriggerStoring(t *testing.T) {
 			So(actualTriggerChecks, ShouldResemble, []*moira.TriggerCheck{triggerCheck})
 
 			//And throttling
-			err = dataBase.SetTriggerThrottling(trigger.ID, time.Now().Add(-time.Minute))
+			err = dataBase.SetTriggerThrottling(trigger.ID, "default", time.Now().Add(-time.Minute), "")
 			So(err, ShouldBeNil)
 
 			//But it is foul
@@ -261,7 +261,7 @@ func TestTriggerStoring(t *testing.T) {
 
 			//Now good throttling
 			th := time.Now().Add(time.Minute)
-			err = dataBase.SetTriggerThrottling(trigger.ID, th)
+			err = dataBase.SetTriggerThrottling(trigger.ID, "default", th, "")
 			So(err, ShouldBeNil)
 
 			triggerCheck.Throttling = th.Unix()
@@ -550,7 +550,7 @@ func TestRemoteTrigger(t *testing.T) {
 			So(ids, ShouldResemble, []string{})
 		})
 		Convey("Trigger should be added to remote triggers collection", func() {
-			ids, err := dataBase.GetRemoteTriggerIDs()
+			ids, err := dataBase.GetRemoteTriggerIDs(moira.DefaultRemoteSource)
 			So(err, ShouldBeNil)
 			So(ids, ShouldResemble, []string{trigger.ID})
 		})
@@ -587,7 +587,7 @@ func TestRemoteTrigger(t *testing.T) {
 			So(ids, ShouldResemble, []string{trigger.ID})
 		})
 		Convey("Trigger shouldn't be added to remote triggers collection", func() {
-			ids, err := dataBase.GetRemoteTriggerIDs()
+			ids, err := dataBase.GetRemoteTriggerIDs(moira.DefaultRemoteSource)
 			So(err, ShouldBeNil)
 			So(ids, ShouldResemble, []string{})
 		})
@@ -626,7 +626,7 @@ func TestRemoteTrigger(t *testing.T) {
 			So(ids, ShouldResemble, []string{trigger.ID})
 		})
 		Convey("Trigger should be added to remote triggers collection", func() {
-			ids, err := dataBase.GetRemoteTriggerIDs()
+			ids, err := dataBase.GetRemoteTriggerIDs(moira.DefaultRemoteSource)
 			So(err, ShouldBeNil)
 			So(ids, ShouldResemble, []string{trigger.ID})
 		})
@@ -643,6 +643,112 @@ func TestRemoteTrigger(t *testing.T) {
 	})
 }
 
+func TestTriggersCheckSettings(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Check settings are kept only for triggers with non default settings", t, func() {
+		trigger := triggers[0]
+		trigger.CheckInterval = 10
+		trigger.Priority = moira.TriggerPriorityHigh
+		err := dataBase.SaveTrigger(trigger.ID, &trigger)
+		So(err, ShouldBeNil)
+
+		defaultTrigger := triggers[len(triggers)-1]
+		err = dataBase.SaveTrigger(defaultTrigger.ID, &defaultTrigger)
+		So(err, ShouldBeNil)
+
+		settings, err := dataBase.GetTriggersCheckSettings()
+		So(err, ShouldBeNil)
+		So(settings, ShouldResemble, map[string]moira.TriggerCheckSettings{
+			trigger.ID: {CheckInterval: 10, Priority: moira.TriggerPriorityHigh},
+		})
+
+		actual, err := dataBase.GetTrigger(trigger.ID)
+		So(err, ShouldBeNil)
+		So(actual.CheckInterval, ShouldEqual, 10)
+		So(actual.Priority, ShouldEqual, moira.TriggerPriorityHigh)
+
+		trigger.CheckInterval = 0
+		trigger.Priority = moira.TriggerPriorityNormal
+		err = dataBase.SaveTrigger(trigger.ID, &trigger)
+		So(err, ShouldBeNil)
+
+		settings, err = dataBase.GetTriggersCheckSettings()
+		So(err, ShouldBeNil)
+		So(settings, ShouldBeEmpty)
+
+		trigger.Priority = moira.TriggerPriorityLow
+		err = dataBase.SaveTrigger(trigger.ID, &trigger)
+		So(err, ShouldBeNil)
+
+		err = dataBase.RemoveTrigger(trigger.ID)
+		So(err, ShouldBeNil)
+
+		settings, err = dataBase.GetTriggersCheckSettings()
+		So(err, ShouldBeNil)
+		So(settings, ShouldBeEmpty)
+	})
+}
+
+func TestRemoteSourceTriggers(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := newTestDatabase(logger, config)
+	defaultTrigger := &moira.Trigger{
+		ID:           "triggerID-0000000000011",
+		Name:         "default remote",
+		Targets:      []string{"test.target.remote1"},
+		IsRemote:     true,
+		TriggerType:  moira.RisingTrigger,
+		AloneMetrics: map[string]bool{},
+	}
+	sourceTrigger := &moira.Trigger{
+		ID:           "triggerID-0000000000012",
+		Name:         "prometheus remote",
+		Targets:      []string{"test_target_remote2"},
+		IsRemote:     true,
+		Source:       "prometheus",
+		TriggerType:  moira.RisingTrigger,
+		AloneMetrics: map[string]bool{},
+	}
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Remote triggers are split by metric sources", t, func() {
+		err := dataBase.SaveTrigger(defaultTrigger.ID, defaultTrigger)
+		So(err, ShouldBeNil)
+		err = dataBase.SaveTrigger(sourceTrigger.ID, sourceTrigger)
+		So(err, ShouldBeNil)
+
+		actual, err := dataBase.GetTrigger(defaultTrigger.ID)
+		So(err, ShouldBeNil)
+		So(actual.Source, ShouldEqual, moira.DefaultRemoteSource)
+
+		ids, err := dataBase.GetRemoteTriggerIDs(moira.DefaultRemoteSource)
+		So(err, ShouldBeNil)
+		So(ids, ShouldResemble, []string{defaultTrigger.ID})
+
+		ids, err = dataBase.GetRemoteTriggerIDs("prometheus")
+		So(err, ShouldBeNil)
+		So(ids, ShouldResemble, []string{sourceTrigger.ID})
+
+		Convey("Trigger moved to default source is removed from named source", func() {
+			sourceTrigger.Source = ""
+			err := dataBase.SaveTrigger(sourceTrigger.ID, sourceTrigger)
+			So(err, ShouldBeNil)
+
+			ids, err := dataBase.GetRemoteTriggerIDs("prometheus")
+			So(err, ShouldBeNil)
+			So(ids, ShouldBeEmpty)
+
+			ids, err = dataBase.GetRemoteTriggerIDs(moira.DefaultRemoteSource)
+			So(err, ShouldBeNil)
+			So(ids, ShouldHaveLength, 2)
+		})
+	})
+}
+
 func TestTriggerErrorConnection(t *testing.T) {
 	logger, _ := logging.GetLogger("dataBase")
 	dataBase := newTestDatabase(logger, emptyConfig)
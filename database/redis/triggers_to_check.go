@@ -4,46 +4,47 @@ import (
 	"fmt"
 
 	"github.com/gomodule/redigo/redis"
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/database"
 )
 
-// AddLocalTriggersToCheck gets trigger IDs and save it to Redis Set
-func (connector *DbConnector) AddLocalTriggersToCheck(triggerIDs []string) error {
-	return connector.addTriggersToCheck(localTriggersToCheckKey, triggerIDs)
+// AddLocalTriggersToCheck gets trigger IDs and save it to Redis Set of given priority
+func (connector *DbConnector) AddLocalTriggersToCheck(triggerIDs []string, priority moira.TriggerPriority) error {
+	return connector.addTriggersToCheck(localTriggersToCheckKey, triggerIDs, priority)
 }
 
-// AddRemoteTriggersToCheck gets remote trigger IDs and save it to Redis Set
-func (connector *DbConnector) AddRemoteTriggersToCheck(triggerIDs []string) error {
-	return connector.addTriggersToCheck(remoteTriggersToCheckKey, triggerIDs)
+// AddRemoteTriggersToCheck gets remote trigger IDs of given remote source and save it to Redis Set of given priority
+func (connector *DbConnector) AddRemoteTriggersToCheck(source string, triggerIDs []string, priority moira.TriggerPriority) error {
+	return connector.addTriggersToCheck(remoteTriggersToCheckKey(source), triggerIDs, priority)
 }
 
-// GetLocalTriggersToCheck return random trigger ID from Redis Set
+// GetLocalTriggersToCheck return random trigger IDs from Redis Sets, higher priority triggers are returned first
 func (connector *DbConnector) GetLocalTriggersToCheck(count int) ([]string, error) {
 	return connector.getTriggersToCheck(localTriggersToCheckKey, count)
 }
 
-// GetRemoteTriggersToCheck return random remote trigger ID from Redis Set
-func (connector *DbConnector) GetRemoteTriggersToCheck(count int) ([]string, error) {
-	return connector.getTriggersToCheck(remoteTriggersToCheckKey, count)
+// GetRemoteTriggersToCheck return random remote trigger IDs of given remote source from Redis Sets, higher priority triggers are returned first
+func (connector *DbConnector) GetRemoteTriggersToCheck(source string, count int) ([]string, error) {
+	return connector.getTriggersToCheck(remoteTriggersToCheckKey(source), count)
 }
 
-// GetLocalTriggersToCheckCount return number of triggers ID to check from Redis Set
+// GetLocalTriggersToCheckCount return number of triggers ID to check from Redis Sets of all priorities
 func (connector *DbConnector) GetLocalTriggersToCheckCount() (int64, error) {
 	return connector.getTriggersToCheckCount(localTriggersToCheckKey)
 }
 
-// GetRemoteTriggersToCheckCount return number of remote triggers ID to check from Redis Set
-func (connector *DbConnector) GetRemoteTriggersToCheckCount() (int64, error) {
-	return connector.getTriggersToCheckCount(remoteTriggersToCheckKey)
+// GetRemoteTriggersToCheckCount return number of remote triggers ID of given remote source to check from Redis Sets of all priorities
+func (connector *DbConnector) GetRemoteTriggersToCheckCount(source string) (int64, error) {
+	return connector.getTriggersToCheckCount(remoteTriggersToCheckKey(source))
 }
 
-func (connector *DbConnector) addTriggersToCheck(key string, triggerIDs []string) error {
+func (connector *DbConnector) addTriggersToCheck(key string, triggerIDs []string, priority moira.TriggerPriority) error {
 	c := connector.pool.Get()
 	defer c.Close()
 
 	c.Send("MULTI") //nolint
 	for _, triggerID := range triggerIDs {
-		c.Send("SADD", key, triggerID) //nolint
+		c.Send("SADD", triggersToCheckKey(key, priority), triggerID) //nolint
 	}
 	_, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
@@ -52,31 +53,67 @@ func (connector *DbConnector) addTriggersToCheck(key string, triggerIDs []string
 	return nil
 }
 
+// getTriggersToCheck pops triggers from sets of higher priorities first,
+// so high priority triggers are never delayed by the backlog of lower priority ones
 func (connector *DbConnector) getTriggersToCheck(key string, count int) ([]string, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	triggerIDs, err := redis.Strings(c.Do("SPOP", key, count))
-	if err != nil {
-		if err == redis.ErrNil {
-			return make([]string, 0), database.ErrNil
+	triggerIDs := make([]string, 0, count)
+	for _, priority := range moira.TriggerPriorities {
+		priorityTriggerIDs, err := redis.Strings(c.Do("SPOP", triggersToCheckKey(key, priority), count-len(triggerIDs)))
+		if err != nil {
+			if err == redis.ErrNil {
+				return make([]string, 0), database.ErrNil
+			}
+			return make([]string, 0), fmt.Errorf("failed to pop trigger to check: %s", err.Error())
+		}
+		triggerIDs = append(triggerIDs, priorityTriggerIDs...)
+		if len(triggerIDs) >= count {
+			break
 		}
-		return make([]string, 0), fmt.Errorf("failed to pop trigger to check: %s", err.Error())
 	}
-	return triggerIDs, err
+	return triggerIDs, nil
 }
 
 func (connector *DbConnector) getTriggersToCheckCount(key string) (int64, error) {
 	c := connector.pool.Get()
 	defer c.Close()
-	triggersToCheckCount, err := redis.Int64(c.Do("SCARD", key))
+	c.Send("MULTI") //nolint
+	for _, priority := range moira.TriggerPriorities {
+		c.Send("SCARD", triggersToCheckKey(key, priority)) //nolint
+	}
+	counts, err := redis.Int64s(c.Do("EXEC"))
 	if err != nil {
 		if err == redis.ErrNil {
 			return 0, nil
 		}
 		return 0, fmt.Errorf("failed to get trigger to check count: %s", err.Error())
 	}
+	var triggersToCheckCount int64
+	for _, count := range counts {
+		triggersToCheckCount += count
+	}
 	return triggersToCheckCount, nil
 }
 
-var remoteTriggersToCheckKey = "moira-remote-triggers-to-check"
+// triggersToCheckKey returns key of triggers to check set of given priority.
+// Normal priority set keeps the old key to preserve triggers queued before priorities were introduced
+func triggersToCheckKey(key string, priority moira.TriggerPriority) string {
+	switch priority {
+	case moira.TriggerPriorityHigh, moira.TriggerPriorityLow:
+		return fmt.Sprintf("%s:%s", key, priority)
+	default:
+		return key
+	}
+}
+
 var localTriggersToCheckKey = "moira-triggers-to-check"
+
+// remoteTriggersToCheckKey returns key of triggers to check set of given remote source.
+// Default remote source keeps the old key to preserve triggers queued before named remote sources were introduced
+func remoteTriggersToCheckKey(source string) string {
+	if source == moira.DefaultRemoteSource {
+		return "moira-remote-triggers-to-check"
+	}
+	return "moira-remote-source-triggers-to-check:" + source
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gofrs/uuid"
 	. "github.com/smartystreets/goconvey/convey"
 
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/logging/go-logging"
 )
 
@@ -30,7 +31,7 @@ func TestTriggerToCheck(t *testing.T) {
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 0)
 
-		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
 		count, err = dataBase.GetLocalTriggersToCheckCount()
@@ -45,10 +46,10 @@ func TestTriggerToCheck(t *testing.T) {
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 0)
 
-		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
-		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddLocalTriggersToCheck([]string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
 		count, err = dataBase.GetLocalTriggersToCheckCount()
@@ -64,7 +65,7 @@ func TestTriggerToCheck(t *testing.T) {
 		So(actual, ShouldBeEmpty)
 
 		triggerArr := []string{triggerID1, triggerID2, triggerID3, triggerID4, triggerID5, triggerID6}
-		err = dataBase.AddLocalTriggersToCheck(triggerArr)
+		err = dataBase.AddLocalTriggersToCheck(triggerArr, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
 		count, err = dataBase.GetLocalTriggersToCheckCount()
@@ -115,61 +116,61 @@ func TestRemoteTriggerToCheck(t *testing.T) {
 		triggerID5 := uuid.Must(uuid.NewV4()).String()
 		triggerID6 := uuid.Must(uuid.NewV4()).String()
 
-		actual, err := dataBase.GetRemoteTriggersToCheck(1)
+		actual, err := dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(err, ShouldBeNil)
 		So(actual, ShouldBeEmpty)
 
-		count, err := dataBase.GetRemoteTriggersToCheckCount()
+		count, err := dataBase.GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource)
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 0)
 
-		err = dataBase.AddRemoteTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddRemoteTriggersToCheck(moira.DefaultRemoteSource, []string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
-		count, err = dataBase.GetRemoteTriggersToCheckCount()
+		count, err = dataBase.GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource)
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 1)
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(1)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(err, ShouldBeNil)
 		So(actual, ShouldResemble, []string{triggerID1})
 
-		count, err = dataBase.GetRemoteTriggersToCheckCount()
+		count, err = dataBase.GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource)
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 0)
 
-		err = dataBase.AddRemoteTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddRemoteTriggersToCheck(moira.DefaultRemoteSource, []string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
-		err = dataBase.AddRemoteTriggersToCheck([]string{triggerID1})
+		err = dataBase.AddRemoteTriggersToCheck(moira.DefaultRemoteSource, []string{triggerID1}, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
-		count, err = dataBase.GetRemoteTriggersToCheckCount()
+		count, err = dataBase.GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource)
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 1)
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(1)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(err, ShouldBeNil)
 		So(actual, ShouldResemble, []string{triggerID1})
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(1)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(err, ShouldBeNil)
 		So(actual, ShouldBeEmpty)
 
 		triggerArr := []string{triggerID1, triggerID2, triggerID3, triggerID4, triggerID5, triggerID6}
-		err = dataBase.AddRemoteTriggersToCheck(triggerArr)
+		err = dataBase.AddRemoteTriggersToCheck(moira.DefaultRemoteSource, triggerArr, moira.TriggerPriorityNormal)
 		So(err, ShouldBeNil)
 
-		count, err = dataBase.GetRemoteTriggersToCheckCount()
+		count, err = dataBase.GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource)
 		So(err, ShouldBeNil)
 		So(count, ShouldEqual, 6)
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(1)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(err, ShouldBeNil)
 		So(actual[0], ShouldBeIn, triggerArr)
 		triggerArr = removeValue(triggerArr, actual[0])
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(2)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 2)
 		So(err, ShouldBeNil)
 		So(actual, ShouldHaveLength, 2)
 		So(actual[0], ShouldBeIn, triggerArr)
@@ -177,14 +178,14 @@ func TestRemoteTriggerToCheck(t *testing.T) {
 		triggerArr = removeValue(triggerArr, actual[0])
 		triggerArr = removeValue(triggerArr, actual[1])
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(6)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 6)
 		So(err, ShouldBeNil)
 		So(actual, ShouldHaveLength, 3)
 		So(actual[0], ShouldBeIn, triggerArr)
 		So(actual[1], ShouldBeIn, triggerArr)
 		So(actual[2], ShouldBeIn, triggerArr)
 
-		actual, err = dataBase.GetRemoteTriggersToCheck(5)
+		actual, err = dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 5)
 		So(err, ShouldBeNil)
 		So(actual, ShouldBeEmpty)
 
@@ -194,16 +195,61 @@ func TestRemoteTriggerToCheck(t *testing.T) {
 	})
 }
 
+func TestTriggerToCheckPriority(t *testing.T) {
+	logger, _ := logging.ConfigureLog("stdout", "info", "test")
+	dataBase := newTestDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Triggers with higher priority are returned first", t, func() {
+		lowTriggerIDs := []string{"low1", "low2"}
+		normalTriggerIDs := []string{"normal1", "normal2"}
+		highTriggerIDs := []string{"high1", "high2"}
+
+		err := dataBase.AddLocalTriggersToCheck(lowTriggerIDs, moira.TriggerPriorityLow)
+		So(err, ShouldBeNil)
+		err = dataBase.AddLocalTriggersToCheck(normalTriggerIDs, moira.TriggerPriorityNormal)
+		So(err, ShouldBeNil)
+		err = dataBase.AddLocalTriggersToCheck(highTriggerIDs, moira.TriggerPriorityHigh)
+		So(err, ShouldBeNil)
+
+		count, err := dataBase.GetLocalTriggersToCheckCount()
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 6)
+
+		actual, err := dataBase.GetLocalTriggersToCheck(1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldHaveLength, 1)
+		So(actual[0], ShouldBeIn, highTriggerIDs)
+
+		actual, err = dataBase.GetLocalTriggersToCheck(2)
+		So(err, ShouldBeNil)
+		So(actual, ShouldHaveLength, 2)
+		So(actual[0], ShouldBeIn, highTriggerIDs)
+		So(actual[1], ShouldBeIn, normalTriggerIDs)
+
+		actual, err = dataBase.GetLocalTriggersToCheck(3)
+		So(err, ShouldBeNil)
+		So(actual, ShouldHaveLength, 3)
+		So(actual[0], ShouldBeIn, normalTriggerIDs)
+		So(actual[1], ShouldBeIn, lowTriggerIDs)
+		So(actual[2], ShouldBeIn, lowTriggerIDs)
+
+		count, err = dataBase.GetLocalTriggersToCheckCount()
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 0)
+	})
+}
+
 func TestRemoteTriggerToCheckConnection(t *testing.T) {
 	logger, _ := logging.ConfigureLog("stdout", "info", "test")
 	dataBase := newTestDatabase(logger, emptyConfig)
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
-		err := dataBase.AddRemoteTriggersToCheck([]string{"123"})
+		err := dataBase.AddRemoteTriggersToCheck(moira.DefaultRemoteSource, []string{"123"}, moira.TriggerPriorityNormal)
 		So(err, ShouldNotBeNil)
 
-		triggerID, err := dataBase.GetRemoteTriggersToCheck(1)
+		triggerID, err := dataBase.GetRemoteTriggersToCheck(moira.DefaultRemoteSource, 1)
 		So(triggerID, ShouldBeEmpty)
 		So(err, ShouldNotBeNil)
 	})
@@ -215,7 +261,7 @@ func TestTriggerToCheckConnection(t *testing.T) {
 	dataBase.flush()
 	defer dataBase.flush()
 	Convey("Should throw error when no connection", t, func() {
-		err := dataBase.AddLocalTriggersToCheck([]string{"123"})
+		err := dataBase.AddLocalTriggersToCheck([]string{"123"}, moira.TriggerPriorityNormal)
 		So(err, ShouldNotBeNil)
 
 		triggerID, err := dataBase.GetLocalTriggersToCheck(1)
@@ -26,8 +26,11 @@ const (
 )
 
 const (
-	format        = "15:04 02.01.2006"
-	remindMessage = "This metric has been in bad state for more than %v hours - please, fix."
+	format             = "15:04 02.01.2006"
+	remindMessage      = "This metric has been in bad state for more than %v hours - please, fix."
+	parentsMessage     = "This metric changed its state while parent triggers were in bad state: %s."
+	aggregationMessage = "%d of %d metrics (%.1f%%) are in bad state"
+	ackMessage         = "This bad state is acknowledged by %s"
 )
 
 // NotificationEvent represents trigger state changes event
@@ -44,12 +47,33 @@ type NotificationEvent struct {
 	OldState         State              `json:"old_state"`
 	Message          *string            `json:"msg,omitempty"`
 	MessageEventInfo *EventInfo         `json:"event_message"`
+	// IsMuted is true for event which is saved to trigger events history, but notifications about it are not sent
+	IsMuted bool `json:"muted,omitempty"`
 }
 
 // EventInfo - a base for creating messages.
 type EventInfo struct {
 	Maintenance *MaintenanceInfo `json:"maintenance,omitempty"`
 	Interval    *int64           `json:"interval,omitempty"`
+	// SuppressedByParents are IDs of parent triggers which were in bad state when state was changed
+	SuppressedByParents []string `json:"suppressed_by_parents,omitempty"`
+	// Aggregation describes metric states which aggregated trigger state was computed from
+	Aggregation *AggregationInfo `json:"aggregation,omitempty"`
+	// Ack is an acknowledgement of bad state which was active when state was changed
+	Ack *AckData `json:"ack,omitempty"`
+}
+
+// AggregationInfo describes distribution of metric states of trigger with aggregation
+type AggregationInfo struct {
+	BadMetrics   int `json:"bad_metrics"`
+	TotalMetrics int `json:"total_metrics"`
+	// TopOffenders are names of worst metrics in bad state
+	TopOffenders []string `json:"top_offenders,omitempty"`
+}
+
+// IsAcknowledged returns true if bad state of event was acknowledged when event was created
+func (event *NotificationEvent) IsAcknowledged() bool {
+	return event.MessageEventInfo != nil && event.MessageEventInfo.Ack != nil
 }
 
 // CreateMessage - creates a message based on EventInfo.
@@ -63,10 +87,54 @@ func (event *NotificationEvent) CreateMessage(location *time.Location) string {
 		return ""
 	}
 
+	messages := make([]string, 0)
+	if message := event.createEventInfoMessage(location); message != "" {
+		messages = append(messages, message)
+	}
+	if event.MessageEventInfo.Ack != nil {
+		messages = append(messages, event.MessageEventInfo.Ack.createMessage(location))
+	}
+	if event.MessageEventInfo.Aggregation != nil {
+		messages = append(messages, event.MessageEventInfo.Aggregation.createMessage())
+	}
+	return strings.Join(messages, " ")
+}
+
+func (ack *AckData) createMessage(location *time.Location) string {
+	if location == nil {
+		location = time.UTC
+	}
+	message := fmt.Sprintf(ackMessage, ack.User)
+	if ack.Expiry != 0 {
+		message += " till " + time.Unix(ack.Expiry, 0).In(location).Format(format)
+	}
+	if ack.Comment != "" {
+		message += ": " + ack.Comment
+	}
+	return message + "."
+}
+
+func (aggregationInfo *AggregationInfo) createMessage() string {
+	var share float64
+	if aggregationInfo.TotalMetrics > 0 {
+		share = float64(aggregationInfo.BadMetrics) * 100 / float64(aggregationInfo.TotalMetrics) //nolint
+	}
+	message := fmt.Sprintf(aggregationMessage, aggregationInfo.BadMetrics, aggregationInfo.TotalMetrics, share)
+	if len(aggregationInfo.TopOffenders) == 0 {
+		return message + "."
+	}
+	return fmt.Sprintf("%s, top offenders: %s.", message, strings.Join(aggregationInfo.TopOffenders, ", "))
+}
+
+func (event *NotificationEvent) createEventInfoMessage(location *time.Location) string {
 	if event.MessageEventInfo.Interval != nil && event.MessageEventInfo.Maintenance == nil {
 		return fmt.Sprintf(remindMessage, *event.MessageEventInfo.Interval)
 	}
 
+	if len(event.MessageEventInfo.SuppressedByParents) > 0 {
+		return fmt.Sprintf(parentsMessage, strings.Join(event.MessageEventInfo.SuppressedByParents, ", "))
+	}
+
 	if event.MessageEventInfo.Maintenance == nil {
 		return ""
 	}
@@ -160,6 +228,8 @@ type ContactData struct {
 	Value string `json:"value"`
 	ID    string `json:"id"`
 	User  string `json:"user"`
+	// DigestWindow is a duration in seconds notifications of all subscriptions to contact are held and combined for, zero disables digests
+	DigestWindow int64 `json:"digest_window,omitempty"`
 }
 
 // SubscriptionData represents user subscription
@@ -175,6 +245,57 @@ type SubscriptionData struct {
 	IgnoreRecoverings bool         `json:"ignore_recoverings,omitempty"`
 	ThrottlingEnabled bool         `json:"throttling"`
 	User              string       `json:"user"`
+	// ThrottlingPolicy is a name of notifier throttling policy used if throttling is enabled, empty name means default policy
+	ThrottlingPolicy string `json:"throttling_policy,omitempty"`
+	// DigestWindow is a duration in seconds notifications of subscription to each contact are held and combined for, zero disables digests
+	DigestWindow int64 `json:"digest_window,omitempty"`
+	// Escalation is a policy of notifying next tiers of contacts while ERROR event is not acknowledged or recovered
+	Escalation *EscalationPolicy `json:"escalation,omitempty"`
+}
+
+// EscalationPolicy represents tiers of contacts notified one by one about ERROR event of trigger metric.
+// The last tier is notified repeatedly with its delay until event is acknowledged or metric is recovered.
+// Escalation notifications are not delayed by subscription schedule and throttling: tier delays already define
+// when next contacts are notified and escalation must reach them while bad state is left unattended
+type EscalationPolicy struct {
+	Tiers []EscalationTier `json:"tiers"`
+}
+
+// EscalationTier represents contacts notified if event is not acknowledged in given delay after previous notification
+type EscalationTier struct {
+	Contacts       []string `json:"contacts"`
+	DelayInMinutes int64    `json:"delay_in_minutes"`
+}
+
+// IsEnabled returns true if escalation policy has tiers to notify
+func (policy *EscalationPolicy) IsEnabled() bool {
+	return policy != nil && len(policy.Tiers) > 0
+}
+
+// HasContact returns true if contact is notified by any of escalation tiers
+func (policy *EscalationPolicy) HasContact(contactID string) bool {
+	if policy == nil {
+		return false
+	}
+	for _, tier := range policy.Tiers {
+		for _, id := range tier.Contacts {
+			if id == contactID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EscalationData represents escalation of trigger metric ERROR event started by subscription
+type EscalationData struct {
+	TriggerID      string `json:"trigger_id"`
+	Metric         string `json:"metric"`
+	SubscriptionID string `json:"subscription_id"`
+	Started        int64  `json:"started"`
+	Tier           int    `json:"tier"`
+	// RepeatInterval is an interval in seconds notification of the last tier is repeated with, zero means no repeats
+	RepeatInterval int64 `json:"repeat_interval,omitempty"`
 }
 
 // PlottingData represents plotting settings
@@ -206,6 +327,46 @@ type ScheduledNotification struct {
 	Throttled bool              `json:"throttled"`
 	SendFail  int               `json:"send_fail"`
 	Timestamp int64             `json:"timestamp"`
+	// Digest is a key of digest notification is combined into, it is empty if notification is sent separately
+	Digest string `json:"digest,omitempty"`
+	// Escalation is a state of escalation notification is sent by, it is nil for notifications of subscription contacts
+	Escalation *EscalationData `json:"escalation,omitempty"`
+}
+
+// NotificationHistoryItem represents single attempt to send notification package to contact
+type NotificationHistoryItem struct {
+	Timestamp    int64  `json:"timestamp"`
+	ContactID    string `json:"contact_id"`
+	ContactValue string `json:"contact_value"`
+	SenderType   string `json:"sender_type"`
+	// TriggerIDs are triggers of package events, digest package contains events of several triggers
+	TriggerIDs []string                   `json:"trigger_ids"`
+	Events     []NotificationHistoryEvent `json:"events"`
+	// Attempt is a number of attempt to send package starting from 1
+	Attempt int `json:"attempt"`
+	// Duration of sending in milliseconds
+	Duration int64  `json:"duration"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NotificationHistoryEvent represents event of notification package stored in history
+type NotificationHistoryEvent struct {
+	TriggerID string `json:"trigger_id"`
+	Metric    string `json:"metric"`
+	OldState  State  `json:"old_state"`
+	State     State  `json:"state"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// HasTrigger checks if notification package contained events of given trigger
+func (item *NotificationHistoryItem) HasTrigger(triggerID string) bool {
+	for _, id := range item.TriggerIDs {
+		if id == triggerID {
+			return true
+		}
+	}
+	return false
 }
 
 // MatchedMetric represents parsed and matched metric data
@@ -216,6 +377,8 @@ type MatchedMetric struct {
 	Timestamp          int64
 	RetentionTimestamp int64
 	Retention          int
+	// Aggregated is true if Value is aggregated value of all points in retention bucket, it replaces saved value of bucket
+	Aggregated bool
 }
 
 // MetricValue represents metric data
@@ -232,27 +395,275 @@ const (
 	RisingTrigger = "rising"
 	// ExpressionTrigger represents trigger type with custom user expression
 	ExpressionTrigger = "expression"
+	// AnomalyTrigger represents trigger type, in which WARN and ERROR mean breaching of band expected from previous seasons
+	AnomalyTrigger = "anomaly"
+)
+
+// DefaultRemoteSource is a name of remote metric source configured in 'remote' section of config.
+// Remote triggers created before named remote sources were introduced use it
+const DefaultRemoteSource = "default"
+
+// GetRemoteSourceName returns name of remote metric source of trigger or empty string for local trigger.
+// Triggers without source name which are marked as remote use default remote source
+func GetRemoteSourceName(isRemote bool, source string) string {
+	if !isRemote {
+		return ""
+	}
+	if source == "" {
+		return DefaultRemoteSource
+	}
+	return source
+}
+
+// Anomaly trigger band directions
+const (
+	// AnomalyDirectionBoth means that values both above and below band are anomalies
+	AnomalyDirectionBoth = "both"
+	// AnomalyDirectionUp means that only values above band are anomalies
+	AnomalyDirectionUp = "up"
+	// AnomalyDirectionDown means that only values below band are anomalies
+	AnomalyDirectionDown = "down"
 )
 
+// AnomalySettings represents anomaly trigger parameters.
+// Expected band of metric value is mean of metric values around the same time of previous seasons
+// plus and minus sensitivity multiplied by standard deviation of these values
+type AnomalySettings struct {
+	// Season is a period of metric seasonality in seconds
+	Season int64 `json:"season"`
+	// Seasons is a number of previous seasons used to calculate band
+	Seasons int `json:"seasons"`
+	// Window is a time in seconds before and after the same time of previous season, values in window are used to calculate band
+	Window int64 `json:"window"`
+	// WarnSensitivity is a number of standard deviations which value must breach band by to switch to WARN
+	WarnSensitivity *float64 `json:"warn_sensitivity,omitempty"`
+	// ErrorSensitivity is a number of standard deviations which value must breach band by to switch to ERROR
+	ErrorSensitivity *float64 `json:"error_sensitivity,omitempty"`
+	// Direction is one of "both", "up" and "down"
+	Direction string `json:"direction,omitempty"`
+}
+
 // Trigger represents trigger data object
 type Trigger struct {
-	ID               string          `json:"id"`
-	Name             string          `json:"name"`
-	Desc             *string         `json:"desc,omitempty"`
-	Targets          []string        `json:"targets"`
-	WarnValue        *float64        `json:"warn_value"`
-	ErrorValue       *float64        `json:"error_value"`
-	TriggerType      string          `json:"trigger_type"`
-	Tags             []string        `json:"tags"`
-	TTLState         *TTLState       `json:"ttl_state,omitempty"`
-	TTL              int64           `json:"ttl,omitempty"`
-	Schedule         *ScheduleData   `json:"sched,omitempty"`
-	Expression       *string         `json:"expression,omitempty"`
-	PythonExpression *string         `json:"python_expression,omitempty"`
-	Patterns         []string        `json:"patterns"`
-	IsRemote         bool            `json:"is_remote"`
-	MuteNewMetrics   bool            `json:"mute_new_metrics"`
-	AloneMetrics     map[string]bool `json:"alone_metrics"`
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	Desc             *string          `json:"desc,omitempty"`
+	Targets          []string         `json:"targets"`
+	WarnValue        *float64         `json:"warn_value"`
+	ErrorValue       *float64         `json:"error_value"`
+	TriggerType      string           `json:"trigger_type"`
+	Tags             []string         `json:"tags"`
+	TTLState         *TTLState        `json:"ttl_state,omitempty"`
+	TTL              int64            `json:"ttl,omitempty"`
+	Schedule         *ScheduleData    `json:"sched,omitempty"`
+	Expression       *string          `json:"expression,omitempty"`
+	PythonExpression *string          `json:"python_expression,omitempty"`
+	Patterns         []string         `json:"patterns"`
+	IsRemote         bool             `json:"is_remote"`
+	MuteNewMetrics   bool             `json:"mute_new_metrics"`
+	AloneMetrics     map[string]bool  `json:"alone_metrics"`
+	Hysteresis       *Hysteresis      `json:"hysteresis,omitempty"`
+	Anomaly          *AnomalySettings `json:"anomaly,omitempty"`
+	// Parents are IDs of triggers this trigger depends on, events are suppressed while any parent is in bad state
+	Parents []string `json:"parents,omitempty"`
+	// CheckInterval is a minimal interval in seconds between trigger checks, zero means checker default.
+	// Triggers are not checked more often than checker ticks of their metric source, so it can't be less than the tick
+	CheckInterval int64 `json:"check_interval,omitempty"`
+	// Priority defines order in which triggers are taken from checks queue
+	Priority TriggerPriority `json:"priority,omitempty"`
+	// Source is a name of remote metric source of remote trigger, it is empty for local trigger
+	Source string `json:"source,omitempty"`
+	// Reminders override checker default intervals between reminders about bad states
+	Reminders ReminderIntervals `json:"reminders,omitempty"`
+	// Aggregation computes trigger state from share or count of metrics in bad state
+	Aggregation *AggregationSettings `json:"aggregation,omitempty"`
+}
+
+// ReminderIntervals are intervals in seconds between reminders about trigger or metric staying in state.
+// Zero interval disables reminders about state
+type ReminderIntervals map[State]int64
+
+// DefaultReminderIntervals are used if checker config does not define reminder intervals
+var DefaultReminderIntervals = ReminderIntervals{
+	StateERROR:  86400, //nolint
+	StateNODATA: 86400, //nolint
+}
+
+// ReminderStates are states which reminders can be configured for
+var ReminderStates = []State{StateWARN, StateERROR, StateNODATA, StateEXCEPTION}
+
+// ReminderIntervalUnit is one hour because reminders tell how many hours metric is in bad state,
+// so reminder intervals should be whole number of hours
+const ReminderIntervalUnit int64 = 3600
+
+// Validate checks that reminders are set only for reminder states and intervals are zero or whole number of hours
+func (reminders ReminderIntervals) Validate() error {
+	for state, interval := range reminders {
+		if !isReminderState(state) {
+			return fmt.Errorf("reminders can be set only for WARN, ERROR, NODATA and EXCEPTION states")
+		}
+		if interval < 0 || interval%ReminderIntervalUnit != 0 {
+			return fmt.Errorf("reminder interval should be zero or whole number of hours (multiple of %d seconds)", ReminderIntervalUnit)
+		}
+	}
+	return nil
+}
+
+func isReminderState(state State) bool {
+	for _, reminderState := range ReminderStates {
+		if state == reminderState {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInterval returns reminder interval of state, intervals of states absent in reminders are taken from defaults
+func (reminders ReminderIntervals) GetInterval(state State, defaults ReminderIntervals) int64 {
+	if interval, ok := reminders[state]; ok {
+		return interval
+	}
+	return defaults[state]
+}
+
+// TriggerPriority defines order in which triggers are taken from checks queue.
+// Triggers with higher priority are checked first regardless of the queue length of lower priorities
+type TriggerPriority string
+
+// Trigger priorities
+const (
+	TriggerPriorityLow    TriggerPriority = "low"
+	TriggerPriorityNormal TriggerPriority = "normal"
+	TriggerPriorityHigh   TriggerPriority = "high"
+)
+
+// TriggerPriorities are all trigger priorities ordered from the highest to the lowest one
+var TriggerPriorities = []TriggerPriority{TriggerPriorityHigh, TriggerPriorityNormal, TriggerPriorityLow}
+
+// TriggerCheckSettings represents how often and in which order trigger is checked
+type TriggerCheckSettings struct {
+	CheckInterval int64           `json:"check_interval,omitempty"`
+	Priority      TriggerPriority `json:"priority,omitempty"`
+}
+
+// GetCheckSettings returns check settings of trigger
+func (trigger *Trigger) GetCheckSettings() TriggerCheckSettings {
+	return TriggerCheckSettings{
+		CheckInterval: trigger.CheckInterval,
+		Priority:      trigger.Priority,
+	}
+}
+
+// IsDefault returns true if trigger is checked with checker default interval and normal priority
+func (settings TriggerCheckSettings) IsDefault() bool {
+	return settings.CheckInterval == 0 && settings.GetPriority() == TriggerPriorityNormal
+}
+
+// GetPriority returns trigger priority, empty priority is normal one
+func (settings TriggerCheckSettings) GetPriority() TriggerPriority {
+	if settings.Priority == "" {
+		return TriggerPriorityNormal
+	}
+	return settings.Priority
+}
+
+// Hysteresis defines how long new metric state must persist before metric switches to it and event is emitted.
+// Worsening condition is used for transitions to worse states and Recovering condition for the rest
+type Hysteresis struct {
+	Worsening  PendingCondition `json:"worsening"`
+	Recovering PendingCondition `json:"recovering"`
+}
+
+// PendingCondition is a minimal duration in seconds and minimal number of consecutive trigger checks of new metric state.
+// Every trigger check is counted once, even if it covers several points of metric.
+// Zero value disables the corresponding limit
+type PendingCondition struct {
+	Seconds int64 `json:"seconds"`
+	Checks  int64 `json:"checks"`
+}
+
+// GetPendingCondition returns condition of transition from old to new metric state
+func (hysteresis *Hysteresis) GetPendingCondition(newState, oldState State) PendingCondition {
+	if hysteresis == nil {
+		return PendingCondition{}
+	}
+	if newState.IsWorseThan(oldState) {
+		return hysteresis.Worsening
+	}
+	return hysteresis.Recovering
+}
+
+// IsSatisfied returns true if new state persisted for given seconds and checks is enough to switch to it
+func (condition PendingCondition) IsSatisfied(pendingSeconds, pendingChecks int64) bool {
+	return pendingSeconds >= condition.Seconds && pendingChecks >= condition.Checks
+}
+
+// Trigger aggregation modes
+const (
+	// AggregationModeShare means that aggregation thresholds are percents of metrics in bad state
+	AggregationModeShare = "share"
+	// AggregationModeCount means that aggregation thresholds are numbers of metrics in bad state
+	AggregationModeCount = "count"
+)
+
+// DefaultAggregationTopOffenders is a number of top offending metrics listed in event message if aggregation does not set it
+const DefaultAggregationTopOffenders = 5
+
+// AggregationTopOffendersDisabled is a value of aggregation top offenders which disables list of top offending metrics
+const AggregationTopOffendersDisabled = -1
+
+// AggregationSettings switches trigger to WARN or ERROR state when share or count of its metrics in bad state exceeds threshold.
+// Metrics in ERROR state are counted for both thresholds, metrics in WARN state only for warn threshold
+type AggregationSettings struct {
+	Mode       string   `json:"mode"`
+	WarnValue  *float64 `json:"warn_value,omitempty"`
+	ErrorValue *float64 `json:"error_value,omitempty"`
+	// MuteMetrics disables notifications about state changes of single metrics
+	MuteMetrics bool `json:"mute_metrics,omitempty"`
+	// TopOffenders is a number of worst metrics listed in trigger event message.
+	// Zero means DefaultAggregationTopOffenders, AggregationTopOffendersDisabled disables the list
+	TopOffenders int `json:"top_offenders,omitempty"`
+}
+
+// GetState returns trigger state by numbers of metrics in WARN and ERROR states among total number of metrics
+func (aggregation *AggregationSettings) GetState(warnCount, errorCount, total int) State {
+	if aggregation == nil || total == 0 {
+		return StateOK
+	}
+	if aggregation.isExceeded(aggregation.ErrorValue, errorCount, total) {
+		return StateERROR
+	}
+	if aggregation.isExceeded(aggregation.WarnValue, warnCount+errorCount, total) {
+		return StateWARN
+	}
+	return StateOK
+}
+
+func (aggregation *AggregationSettings) isExceeded(threshold *float64, count, total int) bool {
+	if threshold == nil {
+		return false
+	}
+	value := float64(count)
+	if aggregation.Mode == AggregationModeShare {
+		value = value * 100 / float64(total) //nolint
+	}
+	return value > *threshold
+}
+
+// IsMetricsMuted returns true if notifications about single metrics of trigger must not be sent
+func (aggregation *AggregationSettings) IsMetricsMuted() bool {
+	return aggregation != nil && aggregation.MuteMetrics
+}
+
+// GetTopOffenders returns number of top offending metrics listed in event message
+func (aggregation *AggregationSettings) GetTopOffenders() int {
+	if aggregation == nil || aggregation.TopOffenders == 0 {
+		return DefaultAggregationTopOffenders
+	}
+	if aggregation.TopOffenders == AggregationTopOffendersDisabled {
+		return 0
+	}
+	return aggregation.TopOffenders
 }
 
 // TriggerCheck represents trigger data with last check data and check timestamp
@@ -285,6 +696,10 @@ type CheckData struct {
 	Suppressed                   bool              `json:"suppressed,omitempty"`
 	SuppressedState              State             `json:"suppressed_state,omitempty"`
 	Message                      string            `json:"msg,omitempty"`
+	// SuppressedByParents are IDs of parent triggers which suppressed trigger events
+	SuppressedByParents []string `json:"suppressed_by_parents,omitempty"`
+	// Ack is an acknowledgement of whole trigger, it is removed when trigger and all its metrics are recovered to OK
+	Ack *AckData `json:"ack,omitempty"`
 }
 
 // RemoveMetricState is a function that removes MetricState from map of states.
@@ -292,6 +707,43 @@ func (checkData CheckData) RemoveMetricState(metricName string) {
 	delete(checkData.Metrics, metricName)
 }
 
+// CheckDiagnostics is a compact record of what checker did during single trigger check
+type CheckDiagnostics struct {
+	Timestamp int64 `json:"timestamp"`
+	// Duration is a duration of whole check in milliseconds
+	Duration int64               `json:"duration"`
+	State    State               `json:"state,omitempty"`
+	Message  string              `json:"msg,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Targets  []TargetDiagnostics `json:"targets"`
+	// AloneMetrics are names of alone metrics by target names
+	AloneMetrics map[string]string `json:"alone_metrics,omitempty"`
+	// Duplicates are names of metrics which were fetched more than once by target names
+	Duplicates map[string][]string `json:"duplicates,omitempty"`
+	// FailedMetrics are last expression inputs and results of metrics which are not in OK state
+	FailedMetrics map[string]MetricDiagnostics `json:"failed_metrics,omitempty"`
+}
+
+// TargetDiagnostics describes fetch of single trigger target
+type TargetDiagnostics struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	// FetchDuration is a duration of target fetch in milliseconds
+	FetchDuration int64 `json:"fetch_duration"`
+	Series        int   `json:"series"`
+	Points        int   `json:"points"`
+	// Wildcards is a number of fetched series which are pattern wildcards without data
+	Wildcards int `json:"wildcards,omitempty"`
+}
+
+// MetricDiagnostics describes last expression evaluation of metric
+type MetricDiagnostics struct {
+	Timestamp int64              `json:"timestamp"`
+	Values    map[string]float64 `json:"values"`
+	State     State              `json:"state,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
 // MetricState represents metric state data for given timestamp
 type MetricState struct {
 	EventTimestamp  int64              `json:"event_timestamp"`
@@ -303,6 +755,17 @@ type MetricState struct {
 	Values          map[string]float64 `json:"values,omitempty"`
 	Maintenance     int64              `json:"maintenance,omitempty"`
 	MaintenanceInfo MaintenanceInfo    `json:"maintenance_info"`
+	// PendingState is a new state which does not persist long enough to switch metric to it according to trigger hysteresis
+	PendingState State `json:"pending_state,omitempty"`
+	PendingSince int64 `json:"pending_since,omitempty"`
+	// PendingChecks is a number of trigger checks the pending state is observed in
+	PendingChecks int64 `json:"pending_checks,omitempty"`
+	// PendingCheck is a timestamp the last trigger check counted in PendingChecks was made until
+	PendingCheck int64 `json:"pending_check,omitempty"`
+	// SuppressedByParents are IDs of parent triggers which suppressed metric events
+	SuppressedByParents []string `json:"suppressed_by_parents,omitempty"`
+	// Ack is an acknowledgement of metric bad state, it is removed when metric is recovered to OK
+	Ack *AckData `json:"ack,omitempty"`
 	// AloneMetrics    map[string]string  `json:"alone_metrics"` // represents a relation between name of alone metrics and their targets
 }
 
@@ -317,6 +780,21 @@ func (metricState *MetricState) GetMaintenance() (MaintenanceInfo, int64) {
 	return metricState.MaintenanceInfo, metricState.Maintenance
 }
 
+// AckData represents acknowledgement of bad state by user who is working on it.
+// Reminders and escalations are not sent while acknowledgement is active
+type AckData struct {
+	User      string `json:"user"`
+	Comment   string `json:"comment,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+	// Expiry is a timestamp acknowledgement expires at, zero means acknowledgement is active until recovery
+	Expiry int64 `json:"expiry,omitempty"`
+}
+
+// IsActive returns true if acknowledgement is set and is not expired at given timestamp
+func (ack *AckData) IsActive(timestamp int64) bool {
+	return ack != nil && (ack.Expiry == 0 || ack.Expiry > timestamp)
+}
+
 // MaintenanceInfo represents user and time set/unset maintenance
 type MaintenanceInfo struct {
 	StartUser *string `json:"setup_user"`
@@ -339,6 +817,27 @@ type MetricEvent struct {
 	Pattern string `json:"pattern"`
 }
 
+// PrefixOffender represents metric prefix which exceeded filter limits and points dropped by filter
+type PrefixOffender struct {
+	Prefix string `json:"prefix"`
+	// Points of new metrics which were not saved because prefix has too many metrics
+	DroppedByCardinality int64 `json:"dropped_by_cardinality"`
+	// Points which were not saved because prefix sends points too often
+	DroppedByRate int64 `json:"dropped_by_rate"`
+	// Timestamp of last dropped point
+	Timestamp int64 `json:"timestamp"`
+}
+
+// RejectedLine represents metric line which was rejected by filter
+type RejectedLine struct {
+	Line   string `json:"line"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+	// Address of line sender
+	Source    string `json:"source"`
+	Timestamp int64  `json:"timestamp"`
+}
+
 // SearchHighlight represents highlight
 type SearchHighlight struct {
 	Field string
@@ -366,6 +865,38 @@ func (events NotificationEvents) GetSubjectState() State {
 	return result
 }
 
+// TriggerEvents are events of one trigger in notification digest
+type TriggerEvents struct {
+	Trigger TriggerData
+	Events  NotificationEvents
+}
+
+// GroupByTriggers splits events of several triggers by given triggers ordered by name, events of unknown triggers are skipped
+func (events NotificationEvents) GroupByTriggers(triggers map[string]TriggerData) []TriggerEvents {
+	groups := make([]TriggerEvents, 0, len(triggers))
+	indexes := make(map[string]int, len(triggers))
+	for _, event := range events {
+		trigger, ok := triggers[event.TriggerID]
+		if !ok {
+			continue
+		}
+		index, ok := indexes[event.TriggerID]
+		if !ok {
+			index = len(groups)
+			indexes[event.TriggerID] = index
+			groups = append(groups, TriggerEvents{Trigger: trigger})
+		}
+		groups[index].Events = append(groups[index].Events, event)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Trigger.Name != groups[j].Trigger.Name {
+			return groups[i].Trigger.Name < groups[j].Trigger.Name
+		}
+		return groups[i].Trigger.ID < groups[j].Trigger.ID
+	})
+	return groups
+}
+
 // GetTags returns "[tag1][tag2]...[tagN]" string
 func (trigger *TriggerData) GetTags() string {
 	var buffer bytes.Buffer
@@ -391,6 +922,16 @@ func (notification *ScheduledNotification) GetKey() string {
 	)
 }
 
+// SetDigest delays notification till the end of digest window and marks it with digest key.
+// Notifications with the same digest key and timestamp are sent to contact in one package
+func (notification *ScheduledNotification) SetDigest(key string, window int64) {
+	if window <= 0 {
+		return
+	}
+	notification.Digest = key
+	notification.Timestamp = (notification.Timestamp + window - 1) / window * window
+}
+
 // IsScheduleAllows check if the time is in the allowed schedule interval
 func (schedule *ScheduleData) IsScheduleAllows(ts int64) bool {
 	if schedule == nil {
@@ -530,6 +1071,19 @@ func (trigger *Trigger) IsSimple() bool {
 	return true
 }
 
+// IsRecovered returns true if trigger and all its metrics are in OK state
+func (checkData *CheckData) IsRecovered() bool {
+	if checkData.State != StateOK {
+		return false
+	}
+	for _, metricState := range checkData.Metrics {
+		if metricState.State != StateOK {
+			return false
+		}
+	}
+	return true
+}
+
 // UpdateScore update and return checkData score, based on metric states and checkData state
 func (checkData *CheckData) UpdateScore() int64 {
 	checkData.Score = stateScores[checkData.State]
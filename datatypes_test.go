@@ -151,6 +151,14 @@ func TestNotificationEvent_CreateMessage(t *testing.T) {
 			event := NotificationEvent{MessageEventInfo: &EventInfo{Interval: &interval}}
 			So(event.CreateMessage(nil), ShouldEqual, message)
 		})
+		Convey("Test: creating parents message", func() {
+			message := "This metric changed its state while parent triggers were in bad state: parent1, parent2."
+			event := NotificationEvent{MessageEventInfo: &EventInfo{
+				Maintenance:         &MaintenanceInfo{},
+				SuppressedByParents: []string{"parent1", "parent2"},
+			}}
+			So(event.CreateMessage(nil), ShouldEqual, message)
+		})
 		Convey("Test: check for void MaintenanceInfo", func() {
 			event := NotificationEvent{MessageEventInfo: &EventInfo{}}
 			So(event.CreateMessage(nil), ShouldEqual, "")
@@ -684,3 +692,219 @@ func testMaintenance(conveyMessage string, actualInfo MaintenanceInfo, maintenan
 		So(lastCheckTest.Maintenance, ShouldEqual, maintenance)
 	})
 }
+
+func TestTriggerCheckSettings(t *testing.T) {
+	Convey("Trigger without check settings uses defaults", t, func() {
+		trigger := Trigger{}
+		settings := trigger.GetCheckSettings()
+		So(settings.IsDefault(), ShouldBeTrue)
+		So(settings.GetPriority(), ShouldEqual, TriggerPriorityNormal)
+	})
+
+	Convey("Trigger with normal priority uses defaults", t, func() {
+		trigger := Trigger{Priority: TriggerPriorityNormal}
+		So(trigger.GetCheckSettings().IsDefault(), ShouldBeTrue)
+	})
+
+	Convey("Trigger with check interval or priority does not use defaults", t, func() {
+		trigger := Trigger{CheckInterval: 10}
+		So(trigger.GetCheckSettings().IsDefault(), ShouldBeFalse)
+		trigger = Trigger{Priority: TriggerPriorityHigh}
+		So(trigger.GetCheckSettings().IsDefault(), ShouldBeFalse)
+		So(trigger.GetCheckSettings().GetPriority(), ShouldEqual, TriggerPriorityHigh)
+	})
+}
+
+func TestGetRemoteSourceName(t *testing.T) {
+	Convey("Local trigger has no remote source", t, func() {
+		So(GetRemoteSourceName(false, ""), ShouldBeEmpty)
+	})
+
+	Convey("Remote trigger without source uses default remote source", t, func() {
+		So(GetRemoteSourceName(true, ""), ShouldEqual, DefaultRemoteSource)
+	})
+
+	Convey("Remote trigger with source uses it", t, func() {
+		So(GetRemoteSourceName(true, "prometheus"), ShouldEqual, "prometheus")
+	})
+
+	Convey("Local trigger ignores source", t, func() {
+		So(GetRemoteSourceName(false, "prometheus"), ShouldBeEmpty)
+	})
+}
+
+func TestReminderIntervals(t *testing.T) {
+	Convey("Reminder intervals of trigger override defaults", t, func() {
+		reminders := ReminderIntervals{StateWARN: 3600, StateERROR: 0}
+		So(reminders.GetInterval(StateWARN, DefaultReminderIntervals), ShouldEqual, 3600)
+		So(reminders.GetInterval(StateERROR, DefaultReminderIntervals), ShouldEqual, 0)
+		So(reminders.GetInterval(StateNODATA, DefaultReminderIntervals), ShouldEqual, 86400)
+		So(reminders.GetInterval(StateEXCEPTION, DefaultReminderIntervals), ShouldEqual, 0)
+	})
+
+	Convey("Empty reminder intervals use defaults", t, func() {
+		var reminders ReminderIntervals
+		So(reminders.GetInterval(StateERROR, DefaultReminderIntervals), ShouldEqual, 86400)
+	})
+
+	Convey("Reminder intervals validation", t, func() {
+		So(DefaultReminderIntervals.Validate(), ShouldBeNil)
+		So(ReminderIntervals{StateWARN: 7200, StateEXCEPTION: 0}.Validate(), ShouldBeNil)
+		So(ReminderIntervals{StateOK: 3600}.Validate(), ShouldNotBeNil)
+		So(ReminderIntervals{StateERROR: 1800}.Validate(), ShouldNotBeNil)
+		So(ReminderIntervals{StateERROR: 5400}.Validate(), ShouldNotBeNil)
+		So(ReminderIntervals{StateERROR: -3600}.Validate(), ShouldNotBeNil)
+	})
+}
+
+func TestAggregationSettings(t *testing.T) {
+	warnValue, errorValue := float64(10), float64(50)
+
+	Convey("Share aggregation", t, func() {
+		aggregation := &AggregationSettings{Mode: AggregationModeShare, WarnValue: &warnValue, ErrorValue: &errorValue}
+		So(aggregation.GetState(0, 0, 0), ShouldEqual, StateOK)
+		So(aggregation.GetState(1, 0, 10), ShouldEqual, StateOK)
+		So(aggregation.GetState(1, 1, 10), ShouldEqual, StateWARN)
+		So(aggregation.GetState(0, 5, 10), ShouldEqual, StateWARN)
+		So(aggregation.GetState(0, 6, 10), ShouldEqual, StateERROR)
+	})
+
+	Convey("Count aggregation without warn threshold", t, func() {
+		aggregation := &AggregationSettings{Mode: AggregationModeCount, ErrorValue: &warnValue}
+		So(aggregation.GetState(100, 10, 1000), ShouldEqual, StateOK)
+		So(aggregation.GetState(0, 11, 1000), ShouldEqual, StateERROR)
+	})
+
+	Convey("Nil aggregation", t, func() {
+		var aggregation *AggregationSettings
+		So(aggregation.GetState(0, 10, 10), ShouldEqual, StateOK)
+		So(aggregation.IsMetricsMuted(), ShouldBeFalse)
+		So(aggregation.GetTopOffenders(), ShouldEqual, DefaultAggregationTopOffenders)
+	})
+
+	Convey("Top offenders", t, func() {
+		aggregation := &AggregationSettings{Mode: AggregationModeCount, TopOffenders: 3}
+		So(aggregation.GetTopOffenders(), ShouldEqual, 3)
+		aggregation.TopOffenders = 0
+		So(aggregation.GetTopOffenders(), ShouldEqual, DefaultAggregationTopOffenders)
+		aggregation.TopOffenders = AggregationTopOffendersDisabled
+		So(aggregation.GetTopOffenders(), ShouldEqual, 0)
+	})
+
+	Convey("Message of event with aggregation info", t, func() {
+		event := NotificationEvent{MessageEventInfo: &EventInfo{Aggregation: &AggregationInfo{
+			BadMetrics:   3,
+			TotalMetrics: 12,
+			TopOffenders: []string{"host1", "host2"},
+		}}}
+		So(event.CreateMessage(nil), ShouldEqual, "3 of 12 metrics (25.0%) are in bad state, top offenders: host1, host2.")
+
+		interval := int64(24)
+		event.MessageEventInfo.Interval = &interval
+		event.MessageEventInfo.Aggregation.TopOffenders = nil
+		So(event.CreateMessage(nil), ShouldEqual, "This metric has been in bad state for more than 24 hours - please, fix. 3 of 12 metrics (25.0%) are in bad state.")
+	})
+
+	Convey("Message of acknowledged event", t, func() {
+		event := NotificationEvent{MessageEventInfo: &EventInfo{Ack: &AckData{User: "user", Timestamp: 1}}}
+		So(event.IsAcknowledged(), ShouldBeTrue)
+		So(event.CreateMessage(nil), ShouldEqual, "This bad state is acknowledged by user.")
+
+		event.MessageEventInfo.Ack.Comment = "working on it"
+		event.MessageEventInfo.Ack.Expiry = 1502719200
+		So(event.CreateMessage(nil), ShouldEqual, "This bad state is acknowledged by user till 14:00 14.08.2017: working on it.")
+
+		event.MessageEventInfo.Aggregation = &AggregationInfo{BadMetrics: 1, TotalMetrics: 4}
+		So(event.CreateMessage(nil), ShouldEqual, "This bad state is acknowledged by user till 14:00 14.08.2017: working on it. 1 of 4 metrics (25.0%) are in bad state.")
+	})
+}
+
+func TestAckData(t *testing.T) {
+	Convey("Acknowledgement is active till expiry", t, func() {
+		var ack *AckData
+		So(ack.IsActive(100), ShouldBeFalse)
+
+		ack = &AckData{User: "user", Timestamp: 100}
+		So(ack.IsActive(1000), ShouldBeTrue)
+
+		ack.Expiry = 200
+		So(ack.IsActive(199), ShouldBeTrue)
+		So(ack.IsActive(200), ShouldBeFalse)
+	})
+
+	Convey("Check data is recovered if trigger and all metrics are OK", t, func() {
+		checkData := CheckData{
+			State: StateOK,
+			Metrics: map[string]MetricState{
+				"metric1": {State: StateOK},
+				"metric2": {State: StateERROR},
+			},
+		}
+		So(checkData.IsRecovered(), ShouldBeFalse)
+
+		checkData.Metrics["metric2"] = MetricState{State: StateOK}
+		So(checkData.IsRecovered(), ShouldBeTrue)
+
+		checkData.State = StateNODATA
+		So(checkData.IsRecovered(), ShouldBeFalse)
+	})
+}
+
+func TestScheduledNotificationDigest(t *testing.T) {
+	Convey("Notification is delayed till the end of digest window", t, func() {
+		notification := ScheduledNotification{Timestamp: 1001}
+		notification.SetDigest("contact", 300)
+		So(notification.Timestamp, ShouldEqual, 1200)
+		So(notification.Digest, ShouldEqual, "contact")
+
+		notification.SetDigest("contact", 300)
+		So(notification.Timestamp, ShouldEqual, 1200)
+	})
+
+	Convey("Zero digest window does not change notification", t, func() {
+		notification := ScheduledNotification{Timestamp: 1001}
+		notification.SetDigest("contact", 0)
+		So(notification, ShouldResemble, ScheduledNotification{Timestamp: 1001})
+	})
+}
+
+func TestGroupByTriggers(t *testing.T) {
+	Convey("Events are grouped by triggers ordered by name", t, func() {
+		triggers := map[string]TriggerData{
+			"trigger1": {ID: "trigger1", Name: "Beta"},
+			"trigger2": {ID: "trigger2", Name: "Alpha"},
+		}
+		events := NotificationEvents{
+			{TriggerID: "trigger1", Metric: "metric1"},
+			{TriggerID: "trigger2", Metric: "metric2"},
+			{TriggerID: "unknown", Metric: "metric3"},
+			{TriggerID: "trigger1", Metric: "metric4"},
+		}
+		So(events.GroupByTriggers(triggers), ShouldResemble, []TriggerEvents{
+			{Trigger: triggers["trigger2"], Events: NotificationEvents{events[1]}},
+			{Trigger: triggers["trigger1"], Events: NotificationEvents{events[0], events[3]}},
+		})
+	})
+}
+
+func TestEscalationPolicy(t *testing.T) {
+	Convey("Escalation policy without tiers is disabled", t, func() {
+		var policy *EscalationPolicy
+		So(policy.IsEnabled(), ShouldBeFalse)
+		So(policy.HasContact("lead"), ShouldBeFalse)
+		So((&EscalationPolicy{}).IsEnabled(), ShouldBeFalse)
+	})
+
+	Convey("Escalation policy has contacts of all tiers", t, func() {
+		policy := &EscalationPolicy{
+			Tiers: []EscalationTier{
+				{Contacts: []string{"lead"}, DelayInMinutes: 10},
+				{Contacts: []string{"manager"}, DelayInMinutes: 30},
+			},
+		}
+		So(policy.IsEnabled(), ShouldBeTrue)
+		So(policy.HasContact("lead"), ShouldBeTrue)
+		So(policy.HasContact("manager"), ShouldBeTrue)
+		So(policy.HasContact("developer"), ShouldBeFalse)
+	})
+}
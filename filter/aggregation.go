@@ -0,0 +1,196 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// AggregationMethod defines how points in the same retention bucket are combined
+type AggregationMethod string
+
+// Aggregation methods supported in storage-aggregation.conf
+const (
+	AggregationAverage AggregationMethod = "average"
+	AggregationSum     AggregationMethod = "sum"
+	AggregationMin     AggregationMethod = "min"
+	AggregationMax     AggregationMethod = "max"
+	AggregationLast    AggregationMethod = "last"
+)
+
+// trackedBucketsCount is a number of the latest retention buckets of metric which values are accumulated,
+// points older than all tracked buckets are ignored
+const trackedBucketsCount = 3
+
+// bucketsSweepPeriod is a period in seconds of removing buckets of metrics which points stopped coming
+const bucketsSweepPeriod = 600
+
+var aggregationMethods = map[AggregationMethod]bool{
+	AggregationAverage: true,
+	AggregationSum:     true,
+	AggregationMin:     true,
+	AggregationMax:     true,
+	AggregationLast:    true,
+}
+
+type aggregationMatcher struct {
+	pattern *regexp.Regexp
+	method  AggregationMethod
+}
+
+// retentionBucket accumulates values of points with the same retention timestamp
+type retentionBucket struct {
+	retentionTimestamp int64
+	method             AggregationMethod
+	count              int
+	sum                float64
+	min                float64
+	max                float64
+	last               float64
+}
+
+// metricBuckets keeps the latest retention buckets of metric, so points which come late are aggregated
+// with points of their own bucket instead of restarting aggregation of the latest one
+type metricBuckets struct {
+	method    AggregationMethod
+	retention int64
+	// buckets are sorted by retention timestamp
+	buckets []*retentionBucket
+}
+
+// get returns bucket of given retention timestamp starting it if needed, it returns nil if bucket is older than all tracked buckets
+func (metricBuckets *metricBuckets) get(retentionTimestamp int64) *retentionBucket {
+	index := len(metricBuckets.buckets)
+	for i, bucket := range metricBuckets.buckets {
+		if bucket.retentionTimestamp == retentionTimestamp {
+			return bucket
+		}
+		if bucket.retentionTimestamp > retentionTimestamp {
+			index = i
+			break
+		}
+	}
+	if index == 0 && len(metricBuckets.buckets) >= trackedBucketsCount {
+		return nil
+	}
+	bucket := &retentionBucket{method: metricBuckets.method}
+	bucket.reset(retentionTimestamp)
+	metricBuckets.buckets = append(metricBuckets.buckets, nil)
+	copy(metricBuckets.buckets[index+1:], metricBuckets.buckets[index:])
+	metricBuckets.buckets[index] = bucket
+	if len(metricBuckets.buckets) > trackedBucketsCount {
+		metricBuckets.buckets = metricBuckets.buckets[1:]
+	}
+	return bucket
+}
+
+// isStale returns true if the newest bucket of metric is out of tracked buckets window at given time
+func (metricBuckets *metricBuckets) isStale(now int64) bool {
+	if len(metricBuckets.buckets) == 0 {
+		return true
+	}
+	newest := metricBuckets.buckets[len(metricBuckets.buckets)-1]
+	return newest.retentionTimestamp+trackedBucketsCount*metricBuckets.retention < now
+}
+
+// reset starts accumulation of values for new retention timestamp
+func (bucket *retentionBucket) reset(retentionTimestamp int64) {
+	bucket.retentionTimestamp = retentionTimestamp
+	bucket.count = 0
+	bucket.sum = 0
+	bucket.min = math.Inf(1)
+	bucket.max = math.Inf(-1)
+	bucket.last = 0
+}
+
+// add adds point value to bucket and returns aggregated value of bucket
+func (bucket *retentionBucket) add(value float64) float64 {
+	bucket.count++
+	bucket.sum += value
+	bucket.min = math.Min(bucket.min, value)
+	bucket.max = math.Max(bucket.max, value)
+	bucket.last = value
+
+	switch bucket.method {
+	case AggregationAverage:
+		return bucket.sum / float64(bucket.count)
+	case AggregationSum:
+		return bucket.sum
+	case AggregationMin:
+		return bucket.min
+	case AggregationMax:
+		return bucket.max
+	default:
+		return bucket.last
+	}
+}
+
+// parseAggregations reads storage-aggregation.conf sections like
+//
+//	[sum]
+//	pattern = \.count$
+//	xFilesFactor = 0
+//	aggregationMethod = sum
+//
+// xFilesFactor is ignored, sections are matched in order of appearance
+func parseAggregations(reader io.Reader) ([]aggregationMatcher, error) {
+	aggregations := make([]aggregationMatcher, 0)
+	scanner := bufio.NewScanner(reader)
+
+	var section string
+	var pattern *regexp.Regexp
+	var method AggregationMethod
+	flush := func() error {
+		if section == "" {
+			return nil
+		}
+		if pattern == nil || method == "" {
+			return fmt.Errorf("aggregation section [%s] must contain pattern and aggregationMethod", section)
+		}
+		aggregations = append(aggregations, aggregationMatcher{pattern: pattern, method: method})
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			section, pattern, method = line[1:len(line)-1], nil, ""
+			continue
+		}
+		index := strings.Index(line, "=")
+		if index == -1 || section == "" {
+			return nil, fmt.Errorf("invalid aggregation config line: '%s'", line)
+		}
+		key := strings.TrimSpace(line[:index])
+		value := strings.TrimSpace(line[index+1:])
+		switch key {
+		case "pattern":
+			compiled, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern in aggregation section [%s]: %s", section, err.Error())
+			}
+			pattern = compiled
+		case "aggregationMethod":
+			method = AggregationMethod(value)
+			if !aggregationMethods[method] {
+				return nil, fmt.Errorf("unsupported aggregation method '%s' in aggregation section [%s]", value, section)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return aggregations, nil
+}
@@ -0,0 +1,160 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/metrics"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testAggregations = `
+	# comment
+	[min]
+	pattern = \.min$
+	xFilesFactor = 0.1
+	aggregationMethod = min
+
+	[max]
+	pattern = \.max$
+	aggregationMethod = max
+
+	[sum]
+	pattern = \.count$
+	xFilesFactor = 0
+	aggregationMethod = sum
+
+	[average]
+	pattern = \.avg$
+	aggregationMethod = average
+
+	[last]
+	pattern = \.last$
+	aggregationMethod = last
+	`
+
+func TestParseAggregations(t *testing.T) {
+	Convey("Test good aggregations", t, func() {
+		aggregations, err := parseAggregations(strings.NewReader(testAggregations))
+		So(err, ShouldBeNil)
+		methods := make([]AggregationMethod, 0, len(aggregations))
+		for _, aggregation := range aggregations {
+			methods = append(methods, aggregation.method)
+		}
+		So(methods, ShouldResemble, []AggregationMethod{AggregationMin, AggregationMax, AggregationSum, AggregationAverage, AggregationLast})
+	})
+
+	Convey("Test bad aggregations", t, func() {
+		badAggregations := []string{
+			"pattern = .*",
+			"[default]\npattern = .*",
+			"[default]\naggregationMethod = sum",
+			"[default]\npattern = (\naggregationMethod = sum",
+			"[default]\npattern = .*\naggregationMethod = avg_zero",
+			"[default]\npattern",
+		}
+		for _, badAggregation := range badAggregations {
+			_, err := parseAggregations(strings.NewReader(badAggregation))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestAggregation(t *testing.T) {
+	filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+	storage, _ := NewCacheStorage(nil, filterMetrics, strings.NewReader(testRetentions))
+	err := storage.LoadAggregations(strings.NewReader(testAggregations))
+
+	enrich := func(metric string, value float64, timestamp int64) *moira.MatchedMetric {
+		buffer := make(map[string]*moira.MatchedMetric)
+		storage.EnrichMatchedMetric(buffer, &moira.MatchedMetric{Metric: metric, Value: value, Timestamp: timestamp})
+		return buffer[metric]
+	}
+
+	Convey("Test points in the same retention bucket are aggregated", t, func() {
+		So(err, ShouldBeNil)
+		cases := []struct {
+			metric   string
+			expected []float64
+		}{
+			{metric: "Simple.min", expected: []float64{4, 2, 2}},
+			{metric: "Simple.max", expected: []float64{4, 4, 6}},
+			{metric: "Simple.count", expected: []float64{4, 6, 12}},
+			{metric: "Simple.avg", expected: []float64{4, 3, 4}},
+			{metric: "Simple.last", expected: []float64{4, 2, 6}},
+			{metric: "Simple.unmatched", expected: []float64{4, 2, 6}},
+		}
+		for _, testCase := range cases {
+			for i, value := range []float64{4, 2, 6} {
+				matchedMetric := enrich(testCase.metric, value, int64(121+i))
+				if i > 0 && testCase.expected[i] == testCase.expected[i-1] {
+					So(matchedMetric, ShouldBeNil)
+					continue
+				}
+				So(matchedMetric, ShouldNotBeNil)
+				So(matchedMetric.RetentionTimestamp, ShouldEqual, 120)
+				So(matchedMetric.Value, ShouldEqual, testCase.expected[i])
+				So(matchedMetric.Aggregated, ShouldEqual, testCase.metric != "Simple.unmatched")
+			}
+		}
+	})
+
+	Convey("Test point in next retention bucket starts new aggregation", t, func() {
+		So(enrich("Other.count", 1, 121).Value, ShouldEqual, 1)
+		So(enrich("Other.count", 2, 130).Value, ShouldEqual, 3)
+		matchedMetric := enrich("Other.count", 5, 241)
+		So(matchedMetric.RetentionTimestamp, ShouldEqual, 240)
+		So(matchedMetric.Value, ShouldEqual, 5)
+	})
+
+	Convey("Test late points are aggregated into their own retention buckets", t, func() {
+		So(enrich("Simple.late.count", 1, 121).Value, ShouldEqual, 1)
+		So(enrich("Simple.late.count", 2, 181).Value, ShouldEqual, 2)
+
+		matchedMetric := enrich("Simple.late.count", 3, 122)
+		So(matchedMetric.RetentionTimestamp, ShouldEqual, 120)
+		So(matchedMetric.Value, ShouldEqual, 4)
+
+		matchedMetric = enrich("Simple.late.count", 4, 182)
+		So(matchedMetric.RetentionTimestamp, ShouldEqual, 180)
+		So(matchedMetric.Value, ShouldEqual, 6)
+	})
+
+	Convey("Test points older than all tracked retention buckets are ignored", t, func() {
+		So(enrich("Simple.old.count", 1, 121).Value, ShouldEqual, 1)
+		So(enrich("Simple.old.count", 1, 181).Value, ShouldEqual, 1)
+		So(enrich("Simple.old.count", 1, 241).Value, ShouldEqual, 1)
+		So(enrich("Simple.old.count", 1, 301).Value, ShouldEqual, 1)
+		So(enrich("Simple.old.count", 5, 122), ShouldBeNil)
+
+		matchedMetric := enrich("Simple.old.count", 2, 182)
+		So(matchedMetric.RetentionTimestamp, ShouldEqual, 180)
+		So(matchedMetric.Value, ShouldEqual, 3)
+	})
+
+	Convey("Test unchanged aggregated value is not saved again", t, func() {
+		So(enrich("Other.max", 5, 121), ShouldNotBeNil)
+		So(enrich("Other.max", 3, 122), ShouldBeNil)
+	})
+
+	Convey("Test buckets cache", t, func() {
+		Convey("Metrics without aggregation are not cached", func() {
+			enrich("Simple.unmatched.value", 1, 121)
+			So(storage.bucketsCache, ShouldNotContainKey, "Simple.unmatched.value")
+		})
+
+		Convey("Stale buckets are removed on sweep", func() {
+			enrich("Simple.stale.count", 1, 121)
+			enrich("Simple.fresh.count", 1, 601)
+			So(storage.bucketsCache, ShouldContainKey, "Simple.stale.count")
+
+			storage.bucketsSweepTimestamp = 0
+			matchedMetric := &moira.MatchedMetric{Metric: "Simple.fresh.count", Value: 1, Timestamp: 602, Retention: 60, RetentionTimestamp: 600}
+			So(storage.aggregate(matchedMetric, 700), ShouldBeTrue)
+			So(matchedMetric.Value, ShouldEqual, 2)
+			So(storage.bucketsCache, ShouldNotContainKey, "Simple.stale.count")
+			So(storage.bucketsSweepTimestamp, ShouldEqual, 700)
+		})
+	})
+}
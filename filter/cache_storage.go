@@ -2,10 +2,13 @@ package filter
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/metrics"
@@ -18,6 +21,11 @@ type retentionMatcher struct {
 	retention int
 }
 
+// retentionConfig is a set of retention matchers read from storage-schemas.conf
+type retentionConfig struct {
+	matchers []retentionMatcher
+}
+
 type retentionCacheItem struct {
 	value     int
 	timestamp int64
@@ -26,10 +34,16 @@ type retentionCacheItem struct {
 // Storage struct to store retention matchers
 type Storage struct {
 	metrics         *metrics.FilterMetrics
-	retentions      []retentionMatcher
+	retentions      atomic.Value
 	retentionsCache map[string]*retentionCacheItem
-	metricsCache    map[string]*moira.MatchedMetric
-	logger          moira.Logger
+	// cachedRetentions is a retention config which retentions cache is built with
+	cachedRetentions *retentionConfig
+	metricsCache     map[string]*moira.MatchedMetric
+	aggregations     []aggregationMatcher
+	bucketsCache     map[string]*metricBuckets
+	logger           moira.Logger
+	// bucketsSweepTimestamp is a time of the last removal of stale buckets from buckets cache
+	bucketsSweepTimestamp int64
 }
 
 // NewCacheStorage create new Storage
@@ -41,16 +55,48 @@ func NewCacheStorage(logger moira.Logger, metrics *metrics.FilterMetrics, reader
 		logger:          logger,
 	}
 
-	if err := storage.buildRetentions(bufio.NewScanner(reader)); err != nil {
+	retentions, err := storage.buildRetentions(bufio.NewScanner(reader))
+	if err != nil {
 		return nil, err
 	}
+	storage.retentions.Store(retentions)
 	return storage, nil
 }
 
-// EnrichMatchedMetric calculate retention and filter cached values
+// ReloadRetentions reads storage-schemas.conf and replaces current retentions if config is valid.
+// It is safe to call ReloadRetentions concurrently with metrics enrichment
+func (storage *Storage) ReloadRetentions(reader io.Reader) error {
+	retentions, err := storage.buildRetentions(bufio.NewScanner(reader))
+	if err != nil {
+		return err
+	}
+	if len(retentions.matchers) == 0 {
+		return fmt.Errorf("no retentions found")
+	}
+	storage.retentions.Store(retentions)
+	return nil
+}
+
+// LoadAggregations reads storage-aggregation.conf and enables aggregation of points in the same retention bucket.
+// Points of metrics matched by no aggregation pattern are saved as is
+func (storage *Storage) LoadAggregations(reader io.Reader) error {
+	aggregations, err := parseAggregations(reader)
+	if err != nil {
+		return err
+	}
+	storage.aggregations = aggregations
+	storage.bucketsCache = make(map[string]*metricBuckets)
+	storage.bucketsSweepTimestamp = time.Now().Unix()
+	return nil
+}
+
+// EnrichMatchedMetric calculate retention, aggregate values in the same retention bucket and filter cached values
 func (storage *Storage) EnrichMatchedMetric(batch map[string]*moira.MatchedMetric, m *moira.MatchedMetric) {
 	m.Retention = storage.getRetention(m)
 	m.RetentionTimestamp = moira.RoundToNearestRetention(m.Timestamp, int64(m.Retention))
+	if len(storage.aggregations) > 0 && !storage.aggregate(m, time.Now().Unix()) {
+		return
+	}
 	if ex, ok := storage.metricsCache[m.Metric]; ok && ex.RetentionTimestamp == m.RetentionTimestamp && ex.Value == m.Value {
 		return
 	}
@@ -60,10 +106,16 @@ func (storage *Storage) EnrichMatchedMetric(batch map[string]*moira.MatchedMetri
 
 // getRetention returns first matched retention for metric
 func (storage *Storage) getRetention(m *moira.MatchedMetric) int {
+	retentions := storage.retentions.Load().(*retentionConfig)
+	if retentions != storage.cachedRetentions {
+		// Retentions are reloaded, so cached retentions may be outdated
+		storage.retentionsCache = make(map[string]*retentionCacheItem)
+		storage.cachedRetentions = retentions
+	}
 	if item, ok := storage.retentionsCache[m.Metric]; ok && item.timestamp+60 > m.Timestamp {
 		return item.value
 	}
-	for _, matcher := range storage.retentions {
+	for _, matcher := range retentions.matchers {
 		if matcher.pattern.MatchString(m.Metric) {
 			storage.retentionsCache[m.Metric] = &retentionCacheItem{
 				value:     matcher.retention,
@@ -75,8 +127,53 @@ func (storage *Storage) getRetention(m *moira.MatchedMetric) int {
 	return defaultRetention
 }
 
-func (storage *Storage) buildRetentions(retentionScanner *bufio.Scanner) error {
-	storage.retentions = make([]retentionMatcher, 0, 100)
+// aggregate adds metric value to its retention bucket and replaces it with aggregated value of bucket if metric
+// is matched by aggregation pattern. It returns false if point is older than all tracked buckets of metric and is ignored
+func (storage *Storage) aggregate(m *moira.MatchedMetric, now int64) bool {
+	if now-storage.bucketsSweepTimestamp >= bucketsSweepPeriod {
+		storage.sweepBuckets(now)
+	}
+	buckets, ok := storage.bucketsCache[m.Metric]
+	if !ok {
+		// Metrics without aggregation are not cached, so buckets cache does not grow with all received metrics
+		method, found := storage.getAggregationMethod(m.Metric)
+		if !found {
+			return true
+		}
+		buckets = &metricBuckets{method: method, retention: int64(m.Retention)}
+		storage.bucketsCache[m.Metric] = buckets
+	}
+	bucket := buckets.get(m.RetentionTimestamp)
+	if bucket == nil {
+		return false
+	}
+	m.Value = bucket.add(m.Value)
+	m.Aggregated = true
+	return true
+}
+
+// sweepBuckets removes buckets of metrics which points did not come during tracked buckets window
+func (storage *Storage) sweepBuckets(now int64) {
+	for metric, buckets := range storage.bucketsCache {
+		if buckets.isStale(now) {
+			delete(storage.bucketsCache, metric)
+		}
+	}
+	storage.bucketsSweepTimestamp = now
+}
+
+// getAggregationMethod returns first matched aggregation method for metric
+func (storage *Storage) getAggregationMethod(metric string) (AggregationMethod, bool) {
+	for _, matcher := range storage.aggregations {
+		if matcher.pattern.MatchString(metric) {
+			return matcher.method, true
+		}
+	}
+	return "", false
+}
+
+func (storage *Storage) buildRetentions(retentionScanner *bufio.Scanner) (*retentionConfig, error) {
+	matchers := make([]retentionMatcher, 0, 100)
 
 	for retentionScanner.Scan() {
 		line1 := retentionScanner.Text()
@@ -87,7 +184,7 @@ func (storage *Storage) buildRetentions(retentionScanner *bufio.Scanner) error {
 		patternString := strings.TrimSpace(strings.Split(line1, "=")[1])
 		pattern, err := regexp.Compile(patternString)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		retentionScanner.Scan()
@@ -100,17 +197,24 @@ func (storage *Storage) buildRetentions(retentionScanner *bufio.Scanner) error {
 		}
 
 		retentions := strings.TrimSpace(splitted[1])
-		retention, err := rawRetentionToSeconds(retentions[0:strings.Index(retentions, ":")])
+		separatorIndex := strings.Index(retentions, ":")
+		if separatorIndex == -1 {
+			return nil, fmt.Errorf("invalid retentions of pattern '%s': '%s'", patternString, retentions)
+		}
+		retention, err := rawRetentionToSeconds(retentions[0:separatorIndex])
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		storage.retentions = append(storage.retentions, retentionMatcher{
+		matchers = append(matchers, retentionMatcher{
 			pattern:   pattern,
 			retention: retention,
 		})
 	}
-	return retentionScanner.Err()
+	if err := retentionScanner.Err(); err != nil {
+		return nil, err
+	}
+	return &retentionConfig{matchers: matchers}, nil
 }
 
 func rawRetentionToSeconds(rawRetention string) (int, error) {
@@ -119,8 +223,10 @@ func rawRetentionToSeconds(rawRetention string) (int, error) {
 		return retention, nil
 	}
 
-	multiplier := 1
+	var multiplier int
 	switch {
+	case strings.HasSuffix(rawRetention, "s"):
+		multiplier = 1
 	case strings.HasSuffix(rawRetention, "m"):
 		multiplier = 60
 	case strings.HasSuffix(rawRetention, "h"):
@@ -131,6 +237,8 @@ func rawRetentionToSeconds(rawRetention string) (int, error) {
 		multiplier = 60 * 60 * 24 * 7 //nolint
 	case strings.HasSuffix(rawRetention, "y"):
 		multiplier = 60 * 60 * 24 * 365 //nolint
+	default:
+		return 0, fmt.Errorf("invalid retention '%s'", rawRetention)
 	}
 
 	retention, err = strconv.Atoi(rawRetention[0 : len(rawRetention)-1])
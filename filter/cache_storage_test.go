@@ -173,7 +173,7 @@ func TestCacheStorage(t *testing.T) {
 	Convey("Test good retentions", t, func() {
 		So(err, ShouldBeEmpty)
 		So(storage, ShouldNotBeNil)
-		for i, retention := range storage.retentions {
+		for i, retention := range storage.retentions.Load().(*retentionConfig).matchers {
 			So(retention.retention, ShouldEqual, expectedRetentionIntervals[i])
 		}
 	})
@@ -231,3 +231,44 @@ func TestRetentions(t *testing.T) {
 		So(metr.RetentionTimestamp, should.Equal, 120)
 	})
 }
+
+func TestReloadRetentions(t *testing.T) {
+	filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+	storage, _ := NewCacheStorage(nil, filterMetrics, strings.NewReader(testRetentions))
+
+	Convey("Valid config should replace retentions and invalidate cached retentions", t, func() {
+		matchedMetric := matchedMetrics[0]
+		storage.EnrichMatchedMetric(make(map[string]*moira.MatchedMetric), &matchedMetric)
+		So(matchedMetric.Retention, ShouldEqual, 60)
+
+		err := storage.ReloadRetentions(strings.NewReader(`
+			[simple]
+			pattern = ^Simple\.
+			retentions = 5m:30d
+		`))
+		So(err, ShouldBeNil)
+
+		matchedMetric = matchedMetrics[0]
+		storage.EnrichMatchedMetric(make(map[string]*moira.MatchedMetric), &matchedMetric)
+		So(matchedMetric.Retention, ShouldEqual, 300)
+		So(matchedMetric.RetentionTimestamp, ShouldEqual, 0)
+	})
+
+	Convey("Invalid config should be rejected and current retentions should be kept", t, func() {
+		invalidConfigs := []string{
+			"",
+			"# comment only",
+			"[simple]\npattern = ^Simple\\.(\nretentions = 60s:2d",
+			"[simple]\npattern = ^Simple\\.\nretentions = 60s",
+			"[simple]\npattern = ^Simple\\.\nretentions = 60z:2d",
+		}
+		for _, invalidConfig := range invalidConfigs {
+			err := storage.ReloadRetentions(strings.NewReader(invalidConfig))
+			So(err, ShouldNotBeNil)
+		}
+
+		matchedMetric := matchedMetrics[0]
+		storage.EnrichMatchedMetric(make(map[string]*moira.MatchedMetric), &matchedMetric)
+		So(matchedMetric.Retention, ShouldEqual, 300)
+	})
+}
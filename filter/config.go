@@ -3,6 +3,6 @@ package filter
 // Config is filter configuration settings
 type Config struct {
 	Enabled         bool
-	Listen          string
+	Listen          []string
 	RetentionConfig string
 }
@@ -0,0 +1,83 @@
+package connection
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	networkTCP  = "tcp"
+	networkUDP  = "udp"
+	networkUnix = "unix"
+)
+
+const (
+	protocolPlaintext = "plaintext"
+	protocolPickle    = "pickle"
+	protocolHTTP      = "http"
+	protocolInflux    = "influx"
+)
+
+const (
+	schemeTCP       = "tcp"
+	schemeUDP       = "udp"
+	schemeUnix      = "unix"
+	schemePickle    = "pickle"
+	schemeHTTP      = "http"
+	schemeInflux    = "influx"
+	schemeSeparator = "://"
+)
+
+// listenAddress represents parsed metrics listener uri
+type listenAddress struct {
+	scheme   string
+	network  string
+	protocol string
+	address  string
+}
+
+// schemes maps listener uri scheme to network and metrics protocol
+var schemes = map[string]listenAddress{
+	schemeTCP:    {scheme: schemeTCP, network: networkTCP, protocol: protocolPlaintext},
+	schemeUDP:    {scheme: schemeUDP, network: networkUDP, protocol: protocolPlaintext},
+	schemeUnix:   {scheme: schemeUnix, network: networkUnix, protocol: protocolPlaintext},
+	schemePickle: {scheme: schemePickle, network: networkTCP, protocol: protocolPickle},
+	schemeHTTP:   {scheme: schemeHTTP, network: networkTCP, protocol: protocolHTTP},
+	schemeInflux: {scheme: schemeInflux, network: networkTCP, protocol: protocolInflux},
+}
+
+// parseListenAddress parses listener uri like "tcp://:2003", "udp://:2003", "unix:///var/run/moira.sock", "pickle://:2004", "influx://:8089" or "http://:8080"
+// Address without scheme is treated as tcp address to stay compatible with old configs
+func parseListenAddress(uri string) (listenAddress, error) {
+	scheme := schemeTCP
+	address := uri
+	if index := strings.Index(uri, schemeSeparator); index != -1 {
+		scheme = strings.ToLower(uri[:index])
+		address = uri[index+len(schemeSeparator):]
+	}
+	result, ok := schemes[scheme]
+	if !ok {
+		return listenAddress{}, fmt.Errorf("unsupported scheme '%s' in listen address [%s]", scheme, uri)
+	}
+	if address == "" {
+		return listenAddress{}, fmt.Errorf("empty address in listen address [%s]", uri)
+	}
+	result.address = address
+	return result, nil
+}
+
+// String returns listen address in uri form
+func (address listenAddress) String() string {
+	return address.scheme + schemeSeparator + address.address
+}
+
+// metricName returns listen address representation suitable for usage in metric names
+func (address listenAddress) metricName() string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, address.address)
+	return address.scheme + "_" + strings.Trim(name, "_")
+}
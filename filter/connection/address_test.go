@@ -0,0 +1,54 @@
+package connection
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseListenAddress(t *testing.T) {
+	Convey("Address without scheme should be tcp", t, func() {
+		address, err := parseListenAddress(":2003")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeTCP, network: networkTCP, protocol: protocolPlaintext, address: ":2003"})
+		So(address.metricName(), ShouldEqual, "tcp_2003")
+	})
+
+	Convey("Addresses with schemes should be parsed", t, func() {
+		address, err := parseListenAddress("tcp://0.0.0.0:2003")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeTCP, network: networkTCP, protocol: protocolPlaintext, address: "0.0.0.0:2003"})
+		So(address.metricName(), ShouldEqual, "tcp_0_0_0_0_2003")
+
+		address, err = parseListenAddress("UDP://:2003")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeUDP, network: networkUDP, protocol: protocolPlaintext, address: ":2003"})
+		So(address.String(), ShouldEqual, "udp://:2003")
+
+		address, err = parseListenAddress("unix:///var/run/moira.sock")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeUnix, network: networkUnix, protocol: protocolPlaintext, address: "/var/run/moira.sock"})
+		So(address.metricName(), ShouldEqual, "unix_var_run_moira_sock")
+
+		address, err = parseListenAddress("pickle://:2004")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemePickle, network: networkTCP, protocol: protocolPickle, address: ":2004"})
+		So(address.metricName(), ShouldEqual, "pickle_2004")
+
+		address, err = parseListenAddress("http://:8080")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeHTTP, network: networkTCP, protocol: protocolHTTP, address: ":8080"})
+
+		address, err = parseListenAddress("influx://:8089")
+		So(err, ShouldBeNil)
+		So(address, ShouldResemble, listenAddress{scheme: schemeInflux, network: networkTCP, protocol: protocolInflux, address: ":8089"})
+	})
+
+	Convey("Invalid addresses should return error", t, func() {
+		_, err := parseListenAddress("https://:2003")
+		So(err, ShouldNotBeNil)
+
+		_, err = parseListenAddress("udp://")
+		So(err, ShouldNotBeNil)
+	})
+}
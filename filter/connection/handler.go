@@ -2,30 +2,46 @@ package connection
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
 	"io"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics"
 )
 
+// maxDatagramSize is a max size of udp datagram payload
+const maxDatagramSize = 65535
+
+// maxPickleFrameSize is a max size of pickle frame, same as in carbon
+const maxPickleFrameSize = 1 << 20
+
+// pickleHeaderSize is a size of pickle frame header which contains big-endian frame length
+const pickleHeaderSize = 4
+
 // Handler handling connection data and shift it to lineChan channel
 type Handler struct {
 	logger    moira.Logger
+	metrics   *metrics.ListenerMetrics
 	wg        sync.WaitGroup
 	terminate chan struct{}
 }
 
 // NewConnectionsHandler creates new Handler
-func NewConnectionsHandler(logger moira.Logger) *Handler {
+func NewConnectionsHandler(logger moira.Logger, metrics *metrics.ListenerMetrics) *Handler {
 	return &Handler{
 		logger:    logger,
+		metrics:   metrics,
 		terminate: make(chan struct{}, 1),
 	}
 }
 
 // HandleConnection convert every line from connection to metric and send it to lineChan channel
-func (handler *Handler) HandleConnection(connection net.Conn, lineChan chan<- []byte) {
+func (handler *Handler) HandleConnection(connection net.Conn, lineChan chan<- filter.IncomingLine) {
 	handler.wg.Add(1)
 	go func() {
 		defer handler.wg.Done()
@@ -33,16 +49,38 @@ func (handler *Handler) HandleConnection(connection net.Conn, lineChan chan<- []
 	}()
 }
 
-func (handler *Handler) handle(connection net.Conn, lineChan chan<- []byte) {
+// HandlePacketConnection convert every line from every datagram received by connection to metric
+// and send it to lineChan channel. Lines are dropped if lineChan channel is full
+func (handler *Handler) HandlePacketConnection(connection net.PacketConn, lineChan chan<- filter.IncomingLine) {
+	handler.wg.Add(1)
+	go func() {
+		defer handler.wg.Done()
+		handler.handlePackets(connection, lineChan)
+	}()
+}
+
+// HandlePickleConnection decodes every pickle frame from connection to metrics and send it to metricsChan channel
+func (handler *Handler) HandlePickleConnection(connection net.Conn, metricsChan chan<- *filter.ParsedMetric) {
+	handler.wg.Add(1)
+	go func() {
+		defer handler.wg.Done()
+		handler.handlePickle(connection, metricsChan)
+	}()
+}
+
+// HandleInfluxConnection converts every influx line protocol line from connection to metrics and send it to metricsChan channel
+func (handler *Handler) HandleInfluxConnection(connection net.Conn, converter *filter.InfluxConverter, metricsChan chan<- *filter.ParsedMetric) {
+	handler.wg.Add(1)
+	go func() {
+		defer handler.wg.Done()
+		handler.handleInflux(connection, converter, metricsChan)
+	}()
+}
+
+func (handler *Handler) handle(connection net.Conn, lineChan chan<- filter.IncomingLine) {
 	buffer := bufio.NewReader(connection)
-	closeConnection := make(chan struct{})
-	go func(conn net.Conn) {
-		select {
-		case <-handler.terminate:
-			conn.Close()
-		case <-closeConnection:
-		}
-	}(connection)
+	source := addressString(connection.RemoteAddr())
+	closeConnection := handler.closeOnTerminate(connection)
 
 	for {
 		bytes, err := buffer.ReadBytes('\n')
@@ -56,17 +94,174 @@ func (handler *Handler) handle(connection net.Conn, lineChan chan<- []byte) {
 		}
 		bytesWithoutCRLF := dropCRLF(bytes)
 		if len(bytesWithoutCRLF) > 0 {
-			lineChan <- bytesWithoutCRLF
+			handler.metrics.LinesReceived.Inc()
+			lineChan <- filter.IncomingLine{Line: bytesWithoutCRLF, Source: source}
+		}
+	}
+}
+
+func (handler *Handler) handlePackets(connection net.PacketConn, lineChan chan<- filter.IncomingLine) {
+	buffer := make([]byte, maxDatagramSize)
+	closeConnection := handler.closeOnTerminate(connection)
+
+	for {
+		n, address, err := connection.ReadFrom(buffer)
+		if err != nil {
+			connection.Close()
+			select {
+			case <-handler.terminate:
+			default:
+				handler.logger.Errorf("Fail to read from metric connection: %s", err)
+			}
+			close(closeConnection)
+			return
+		}
+		source := addressString(address)
+		for _, line := range splitDatagram(buffer[:n]) {
+			handler.metrics.LinesReceived.Inc()
+			select {
+			case lineChan <- filter.IncomingLine{Line: line, Source: source}:
+			default:
+				handler.metrics.LinesDropped.Inc()
+			}
 		}
 	}
 }
 
+func (handler *Handler) handleInflux(connection net.Conn, converter *filter.InfluxConverter, metricsChan chan<- *filter.ParsedMetric) {
+	buffer := bufio.NewReader(connection)
+	closeConnection := handler.closeOnTerminate(connection)
+	defer func() {
+		connection.Close()
+		close(closeConnection)
+	}()
+
+	for {
+		bytes, err := buffer.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			handler.logger.Errorf("Fail to read from influx connection: %s", err)
+			return
+		}
+		if line := dropCRLF(bytes); len(line) > 0 {
+			handler.sendInfluxLine(line, converter, time.Nanosecond, metricsChan)
+		}
+		if err == io.EOF {
+			return
+		}
+	}
+}
+
+// sendInfluxLine converts influx line protocol line to metrics and send it to metricsChan channel
+func (handler *Handler) sendInfluxLine(line []byte, converter *filter.InfluxConverter, precision time.Duration, metricsChan chan<- *filter.ParsedMetric) {
+	if line[0] == '#' {
+		return
+	}
+	parsedMetrics, invalidCount, err := converter.ParseLine(line, precision)
+	if err != nil {
+		handler.metrics.LinesReceived.Inc()
+		handler.metrics.LinesDropped.Inc()
+		handler.logger.Infof("cannot parse influx line: %v", err)
+		return
+	}
+	for i := 0; i < invalidCount; i++ {
+		handler.metrics.LinesReceived.Inc()
+		handler.metrics.LinesDropped.Inc()
+	}
+	for _, parsedMetric := range parsedMetrics {
+		handler.metrics.LinesReceived.Inc()
+		metricsChan <- parsedMetric
+	}
+}
+
+func (handler *Handler) handlePickle(connection net.Conn, metricsChan chan<- *filter.ParsedMetric) {
+	buffer := bufio.NewReader(connection)
+	closeConnection := handler.closeOnTerminate(connection)
+	defer func() {
+		connection.Close()
+		close(closeConnection)
+	}()
+
+	header := make([]byte, pickleHeaderSize)
+	for {
+		if _, err := io.ReadFull(buffer, header); err != nil {
+			if err != io.EOF {
+				handler.logger.Errorf("Fail to read from pickle connection: %s", err)
+			}
+			return
+		}
+		frameSize := binary.BigEndian.Uint32(header)
+		if frameSize > maxPickleFrameSize {
+			handler.metrics.LinesDropped.Inc()
+			handler.logger.Errorf("Pickle frame from %s is too big: %d bytes, closing connection", connection.RemoteAddr(), frameSize)
+			return
+		}
+		frame := make([]byte, frameSize)
+		if _, err := io.ReadFull(buffer, frame); err != nil {
+			handler.logger.Errorf("Fail to read pickle frame: %s", err)
+			return
+		}
+		unpickled, err := unpickle(frame)
+		if err != nil {
+			handler.metrics.LinesDropped.Inc()
+			handler.logger.Infof("cannot unpickle frame from %s: %v", connection.RemoteAddr(), err)
+			continue
+		}
+		parsedMetrics, invalidCount, err := parsePickledMetrics(unpickled)
+		if err != nil {
+			handler.metrics.LinesDropped.Inc()
+			handler.logger.Infof("cannot parse pickled metrics from %s: %v", connection.RemoteAddr(), err)
+			continue
+		}
+		for i := 0; i < invalidCount; i++ {
+			handler.metrics.LinesReceived.Inc()
+			handler.metrics.LinesDropped.Inc()
+		}
+		for _, parsedMetric := range parsedMetrics {
+			handler.metrics.LinesReceived.Inc()
+			metricsChan <- parsedMetric
+		}
+	}
+}
+
+// closeOnTerminate closes connection when handler terminates, returned channel must be closed when connection is handled
+func (handler *Handler) closeOnTerminate(connection io.Closer) chan struct{} {
+	closeConnection := make(chan struct{})
+	go func() {
+		select {
+		case <-handler.terminate:
+			connection.Close()
+		case <-closeConnection:
+		}
+	}()
+	return closeConnection
+}
+
 // StopHandlingConnections closes all open connections and wait for handling remaining metrics
 func (handler *Handler) StopHandlingConnections() {
 	close(handler.terminate)
 	handler.wg.Wait()
 }
 
+// splitDatagram splits datagram payload to non-empty lines, lines are copied to be safe for buffer reuse
+func splitDatagram(payload []byte) [][]byte {
+	lines := make([][]byte, 0)
+	for _, line := range bytes.Split(payload, []byte{'\n'}) {
+		lineWithoutCRLF := dropCRLF(line)
+		if len(lineWithoutCRLF) > 0 {
+			lines = append(lines, append([]byte(nil), lineWithoutCRLF...))
+		}
+	}
+	return lines
+}
+
+// addressString returns string representation of connection address, address of unix socket client is usually empty
+func addressString(address net.Addr) string {
+	if address == nil {
+		return ""
+	}
+	return address.String()
+}
+
 func dropCRLF(bytes []byte) []byte {
 	bytesLength := len(bytes)
 	if bytesLength > 0 && bytes[bytesLength-1] == '\n' {
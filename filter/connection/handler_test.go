@@ -27,3 +27,18 @@ func TestDropCRLF(t *testing.T) {
 		}
 	})
 }
+
+func TestSplitDatagram(t *testing.T) {
+	Convey("Should split datagram to non-empty lines", t, func() {
+		payload := []byte("a.b 1 2\r\n\nc.d 3 4\ne.f 5 6")
+		lines := splitDatagram(payload)
+		So(lines, ShouldResemble, [][]byte{[]byte("a.b 1 2"), []byte("c.d 3 4"), []byte("e.f 5 6")})
+
+		payload[0] = 'x'
+		So(lines[0], ShouldResemble, []byte("a.b 1 2"))
+	})
+
+	Convey("Should return no lines for empty datagram", t, func() {
+		So(splitDatagram([]byte("\n\r\n")), ShouldBeEmpty)
+	})
+}
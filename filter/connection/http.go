@@ -0,0 +1,117 @@
+package connection
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+// maxHTTPRequestBodySize is a max size of http request body received by metrics listener
+const maxHTTPRequestBodySize = 16 << 20
+
+// httpShutdownTimeout is a time to wait for handling of in-flight http requests on listener stop
+const httpShutdownTimeout = 10 * time.Second
+
+// prometheusRemoteWritePath is a path of prometheus remote_write endpoint
+const prometheusRemoteWritePath = "/api/v1/write"
+
+// influxWritePath is a path of influxdb v1 compatible write endpoint
+const influxWritePath = "/write"
+
+// influxPrecisions maps precision parameter of influx write request to timestamp unit
+var influxPrecisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"n":  time.Nanosecond,
+	"ns": time.Nanosecond,
+	"u":  time.Microsecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// newHTTPServer creates http server which handles metrics write requests and sends parsed metrics to metricsChan
+func (handler *Handler) newHTTPServer(converter *filter.InfluxConverter, metricsChan chan<- *filter.ParsedMetric) *http.Server {
+	router := http.NewServeMux()
+	router.HandleFunc(prometheusRemoteWritePath, handler.handleHTTPWrite(metricsChan, func(_ *http.Request, body []byte) ([]*filter.ParsedMetric, int, error) {
+		return parseRemoteWriteRequest(body)
+	}))
+	router.HandleFunc(influxWritePath, handler.handleHTTPWrite(metricsChan, func(request *http.Request, body []byte) ([]*filter.ParsedMetric, int, error) {
+		return handler.parseInfluxWriteRequest(request, body, converter)
+	}))
+	return &http.Server{
+		Handler:      router,
+		ReadTimeout:  time.Minute,
+		WriteTimeout: time.Minute,
+	}
+}
+
+// handleHTTPWrite returns http handler which parses request body by given parser and sends parsed metrics to metricsChan
+func (handler *Handler) handleHTTPWrite(metricsChan chan<- *filter.ParsedMetric, parse func(*http.Request, []byte) ([]*filter.ParsedMetric, int, error)) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "only POST method is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(writer, request.Body, maxHTTPRequestBodySize))
+		if err != nil {
+			handler.metrics.LinesDropped.Inc()
+			http.Error(writer, fmt.Sprintf("can not read request body: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		parsedMetrics, invalidCount, err := parse(request, body)
+		if err != nil {
+			handler.metrics.LinesDropped.Inc()
+			handler.logger.Infof("cannot parse %s request from %s: %v", request.URL.Path, request.RemoteAddr, err)
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for i := 0; i < invalidCount; i++ {
+			handler.metrics.LinesReceived.Inc()
+			handler.metrics.LinesDropped.Inc()
+		}
+		for _, parsedMetric := range parsedMetrics {
+			handler.metrics.LinesReceived.Inc()
+			metricsChan <- parsedMetric
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// parseInfluxWriteRequest parses body of influx write request, malformed lines are counted as invalid and skipped
+func (handler *Handler) parseInfluxWriteRequest(request *http.Request, body []byte, converter *filter.InfluxConverter) ([]*filter.ParsedMetric, int, error) {
+	precision, ok := influxPrecisions[request.URL.Query().Get("precision")]
+	if !ok {
+		return nil, 0, fmt.Errorf("unsupported precision '%s'", request.URL.Query().Get("precision"))
+	}
+	parsedMetrics := make([]*filter.ParsedMetric, 0)
+	invalidCount := 0
+	for _, line := range bytes.Split(body, []byte{'\n'}) {
+		line = dropCRLF(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		lineMetrics, lineInvalidCount, err := converter.ParseLine(line, precision)
+		if err != nil {
+			handler.logger.Infof("cannot parse influx line: %v", err)
+			invalidCount++
+			continue
+		}
+		parsedMetrics = append(parsedMetrics, lineMetrics...)
+		invalidCount += lineInvalidCount
+	}
+	return parsedMetrics, invalidCount, nil
+}
+
+// shutdownHTTPServer stops accepting new http requests and waits for in-flight requests
+func shutdownHTTPServer(server *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
@@ -3,86 +3,236 @@ package connection
 import (
 	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"gopkg.in/tomb.v2"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter"
 	"github.com/moira-alert/moira/metrics"
 )
 
-// MetricsListener is facade for standard net.MetricsListener and accept connection for handling it
+// MetricsListener is facade for standard net listeners and accept connections for handling it
 type MetricsListener struct {
-	listener *net.TCPListener
-	handler  *Handler
-	logger   moira.Logger
-	tomb     tomb.Tomb
-	metrics  *metrics.FilterMetrics
+	listeners       []*addressListener
+	influxConverter *filter.InfluxConverter
+	logger          moira.Logger
+	tomb            tomb.Tomb
+	metrics         *metrics.FilterMetrics
 }
 
-// NewListener creates new listener
-func NewListener(port string, logger moira.Logger, metrics *metrics.FilterMetrics) (*MetricsListener, error) {
-	address, err := net.ResolveTCPAddr("tcp", port)
-	if nil != err {
-		return nil, fmt.Errorf("failed to resolve tcp address [%s]: %s", port, err.Error())
-	}
-	newListener, err := net.ListenTCP("tcp", address)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen on [%s]: %s", port, err.Error())
+// addressListener listens single address, only one of stream and packet is set.
+// Stream listeners of http protocol are served by http server
+type addressListener struct {
+	address listenAddress
+	stream  streamListener
+	packet  net.PacketConn
+	server  *http.Server
+	handler *Handler
+}
+
+// streamListener is a stream-oriented listener which supports accept deadlines, e.g. *net.TCPListener or *net.UnixListener
+type streamListener interface {
+	net.Listener
+	SetDeadline(t time.Time) error
+}
+
+// NewListener creates new listener for every given address.
+// Supported address formats are "tcp://host:port", "udp://host:port", "unix:///path/to/socket", "host:port" for tcp
+// "pickle://host:port" for tcp with carbon pickle protocol, "influx://host:port" for tcp with influx line protocol
+// and "http://host:port" for http write endpoints: prometheus remote_write on /api/v1/write and influx write on /write.
+// Influx line protocol points are converted to metrics by influxConverter
+func NewListener(addresses []string, influxConverter *filter.InfluxConverter, logger moira.Logger, metrics *metrics.FilterMetrics) (*MetricsListener, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no listen addresses configured")
 	}
 	listener := MetricsListener{
-		listener: newListener,
-		logger:   logger,
-		handler:  NewConnectionsHandler(logger),
-		metrics:  metrics,
+		listeners:       make([]*addressListener, 0, len(addresses)),
+		influxConverter: influxConverter,
+		logger:          logger,
+		metrics:         metrics,
+	}
+	for _, uri := range addresses {
+		newListener, err := listen(uri)
+		if err != nil {
+			listener.closeAll()
+			return nil, err
+		}
+		newListener.handler = NewConnectionsHandler(logger, metrics.ConfigureListenerMetrics(newListener.address.metricName()))
+		listener.listeners = append(listener.listeners, newListener)
 	}
 	return &listener, nil
 }
 
-// Listen waits for new data in connection and handles it in ConnectionHandler
-// All handled data sets to lineChan
-func (listener *MetricsListener) Listen() chan []byte {
-	lineChan := make(chan []byte, 16384) //nolint
+func listen(uri string) (*addressListener, error) {
+	address, err := parseListenAddress(uri)
+	if err != nil {
+		return nil, err
+	}
+	switch address.network {
+	case networkUDP:
+		udpAddress, err := net.ResolveUDPAddr(networkUDP, address.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve udp address [%s]: %s", address.address, err.Error())
+		}
+		packetListener, err := net.ListenUDP(networkUDP, udpAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on [%s]: %s", address, err.Error())
+		}
+		return &addressListener{address: address, packet: packetListener}, nil
+	case networkUnix:
+		if err := removeStaleSocket(address.address); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket [%s]: %s", address.address, err.Error())
+		}
+		unixListener, err := net.ListenUnix(networkUnix, &net.UnixAddr{Name: address.address, Net: networkUnix})
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on [%s]: %s", address, err.Error())
+		}
+		return &addressListener{address: address, stream: unixListener}, nil
+	default:
+		tcpAddress, err := net.ResolveTCPAddr(networkTCP, address.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tcp address [%s]: %s", address.address, err.Error())
+		}
+		tcpListener, err := net.ListenTCP(networkTCP, tcpAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on [%s]: %s", address, err.Error())
+		}
+		return &addressListener{address: address, stream: tcpListener}, nil
+	}
+}
+
+// removeStaleSocket removes unix socket file left by previous filter run
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("file exists and is not a socket")
+	}
+	return os.Remove(path)
+}
+
+// Listen waits for new data in connections and handles it in ConnectionHandler
+// All handled plaintext lines from all listeners sets to lineChan,
+// metrics received by listeners with binary protocols are parsed by listeners and sets to metricsChan
+func (listener *MetricsListener) Listen() (lineChan chan filter.IncomingLine, metricsChan chan *filter.ParsedMetric) {
+	lineChan = make(chan filter.IncomingLine, 16384)     //nolint
+	metricsChan = make(chan *filter.ParsedMetric, 16384) //nolint
+	acceptors := &sync.WaitGroup{}
+	for _, addressListener := range listener.listeners {
+		addressListener := addressListener
+		if addressListener.address.protocol == protocolHTTP {
+			addressListener.server = addressListener.handler.newHTTPServer(listener.influxConverter, metricsChan)
+		}
+		acceptors.Add(1)
+		listener.tomb.Go(func() error {
+			defer acceptors.Done()
+			listener.serve(addressListener, lineChan, metricsChan)
+			return nil
+		})
+		listener.logger.Infof("Moira Filter Listener started on %s", addressListener.address)
+	}
 	listener.tomb.Go(func() error {
-		for {
-			select {
-			case <-listener.tomb.Dying():
-				{
-					listener.logger.Info("Stopping listener...")
-					listener.listener.Close()
-					listener.handler.StopHandlingConnections()
-					close(lineChan)
-					listener.logger.Info("Moira Filter Listener stopped")
-					return nil
-				}
-			default:
-			}
-			listener.listener.SetDeadline(time.Now().Add(1e9)) //nolint
-			conn, err := listener.listener.Accept()
-			if nil != err {
-				if opErr, ok := err.(*net.OpError); ok && opErr.Timeout() {
-					continue
-				}
-				listener.logger.Infof("Failed to accept connection: %s", err.Error())
-				continue
-			}
-			listener.logger.Infof("%s connected", conn.RemoteAddr())
-			listener.handler.HandleConnection(conn, lineChan)
+		<-listener.tomb.Dying()
+		listener.logger.Info("Stopping listener...")
+		listener.close()
+		acceptors.Wait()
+		for _, addressListener := range listener.listeners {
+			addressListener.handler.StopHandlingConnections()
 		}
+		close(lineChan)
+		close(metricsChan)
+		listener.logger.Info("Moira Filter Listener stopped")
+		return nil
 	})
-	listener.tomb.Go(func() error { return listener.checkNewLinesChannelLen(lineChan) })
+	listener.tomb.Go(func() error { return listener.checkNewLinesChannelLen(lineChan, metricsChan) })
 	listener.logger.Info("Moira Filter Listener Started")
-	return lineChan
+	return lineChan, metricsChan
+}
+
+func (listener *MetricsListener) serve(addressListener *addressListener, lineChan chan<- filter.IncomingLine, metricsChan chan<- *filter.ParsedMetric) {
+	if addressListener.packet != nil {
+		addressListener.handler.HandlePacketConnection(addressListener.packet, lineChan)
+		return
+	}
+	if addressListener.server != nil {
+		if err := addressListener.server.Serve(addressListener.stream); err != http.ErrServerClosed {
+			listener.logger.Errorf("Failed to serve http on %s: %s", addressListener.address, err.Error())
+		}
+		return
+	}
+	for {
+		select {
+		case <-listener.tomb.Dying():
+			return
+		default:
+		}
+		addressListener.stream.SetDeadline(time.Now().Add(1e9)) //nolint
+		conn, err := addressListener.stream.Accept()
+		if nil != err {
+			if opErr, ok := err.(*net.OpError); ok && opErr.Timeout() {
+				continue
+			}
+			if !listener.tomb.Alive() {
+				return
+			}
+			listener.logger.Infof("Failed to accept connection on %s: %s", addressListener.address, err.Error())
+			continue
+		}
+		listener.logger.Infof("%s connected to %s", conn.RemoteAddr(), addressListener.address)
+		switch addressListener.address.protocol {
+		case protocolPickle:
+			addressListener.handler.HandlePickleConnection(conn, metricsChan)
+		case protocolInflux:
+			addressListener.handler.HandleInfluxConnection(conn, listener.influxConverter, metricsChan)
+		default:
+			addressListener.handler.HandleConnection(conn, lineChan)
+		}
+	}
+}
+
+// close stops accepting new stream connections and waits for in-flight http requests,
+// packet connections are closed by its handlers
+func (listener *MetricsListener) close() {
+	for _, addressListener := range listener.listeners {
+		if addressListener.server != nil {
+			if err := shutdownHTTPServer(addressListener.server); err != nil {
+				listener.logger.Errorf("Failed to shutdown http server on %s: %s", addressListener.address, err.Error())
+			}
+			continue
+		}
+		if addressListener.stream != nil {
+			addressListener.stream.Close()
+		}
+	}
+}
+
+// closeAll closes all listeners which are not handled yet
+func (listener *MetricsListener) closeAll() {
+	listener.close()
+	for _, addressListener := range listener.listeners {
+		if addressListener.packet != nil {
+			addressListener.packet.Close()
+		}
+	}
 }
 
-func (listener *MetricsListener) checkNewLinesChannelLen(channel <-chan []byte) error {
+func (listener *MetricsListener) checkNewLinesChannelLen(channel <-chan filter.IncomingLine, metricsChannel <-chan *filter.ParsedMetric) error {
 	checkTicker := time.NewTicker(time.Millisecond * 100) //nolint
 	for {
 		select {
 		case <-listener.tomb.Dying():
 			return nil
 		case <-checkTicker.C:
-			listener.metrics.LineChannelLen.Update(int64(len(channel)))
+			listener.metrics.LineChannelLen.Update(int64(len(channel) + len(metricsChannel)))
 		}
 	}
 }
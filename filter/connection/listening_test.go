@@ -0,0 +1,237 @@
+package connection
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics"
+)
+
+var influxConverter, _ = filter.NewInfluxConverter(filter.InfluxConfig{})
+
+func TestMetricsListener(t *testing.T) {
+	logger, _ := logging.GetLogger("Listener")
+	socketDir, err := ioutil.TempDir("", "moira-listener")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(socketDir)
+	socketPath := filepath.Join(socketDir, "filter.sock")
+
+	Convey("Invalid address should return error", t, func() {
+		_, err := NewListener([]string{"https://127.0.0.1:0"}, nil, logger, metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry()))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Lines from all listeners should be sent to the same channel", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		listener, err := NewListener([]string{"127.0.0.1:0", "udp://127.0.0.1:0", "unix://" + socketPath}, influxConverter, logger, filterMetrics)
+		So(err, ShouldBeNil)
+		lineChan, _ := listener.Listen()
+
+		tcpConnection, err := net.Dial("tcp", listener.listeners[0].stream.Addr().String())
+		So(err, ShouldBeNil)
+		_, err = tcpConnection.Write([]byte("tcp.metric 1 1\n"))
+		So(err, ShouldBeNil)
+		So(receiveLine(lineChan), ShouldEqual, "tcp.metric 1 1")
+		tcpConnection.Close()
+
+		udpConnection, err := net.Dial("udp", listener.listeners[1].packet.LocalAddr().String())
+		So(err, ShouldBeNil)
+		_, err = udpConnection.Write([]byte("udp.metric 2 2\n"))
+		So(err, ShouldBeNil)
+		So(receiveLine(lineChan), ShouldEqual, "udp.metric 2 2")
+		udpConnection.Close()
+
+		unixConnection, err := net.Dial("unix", socketPath)
+		So(err, ShouldBeNil)
+		_, err = unixConnection.Write([]byte("unix.metric 3 3\n"))
+		So(err, ShouldBeNil)
+		So(receiveLine(lineChan), ShouldEqual, "unix.metric 3 3")
+		unixConnection.Close()
+
+		So(listener.listeners[1].handler.metrics.LinesReceived.Count(), ShouldEqual, 1)
+		So(listener.listeners[1].handler.metrics.LinesDropped.Count(), ShouldEqual, 0)
+
+		So(listener.Stop(), ShouldBeNil)
+		_, ok := <-lineChan
+		So(ok, ShouldBeFalse)
+		_, err = os.Stat(socketPath)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+}
+
+func TestPickleListener(t *testing.T) {
+	logger, _ := logging.GetLogger("Listener")
+
+	Convey("Pickled metrics should be sent to metrics channel", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		listener, err := NewListener([]string{"pickle://127.0.0.1:0"}, influxConverter, logger, filterMetrics)
+		So(err, ShouldBeNil)
+		_, metricsChan := listener.Listen()
+		handlerMetrics := listener.listeners[0].handler.metrics
+
+		connection, err := net.Dial("tcp", listener.listeners[0].stream.Addr().String())
+		So(err, ShouldBeNil)
+		payload := "(lp0\n(S'one.two.three'\np1\n(I1234567890\nF12.5\ntp2\ntp3\na."
+		frame := append([]byte{0, 0, 0, byte(len(payload))}, payload...)
+		_, err = connection.Write(frame)
+		So(err, ShouldBeNil)
+
+		select {
+		case parsedMetric := <-metricsChan:
+			So(parsedMetric.Metric, ShouldEqual, "one.two.three")
+			So(parsedMetric.Value, ShouldEqual, 12.5)
+			So(parsedMetric.Timestamp, ShouldEqual, 1234567890)
+		case <-time.After(time.Second):
+			t.Fatal("pickled metric was not received")
+		}
+
+		Convey("Oversized frame should close connection", func() {
+			_, err = connection.Write([]byte{0xff, 0xff, 0xff, 0xff})
+			So(err, ShouldBeNil)
+			connection.SetReadDeadline(time.Now().Add(time.Second)) //nolint
+			_, err = connection.Read(make([]byte, 1))
+			So(err, ShouldNotBeNil)
+			So(handlerMetrics.LinesReceived.Count(), ShouldEqual, 1)
+			So(handlerMetrics.LinesDropped.Count(), ShouldEqual, 1)
+		})
+
+		connection.Close()
+		So(listener.Stop(), ShouldBeNil)
+	})
+}
+
+func TestHTTPListener(t *testing.T) {
+	logger, _ := logging.GetLogger("Listener")
+
+	Convey("Prometheus remote write metrics should be sent to metrics channel", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		listener, err := NewListener([]string{"http://127.0.0.1:0"}, influxConverter, logger, filterMetrics)
+		So(err, ShouldBeNil)
+		_, metricsChan := listener.Listen()
+		url := "http://" + listener.listeners[0].stream.Addr().String() + prometheusRemoteWritePath
+
+		request := encodeRemoteWriteRequest([]testSeries{{
+			labels:  [][2]string{{"__name__", "up"}, {"job", "api"}},
+			samples: []prometheusSample{{value: 1, timestamp: 1234567890000}},
+		}})
+		response, err := http.Post(url, "application/x-protobuf", bytes.NewReader(request))
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusNoContent)
+
+		select {
+		case parsedMetric := <-metricsChan:
+			So(parsedMetric.Metric, ShouldEqual, "up;job=api")
+		case <-time.After(time.Second):
+			t.Fatal("remote write metric was not received")
+		}
+
+		response, err = http.Post(url, "application/x-protobuf", bytes.NewReader([]byte("malformed")))
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+		response, err = http.Get(url)
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusMethodNotAllowed)
+
+		So(listener.Stop(), ShouldBeNil)
+	})
+
+	Convey("Influx write metrics should be sent to metrics channel", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		listener, err := NewListener([]string{"http://127.0.0.1:0"}, influxConverter, logger, filterMetrics)
+		So(err, ShouldBeNil)
+		_, metricsChan := listener.Listen()
+		url := "http://" + listener.listeners[0].stream.Addr().String() + influxWritePath
+
+		body := "# comment\ncpu,host=server01 usage=12.5 1234567890\n\n"
+		response, err := http.Post(url+"?precision=s", "text/plain", bytes.NewReader([]byte(body)))
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusNoContent)
+
+		select {
+		case parsedMetric := <-metricsChan:
+			So(parsedMetric.Metric, ShouldEqual, "cpu.usage;host=server01")
+			So(parsedMetric.Value, ShouldEqual, 12.5)
+			So(parsedMetric.Timestamp, ShouldEqual, 1234567890)
+		case <-time.After(time.Second):
+			t.Fatal("influx metric was not received")
+		}
+
+		response, err = http.Post(url+"?precision=ps", "text/plain", bytes.NewReader([]byte(body)))
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusBadRequest)
+
+		response, err = http.Post(url, "text/plain", bytes.NewReader([]byte("cpu usage=\ncpu,host=server02 usage=7 1234567890000000000\n")))
+		So(err, ShouldBeNil)
+		response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusNoContent)
+
+		select {
+		case parsedMetric := <-metricsChan:
+			So(parsedMetric.Metric, ShouldEqual, "cpu.usage;host=server02")
+			So(parsedMetric.Value, ShouldEqual, 7)
+		case <-time.After(time.Second):
+			t.Fatal("influx metric was not received")
+		}
+
+		So(listener.Stop(), ShouldBeNil)
+		So(listener.listeners[0].handler.metrics.LinesDropped.Count(), ShouldEqual, 2)
+	})
+}
+
+func TestInfluxListener(t *testing.T) {
+	logger, _ := logging.GetLogger("Listener")
+
+	Convey("Influx line protocol metrics should be sent to metrics channel", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		listener, err := NewListener([]string{"influx://127.0.0.1:0"}, influxConverter, logger, filterMetrics)
+		So(err, ShouldBeNil)
+		_, metricsChan := listener.Listen()
+		handlerMetrics := listener.listeners[0].handler.metrics
+
+		connection, err := net.Dial("tcp", listener.listeners[0].stream.Addr().String())
+		So(err, ShouldBeNil)
+		_, err = connection.Write([]byte("malformed\ndisk,path=/ free=10i,label=\"root\" 1234567890000000000\n"))
+		So(err, ShouldBeNil)
+
+		select {
+		case parsedMetric := <-metricsChan:
+			So(parsedMetric.Metric, ShouldEqual, "disk.free;path=/")
+			So(parsedMetric.Value, ShouldEqual, 10)
+			So(parsedMetric.Timestamp, ShouldEqual, 1234567890)
+		case <-time.After(time.Second):
+			t.Fatal("influx metric was not received")
+		}
+		connection.Close()
+
+		So(listener.Stop(), ShouldBeNil)
+		So(handlerMetrics.LinesReceived.Count(), ShouldEqual, 3)
+		So(handlerMetrics.LinesDropped.Count(), ShouldEqual, 2)
+	})
+}
+
+func receiveLine(lineChan <-chan filter.IncomingLine) string {
+	select {
+	case line := <-lineChan:
+		return string(line.Line)
+	case <-time.After(time.Second):
+		return ""
+	}
+}
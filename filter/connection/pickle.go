@@ -0,0 +1,562 @@
+package connection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+// Pickle opcodes which can be produced by carbon relays for list of (path, (timestamp, value)) tuples.
+// Opcodes which can construct arbitrary objects (GLOBAL, REDUCE, BUILD, INST, OBJ etc.) are not supported on purpose.
+const (
+	pickleMark            = '('
+	pickleStop            = '.'
+	picklePop             = '0'
+	pickleNone            = 'N'
+	pickleInt             = 'I'
+	pickleLong            = 'L'
+	pickleFloat           = 'F'
+	pickleString          = 'S'
+	pickleUnicode         = 'V'
+	pickleAppend          = 'a'
+	pickleList            = 'l'
+	pickleTuple           = 't'
+	picklePut             = 'p'
+	pickleGet             = 'g'
+	pickleBinInt          = 'J'
+	pickleBinInt1         = 'K'
+	pickleBinInt2         = 'M'
+	pickleBinFloat        = 'G'
+	pickleBinString       = 'T'
+	pickleShortBinString  = 'U'
+	pickleBinUnicode      = 'X'
+	pickleEmptyList       = ']'
+	pickleEmptyTuple      = ')'
+	pickleAppends         = 'e'
+	pickleBinPut          = 'q'
+	pickleLongBinPut      = 'r'
+	pickleBinGet          = 'h'
+	pickleLongBinGet      = 'j'
+	pickleBinBytes        = 'B'
+	pickleShortBinBytes   = 'C'
+	pickleProto           = 0x80
+	pickleTuple1          = 0x85
+	pickleTuple2          = 0x86
+	pickleTuple3          = 0x87
+	pickleNewTrue         = 0x88
+	pickleNewFalse        = 0x89
+	pickleLong1           = 0x8a
+	pickleShortBinUnicode = 0x8c
+	pickleMemoize         = 0x94
+	pickleFrame           = 0x95
+)
+
+// pickleMarkObject is pushed to the unpickler stack by MARK opcode
+type pickleMarkObject struct{}
+
+// pickleListObject is a mutable python list, it is shared by memo and stack
+type pickleListObject struct {
+	items []interface{}
+}
+
+// unpickler is a restricted pickle decoder, it only supports primitive values, lists and tuples
+type unpickler struct {
+	reader *bytes.Reader
+	stack  []interface{}
+	memo   map[int64]interface{}
+}
+
+// unpickle decodes pickle payload which contains only primitive values, lists and tuples
+func unpickle(payload []byte) (interface{}, error) {
+	decoder := &unpickler{
+		reader: bytes.NewReader(payload),
+		stack:  make([]interface{}, 0),
+		memo:   make(map[int64]interface{}),
+	}
+	return decoder.load()
+}
+
+func (decoder *unpickler) load() (interface{}, error) { //nolint
+	for {
+		opcode, err := decoder.reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected end of pickle data")
+		}
+		switch opcode {
+		case pickleStop:
+			if len(decoder.stack) != 1 {
+				return nil, fmt.Errorf("invalid stack size %d on pickle stop", len(decoder.stack))
+			}
+			return decoder.unwrap(decoder.stack[0]), nil
+		case pickleProto:
+			if _, err = decoder.readBytes(1); err != nil {
+				return nil, err
+			}
+		case pickleFrame:
+			if _, err = decoder.readBytes(8); err != nil { //nolint
+				return nil, err
+			}
+		case pickleMark:
+			decoder.push(pickleMarkObject{})
+		case picklePop:
+			if _, err = decoder.pop(); err != nil {
+				return nil, err
+			}
+		case pickleNone:
+			decoder.push(nil)
+		case pickleNewTrue:
+			decoder.push(true)
+		case pickleNewFalse:
+			decoder.push(false)
+		case pickleInt:
+			err = decoder.loadTextInt()
+		case pickleLong:
+			err = decoder.loadTextLong()
+		case pickleFloat:
+			err = decoder.loadTextFloat()
+		case pickleString:
+			err = decoder.loadTextString()
+		case pickleUnicode:
+			err = decoder.loadTextUnicode()
+		case pickleBinInt:
+			err = decoder.loadBinInt(4) //nolint
+		case pickleBinInt1:
+			err = decoder.loadBinInt(1)
+		case pickleBinInt2:
+			err = decoder.loadBinInt(2) //nolint
+		case pickleLong1:
+			err = decoder.loadLong1()
+		case pickleBinFloat:
+			err = decoder.loadBinFloat()
+		case pickleBinString, pickleBinUnicode, pickleBinBytes:
+			err = decoder.loadBinString(4) //nolint
+		case pickleShortBinString, pickleShortBinUnicode, pickleShortBinBytes:
+			err = decoder.loadBinString(1)
+		case pickleEmptyList:
+			decoder.push(&pickleListObject{items: make([]interface{}, 0)})
+		case pickleList:
+			err = decoder.loadList()
+		case pickleAppend:
+			err = decoder.loadAppend()
+		case pickleAppends:
+			err = decoder.loadAppends()
+		case pickleEmptyTuple:
+			decoder.push([]interface{}{})
+		case pickleTuple:
+			err = decoder.loadTuple()
+		case pickleTuple1, pickleTuple2, pickleTuple3:
+			err = decoder.loadShortTuple(int(opcode-pickleTuple1) + 1)
+		case picklePut:
+			err = decoder.loadTextPut()
+		case pickleBinPut:
+			err = decoder.loadBinPut(1)
+		case pickleLongBinPut:
+			err = decoder.loadBinPut(4) //nolint
+		case pickleMemoize:
+			err = decoder.loadMemoize()
+		case pickleGet:
+			err = decoder.loadTextGet()
+		case pickleBinGet:
+			err = decoder.loadBinGet(1)
+		case pickleLongBinGet:
+			err = decoder.loadBinGet(4) //nolint
+		default:
+			return nil, fmt.Errorf("unsupported pickle opcode 0x%x", opcode)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (decoder *unpickler) push(value interface{}) {
+	decoder.stack = append(decoder.stack, value)
+}
+
+func (decoder *unpickler) pop() (interface{}, error) {
+	if len(decoder.stack) == 0 {
+		return nil, fmt.Errorf("pickle stack underflow")
+	}
+	value := decoder.stack[len(decoder.stack)-1]
+	decoder.stack = decoder.stack[:len(decoder.stack)-1]
+	return value, nil
+}
+
+// popMark pops all values pushed after last MARK
+func (decoder *unpickler) popMark() ([]interface{}, error) {
+	for i := len(decoder.stack) - 1; i >= 0; i-- {
+		if _, ok := decoder.stack[i].(pickleMarkObject); ok {
+			values := make([]interface{}, len(decoder.stack)-i-1)
+			copy(values, decoder.stack[i+1:])
+			decoder.stack = decoder.stack[:i]
+			return values, nil
+		}
+	}
+	return nil, fmt.Errorf("pickle mark not found")
+}
+
+func (decoder *unpickler) readBytes(count int) ([]byte, error) {
+	if count < 0 || count > decoder.reader.Len() {
+		return nil, fmt.Errorf("unexpected end of pickle data")
+	}
+	result := make([]byte, count)
+	decoder.reader.Read(result) //nolint
+	return result, nil
+}
+
+func (decoder *unpickler) readLine() (string, error) {
+	var builder strings.Builder
+	for {
+		char, err := decoder.reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("unexpected end of pickle data")
+		}
+		if char == '\n' {
+			return builder.String(), nil
+		}
+		builder.WriteByte(char)
+	}
+}
+
+func (decoder *unpickler) readUint(size int) (uint64, error) {
+	data, err := decoder.readBytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var result uint64
+	for i := size - 1; i >= 0; i-- {
+		result = result<<8 | uint64(data[i]) //nolint
+	}
+	return result, nil
+}
+
+func (decoder *unpickler) loadTextInt() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	switch line {
+	case "00":
+		decoder.push(false)
+		return nil
+	case "01":
+		decoder.push(true)
+		return nil
+	}
+	value, err := strconv.ParseInt(line, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle int: %s", err.Error())
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadTextLong() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseInt(strings.TrimSuffix(line, "L"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle long: %s", err.Error())
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadTextFloat() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle float: %s", err.Error())
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadTextString() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	value, err := strconv.Unquote(line)
+	if err != nil && len(line) >= 2 && line[0] == '\'' && line[len(line)-1] == '\'' {
+		value, err = strconv.Unquote(`"` + strings.ReplaceAll(line[1:len(line)-1], `"`, `\"`) + `"`)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid pickle string: %s", err.Error())
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadTextUnicode() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	decoder.push(line)
+	return nil
+}
+
+func (decoder *unpickler) loadBinInt(size int) error {
+	value, err := decoder.readUint(size)
+	if err != nil {
+		return err
+	}
+	if size == 4 { //nolint
+		decoder.push(int64(int32(uint32(value))))
+		return nil
+	}
+	decoder.push(int64(value))
+	return nil
+}
+
+func (decoder *unpickler) loadLong1() error {
+	size, err := decoder.readUint(1)
+	if err != nil {
+		return err
+	}
+	if size > 8 { //nolint
+		return fmt.Errorf("pickle long is too big: %d bytes", size)
+	}
+	value, err := decoder.readUint(int(size))
+	if err != nil {
+		return err
+	}
+	if size > 0 && size < 8 && value&(1<<(size*8-1)) != 0 {
+		value -= 1 << (size * 8) //nolint
+	}
+	decoder.push(int64(value))
+	return nil
+}
+
+func (decoder *unpickler) loadBinFloat() error {
+	data, err := decoder.readBytes(8) //nolint
+	if err != nil {
+		return err
+	}
+	decoder.push(math.Float64frombits(binary.BigEndian.Uint64(data)))
+	return nil
+}
+
+func (decoder *unpickler) loadBinString(lengthSize int) error {
+	length, err := decoder.readUint(lengthSize)
+	if err != nil {
+		return err
+	}
+	if length > uint64(decoder.reader.Len()) {
+		return fmt.Errorf("unexpected end of pickle data")
+	}
+	data, err := decoder.readBytes(int(length))
+	if err != nil {
+		return err
+	}
+	decoder.push(string(data))
+	return nil
+}
+
+func (decoder *unpickler) loadList() error {
+	values, err := decoder.popMark()
+	if err != nil {
+		return err
+	}
+	decoder.push(&pickleListObject{items: values})
+	return nil
+}
+
+func (decoder *unpickler) loadAppend() error {
+	value, err := decoder.pop()
+	if err != nil {
+		return err
+	}
+	return decoder.appendToList([]interface{}{value})
+}
+
+func (decoder *unpickler) loadAppends() error {
+	values, err := decoder.popMark()
+	if err != nil {
+		return err
+	}
+	return decoder.appendToList(values)
+}
+
+func (decoder *unpickler) appendToList(values []interface{}) error {
+	if len(decoder.stack) == 0 {
+		return fmt.Errorf("pickle stack underflow")
+	}
+	list, ok := decoder.stack[len(decoder.stack)-1].(*pickleListObject)
+	if !ok {
+		return fmt.Errorf("can not append to non-list pickle object")
+	}
+	list.items = append(list.items, values...)
+	return nil
+}
+
+func (decoder *unpickler) loadTuple() error {
+	values, err := decoder.popMark()
+	if err != nil {
+		return err
+	}
+	decoder.push(values)
+	return nil
+}
+
+func (decoder *unpickler) loadShortTuple(size int) error {
+	if len(decoder.stack) < size {
+		return fmt.Errorf("pickle stack underflow")
+	}
+	values := make([]interface{}, size)
+	copy(values, decoder.stack[len(decoder.stack)-size:])
+	decoder.stack = decoder.stack[:len(decoder.stack)-size]
+	decoder.push(values)
+	return nil
+}
+
+func (decoder *unpickler) loadTextPut() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	index, err := strconv.ParseInt(line, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle memo index: %s", err.Error())
+	}
+	return decoder.put(index)
+}
+
+func (decoder *unpickler) loadBinPut(size int) error {
+	index, err := decoder.readUint(size)
+	if err != nil {
+		return err
+	}
+	return decoder.put(int64(index))
+}
+
+func (decoder *unpickler) loadMemoize() error {
+	return decoder.put(int64(len(decoder.memo)))
+}
+
+func (decoder *unpickler) put(index int64) error {
+	if len(decoder.stack) == 0 {
+		return fmt.Errorf("pickle stack underflow")
+	}
+	decoder.memo[index] = decoder.stack[len(decoder.stack)-1]
+	return nil
+}
+
+func (decoder *unpickler) loadTextGet() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	index, err := strconv.ParseInt(line, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle memo index: %s", err.Error())
+	}
+	return decoder.get(index)
+}
+
+func (decoder *unpickler) loadBinGet(size int) error {
+	index, err := decoder.readUint(size)
+	if err != nil {
+		return err
+	}
+	return decoder.get(int64(index))
+}
+
+func (decoder *unpickler) get(index int64) error {
+	value, ok := decoder.memo[index]
+	if !ok {
+		return fmt.Errorf("pickle memo index %d not found", index)
+	}
+	decoder.push(value)
+	return nil
+}
+
+// unwrap converts pickle lists to plain slices
+func (decoder *unpickler) unwrap(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case *pickleListObject:
+		return typedValue.items
+	default:
+		return value
+	}
+}
+
+// parsePickledMetrics converts unpickled list of (path, (timestamp, value)) tuples to parsed metrics.
+// Invalid items are skipped and counted
+func parsePickledMetrics(unpickled interface{}) ([]*filter.ParsedMetric, int, error) {
+	items, ok := unpickled.([]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("pickle payload is not a list")
+	}
+	parsedMetrics := make([]*filter.ParsedMetric, 0, len(items))
+	invalidCount := 0
+	for _, item := range items {
+		parsedMetric, err := parsePickledMetric(item)
+		if err != nil {
+			invalidCount++
+			continue
+		}
+		parsedMetrics = append(parsedMetrics, parsedMetric)
+	}
+	return parsedMetrics, invalidCount, nil
+}
+
+func parsePickledMetric(item interface{}) (*filter.ParsedMetric, error) {
+	metricTuple, ok := unwrapPickleSequence(item)
+	if !ok || len(metricTuple) != 2 { //nolint
+		return nil, fmt.Errorf("metric is not a (path, (timestamp, value)) tuple")
+	}
+	path, ok := metricTuple[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("metric path is not a string")
+	}
+	datapoint, ok := unwrapPickleSequence(metricTuple[1])
+	if !ok || len(datapoint) != 2 { //nolint
+		return nil, fmt.Errorf("metric datapoint is not a (timestamp, value) tuple")
+	}
+	timestamp, ok := pickleNumber(datapoint[0])
+	if !ok {
+		return nil, fmt.Errorf("metric timestamp is not a number")
+	}
+	value, ok := pickleNumber(datapoint[1])
+	if !ok {
+		return nil, fmt.Errorf("metric value is not a number")
+	}
+	return filter.NewParsedMetric(path, value, int64(timestamp))
+}
+
+func unwrapPickleSequence(value interface{}) ([]interface{}, bool) {
+	switch typedValue := value.(type) {
+	case []interface{}:
+		return typedValue, true
+	case *pickleListObject:
+		return typedValue.items, true
+	default:
+		return nil, false
+	}
+}
+
+func pickleNumber(value interface{}) (float64, bool) {
+	switch typedValue := value.(type) {
+	case int64:
+		return float64(typedValue), true
+	case float64:
+		return typedValue, true
+	case string:
+		number, err := strconv.ParseFloat(typedValue, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,83 @@
+package connection
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+func TestUnpickle(t *testing.T) {
+	expected := []*filter.ParsedMetric{
+		{Metric: "one.two.three", Name: "one.two.three", Labels: map[string]string{}, Value: 12.5, Timestamp: 1234567890},
+		{Metric: "tagged.metric;tag=value", Name: "tagged.metric", Labels: map[string]string{"tag": "value"}, Value: -3, Timestamp: 1234567890},
+		{Metric: "memo.metric", Name: "memo.metric", Labels: map[string]string{}, Value: 1 << 40, Timestamp: 1234567891},
+	}
+
+	Convey("Given pickled metrics of different protocols, should decode them", t, func() {
+		payloads := map[string]string{
+			"protocol 0": "(lp0\n(Vone.two.three\np1\n(I1234567890\nF12.5\ntp2\ntp3\na(Vtagged.metric;tag=value\np4\n(F1234567890.0\nI-3\ntp5\ntp6\na" +
+				"(Vmemo.metric\np7\n(I1234567891\nL1099511627776L\ntp8\ntp9\na.",
+			"protocol 2": "\x80\x02]q\x00(X\r\x00\x00\x00one.two.threeq\x01J\xd2\x02\x96IG@)\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03" +
+				"X\x17\x00\x00\x00tagged.metric;tag=valueq\x04GA\xd2e\x80\xb4\x80\x00\x00J\xfd\xff\xff\xff\x86q\x05\x86q\x06" +
+				"X\x0b\x00\x00\x00memo.metricq\x07J\xd3\x02\x96I\x8a\x06\x00\x00\x00\x00\x00\x01\x86q\x08\x86q\te.",
+			"protocol 4": "\x80\x04\x95r\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\rone.two.three\x94J\xd2\x02\x96IG@)\x00\x00\x00\x00\x00\x00\x86\x94\x86\x94" +
+				"\x8c\x17tagged.metric;tag=value\x94GA\xd2e\x80\xb4\x80\x00\x00J\xfd\xff\xff\xff\x86\x94\x86\x94" +
+				"\x8c\x0bmemo.metric\x94J\xd3\x02\x96I\x8a\x06\x00\x00\x00\x00\x00\x01\x86\x94\x86\x94e.",
+		}
+		for protocol, payload := range payloads {
+			Convey(protocol, func() {
+				unpickled, err := unpickle([]byte(payload))
+				So(err, ShouldBeNil)
+				parsedMetrics, invalidCount, err := parsePickledMetrics(unpickled)
+				So(err, ShouldBeNil)
+				So(invalidCount, ShouldEqual, 0)
+				So(parsedMetrics, ShouldResemble, expected)
+			})
+		}
+	})
+
+	Convey("Given python 2 pickled strings, should decode them", t, func() {
+		unpickled, err := unpickle([]byte("(lp0\n(S'one.two.three'\np1\n(I1234567890\nF12.5\ntp2\ntp3\na."))
+		So(err, ShouldBeNil)
+		parsedMetrics, invalidCount, err := parsePickledMetrics(unpickled)
+		So(err, ShouldBeNil)
+		So(invalidCount, ShouldEqual, 0)
+		So(parsedMetrics, ShouldResemble, expected[:1])
+	})
+
+	Convey("Given invalid metric tuples, should skip them", t, func() {
+		unpickled, err := unpickle([]byte("(lp0\n(S'one.two.three'\n(I1234567890\nF12.5\nttp1\na(S'invalid'\nNtp2\na(S'non-ascii.\xd0\xb9'\n(I1\nI2\nttp3\na."))
+		So(err, ShouldBeNil)
+		parsedMetrics, invalidCount, err := parsePickledMetrics(unpickled)
+		So(err, ShouldBeNil)
+		So(invalidCount, ShouldEqual, 2)
+		So(parsedMetrics, ShouldResemble, expected[:1])
+	})
+
+	Convey("Given malformed or unsafe payloads, should return errors", t, func() {
+		payloads := []string{
+			"",
+			".",
+			"\x80\x02]q\x00(X\r\x00\x00",
+			"\x80\x02]q\x00(X\xff\xff\xff\x7fone.two.threee.",
+			"\x80\x02]q\x00cposix\nsystem\nq\x01X\x04\x00\x00\x00trueq\x02\x85q\x03Rq\x04a.",
+			"\x80\x02h\x05.",
+			"\x80\x02e.",
+			"\x80\x02]]e",
+			"\x80\x02\x86.",
+		}
+		for _, payload := range payloads {
+			_, err := unpickle([]byte(payload))
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Given payload which is not a list, should return error", t, func() {
+		unpickled, err := unpickle([]byte("I1\n."))
+		So(err, ShouldBeNil)
+		_, _, err = parsePickledMetrics(unpickled)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,175 @@
+package connection
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+// maxRemoteWriteRequestSize is a max size of uncompressed prometheus remote write request
+const maxRemoteWriteRequestSize = 32 << 20
+
+// prometheusNameLabel is a label which holds prometheus metric name
+const prometheusNameLabel = "__name__"
+
+// Field numbers of prometheus remote write protobuf messages, see prometheus/prompb/remote.proto and types.proto
+const (
+	writeRequestTimeseriesField protowire.Number = 1
+	timeSeriesLabelsField       protowire.Number = 1
+	timeSeriesSamplesField      protowire.Number = 2
+	labelNameField              protowire.Number = 1
+	labelValueField             protowire.Number = 2
+	sampleValueField            protowire.Number = 1
+	sampleTimestampField        protowire.Number = 2
+)
+
+// prometheusSample is a single value of prometheus time series
+type prometheusSample struct {
+	value     float64
+	timestamp int64
+}
+
+// parseRemoteWriteRequest decodes snappy-compressed protobuf prometheus remote write request to parsed metrics.
+// Every sample becomes a metric whose name is a series name and labels are series labels. Invalid samples are skipped and counted
+func parseRemoteWriteRequest(compressed []byte) ([]*filter.ParsedMetric, int, error) {
+	decodedLength, err := snappy.DecodedLen(compressed)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid snappy payload: %s", err.Error())
+	}
+	if decodedLength > maxRemoteWriteRequestSize {
+		return nil, 0, fmt.Errorf("remote write request is too big: %d bytes", decodedLength)
+	}
+	request, err := snappy.Decode(nil, compressed)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid snappy payload: %s", err.Error())
+	}
+
+	parsedMetrics := make([]*filter.ParsedMetric, 0)
+	invalidCount := 0
+	err = consumeMessage(request, func(number protowire.Number, data []byte) error {
+		if number != writeRequestTimeseriesField {
+			return nil
+		}
+		labels, samples, err := parseTimeSeries(data)
+		if err != nil {
+			return err
+		}
+		name := labels[prometheusNameLabel]
+		delete(labels, prometheusNameLabel)
+		for _, sample := range samples {
+			if math.IsNaN(sample.value) || math.IsInf(sample.value, 0) {
+				invalidCount++
+				continue
+			}
+			parsedMetric, err := filter.NewParsedMetricWithLabels(name, labels, sample.value, sample.timestamp/1000) //nolint
+			if err != nil {
+				invalidCount++
+				continue
+			}
+			parsedMetrics = append(parsedMetrics, parsedMetric)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return parsedMetrics, invalidCount, nil
+}
+
+func parseTimeSeries(data []byte) (map[string]string, []prometheusSample, error) {
+	labels := make(map[string]string)
+	samples := make([]prometheusSample, 0)
+	err := consumeMessage(data, func(number protowire.Number, data []byte) error {
+		switch number {
+		case timeSeriesLabelsField:
+			var name, value string
+			err := consumeMessage(data, func(number protowire.Number, data []byte) error {
+				switch number {
+				case labelNameField:
+					name = string(data)
+				case labelValueField:
+					value = string(data)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			labels[name] = value
+		case timeSeriesSamplesField:
+			sample, err := parseSample(data)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, sample)
+		}
+		return nil
+	})
+	return labels, samples, err
+}
+
+func parseSample(data []byte) (prometheusSample, error) {
+	sample := prometheusSample{}
+	for len(data) > 0 {
+		number, fieldType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return sample, protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch {
+		case number == sampleValueField && fieldType == protowire.Fixed64Type:
+			value, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+			sample.value = math.Float64frombits(value)
+			data = data[n:]
+		case number == sampleTimestampField && fieldType == protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+			sample.timestamp = int64(value)
+			data = data[n:]
+		default:
+			n = protowire.ConsumeFieldValue(number, fieldType, data)
+			if n < 0 {
+				return sample, protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+	return sample, nil
+}
+
+// consumeMessage calls consume for every length-delimited field of protobuf message, other fields are skipped
+func consumeMessage(data []byte, consume func(number protowire.Number, data []byte) error) error {
+	for len(data) > 0 {
+		number, fieldType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if fieldType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, fieldType, data)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			data = data[n:]
+			continue
+		}
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := consume(number, value); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
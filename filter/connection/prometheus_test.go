@@ -0,0 +1,100 @@
+package connection
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	. "github.com/smartystreets/goconvey/convey"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+type testSeries struct {
+	labels  [][2]string
+	samples []prometheusSample
+}
+
+func encodeRemoteWriteRequest(series []testSeries) []byte {
+	request := make([]byte, 0)
+	for _, item := range series {
+		timeSeries := make([]byte, 0)
+		for _, label := range item.labels {
+			encodedLabel := protowire.AppendTag(nil, labelNameField, protowire.BytesType)
+			encodedLabel = protowire.AppendString(encodedLabel, label[0])
+			encodedLabel = protowire.AppendTag(encodedLabel, labelValueField, protowire.BytesType)
+			encodedLabel = protowire.AppendString(encodedLabel, label[1])
+			timeSeries = protowire.AppendTag(timeSeries, timeSeriesLabelsField, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, encodedLabel)
+		}
+		for _, sample := range item.samples {
+			encodedSample := protowire.AppendTag(nil, sampleValueField, protowire.Fixed64Type)
+			encodedSample = protowire.AppendFixed64(encodedSample, math.Float64bits(sample.value))
+			encodedSample = protowire.AppendTag(encodedSample, sampleTimestampField, protowire.VarintType)
+			encodedSample = protowire.AppendVarint(encodedSample, uint64(sample.timestamp))
+			timeSeries = protowire.AppendTag(timeSeries, timeSeriesSamplesField, protowire.BytesType)
+			timeSeries = protowire.AppendBytes(timeSeries, encodedSample)
+		}
+		request = protowire.AppendTag(request, writeRequestTimeseriesField, protowire.BytesType)
+		request = protowire.AppendBytes(request, timeSeries)
+	}
+	return snappy.Encode(nil, request)
+}
+
+func TestParseRemoteWriteRequest(t *testing.T) {
+	Convey("Given remote write request, should return metric for every sample", t, func() {
+		request := encodeRemoteWriteRequest([]testSeries{
+			{
+				labels:  [][2]string{{"__name__", "http_requests_total"}, {"job", "api"}, {"code", "200"}},
+				samples: []prometheusSample{{value: 10, timestamp: 1234567890000}, {value: 15, timestamp: 1234567950500}},
+			},
+			{
+				labels:  [][2]string{{"__name__", "up"}},
+				samples: []prometheusSample{{value: 1, timestamp: 1234567890000}},
+			},
+		})
+		parsedMetrics, invalidCount, err := parseRemoteWriteRequest(request)
+		So(err, ShouldBeNil)
+		So(invalidCount, ShouldEqual, 0)
+		So(parsedMetrics, ShouldResemble, []*filter.ParsedMetric{
+			{Metric: "http_requests_total;code=200;job=api", Name: "http_requests_total", Labels: map[string]string{"job": "api", "code": "200"}, Value: 10, Timestamp: 1234567890},
+			{Metric: "http_requests_total;code=200;job=api", Name: "http_requests_total", Labels: map[string]string{"job": "api", "code": "200"}, Value: 15, Timestamp: 1234567950},
+			{Metric: "up", Name: "up", Labels: map[string]string{}, Value: 1, Timestamp: 1234567890},
+		})
+	})
+
+	Convey("Given invalid samples, should skip them", t, func() {
+		request := encodeRemoteWriteRequest([]testSeries{
+			{
+				labels:  [][2]string{{"__name__", "stale"}},
+				samples: []prometheusSample{{value: math.NaN(), timestamp: 1234567890000}},
+			},
+			{
+				labels:  [][2]string{{"job", "api"}},
+				samples: []prometheusSample{{value: 1, timestamp: 1234567890000}},
+			},
+			{
+				labels:  [][2]string{{"__name__", "invalid"}, {"path", "a;b"}},
+				samples: []prometheusSample{{value: 1, timestamp: 1234567890000}},
+			},
+		})
+		parsedMetrics, invalidCount, err := parseRemoteWriteRequest(request)
+		So(err, ShouldBeNil)
+		So(invalidCount, ShouldEqual, 3)
+		So(parsedMetrics, ShouldBeEmpty)
+	})
+
+	Convey("Given malformed request, should return error", t, func() {
+		_, _, err := parseRemoteWriteRequest([]byte("not snappy"))
+		So(err, ShouldNotBeNil)
+
+		_, _, err = parseRemoteWriteRequest(snappy.Encode(nil, []byte{0x0a, 0xff, 0x01}))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given too big request, should return error", t, func() {
+		_, _, err := parseRemoteWriteRequest(snappy.Encode(nil, make([]byte, maxRemoteWriteRequestSize+1)))
+		So(err, ShouldNotBeNil)
+	})
+}
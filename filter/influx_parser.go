@@ -0,0 +1,298 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// InfluxTemplateMeasurement is a template part which is replaced by point measurement
+	InfluxTemplateMeasurement = "measurement"
+	// InfluxTemplateField is a template part which is replaced by point field name
+	InfluxTemplateField = "field"
+	// influxValueField is a conventional name of the single point field, e.g. in telegraf statsd input
+	influxValueField = "value"
+	// defaultInfluxTemplate is a template used if no rule matches point measurement
+	defaultInfluxTemplate = InfluxTemplateMeasurement + "." + InfluxTemplateField
+)
+
+// InfluxPoint represents a result of ParseInfluxPoint
+type InfluxPoint struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]float64
+	// SkippedFields is a count of fields with string values which can not be converted to metrics
+	SkippedFields int
+	Timestamp     int64
+}
+
+// InfluxRule defines how to build metric name from points with measurement matched by pattern
+type InfluxRule struct {
+	// Regular expression to match point measurement, empty pattern matches any measurement
+	Measurement string
+	// Dot-separated metric name template which consists of "measurement", "field" and tag names, e.g. "host.measurement.field"
+	Template string
+}
+
+// InfluxConfig is a set of rules to convert influx line protocol points to graphite metrics
+type InfluxConfig struct {
+	// Rules are checked in order, first rule with matching measurement is applied
+	Rules []InfluxRule
+	// If true, field named "value" is not added to metric name
+	SkipValueField bool
+	// If true, tags which are not used in template are not added to metric as graphite tags
+	DropTags bool
+}
+
+type influxRule struct {
+	measurement *regexp.Regexp
+	template    []string
+}
+
+// InfluxConverter converts influx line protocol points to graphite metrics, every point field becomes a separate metric
+type InfluxConverter struct {
+	rules          []influxRule
+	skipValueField bool
+	dropTags       bool
+}
+
+// NewInfluxConverter creates new InfluxConverter and validates its rules
+func NewInfluxConverter(config InfluxConfig) (*InfluxConverter, error) {
+	converter := &InfluxConverter{
+		rules:          make([]influxRule, 0, len(config.Rules)+1),
+		skipValueField: config.SkipValueField,
+		dropTags:       config.DropTags,
+	}
+	rules := make([]InfluxRule, 0, len(config.Rules)+1)
+	rules = append(rules, config.Rules...)
+	rules = append(rules, InfluxRule{Template: defaultInfluxTemplate})
+	for _, rule := range rules {
+		measurement, err := regexp.Compile(rule.Measurement)
+		if err != nil {
+			return nil, fmt.Errorf("invalid influx rule measurement '%s': %s", rule.Measurement, err.Error())
+		}
+		template := strings.Split(rule.Template, ".")
+		if hasEmptyParts(template) {
+			return nil, fmt.Errorf("invalid influx rule template '%s': empty template part", rule.Template)
+		}
+		converter.rules = append(converter.rules, influxRule{measurement: measurement, template: template})
+	}
+	return converter, nil
+}
+
+// ParseLine parses influx line protocol line and converts every point field to parsed metric.
+// Timestamps are treated according to precision. Returns count of fields which can not be converted to metrics
+func (converter *InfluxConverter) ParseLine(input []byte, precision time.Duration) ([]*ParsedMetric, int, error) {
+	point, err := ParseInfluxPoint(input, precision)
+	if err != nil {
+		return nil, 0, err
+	}
+	parsedMetrics, invalidCount := converter.Convert(point)
+	return parsedMetrics, invalidCount, nil
+}
+
+// Convert converts every point field to parsed metric named by first matched rule.
+// Returns count of fields which can not be converted to metrics
+func (converter *InfluxConverter) Convert(point *InfluxPoint) ([]*ParsedMetric, int) {
+	rule := converter.matchRule(point.Measurement)
+	labels := make(map[string]string)
+	if !converter.dropTags {
+		for tagName, tagValue := range point.Tags {
+			labels[tagName] = tagValue
+		}
+	}
+	for _, part := range rule.template {
+		if part != InfluxTemplateMeasurement && part != InfluxTemplateField {
+			delete(labels, part)
+		}
+	}
+
+	parsedMetrics := make([]*ParsedMetric, 0, len(point.Fields))
+	invalidCount := point.SkippedFields
+	for field, value := range point.Fields {
+		name := converter.buildName(rule, point, field)
+		parsedMetric, err := NewParsedMetricWithLabels(name, labels, value, point.Timestamp)
+		if err != nil {
+			invalidCount++
+			continue
+		}
+		parsedMetrics = append(parsedMetrics, parsedMetric)
+	}
+	return parsedMetrics, invalidCount
+}
+
+func (converter *InfluxConverter) matchRule(measurement string) influxRule {
+	for _, rule := range converter.rules {
+		if rule.measurement.MatchString(measurement) {
+			return rule
+		}
+	}
+	return converter.rules[len(converter.rules)-1]
+}
+
+func (converter *InfluxConverter) buildName(rule influxRule, point *InfluxPoint, field string) string {
+	parts := make([]string, 0, len(rule.template))
+	for _, part := range rule.template {
+		switch part {
+		case InfluxTemplateMeasurement:
+			parts = append(parts, point.Measurement)
+		case InfluxTemplateField:
+			if converter.skipValueField && field == influxValueField {
+				continue
+			}
+			parts = append(parts, field)
+		default:
+			if tagValue, ok := point.Tags[part]; ok && tagValue != "" {
+				parts = append(parts, tagValue)
+			}
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// ParseInfluxPoint parses point from influx line protocol line
+// supported format: "<measurement>[,<tag>=<value>...] <field>=<value>[,<field>=<value>...] [<timestamp>]"
+func ParseInfluxPoint(input []byte, precision time.Duration) (*InfluxPoint, error) {
+	if !isPrintableASCII(input) {
+		return nil, fmt.Errorf("non-ascii or non-printable chars in line: '%s'", input)
+	}
+	line := string(input)
+	point := &InfluxPoint{
+		Tags:   make(map[string]string),
+		Fields: make(map[string]float64),
+	}
+
+	measurement, position := scanInfluxToken(line, 0, ", ")
+	if measurement == "" {
+		return nil, fmt.Errorf("empty measurement: '%s'", line)
+	}
+	point.Measurement = measurement
+
+	for position < len(line) && line[position] == ',' {
+		var tagName, tagValue string
+		tagName, position = scanInfluxToken(line, position+1, "=, ")
+		if position >= len(line) || line[position] != '=' || tagName == "" {
+			return nil, fmt.Errorf("invalid tag set: '%s'", line)
+		}
+		tagValue, position = scanInfluxToken(line, position+1, ", ")
+		point.Tags[tagName] = tagValue
+	}
+	if position >= len(line) || line[position] != ' ' {
+		return nil, fmt.Errorf("too few space-separated items: '%s'", line)
+	}
+
+	position++
+	for {
+		var fieldName string
+		fieldName, position = scanInfluxToken(line, position, "=, ")
+		if position >= len(line) || line[position] != '=' || fieldName == "" {
+			return nil, fmt.Errorf("invalid field set: '%s'", line)
+		}
+		position++
+		if position < len(line) && line[position] == '"' {
+			end := scanInfluxString(line, position+1)
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated string field value: '%s'", line)
+			}
+			point.SkippedFields++
+			position = end + 1
+		} else {
+			var rawValue string
+			rawValue, position = scanInfluxToken(line, position, ", ")
+			value, err := parseInfluxFieldValue(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse field value: '%s' (%s)", line, err)
+			}
+			point.Fields[fieldName] = value
+		}
+		if position >= len(line) || line[position] != ',' {
+			break
+		}
+		position++
+	}
+
+	point.Timestamp = time.Now().Unix()
+	if position < len(line) {
+		if line[position] != ' ' {
+			return nil, fmt.Errorf("invalid field set: '%s'", line)
+		}
+		rawTimestamp := line[position+1:]
+		timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse timestamp: '%s' (%s)", line, err)
+		}
+		point.Timestamp, err = convertInfluxTimestamp(timestamp, precision)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert timestamp: '%s' (%s)", line, err)
+		}
+	}
+	return point, nil
+}
+
+// convertInfluxTimestamp converts timestamp in given precision to unix seconds
+func convertInfluxTimestamp(timestamp int64, precision time.Duration) (int64, error) {
+	if precision < time.Second {
+		return timestamp / (int64(time.Second) / int64(precision)), nil
+	}
+	multiplier := int64(precision / time.Second)
+	if timestamp > math.MaxInt64/multiplier || timestamp < math.MinInt64/multiplier {
+		return 0, fmt.Errorf("timestamp is out of range")
+	}
+	return timestamp * multiplier, nil
+}
+
+// scanInfluxToken reads token from position until one of unescaped stop chars, returns unescaped token and stop position
+func scanInfluxToken(line string, position int, stops string) (string, int) {
+	var token strings.Builder
+	for position < len(line) {
+		char := line[position]
+		if char == '\\' && position+1 < len(line) && strings.ContainsRune(`,= "\`, rune(line[position+1])) {
+			token.WriteByte(line[position+1])
+			position += 2
+			continue
+		}
+		if strings.IndexByte(stops, char) != -1 {
+			break
+		}
+		token.WriteByte(char)
+		position++
+	}
+	return token.String(), position
+}
+
+// scanInfluxString returns position of closing quote of string field value started from position
+func scanInfluxString(line string, position int) int {
+	for position < len(line) {
+		switch line[position] {
+		case '\\':
+			position += 2
+			continue
+		case '"':
+			return position
+		}
+		position++
+	}
+	return -1
+}
+
+func parseInfluxFieldValue(rawValue string) (float64, error) {
+	switch rawValue {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, nil
+	}
+	if strings.HasSuffix(rawValue, "i") {
+		value, err := strconv.ParseInt(rawValue[:len(rawValue)-1], 10, 64)
+		return float64(value), err
+	}
+	if strings.HasSuffix(rawValue, "u") {
+		value, err := strconv.ParseUint(rawValue[:len(rawValue)-1], 10, 64)
+		return float64(value), err
+	}
+	return strconv.ParseFloat(rawValue, 64)
+}
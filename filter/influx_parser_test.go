@@ -0,0 +1,164 @@
+package filter
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseInfluxPoint(t *testing.T) {
+	Convey("Given invalid influx lines, should return errors", t, func() {
+		invalidLines := []string{
+			"",
+			"cpu",
+			"cpu ",
+			",host=a value=1",
+			"cpu,host value=1",
+			"cpu,=a value=1",
+			"cpu value",
+			"cpu =1",
+			"cpu value=abc",
+			"cpu value=1 abc",
+			"cpu value=1,",
+			"cpu value=\"unterminated",
+			"cpu value=1 1234567890 1",
+			"cpu value=1i2",
+			"cpu value=-1u",
+			"cpu,host=こんにちは value=1",
+		}
+		for _, invalidLine := range invalidLines {
+			_, err := ParseInfluxPoint([]byte(invalidLine), time.Nanosecond)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Given valid influx line, should parse measurement, tags, fields and timestamp", t, func() {
+		point, err := ParseInfluxPoint([]byte(`cpu,host=server01,region=us-west usage_idle=99.5,usage_user=1e-1,cores=8i,total=16u,up=t,down=FALSE,name="cpu \"0\"" 1234567890123456789`), time.Nanosecond)
+		So(err, ShouldBeNil)
+		So(point.Measurement, ShouldEqual, "cpu")
+		So(point.Tags, ShouldResemble, map[string]string{"host": "server01", "region": "us-west"})
+		So(point.Fields, ShouldResemble, map[string]float64{
+			"usage_idle": 99.5,
+			"usage_user": 0.1,
+			"cores":      8,
+			"total":      16,
+			"up":         1,
+			"down":       0,
+		})
+		So(point.SkippedFields, ShouldEqual, 1)
+		So(point.Timestamp, ShouldEqual, 1234567890)
+	})
+
+	Convey("Given escaped chars, should unescape them", t, func() {
+		point, err := ParseInfluxPoint([]byte(`disk\ io,path=C:\\data,mount\=point=a\,b read\ bytes=1`), time.Nanosecond)
+		So(err, ShouldBeNil)
+		So(point.Measurement, ShouldEqual, "disk io")
+		So(point.Tags, ShouldResemble, map[string]string{"path": `C:\data`, "mount=point": "a,b"})
+		So(point.Fields, ShouldResemble, map[string]float64{"read bytes": 1})
+	})
+
+	Convey("Given timestamp precision, should convert timestamp to seconds", t, func() {
+		point, err := ParseInfluxPoint([]byte("cpu value=1 1234567890"), time.Second)
+		So(err, ShouldBeNil)
+		So(point.Timestamp, ShouldEqual, 1234567890)
+
+		point, err = ParseInfluxPoint([]byte("cpu value=1 1234567890123"), time.Millisecond)
+		So(err, ShouldBeNil)
+		So(point.Timestamp, ShouldEqual, 1234567890)
+
+		point, err = ParseInfluxPoint([]byte("cpu value=1 9223372036854775807"), time.Microsecond)
+		So(err, ShouldBeNil)
+		So(point.Timestamp, ShouldEqual, 9223372036854)
+
+		point, err = ParseInfluxPoint([]byte("cpu value=1 20576131"), time.Minute)
+		So(err, ShouldBeNil)
+		So(point.Timestamp, ShouldEqual, 1234567860)
+	})
+
+	Convey("Given timestamp out of range, should return error", t, func() {
+		_, err := ParseInfluxPoint([]byte("cpu value=1 9223372036854775807"), time.Hour)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given line without timestamp, should use current time", t, func() {
+		before := time.Now().Unix()
+		point, err := ParseInfluxPoint([]byte("cpu value=1"), time.Nanosecond)
+		So(err, ShouldBeNil)
+		So(point.Timestamp, ShouldBeGreaterThanOrEqualTo, before)
+		So(point.Timestamp, ShouldBeLessThanOrEqualTo, time.Now().Unix())
+	})
+}
+
+func TestInfluxConverter(t *testing.T) {
+	parseLine := func(converter *InfluxConverter, line string) ([]string, int) {
+		parsedMetrics, invalidCount, err := converter.ParseLine([]byte(line), time.Second)
+		So(err, ShouldBeNil)
+		names := make([]string, 0, len(parsedMetrics))
+		for _, parsedMetric := range parsedMetrics {
+			So(parsedMetric.Timestamp, ShouldEqual, 1234567890)
+			names = append(names, parsedMetric.Metric)
+		}
+		sort.Strings(names)
+		return names, invalidCount
+	}
+
+	Convey("Given invalid rules, should return error", t, func() {
+		_, err := NewInfluxConverter(InfluxConfig{Rules: []InfluxRule{{Measurement: "(", Template: "measurement"}}})
+		So(err, ShouldNotBeNil)
+
+		_, err = NewInfluxConverter(InfluxConfig{Rules: []InfluxRule{{Template: "host..measurement"}}})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Given default config, should name metrics as measurement.field with tags as labels", t, func() {
+		converter, err := NewInfluxConverter(InfluxConfig{})
+		So(err, ShouldBeNil)
+		names, invalidCount := parseLine(converter, `cpu,host=server01,region=us-west idle=99,user=1,name="cpu0" 1234567890`)
+		So(names, ShouldResemble, []string{"cpu.idle;host=server01;region=us-west", "cpu.user;host=server01;region=us-west"})
+		So(invalidCount, ShouldEqual, 1)
+	})
+
+	Convey("Given rules, first matched rule should be applied and used tags removed from labels", t, func() {
+		converter, err := NewInfluxConverter(InfluxConfig{
+			Rules: []InfluxRule{
+				{Measurement: "^disk", Template: "host.measurement.path.field"},
+				{Measurement: "^cpu$", Template: "region.host.measurement.field"},
+				{Measurement: "^cpu", Template: "measurement"},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		names, _ := parseLine(converter, "cpu,host=server01,region=us-west,measurement=x idle=99 1234567890")
+		So(names, ShouldResemble, []string{"us-west.server01.cpu.idle;measurement=x"})
+
+		names, _ = parseLine(converter, "disk,host=server01 free=1 1234567890")
+		So(names, ShouldResemble, []string{"server01.disk.free"})
+
+		names, _ = parseLine(converter, "cpu_total,host=server01 idle=99 1234567890")
+		So(names, ShouldResemble, []string{"cpu_total;host=server01"})
+
+		names, _ = parseLine(converter, "mem used=1 1234567890")
+		So(names, ShouldResemble, []string{"mem.used"})
+	})
+
+	Convey("Given SkipValueField and DropTags, should skip value field and drop unused tags", t, func() {
+		converter, err := NewInfluxConverter(InfluxConfig{
+			Rules:          []InfluxRule{{Template: "host.measurement.field"}},
+			SkipValueField: true,
+			DropTags:       true,
+		})
+		So(err, ShouldBeNil)
+		names, _ := parseLine(converter, "requests,host=server01,dc=eu value=10,max=20 1234567890")
+		So(names, ShouldResemble, []string{"server01.requests", "server01.requests.max"})
+	})
+
+	Convey("Given metric name which can not be used, should count it as invalid", t, func() {
+		converter, err := NewInfluxConverter(InfluxConfig{})
+		So(err, ShouldBeNil)
+		names, invalidCount := parseLine(converter, "cpu,host=a;b idle=99 1234567890")
+		So(names, ShouldBeEmpty)
+		So(invalidCount, ShouldEqual, 1)
+	})
+}
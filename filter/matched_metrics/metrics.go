@@ -9,24 +9,29 @@ import (
 	"github.com/moira-alert/moira/metrics"
 )
 
+// offendersSavePeriod is a period to save prefixes which exceeded limits
+const offendersSavePeriod = 10 * time.Second
+
 // MetricsMatcher make buffer of metrics and save it
 type MetricsMatcher struct {
 	logger        moira.Logger
 	metrics       *metrics.FilterMetrics
 	database      moira.Database
 	cacheStorage  *filter.Storage
+	prefixGuard   *filter.PrefixGuard
 	cacheCapacity int
 	waitGroup     *sync.WaitGroup
 	closeRequest  chan struct{}
 }
 
 // NewMetricsMatcher creates new MetricsMatcher
-func NewMetricsMatcher(metrics *metrics.FilterMetrics, logger moira.Logger, database moira.Database, cacheStorage *filter.Storage, cacheCapacity int) *MetricsMatcher {
+func NewMetricsMatcher(metrics *metrics.FilterMetrics, logger moira.Logger, database moira.Database, cacheStorage *filter.Storage, prefixGuard *filter.PrefixGuard, cacheCapacity int) *MetricsMatcher {
 	return &MetricsMatcher{
 		metrics:       metrics,
 		logger:        logger,
 		database:      database,
 		cacheStorage:  cacheStorage,
+		prefixGuard:   prefixGuard,
 		cacheCapacity: cacheCapacity,
 		waitGroup:     &sync.WaitGroup{},
 		closeRequest:  make(chan struct{}),
@@ -39,11 +44,17 @@ func (matcher *MetricsMatcher) Start(matchedMetricsChan chan *moira.MatchedMetri
 	go func() {
 		defer matcher.waitGroup.Done()
 
+		lastOffendersSave := time.Now()
 		for batch := range matcher.receiveBatch(matchedMetricsChan) {
 			timer := time.Now()
 			matcher.save(batch)
 			matcher.metrics.SavingTimer.UpdateSince(timer)
+			if time.Since(lastOffendersSave) >= offendersSavePeriod {
+				matcher.saveOffenders()
+				lastOffendersSave = time.Now()
+			}
 		}
+		matcher.saveOffenders()
 	}()
 	matcher.logger.Infof("Moira Filter Metrics Matcher started to save %d cached metrics every %.4f", matcher.cacheCapacity, time.Second.Seconds())
 }
@@ -67,6 +78,9 @@ func (matcher *MetricsMatcher) receiveBatch(metrics <-chan *moira.MatchedMetric)
 					batchedMetrics <- batch
 					return
 				}
+				if !matcher.prefixGuard.Allow(metric.Metric) {
+					goto retry
+				}
 				matcher.cacheStorage.EnrichMatchedMetric(batch, metric)
 				if len(batch) < matcher.cacheCapacity {
 					goto retry
@@ -93,3 +107,17 @@ func (matcher *MetricsMatcher) save(buffer map[string]*moira.MatchedMetric) {
 		matcher.logger.Errorf("Failed to save matched metrics: %s", err.Error())
 	}
 }
+
+func (matcher *MetricsMatcher) saveOffenders() {
+	offenders := matcher.prefixGuard.TakeOffenders()
+	if len(offenders) == 0 {
+		return
+	}
+	for _, offender := range offenders {
+		matcher.logger.Warningf("Prefix %s exceeded limits: %d points of new metrics and %d points over rate are dropped",
+			offender.Prefix, offender.DroppedByCardinality, offender.DroppedByRate)
+	}
+	if err := matcher.database.AddPrefixOffenders(offenders); err != nil {
+		matcher.logger.Errorf("Failed to save prefix offenders: %s", err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package filter
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -9,6 +10,37 @@ import (
 	"github.com/moira-alert/moira"
 )
 
+// Reasons of metric line parsing errors
+const (
+	ParseErrorNonPrintable     = "non_printable"
+	ParseErrorItemsCount       = "items_count"
+	ParseErrorInvalidName      = "invalid_name"
+	ParseErrorInvalidValue     = "invalid_value"
+	ParseErrorInvalidTimestamp = "invalid_timestamp"
+)
+
+// ParseError is an error of ParseMetric with reason why metric line is invalid
+type ParseError struct {
+	Reason  string
+	message string
+}
+
+func newParseError(reason string, format string, args ...interface{}) *ParseError {
+	return &ParseError{Reason: reason, message: fmt.Sprintf(format, args...)}
+}
+
+// Error returns error message
+func (err *ParseError) Error() string {
+	return err.message
+}
+
+// IncomingLine is a raw metric line received by metrics listener
+type IncomingLine struct {
+	Line []byte
+	// Address of line sender
+	Source string
+}
+
 // ParsedMetric represents a result of ParseMetric.
 type ParsedMetric struct {
 	Metric    string
@@ -22,40 +54,40 @@ type ParsedMetric struct {
 // supported format: "<metricString> <valueFloat64> <timestampInt64>"
 func ParseMetric(input []byte) (*ParsedMetric, error) {
 	if !isPrintableASCII(input) {
-		return nil, fmt.Errorf("non-ascii or non-printable chars in metric name: '%s'", input)
+		return nil, newParseError(ParseErrorNonPrintable, "non-ascii or non-printable chars in metric name: '%s'", input)
 	}
 
 	var metricBytes, valueBytes, timestampBytes []byte
 	inputScanner := moira.NewBytesScanner(input, ' ')
 	if !inputScanner.HasNext() {
-		return nil, fmt.Errorf("too few space-separated items: '%s'", input)
+		return nil, newParseError(ParseErrorItemsCount, "too few space-separated items: '%s'", input)
 	}
 	metricBytes = inputScanner.Next()
 	if !inputScanner.HasNext() {
-		return nil, fmt.Errorf("too few space-separated items: '%s'", input)
+		return nil, newParseError(ParseErrorItemsCount, "too few space-separated items: '%s'", input)
 	}
 	valueBytes = inputScanner.Next()
 	if !inputScanner.HasNext() {
-		return nil, fmt.Errorf("too few space-separated items: '%s'", input)
+		return nil, newParseError(ParseErrorItemsCount, "too few space-separated items: '%s'", input)
 	}
 	timestampBytes = inputScanner.Next()
 	if inputScanner.HasNext() {
-		return nil, fmt.Errorf("too many space-separated items: '%s'", input)
+		return nil, newParseError(ParseErrorItemsCount, "too many space-separated items: '%s'", input)
 	}
 
 	name, labels, err := parseNameAndLabels(metricBytes)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse metric: '%s' (%s)", input, err)
+		return nil, newParseError(ParseErrorInvalidName, "cannot parse metric: '%s' (%s)", input, err)
 	}
 
 	value, err := parseFloat(valueBytes)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse value: '%s' (%s)", input, err)
+		return nil, newParseError(ParseErrorInvalidValue, "cannot parse value: '%s' (%s)", input, err)
 	}
 
 	timestamp, err := parseFloat(timestampBytes)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse timestamp: '%s' (%s)", input, err)
+		return nil, newParseError(ParseErrorInvalidTimestamp, "cannot parse timestamp: '%s' (%s)", input, err)
 	}
 
 	parsedMetric := &ParsedMetric{
@@ -71,6 +103,53 @@ func ParseMetric(input []byte) (*ParsedMetric, error) {
 	return parsedMetric, nil
 }
 
+// NewParsedMetric creates ParsedMetric from metric string with optional labels
+// supported metric format: "<name>[;<labelName>=<labelValue>...]"
+func NewParsedMetric(metric string, value float64, timestamp int64) (*ParsedMetric, error) {
+	metricBytes := []byte(metric)
+	if !isPrintableASCII(metricBytes) {
+		return nil, fmt.Errorf("non-ascii or non-printable chars in metric name: '%s'", metric)
+	}
+	name, labels, err := parseNameAndLabels(metricBytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse metric: '%s' (%s)", metric, err)
+	}
+	if timestamp == -1 {
+		timestamp = time.Now().Unix()
+	}
+	return &ParsedMetric{
+		Metric:    metric,
+		Name:      name,
+		Labels:    labels,
+		Value:     value,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// NewParsedMetricWithLabels creates ParsedMetric from metric name and labels,
+// metric string is built in graphite tagged format with labels sorted by name
+func NewParsedMetricWithLabels(name string, labels map[string]string, value float64, timestamp int64) (*ParsedMetric, error) {
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	var metric strings.Builder
+	metric.WriteString(name)
+	for _, labelName := range labelNames {
+		labelValue := labels[labelName]
+		if strings.ContainsAny(labelName, ";=") || strings.Contains(labelValue, ";") {
+			return nil, fmt.Errorf("invalid label '%s=%s' of metric '%s'", labelName, labelValue, name)
+		}
+		metric.WriteString(";")
+		metric.WriteString(labelName)
+		metric.WriteString("=")
+		metric.WriteString(labelValue)
+	}
+	return NewParsedMetric(metric.String(), value, timestamp)
+}
+
 func parseNameAndLabels(metricBytes []byte) (string, map[string]string, error) {
 	metricBytesScanner := moira.NewBytesScanner(metricBytes, ';')
 	if !metricBytesScanner.HasNext() {
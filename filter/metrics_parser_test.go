@@ -51,6 +51,23 @@ func TestParseMetric(t *testing.T) {
 		}
 	})
 
+	Convey("Given invalid metric strings, should return errors with reason", t, func() {
+		invalidMetrics := map[string]string{
+			"Невалидная.метрика 1 1234567890": ParseErrorNonPrintable,
+			"one.two 1":              ParseErrorItemsCount,
+			"one.two 1 2 3":          ParseErrorItemsCount,
+			"Empty.label.name;= 1 2": ParseErrorInvalidName,
+			"one.two one 1234567890": ParseErrorInvalidValue,
+			"one.two 1 two":          ParseErrorInvalidTimestamp,
+		}
+
+		for invalidMetric, reason := range invalidMetrics {
+			_, err := ParseMetric([]byte(invalidMetric))
+			So(err, ShouldHaveSameTypeAs, &ParseError{})
+			So(err.(*ParseError).Reason, ShouldEqual, reason)
+		}
+	})
+
 	Convey("Given valid metric strings, should return parsed values", t, func() {
 		validMetrics := []ValidMetricCase{
 			{"One.two.three 123 1234567890", "One.two.three", "One.two.three", map[string]string{}, 123, 1234567890},
@@ -183,3 +200,57 @@ func TestParseMetric(t *testing.T) {
 		}
 	})
 }
+
+func TestNewParsedMetric(t *testing.T) {
+	Convey("Given valid metric with labels, should return parsed metric", t, func() {
+		parsedMetric, err := NewParsedMetric("One.two.three;four=five", 123, 1234567890)
+		So(err, ShouldBeNil)
+		So(parsedMetric, ShouldResemble, &ParsedMetric{
+			Metric:    "One.two.three;four=five",
+			Name:      "One.two.three",
+			Labels:    map[string]string{"four": "five"},
+			Value:     123,
+			Timestamp: 1234567890,
+		})
+	})
+
+	Convey("Given -1 timestamp, should use current time", t, func() {
+		parsedMetric, err := NewParsedMetric("One.two.three", 123, -1)
+		So(err, ShouldBeNil)
+		So(parsedMetric.Timestamp, ShouldBeGreaterThan, 0)
+	})
+
+	Convey("Given invalid metrics, should return errors", t, func() {
+		for _, metric := range []string{"", "Non-ascii.こんにちは", "Empty.label.name;=1", ";Empty.name"} {
+			_, err := NewParsedMetric(metric, 123, 1234567890)
+			So(err, ShouldBeError)
+		}
+	})
+}
+
+func TestNewParsedMetricWithLabels(t *testing.T) {
+	Convey("Given name and labels, should build tagged metric with sorted labels", t, func() {
+		parsedMetric, err := NewParsedMetricWithLabels("One.two.three", map[string]string{"six": "seven", "four": "five=5"}, 123, 1234567890)
+		So(err, ShouldBeNil)
+		So(parsedMetric, ShouldResemble, &ParsedMetric{
+			Metric:    "One.two.three;four=five=5;six=seven",
+			Name:      "One.two.three",
+			Labels:    map[string]string{"four": "five=5", "six": "seven"},
+			Value:     123,
+			Timestamp: 1234567890,
+		})
+	})
+
+	Convey("Given labels with delimiters, should return errors", t, func() {
+		invalidLabels := []map[string]string{
+			{"four": "five;six=seven"},
+			{"four;six": "five"},
+			{"four=six": "five"},
+			{"": "five"},
+		}
+		for _, labels := range invalidLabels {
+			_, err := NewParsedMetricWithLabels("One.two.three", labels, 123, 1234567890)
+			So(err, ShouldBeError)
+		}
+	})
+}
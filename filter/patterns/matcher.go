@@ -26,13 +26,13 @@ func NewMatcher(logger moira.Logger, metrics *metrics.FilterMetrics, patternsSto
 	}
 }
 
-// Start spawns pattern matcher workers
-func (m *Matcher) Start(matchersCount int, lineChan <-chan []byte) chan *moira.MatchedMetric {
+// Start spawns pattern matcher workers which match both raw lines and metrics parsed by listeners
+func (m *Matcher) Start(matchersCount int, lineChan <-chan filter.IncomingLine, metricsChan <-chan *filter.ParsedMetric) chan *moira.MatchedMetric {
 	matchedMetricsChan := make(chan *moira.MatchedMetric, 16384) //nolint
 	m.logger.Infof("Start %d pattern matcher workers", matchersCount)
 	for i := 0; i < matchersCount; i++ {
 		m.tomb.Go(func() error {
-			return m.worker(lineChan, matchedMetricsChan)
+			return m.worker(lineChan, metricsChan, matchedMetricsChan)
 		})
 	}
 	go func() {
@@ -46,9 +46,24 @@ func (m *Matcher) Start(matchersCount int, lineChan <-chan []byte) chan *moira.M
 	return matchedMetricsChan
 }
 
-func (m *Matcher) worker(metricsChan <-chan []byte, matchedMetricsChan chan<- *moira.MatchedMetric) error {
-	for line := range metricsChan {
-		if metric := m.patternStorage.ProcessIncomingMetric(line); metric != nil {
+func (m *Matcher) worker(lineChan <-chan filter.IncomingLine, metricsChan <-chan *filter.ParsedMetric, matchedMetricsChan chan<- *moira.MatchedMetric) error {
+	for lineChan != nil || metricsChan != nil {
+		var metric *moira.MatchedMetric
+		select {
+		case line, ok := <-lineChan:
+			if !ok {
+				lineChan = nil
+				continue
+			}
+			metric = m.patternStorage.ProcessIncomingMetric(line.Line, line.Source)
+		case parsedMetric, ok := <-metricsChan:
+			if !ok {
+				metricsChan = nil
+				continue
+			}
+			metric = m.patternStorage.ProcessParsedMetric(parsedMetric)
+		}
+		if metric != nil {
 			matchedMetricsChan <- metric
 		}
 	}
@@ -0,0 +1,111 @@
+package patterns
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// ReloadableFileWorker loads config file and reloads it on SIGHUP or when file is changed
+type ReloadableFileWorker struct {
+	logger  moira.Logger
+	tomb    tomb.Tomb
+	name    string
+	path    string
+	period  time.Duration
+	load    func(reader io.Reader) error
+	modTime time.Time
+	size    int64
+}
+
+// NewReloadableFileWorker creates new ReloadableFileWorker. Name is used in logs, load is called with file contents
+// on start and on every reload
+func NewReloadableFileWorker(logger moira.Logger, name, path string, period time.Duration, load func(reader io.Reader) error) *ReloadableFileWorker {
+	return &ReloadableFileWorker{
+		logger: logger,
+		name:   name,
+		path:   path,
+		period: period,
+		load:   load,
+	}
+}
+
+// Start loads file and starts process to reload it.
+// Invalid file is reported on reload and previously loaded config is kept
+func (worker *ReloadableFileWorker) Start() error {
+	if err := worker.loadFile(); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	worker.tomb.Go(func() error {
+		defer signal.Stop(signals)
+		checkTicker := time.NewTicker(worker.period)
+		defer checkTicker.Stop()
+		for {
+			select {
+			case <-worker.tomb.Dying():
+				worker.logger.Infof("Moira Filter %s updater stopped", worker.name)
+				return nil
+			case <-signals:
+				worker.logger.Infof("SIGHUP received, reloading %s from %s", worker.name, worker.path)
+				worker.reload()
+			case <-checkTicker.C:
+				if worker.isChanged() {
+					worker.reload()
+				}
+			}
+		}
+	})
+	worker.logger.Infof("Moira Filter %s updater started", worker.name)
+	return nil
+}
+
+// Stop stops file updates
+func (worker *ReloadableFileWorker) Stop() error {
+	worker.tomb.Kill(nil)
+	return worker.tomb.Wait()
+}
+
+func (worker *ReloadableFileWorker) isChanged() bool {
+	info, err := os.Stat(worker.path)
+	if err != nil {
+		worker.logger.Errorf("Failed to stat %s file %s: %s", worker.name, worker.path, err.Error())
+		return false
+	}
+	return !info.ModTime().Equal(worker.modTime) || info.Size() != worker.size
+}
+
+func (worker *ReloadableFileWorker) reload() {
+	if err := worker.loadFile(); err != nil {
+		worker.logger.Errorf("Failed to reload %s, current %s are kept: %s", worker.name, worker.name, err.Error())
+		return
+	}
+	worker.logger.Infof("%s reloaded from %s", worker.name, worker.path)
+}
+
+func (worker *ReloadableFileWorker) loadFile() error {
+	file, err := os.Open(worker.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// Remember file state before parsing, so invalid file is not parsed again until it is changed
+	worker.modTime = info.ModTime()
+	worker.size = info.Size()
+
+	return worker.load(file)
+}
@@ -0,0 +1,13 @@
+package patterns
+
+import (
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter"
+)
+
+// NewRetentionsWorker creates worker which loads retention config file to cache storage and reloads it on SIGHUP or when file is changed
+func NewRetentionsWorker(logger moira.Logger, cacheStorage *filter.Storage, path string, period time.Duration) *ReloadableFileWorker {
+	return NewReloadableFileWorker(logger, "retentions", path, period, cacheStorage.ReloadRetentions)
+}
@@ -0,0 +1,26 @@
+package patterns
+
+import (
+	"io"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics"
+)
+
+// NewRewriteRulesWorker creates worker which loads rewrite rules file to pattern storage and reloads it when file is changed
+func NewRewriteRulesWorker(metrics *metrics.FilterMetrics, logger moira.Logger, patternStorage *filter.PatternStorage, path string, period time.Duration) *ReloadableFileWorker {
+	return NewReloadableFileWorker(logger, "rewrite rules", path, period, func(reader io.Reader) error {
+		configs, err := filter.ParseRewriteRules(reader)
+		if err != nil {
+			return err
+		}
+		rules, err := filter.NewRewriteRules(configs, metrics)
+		if err != nil {
+			return err
+		}
+		patternStorage.SetRewriteRules(rules)
+		return nil
+	})
+}
@@ -15,6 +15,8 @@ type PatternStorage struct {
 	logger                  moira.Logger
 	PatternIndex            atomic.Value
 	SeriesByTagPatternIndex atomic.Value
+	rewriteRules            atomic.Value
+	rejectedLines           *RejectedLinesRecorder
 }
 
 // NewPatternStorage creates new PatternStorage struct
@@ -51,19 +53,50 @@ func (storage *PatternStorage) Refresh() error {
 	return nil
 }
 
-// ProcessIncomingMetric validates, parses and matches incoming raw string
-func (storage *PatternStorage) ProcessIncomingMetric(lineBytes []byte) *moira.MatchedMetric {
+// SetRewriteRules replaces rules which are applied to every valid metric before pattern matching
+func (storage *PatternStorage) SetRewriteRules(rules *RewriteRules) {
+	storage.rewriteRules.Store(rules)
+}
+
+// SetRejectedLinesRecorder sets recorder of lines which can not be parsed, it must be set before metrics processing
+func (storage *PatternStorage) SetRejectedLinesRecorder(recorder *RejectedLinesRecorder) {
+	storage.rejectedLines = recorder
+}
+
+// ProcessIncomingMetric validates, parses and matches incoming raw string received from source address
+func (storage *PatternStorage) ProcessIncomingMetric(lineBytes []byte, source string) *moira.MatchedMetric {
 	storage.metrics.TotalMetricsReceived.Inc()
-	count := storage.metrics.TotalMetricsReceived.Count()
 
 	parsedMetric, err := ParseMetric(lineBytes)
 	if err != nil {
 		storage.logger.Infof("cannot parse input: %v", err)
+		storage.rejectedLines.Record(lineBytes, source, err)
 		return nil
 	}
 
+	return storage.processParsedMetric(parsedMetric)
+}
+
+// ProcessParsedMetric matches metric which was already parsed by listener
+func (storage *PatternStorage) ProcessParsedMetric(parsedMetric *ParsedMetric) *moira.MatchedMetric {
+	storage.metrics.TotalMetricsReceived.Inc()
+	return storage.processParsedMetric(parsedMetric)
+}
+
+func (storage *PatternStorage) processParsedMetric(parsedMetric *ParsedMetric) *moira.MatchedMetric {
+	count := storage.metrics.TotalMetricsReceived.Count()
 	storage.metrics.ValidMetricsReceived.Inc()
 
+	rewriteRules, _ := storage.rewriteRules.Load().(*RewriteRules)
+	parsedMetric, err := rewriteRules.Apply(parsedMetric)
+	if err != nil {
+		storage.logger.Infof("cannot rewrite metric: %v", err)
+		return nil
+	}
+	if parsedMetric == nil {
+		return nil
+	}
+
 	matchingStart := time.Now()
 	matchedPatterns := storage.matchPatterns(parsedMetric)
 	if count%10 == 0 {
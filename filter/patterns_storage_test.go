@@ -37,7 +37,7 @@ func TestProcessIncomingMetric(t *testing.T) {
 	})
 
 	Convey("When invalid metric arrives, should be properly counted", t, func() {
-		matchedMetrics := patternsStorage.ProcessIncomingMetric(nil)
+		matchedMetrics := patternsStorage.ProcessIncomingMetric(nil, "")
 		So(matchedMetrics, ShouldBeNil)
 		So(patternsStorage.metrics.TotalMetricsReceived.Count(), ShouldEqual, 1)
 		So(patternsStorage.metrics.ValidMetricsReceived.Count(), ShouldEqual, 0)
@@ -46,7 +46,7 @@ func TestProcessIncomingMetric(t *testing.T) {
 
 	Convey("When valid non-matching metric arrives", t, func() {
 		patternsStorage.metrics = metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
-		matchedMetrics := patternsStorage.ProcessIncomingMetric([]byte("disk.used 12 1234567890"))
+		matchedMetrics := patternsStorage.ProcessIncomingMetric([]byte("disk.used 12 1234567890"), "")
 		So(matchedMetrics, ShouldBeNil)
 		So(patternsStorage.metrics.TotalMetricsReceived.Count(), ShouldEqual, 1)
 		So(patternsStorage.metrics.ValidMetricsReceived.Count(), ShouldEqual, 1)
@@ -55,17 +55,49 @@ func TestProcessIncomingMetric(t *testing.T) {
 
 	Convey("When valid matching metric arrives", t, func() {
 		patternsStorage.metrics = metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
-		matchedMetrics := patternsStorage.ProcessIncomingMetric([]byte("cpu.used 12 1234567890"))
+		matchedMetrics := patternsStorage.ProcessIncomingMetric([]byte("cpu.used 12 1234567890"), "")
 		So(matchedMetrics, ShouldNotBeNil)
 		So(patternsStorage.metrics.TotalMetricsReceived.Count(), ShouldEqual, 1)
 		So(patternsStorage.metrics.ValidMetricsReceived.Count(), ShouldEqual, 1)
 		So(patternsStorage.metrics.MatchingMetricsReceived.Count(), ShouldEqual, 1)
 	})
 
+	Convey("When parsed matching metric arrives", t, func() {
+		patternsStorage.metrics = metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		parsedMetric, _ := NewParsedMetric("cpu.used", 12, 1234567890)
+		matchedMetrics := patternsStorage.ProcessParsedMetric(parsedMetric)
+		So(matchedMetrics, ShouldNotBeNil)
+		So(matchedMetrics.Patterns, ShouldHaveLength, 2)
+		So(patternsStorage.metrics.TotalMetricsReceived.Count(), ShouldEqual, 1)
+		So(patternsStorage.metrics.ValidMetricsReceived.Count(), ShouldEqual, 1)
+		So(patternsStorage.metrics.MatchingMetricsReceived.Count(), ShouldEqual, 1)
+	})
+
+	Convey("When metric is renamed by rewrite rules, renamed metric should be matched", t, func() {
+		patternsStorage.metrics = metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		rules, err := NewRewriteRules([]RewriteRuleConfig{
+			{Action: RewriteRename, Match: "^processor\\.", Replacement: "cpu."},
+			{Action: RewriteDrop, Match: "^disk\\."},
+		}, patternsStorage.metrics)
+		So(err, ShouldBeNil)
+		patternsStorage.SetRewriteRules(rules)
+		defer patternsStorage.SetRewriteRules(nil)
+
+		matchedMetrics := patternsStorage.ProcessIncomingMetric([]byte("processor.used 12 1234567890"), "")
+		So(matchedMetrics, ShouldNotBeNil)
+		So(matchedMetrics.Metric, ShouldEqual, "cpu.used")
+		So(matchedMetrics.Patterns, ShouldHaveLength, 2)
+
+		matchedMetrics = patternsStorage.ProcessIncomingMetric([]byte("disk.used 12 1234567890"), "")
+		So(matchedMetrics, ShouldBeNil)
+		So(patternsStorage.metrics.ValidMetricsReceived.Count(), ShouldEqual, 2)
+		So(patternsStorage.metrics.MatchingMetricsReceived.Count(), ShouldEqual, 1)
+	})
+
 	Convey("When ten valid metrics arrive match timer should be updated", t, func() {
 		patternsStorage.metrics = metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
 		for i := 0; i < 10; i++ {
-			patternsStorage.ProcessIncomingMetric([]byte("cpu.used 12 1234567890"))
+			patternsStorage.ProcessIncomingMetric([]byte("cpu.used 12 1234567890"), "")
 		}
 		So(patternsStorage.metrics.MatchingTimer.Count(), ShouldEqual, 1)
 	})
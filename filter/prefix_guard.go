@@ -0,0 +1,161 @@
+package filter
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/metrics"
+)
+
+// prefixGuardCleanupInterval is an interval to forget metrics which were not received for metric TTL
+const prefixGuardCleanupInterval = time.Minute
+
+// PrefixGuardConfig defines limits of matched metrics grouped by metric name prefix
+type PrefixGuardConfig struct {
+	// Number of first dot-separated metric name parts used as prefix
+	PrefixDepth int
+	// Max count of metric names per prefix, points of new metrics over the limit are dropped. 0 disables limit
+	MaxMetrics int
+	// Max count of points per second per prefix, points over the limit are dropped. 0 disables limit
+	MaxPointsPerSecond int
+	// Metric is not counted in prefix metrics after it was not received for MetricTTL
+	MetricTTL time.Duration
+}
+
+type prefixState struct {
+	metrics      map[string]int64
+	second       int64
+	pointsCount  int
+	lastActivity int64
+}
+
+// PrefixGuard protects storage from prefixes with too many metric names or points
+// and records prefixes which exceeded limits
+type PrefixGuard struct {
+	config        PrefixGuardConfig
+	metrics       *metrics.FilterMetrics
+	prefixes      map[string]*prefixState
+	lastCleanup   int64
+	offenders     map[string]*moira.PrefixOffender
+	offendersLock sync.Mutex
+}
+
+// NewPrefixGuard creates new PrefixGuard
+func NewPrefixGuard(config PrefixGuardConfig, metrics *metrics.FilterMetrics) *PrefixGuard {
+	if config.PrefixDepth < 1 {
+		config.PrefixDepth = 1
+	}
+	return &PrefixGuard{
+		config:    config,
+		metrics:   metrics,
+		prefixes:  make(map[string]*prefixState),
+		offenders: make(map[string]*moira.PrefixOffender),
+	}
+}
+
+// Allow checks if metric point does not exceed limits of metric prefix.
+// Allow is not safe for concurrent use, it is called from single goroutine which prepares metrics to save
+func (guard *PrefixGuard) Allow(metric string) bool {
+	if guard.config.MaxMetrics <= 0 && guard.config.MaxPointsPerSecond <= 0 {
+		return true
+	}
+	return guard.allow(metric, time.Now().Unix())
+}
+
+func (guard *PrefixGuard) allow(metric string, now int64) bool {
+	if now-guard.lastCleanup >= int64(prefixGuardCleanupInterval.Seconds()) {
+		guard.cleanup(now)
+		guard.lastCleanup = now
+	}
+
+	prefix := metricPrefix(metric, guard.config.PrefixDepth)
+	state, ok := guard.prefixes[prefix]
+	if !ok {
+		state = &prefixState{metrics: make(map[string]int64)}
+		guard.prefixes[prefix] = state
+	}
+	state.lastActivity = now
+
+	if guard.config.MaxPointsPerSecond > 0 {
+		if state.second != now {
+			state.second = now
+			state.pointsCount = 0
+		}
+		state.pointsCount++
+		if state.pointsCount > guard.config.MaxPointsPerSecond {
+			guard.metrics.RateDroppedMetrics.Inc()
+			guard.addOffender(prefix, now, func(offender *moira.PrefixOffender) { offender.DroppedByRate++ })
+			return false
+		}
+	}
+
+	if guard.config.MaxMetrics > 0 {
+		if _, ok := state.metrics[metric]; !ok && len(state.metrics) >= guard.config.MaxMetrics {
+			guard.metrics.CardinalityDroppedMetrics.Inc()
+			guard.addOffender(prefix, now, func(offender *moira.PrefixOffender) { offender.DroppedByCardinality++ })
+			return false
+		}
+		state.metrics[metric] = now
+	}
+	return true
+}
+
+// TakeOffenders returns prefixes which exceeded limits since previous call
+func (guard *PrefixGuard) TakeOffenders() []*moira.PrefixOffender {
+	guard.offendersLock.Lock()
+	defer guard.offendersLock.Unlock()
+
+	offenders := make([]*moira.PrefixOffender, 0, len(guard.offenders))
+	for _, offender := range guard.offenders {
+		offenders = append(offenders, offender)
+	}
+	guard.offenders = make(map[string]*moira.PrefixOffender)
+	return offenders
+}
+
+func (guard *PrefixGuard) addOffender(prefix string, now int64, update func(offender *moira.PrefixOffender)) {
+	guard.offendersLock.Lock()
+	defer guard.offendersLock.Unlock()
+
+	offender, ok := guard.offenders[prefix]
+	if !ok {
+		offender = &moira.PrefixOffender{Prefix: prefix}
+		guard.offenders[prefix] = offender
+	}
+	offender.Timestamp = now
+	update(offender)
+}
+
+// cleanup forgets metrics which were not received for metric TTL and inactive prefixes
+func (guard *PrefixGuard) cleanup(now int64) {
+	expiration := now - int64(guard.config.MetricTTL.Seconds())
+	for prefix, state := range guard.prefixes {
+		for metric, lastSeen := range state.metrics {
+			if lastSeen < expiration {
+				delete(state.metrics, metric)
+			}
+		}
+		if len(state.metrics) == 0 && state.lastActivity < now-int64(prefixGuardCleanupInterval.Seconds()) {
+			delete(guard.prefixes, prefix)
+		}
+	}
+}
+
+// metricPrefix returns first depth dot-separated parts of metric name without tags
+func metricPrefix(metric string, depth int) string {
+	name := metric
+	if index := strings.IndexByte(name, ';'); index != -1 {
+		name = name[:index]
+	}
+	position := 0
+	for i := 0; i < depth; i++ {
+		index := strings.IndexByte(name[position:], '.')
+		if index == -1 {
+			return name
+		}
+		position += index + 1
+	}
+	return name[:position-1]
+}
@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/metrics"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetricPrefix(t *testing.T) {
+	Convey("Test metric prefix", t, func() {
+		So(metricPrefix("first.second.third", 1), ShouldEqual, "first")
+		So(metricPrefix("first.second.third", 2), ShouldEqual, "first.second")
+		So(metricPrefix("first.second.third", 3), ShouldEqual, "first.second.third")
+		So(metricPrefix("first.second.third", 5), ShouldEqual, "first.second.third")
+		So(metricPrefix("first.second;tag=a.b.c", 3), ShouldEqual, "first.second")
+	})
+}
+
+func TestPrefixGuard(t *testing.T) {
+	Convey("Guard without limits should allow everything", t, func() {
+		guard := NewPrefixGuard(PrefixGuardConfig{PrefixDepth: 1}, metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry()))
+		for i := 0; i < 100; i++ {
+			So(guard.Allow("first.metric"), ShouldBeTrue)
+		}
+		So(guard.TakeOffenders(), ShouldBeEmpty)
+	})
+
+	Convey("Guard should drop points of new metrics over limit", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		guard := NewPrefixGuard(PrefixGuardConfig{PrefixDepth: 2, MaxMetrics: 2, MetricTTL: time.Hour}, filterMetrics)
+		So(guard.allow("service.one.first", 100), ShouldBeTrue)
+		So(guard.allow("service.one.second", 100), ShouldBeTrue)
+		So(guard.allow("service.one.third", 101), ShouldBeFalse)
+		So(guard.allow("service.one.third;tag=value", 102), ShouldBeFalse)
+		So(guard.allow("service.one.first", 103), ShouldBeTrue)
+		So(guard.allow("service.two.first", 103), ShouldBeTrue)
+		So(filterMetrics.CardinalityDroppedMetrics.Count(), ShouldEqual, 2)
+		So(guard.TakeOffenders(), ShouldResemble, []*moira.PrefixOffender{
+			{Prefix: "service.one", DroppedByCardinality: 2, Timestamp: 102},
+		})
+		So(guard.TakeOffenders(), ShouldBeEmpty)
+
+		Convey("Metrics should be forgotten after metric TTL", func() {
+			So(guard.allow("service.one.third", 3700), ShouldBeFalse)
+			So(guard.allow("service.one.third", 3760), ShouldBeTrue)
+		})
+	})
+
+	Convey("Guard should drop points over rate", t, func() {
+		filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+		guard := NewPrefixGuard(PrefixGuardConfig{PrefixDepth: 1, MaxPointsPerSecond: 2}, filterMetrics)
+		So(guard.allow("service.first", 100), ShouldBeTrue)
+		So(guard.allow("service.second", 100), ShouldBeTrue)
+		So(guard.allow("service.first", 100), ShouldBeFalse)
+		So(guard.allow("other.first", 100), ShouldBeTrue)
+		So(guard.allow("service.first", 101), ShouldBeTrue)
+		So(filterMetrics.RateDroppedMetrics.Count(), ShouldEqual, 1)
+		So(guard.TakeOffenders(), ShouldResemble, []*moira.PrefixOffender{
+			{Prefix: "service", DroppedByRate: 1, Timestamp: 100},
+		})
+	})
+}
@@ -0,0 +1,129 @@
+package filter
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// rejectedLinesSavePeriod is a period to save recorded rejected lines
+const rejectedLinesSavePeriod = time.Second
+
+// maxRejectedLineLength is a max length of recorded line, longer lines are truncated
+const maxRejectedLineLength = 1024
+
+// RejectReasonUnknown is a reason of rejection by error which has no reason
+const RejectReasonUnknown = "unknown"
+
+// RejectedLinesConfig defines how many rejected lines are recorded
+type RejectedLinesConfig struct {
+	// Every SampleRate-th rejected line of each reason is recorded
+	SampleRate int
+	// Max count of recorded lines per reason, older lines are removed
+	MaxLines int
+}
+
+// RejectedLinesRecorder keeps sampled ring of recently rejected lines per reason in database
+type RejectedLinesRecorder struct {
+	database moira.Database
+	logger   moira.Logger
+	config   RejectedLinesConfig
+	counts   map[string]int
+	pending  map[string][]*moira.RejectedLine
+	lock     sync.Mutex
+	tomb     tomb.Tomb
+}
+
+// NewRejectedLinesRecorder creates new RejectedLinesRecorder
+func NewRejectedLinesRecorder(database moira.Database, logger moira.Logger, config RejectedLinesConfig) *RejectedLinesRecorder {
+	if config.SampleRate < 1 {
+		config.SampleRate = 1
+	}
+	return &RejectedLinesRecorder{
+		database: database,
+		logger:   logger,
+		config:   config,
+		counts:   make(map[string]int),
+		pending:  make(map[string][]*moira.RejectedLine),
+	}
+}
+
+// Record records line rejected by error if line is sampled
+func (recorder *RejectedLinesRecorder) Record(line []byte, source string, err error) {
+	if recorder == nil || recorder.config.MaxLines <= 0 {
+		return
+	}
+	reason := RejectReasonUnknown
+	if parseError, ok := err.(*ParseError); ok {
+		reason = parseError.Reason
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	count := recorder.counts[reason]
+	recorder.counts[reason] = (count + 1) % recorder.config.SampleRate
+	if count != 0 {
+		return
+	}
+	if len(line) > maxRejectedLineLength {
+		line = line[:maxRejectedLineLength]
+	}
+	lines := append(recorder.pending[reason], &moira.RejectedLine{
+		Line:      string(line),
+		Reason:    reason,
+		Error:     err.Error(),
+		Source:    source,
+		Timestamp: time.Now().Unix(),
+	})
+	if len(lines) > recorder.config.MaxLines {
+		lines = lines[len(lines)-recorder.config.MaxLines:]
+	}
+	recorder.pending[reason] = lines
+}
+
+// Start starts process to save recorded lines to database
+func (recorder *RejectedLinesRecorder) Start() {
+	recorder.tomb.Go(func() error {
+		checkTicker := time.NewTicker(rejectedLinesSavePeriod)
+		defer checkTicker.Stop()
+		for {
+			select {
+			case <-recorder.tomb.Dying():
+				recorder.save()
+				recorder.logger.Info("Moira Filter Rejected Lines Recorder stopped")
+				return nil
+			case <-checkTicker.C:
+				recorder.save()
+			}
+		}
+	})
+	recorder.logger.Info("Moira Filter Rejected Lines Recorder started")
+}
+
+// Stop saves remaining recorded lines and stops recorder
+func (recorder *RejectedLinesRecorder) Stop() error {
+	recorder.tomb.Kill(nil)
+	return recorder.tomb.Wait()
+}
+
+func (recorder *RejectedLinesRecorder) save() {
+	recorder.lock.Lock()
+	pending := recorder.pending
+	recorder.pending = make(map[string][]*moira.RejectedLine)
+	recorder.lock.Unlock()
+
+	lines := make([]*moira.RejectedLine, 0)
+	for _, reasonLines := range pending {
+		lines = append(lines, reasonLines...)
+	}
+	if len(lines) == 0 {
+		return
+	}
+	if err := recorder.database.AddRejectedLines(lines, int64(recorder.config.MaxLines)); err != nil {
+		recorder.logger.Errorf("Failed to save rejected lines: %s", err.Error())
+	}
+}
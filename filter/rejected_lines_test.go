@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRejectedLinesRecorder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("RejectedLines")
+
+	Convey("Nil or disabled recorder should ignore lines", t, func() {
+		var recorder *RejectedLinesRecorder
+		recorder.Record([]byte("metric"), "127.0.0.1:1234", fmt.Errorf("error"))
+
+		recorder = NewRejectedLinesRecorder(database, logger, RejectedLinesConfig{SampleRate: 1})
+		recorder.Record([]byte("metric"), "127.0.0.1:1234", fmt.Errorf("error"))
+		So(recorder.pending, ShouldBeEmpty)
+	})
+
+	Convey("Every sampled line should be recorded with its reason", t, func() {
+		recorder := NewRejectedLinesRecorder(database, logger, RejectedLinesConfig{SampleRate: 2, MaxLines: 10})
+		for i := 0; i < 5; i++ {
+			line := fmt.Sprintf("metric.%d 1", i)
+			_, err := ParseMetric([]byte(line))
+			recorder.Record([]byte(line), "127.0.0.1:1234", err)
+		}
+		recorder.Record([]byte("metric"), "127.0.0.1:1235", fmt.Errorf("error"))
+
+		So(recorder.pending[ParseErrorItemsCount], ShouldHaveLength, 3)
+		recorded := recorder.pending[ParseErrorItemsCount][1]
+		So(recorded.Line, ShouldEqual, "metric.2 1")
+		So(recorded.Reason, ShouldEqual, ParseErrorItemsCount)
+		So(recorded.Error, ShouldEqual, "too few space-separated items: 'metric.2 1'")
+		So(recorded.Source, ShouldEqual, "127.0.0.1:1234")
+		So(recorder.pending[RejectReasonUnknown], ShouldHaveLength, 1)
+		So(recorder.pending[RejectReasonUnknown][0].Source, ShouldEqual, "127.0.0.1:1235")
+	})
+
+	Convey("Only max lines latest lines should be kept and long lines should be truncated", t, func() {
+		recorder := NewRejectedLinesRecorder(database, logger, RejectedLinesConfig{SampleRate: 1, MaxLines: 2})
+		for i := 0; i < 3; i++ {
+			recorder.Record([]byte(fmt.Sprintf("line.%d", i)), "", fmt.Errorf("error"))
+		}
+		recorder.Record([]byte(strings.Repeat("a", maxRejectedLineLength+1)), "", newParseError(ParseErrorInvalidName, "error"))
+
+		lines := recorder.pending[RejectReasonUnknown]
+		So(lines, ShouldHaveLength, 2)
+		So(lines[0].Line, ShouldEqual, "line.1")
+		So(lines[1].Line, ShouldEqual, "line.2")
+		So(recorder.pending[ParseErrorInvalidName][0].Line, ShouldHaveLength, maxRejectedLineLength)
+	})
+
+	Convey("Recorded lines should be saved to database", t, func() {
+		recorder := NewRejectedLinesRecorder(database, logger, RejectedLinesConfig{SampleRate: 1, MaxLines: 2})
+		recorder.Record([]byte("line"), "127.0.0.1:1234", fmt.Errorf("error"))
+		line := *recorder.pending[RejectReasonUnknown][0]
+		database.EXPECT().AddRejectedLines([]*moira.RejectedLine{&line}, int64(2)).Return(nil)
+		recorder.save()
+		So(recorder.pending, ShouldBeEmpty)
+
+		Convey("Nothing should be saved without recorded lines", func() {
+			recorder.save()
+		})
+	})
+}
@@ -0,0 +1,195 @@
+package filter
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/moira-alert/moira/metrics"
+)
+
+// RewriteAction defines what rewrite rule does with matched metric
+type RewriteAction string
+
+// Supported rewrite rule actions
+const (
+	// RewriteRename replaces metric name by regex replacement
+	RewriteRename RewriteAction = "rename"
+	// RewriteDrop drops metrics with matched name
+	RewriteDrop RewriteAction = "drop"
+	// RewriteKeep drops metrics with not matched name
+	RewriteKeep RewriteAction = "keep"
+	// RewriteAddTag sets tag to given value
+	RewriteAddTag RewriteAction = "add_tag"
+	// RewriteRemoveTag removes tag
+	RewriteRemoveTag RewriteAction = "remove_tag"
+)
+
+var validRewriteRuleName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// RewriteRuleConfig is a single rule of rewrite rules file
+type RewriteRuleConfig struct {
+	// Rule name used in hit counter metric name, rule index is used by default
+	Name string `yaml:"name"`
+	// One of rename, drop, keep, add_tag and remove_tag
+	Action RewriteAction `yaml:"action"`
+	// Regular expression to match metric name. Required for rename, drop and keep,
+	// add_tag and remove_tag rules without match are applied to every metric
+	Match string `yaml:"match"`
+	// Replacement of matched name for rename rule, supports $1 style references to regex groups
+	Replacement string `yaml:"replacement"`
+	// Tag name for add_tag and remove_tag rules
+	Tag string `yaml:"tag"`
+	// Tag value for add_tag rule
+	Value string `yaml:"value"`
+}
+
+type rewriteRule struct {
+	action      RewriteAction
+	match       *regexp.Regexp
+	replacement string
+	tag         string
+	value       string
+	hits        metrics.Counter
+}
+
+// RewriteRules is an ordered chain of rules which rename, drop or retag metrics before pattern matching
+type RewriteRules struct {
+	rules []rewriteRule
+}
+
+// ParseRewriteRules reads yaml list of rewrite rules
+func ParseRewriteRules(reader io.Reader) ([]RewriteRuleConfig, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]RewriteRuleConfig, 0)
+	if err := yaml.UnmarshalStrict(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid rewrite rules: %s", err.Error())
+	}
+	return configs, nil
+}
+
+// NewRewriteRules validates rules and creates rules chain, every rule hit is counted by metric named by rule name
+func NewRewriteRules(configs []RewriteRuleConfig, filterMetrics *metrics.FilterMetrics) (*RewriteRules, error) {
+	rules := &RewriteRules{rules: make([]rewriteRule, 0, len(configs))}
+	names := make(map[string]bool, len(configs))
+	for i, config := range configs {
+		name := config.Name
+		if name == "" {
+			name = fmt.Sprintf("rule_%d", i)
+		}
+		if !validRewriteRuleName.MatchString(name) {
+			return nil, fmt.Errorf("invalid rewrite rule name '%s': only letters, digits, '_' and '-' are allowed", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate rewrite rule name '%s'", name)
+		}
+		names[name] = true
+
+		rule, err := newRewriteRule(config)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rewrite rule '%s': %s", name, err.Error())
+		}
+		rule.hits = filterMetrics.GetRewriteRuleHits(name)
+		rules.rules = append(rules.rules, rule)
+	}
+	return rules, nil
+}
+
+func newRewriteRule(config RewriteRuleConfig) (rewriteRule, error) {
+	rule := rewriteRule{
+		action:      config.Action,
+		replacement: config.Replacement,
+		tag:         config.Tag,
+		value:       config.Value,
+	}
+	if config.Match != "" {
+		match, err := regexp.Compile(config.Match)
+		if err != nil {
+			return rule, fmt.Errorf("invalid match: %s", err.Error())
+		}
+		rule.match = match
+	}
+
+	switch config.Action {
+	case RewriteRename, RewriteDrop, RewriteKeep:
+		if rule.match == nil {
+			return rule, fmt.Errorf("match is required for %s action", config.Action)
+		}
+	case RewriteAddTag, RewriteRemoveTag:
+		if config.Tag == "" || strings.ContainsAny(config.Tag, ";=") {
+			return rule, fmt.Errorf("tag must be non-empty and must not contain ';' or '='")
+		}
+		if strings.Contains(config.Value, ";") {
+			return rule, fmt.Errorf("tag value must not contain ';'")
+		}
+		if config.Action == RewriteAddTag && config.Value == "" {
+			return rule, fmt.Errorf("value is required for %s action", config.Action)
+		}
+	default:
+		return rule, fmt.Errorf("unknown action '%s'", config.Action)
+	}
+	return rule, nil
+}
+
+// Apply applies rules to metric in order. Returns nil if metric is dropped by rules
+// or error if rewritten metric is not valid. Metric is returned as is if no rule changes it
+func (rules *RewriteRules) Apply(metric *ParsedMetric) (*ParsedMetric, error) {
+	if rules == nil || len(rules.rules) == 0 {
+		return metric, nil
+	}
+
+	name := metric.Name
+	var labels map[string]string
+	changed := false
+	for _, rule := range rules.rules {
+		matched := rule.match == nil || rule.match.MatchString(name)
+		switch rule.action {
+		case RewriteDrop:
+			if matched {
+				rule.hits.Inc()
+				return nil, nil
+			}
+			continue
+		case RewriteKeep:
+			if !matched {
+				rule.hits.Inc()
+				return nil, nil
+			}
+			continue
+		}
+		if !matched {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string, len(metric.Labels)+1)
+			for labelName, labelValue := range metric.Labels {
+				labels[labelName] = labelValue
+			}
+		}
+		switch rule.action {
+		case RewriteRename:
+			name = rule.match.ReplaceAllString(name, rule.replacement)
+		case RewriteAddTag:
+			labels[rule.tag] = rule.value
+		case RewriteRemoveTag:
+			if _, ok := labels[rule.tag]; !ok {
+				continue
+			}
+			delete(labels, rule.tag)
+		}
+		rule.hits.Inc()
+		changed = true
+	}
+
+	if !changed {
+		return metric, nil
+	}
+	return NewParsedMetricWithLabels(name, labels, metric.Value, metric.Timestamp)
+}
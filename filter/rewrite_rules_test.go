@@ -0,0 +1,126 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moira-alert/moira/metrics"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testRewriteRules = `
+- name: rename_old_tree
+  action: rename
+  match: ^old\.(.*)$
+  replacement: new.$1
+- name: drop_noisy
+  action: drop
+  match: ^new\.noisy\.
+- name: keep_known
+  action: keep
+  match: ^(new|cpu)\.
+- name: add_env
+  action: add_tag
+  match: ^cpu\.
+  tag: env
+  value: prod
+- action: remove_tag
+  tag: host
+`
+
+func TestParseRewriteRules(t *testing.T) {
+	filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+
+	Convey("Test good rewrite rules", t, func() {
+		configs, err := ParseRewriteRules(strings.NewReader(testRewriteRules))
+		So(err, ShouldBeNil)
+		So(configs, ShouldHaveLength, 5)
+		So(configs[0], ShouldResemble, RewriteRuleConfig{Name: "rename_old_tree", Action: RewriteRename, Match: `^old\.(.*)$`, Replacement: "new.$1"})
+
+		rules, err := NewRewriteRules(configs, filterMetrics)
+		So(err, ShouldBeNil)
+		So(rules.rules, ShouldHaveLength, 5)
+	})
+
+	Convey("Test bad rewrite rules", t, func() {
+		_, err := ParseRewriteRules(strings.NewReader("- action: drop\n  unknown: field"))
+		So(err, ShouldNotBeNil)
+
+		badRules := [][]RewriteRuleConfig{
+			{{Action: "replace", Match: ".*"}},
+			{{Action: RewriteDrop}},
+			{{Action: RewriteRename, Match: "("}},
+			{{Action: RewriteAddTag, Tag: "env"}},
+			{{Action: RewriteAddTag, Tag: "env=", Value: "prod"}},
+			{{Action: RewriteAddTag, Tag: "env", Value: "prod;dev"}},
+			{{Action: RewriteRemoveTag}},
+			{{Name: "bad.name", Action: RewriteDrop, Match: ".*"}},
+			{{Name: "same", Action: RewriteDrop, Match: "a"}, {Name: "same", Action: RewriteDrop, Match: "b"}},
+		}
+		for _, badRule := range badRules {
+			_, err := NewRewriteRules(badRule, filterMetrics)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestRewriteRules(t *testing.T) {
+	filterMetrics := metrics.ConfigureFilterMetrics(metrics.NewDummyRegistry())
+	configs, _ := ParseRewriteRules(strings.NewReader(testRewriteRules))
+	rules, _ := NewRewriteRules(configs, filterMetrics)
+
+	apply := func(metric string) *ParsedMetric {
+		parsedMetric, err := ParseMetric([]byte(metric + " 12 1234567890"))
+		So(err, ShouldBeNil)
+		rewritten, err := rules.Apply(parsedMetric)
+		So(err, ShouldBeNil)
+		return rewritten
+	}
+
+	Convey("Test metric is renamed and tags are changed", t, func() {
+		rewritten := apply("old.disk.used;host=server01;dc=eu")
+		So(rewritten.Metric, ShouldEqual, "new.disk.used;dc=eu")
+		So(rewritten.Name, ShouldEqual, "new.disk.used")
+		So(rewritten.Labels, ShouldResemble, map[string]string{"dc": "eu"})
+		So(rewritten.Value, ShouldEqual, 12)
+		So(rewritten.Timestamp, ShouldEqual, 1234567890)
+
+		rewritten = apply("cpu.used")
+		So(rewritten.Metric, ShouldEqual, "cpu.used;env=prod")
+	})
+
+	Convey("Test not changed metric is returned as is", t, func() {
+		parsedMetric, _ := ParseMetric([]byte("new.disk.used 12 1234567890"))
+		rewritten, err := rules.Apply(parsedMetric)
+		So(err, ShouldBeNil)
+		So(rewritten, ShouldEqual, parsedMetric)
+	})
+
+	Convey("Test metrics are dropped", t, func() {
+		So(apply("old.noisy.metric"), ShouldBeNil)
+		So(apply("unknown.metric"), ShouldBeNil)
+	})
+
+	Convey("Test rule hits are counted", t, func() {
+		So(filterMetrics.GetRewriteRuleHits("rename_old_tree").Count(), ShouldEqual, 2)
+		So(filterMetrics.GetRewriteRuleHits("drop_noisy").Count(), ShouldEqual, 1)
+		So(filterMetrics.GetRewriteRuleHits("keep_known").Count(), ShouldEqual, 1)
+		So(filterMetrics.GetRewriteRuleHits("add_env").Count(), ShouldEqual, 1)
+		So(filterMetrics.GetRewriteRuleHits("rule_4").Count(), ShouldEqual, 1)
+	})
+
+	Convey("Test invalid rewritten metric returns error", t, func() {
+		invalidRules, _ := NewRewriteRules([]RewriteRuleConfig{{Action: RewriteRename, Match: ".*", Replacement: ""}}, filterMetrics)
+		parsedMetric, _ := ParseMetric([]byte("cpu.used 12 1234567890"))
+		_, err := invalidRules.Apply(parsedMetric)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Test nil rules do not change metric", t, func() {
+		parsedMetric, _ := ParseMetric([]byte("cpu.used 12 1234567890"))
+		var nilRules *RewriteRules
+		rewritten, err := nilRules.Apply(parsedMetric)
+		So(err, ShouldBeNil)
+		So(rewritten, ShouldEqual, parsedMetric)
+	})
+}
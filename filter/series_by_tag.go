@@ -114,42 +114,107 @@ func createMatcher(spec TagSpec) func(string, map[string]string) bool {
 	}
 }
 
-// SeriesByTagPatternIndex helps to index the seriesByTag patterns and allows to match them by metric
+// seriesByTagPattern is a seriesByTag pattern with matchers of its tag specs
+type seriesByTagPattern struct {
+	pattern  string
+	matchers []func(string, map[string]string) bool
+}
+
+func (pattern *seriesByTagPattern) match(name string, labels map[string]string) bool {
+	for _, matcher := range pattern.matchers {
+		if !matcher(name, labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// SeriesByTagPatternIndex helps to index the seriesByTag patterns and allows to match them by metric.
+// Patterns with equality spec are indexed by tag name and value of the first such spec,
+// other patterns are indexed by tag name which must be present in metric if possible,
+// so other specs are checked only for patterns which can match metric
 type SeriesByTagPatternIndex struct {
-	patternToMatcher map[string]func(string, map[string]string) bool
+	tagValueToPatterns map[string]map[string][]*seriesByTagPattern
+	tagToPatterns      map[string][]*seriesByTagPattern
+	unindexedPatterns  []*seriesByTagPattern
 }
 
 // NewSeriesByTagPatternIndex creates new SeriesByTagPatternIndex using seriesByTag patterns and parsed specs comes from ParseSeriesByTag
 func NewSeriesByTagPatternIndex(tagSpecsByPattern map[string][]TagSpec) *SeriesByTagPatternIndex {
-	patternToMatcher := make(map[string]func(string, map[string]string) bool)
+	index := &SeriesByTagPatternIndex{
+		tagValueToPatterns: make(map[string]map[string][]*seriesByTagPattern),
+		tagToPatterns:      make(map[string][]*seriesByTagPattern),
+		unindexedPatterns:  make([]*seriesByTagPattern, 0),
+	}
 	for pattern, tagSpecs := range tagSpecsByPattern {
-		matchers := make([]func(string, map[string]string) bool, 0)
-		for _, tagSpec := range tagSpecs {
-			matchers = append(matchers, createMatcher(tagSpec))
-		}
+		index.add(pattern, tagSpecs)
+	}
+	return index
+}
 
-		patternToMatcher[pattern] = func(metric string, labels map[string]string) bool {
-			for _, matcher := range matchers {
-				if !matcher(metric, labels) {
-					return false
-				}
-			}
-			return true
+func (index *SeriesByTagPatternIndex) add(pattern string, tagSpecs []TagSpec) {
+	indexedPattern := &seriesByTagPattern{
+		pattern:  pattern,
+		matchers: make([]func(string, map[string]string) bool, 0, len(tagSpecs)),
+	}
+
+	equalitySpec := -1
+	requiredTag := ""
+	for i, tagSpec := range tagSpecs {
+		if equalitySpec == -1 && tagSpec.Operator == EqualOperator && tagSpec.Value != "" {
+			// Metric matches equality spec only if it has exactly this tag value, so spec is checked by index
+			equalitySpec = i
+			continue
+		}
+		matcher := createMatcher(tagSpec)
+		if requiredTag == "" && tagSpec.Name != "name" && !matcher("", nil) {
+			requiredTag = tagSpec.Name
 		}
+		indexedPattern.matchers = append(indexedPattern.matchers, matcher)
 	}
 
-	return &SeriesByTagPatternIndex{patternToMatcher: patternToMatcher}
+	switch {
+	case equalitySpec != -1:
+		tagSpec := tagSpecs[equalitySpec]
+		valueToPatterns, ok := index.tagValueToPatterns[tagSpec.Name]
+		if !ok {
+			valueToPatterns = make(map[string][]*seriesByTagPattern)
+			index.tagValueToPatterns[tagSpec.Name] = valueToPatterns
+		}
+		valueToPatterns[tagSpec.Value] = append(valueToPatterns[tagSpec.Value], indexedPattern)
+	case requiredTag != "":
+		index.tagToPatterns[requiredTag] = append(index.tagToPatterns[requiredTag], indexedPattern)
+	default:
+		index.unindexedPatterns = append(index.unindexedPatterns, indexedPattern)
+	}
 }
 
 // MatchPatterns allows to match patterns by metric name and its labels
 func (index *SeriesByTagPatternIndex) MatchPatterns(name string, labels map[string]string) []string {
 	matchedPatterns := make([]string, 0)
 
-	for pattern, matcher := range index.patternToMatcher {
-		if matcher(name, labels) {
-			matchedPatterns = append(matchedPatterns, pattern)
+	matchedPatterns = appendMatchedPatterns(matchedPatterns, index.unindexedPatterns, name, labels)
+	if valueToPatterns, ok := index.tagValueToPatterns["name"]; ok {
+		matchedPatterns = appendMatchedPatterns(matchedPatterns, valueToPatterns[name], name, labels)
+	}
+	for tag, value := range labels {
+		if tag == "name" {
+			continue
 		}
+		if valueToPatterns, ok := index.tagValueToPatterns[tag]; ok {
+			matchedPatterns = appendMatchedPatterns(matchedPatterns, valueToPatterns[value], name, labels)
+		}
+		matchedPatterns = appendMatchedPatterns(matchedPatterns, index.tagToPatterns[tag], name, labels)
 	}
 
 	return matchedPatterns
 }
+
+func appendMatchedPatterns(matchedPatterns []string, candidates []*seriesByTagPattern, name string, labels map[string]string) []string {
+	for _, candidate := range candidates {
+		if candidate.match(name, labels) {
+			matchedPatterns = append(matchedPatterns, candidate.pattern)
+		}
+	}
+	return matchedPatterns
+}
@@ -92,6 +92,38 @@ func TestSeriesByTagPatternIndex(t *testing.T) {
 			{"cpu1", map[string]string{"machine": "machine"}, []string{"name=cpu1", "name~=cpu", "name~=cpu;dc=", "name~=cpu;dc~="}},
 		}
 
+		index := NewSeriesByTagPatternIndex(tagSpecsByPattern)
+		for _, testCase := range testCases {
+			patterns := index.MatchPatterns(testCase.Name, testCase.Labels)
+			sort.Strings(patterns)
+			c.So(patterns, ShouldResemble, testCase.MatchedPatterns)
+		}
+	})
+	Convey("Given patterns with several tag specs, should match patterns only if all specs match", t, func(c C) {
+		tagSpecsByPattern := map[string][]TagSpec{
+			"dc=ru1;host=web1":        {{"dc", EqualOperator, "ru1"}, {"host", EqualOperator, "web1"}},
+			"dc=ru1;host~=web":        {{"dc", EqualOperator, "ru1"}, {"host", MatchOperator, "web"}},
+			"host~=web;dc=ru1":        {{"host", MatchOperator, "web"}, {"dc", EqualOperator, "ru1"}},
+			"host~=db;dc!=ru1":        {{"host", MatchOperator, "db"}, {"dc", NotEqualOperator, "ru1"}},
+			"name~=cpu;host!~=web":    {{"name", MatchOperator, "cpu"}, {"host", NotMatchOperator, "web"}},
+			"name=cpu;dc=;host=~.*":   {{"name", EqualOperator, "cpu"}, {"dc", EqualOperator, ""}, {"host", MatchOperator, ".*"}},
+			"dc=ru1;dc=ru2":           {{"dc", EqualOperator, "ru1"}, {"dc", EqualOperator, "ru2"}},
+			"name=disk;dc=ru1;host!=": {{"name", EqualOperator, "disk"}, {"dc", EqualOperator, "ru1"}, {"host", NotEqualOperator, ""}},
+		}
+		testCases := []struct {
+			Name            string
+			Labels          map[string]string
+			MatchedPatterns []string
+		}{
+			{"cpu", map[string]string{}, []string{"name=cpu;dc=;host=~.*"}},
+			{"cpu", map[string]string{"dc": "ru1"}, []string{}},
+			{"cpu", map[string]string{"host": "db1"}, []string{"name=cpu;dc=;host=~.*", "name~=cpu;host!~=web"}},
+			{"cpu", map[string]string{"dc": "ru1", "host": "web1"}, []string{"dc=ru1;host=web1", "dc=ru1;host~=web", "host~=web;dc=ru1"}},
+			{"cpu", map[string]string{"dc": "ru2", "host": "db1"}, []string{"host~=db;dc!=ru1", "name~=cpu;host!~=web"}},
+			{"disk", map[string]string{"dc": "ru1", "host": "web2"}, []string{"dc=ru1;host~=web", "host~=web;dc=ru1", "name=disk;dc=ru1;host!="}},
+			{"disk", map[string]string{"dc": "ru1"}, []string{}},
+		}
+
 		index := NewSeriesByTagPatternIndex(tagSpecsByPattern)
 		for _, testCase := range testCases {
 			patterns := index.MatchPatterns(testCase.Name, testCase.Labels)
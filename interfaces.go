@@ -16,7 +16,7 @@ type Database interface {
 	UpdateMetricsHeartbeat() error
 	GetMetricsUpdatesCount() (int64, error)
 	GetChecksUpdatesCount() (int64, error)
-	GetRemoteChecksUpdatesCount() (int64, error)
+	GetRemoteChecksUpdatesCount(source string) (int64, error)
 	GetNotifierState() (string, error)
 	SetNotifierState(string) error
 
@@ -27,14 +27,20 @@ type Database interface {
 
 	// LastCheck storing
 	GetTriggerLastCheck(triggerID string) (CheckData, error)
-	SetTriggerLastCheck(triggerID string, checkData *CheckData, isRemote bool) error
+	SetTriggerLastCheck(triggerID string, checkData *CheckData, source string) error
 	RemoveTriggerLastCheck(triggerID string) error
 	SetTriggerCheckMaintenance(triggerID string, metrics map[string]int64, triggerMaintenance *int64, userLogin string, timeCallMaintenance int64) error
+	SetTriggerCheckAck(triggerID string, metrics []string, ack *AckData) error
+
+	// CheckDiagnostics storing
+	SaveTriggerCheckDiagnostics(triggerID string, diagnostics *CheckDiagnostics, maxCount int, ttl time.Duration) error
+	GetTriggerCheckDiagnostics(triggerID string) ([]*CheckDiagnostics, error)
 
 	// Trigger storing
 	GetLocalTriggerIDs() ([]string, error)
 	GetAllTriggerIDs() ([]string, error)
-	GetRemoteTriggerIDs() ([]string, error)
+	GetRemoteTriggerIDs(source string) ([]string, error)
+	GetTriggersCheckSettings() (map[string]TriggerCheckSettings, error)
 	GetTrigger(triggerID string) (Trigger, error)
 	GetTriggers(triggerIDs []string) ([]*Trigger, error)
 	GetTriggerChecks(triggerIDs []string) ([]*TriggerCheck, error)
@@ -49,7 +55,9 @@ type Database interface {
 
 	// Throttling
 	GetTriggerThrottling(triggerID string) (time.Time, time.Time)
-	SetTriggerThrottling(triggerID string, next time.Time) error
+	GetTriggerPolicyThrottling(triggerID, policy string) (time.Time, time.Time)
+	SetTriggerThrottling(triggerID, policy string, next time.Time, reason string) error
+	GetTriggerThrottlingReason(triggerID string) (string, error)
 	DeleteTriggerThrottling(triggerID string) error
 
 	// NotificationEvent storing
@@ -84,6 +92,16 @@ type Database interface {
 	AddNotification(notification *ScheduledNotification) error
 	AddNotifications(notification []*ScheduledNotification, timestamp int64) error
 
+	// Escalations storing
+	AddEscalation(escalation *EscalationData) (bool, error)
+	IsEscalationActive(escalation *EscalationData) (bool, error)
+	RemoveEscalation(escalation *EscalationData) error
+	RemoveEscalations(triggerID string, metrics ...string) error
+
+	// Notification delivery history storing
+	AddNotificationHistory(item *NotificationHistoryItem, ttl time.Duration) error
+	GetNotificationHistory(contactID, triggerID string, from, to, page, size int64) ([]*NotificationHistoryItem, int64, error)
+
 	// Patterns and metrics storing
 	GetPatterns() ([]string, error)
 	AddPatternMetric(pattern, metric string) error
@@ -100,13 +118,21 @@ type Database interface {
 	RemoveMetricsValues(metrics []string, toTime int64) error
 	GetMetricsTTLSeconds() int64
 
-	AddLocalTriggersToCheck(triggerIDs []string) error
+	// Metric prefixes which exceeded filter limits
+	AddPrefixOffenders(offenders []*PrefixOffender) error
+	GetPrefixOffenders(count int64) ([]*PrefixOffender, error)
+
+	// Metric lines rejected by filter
+	AddRejectedLines(lines []*RejectedLine, maxCount int64) error
+	GetRejectedLines(reason string) ([]*RejectedLine, error)
+
+	AddLocalTriggersToCheck(triggerIDs []string, priority TriggerPriority) error
 	GetLocalTriggersToCheck(count int) ([]string, error)
 	GetLocalTriggersToCheckCount() (int64, error)
 
-	AddRemoteTriggersToCheck(triggerIDs []string) error
-	GetRemoteTriggersToCheck(count int) ([]string, error)
-	GetRemoteTriggersToCheckCount() (int64, error)
+	AddRemoteTriggersToCheck(source string, triggerIDs []string, priority TriggerPriority) error
+	GetRemoteTriggersToCheck(source string, count int) ([]string, error)
+	GetRemoteTriggersToCheckCount(source string) (int64, error)
 
 	// TriggerCheckLock storing
 	AcquireTriggerCheckLock(triggerID string, timeout int) error
@@ -157,6 +183,11 @@ type Sender interface {
 	Init(senderSettings map[string]string, logger Logger, location *time.Location, dateTimeFormat string) error
 }
 
+// DigestSender is implemented by senders which can render events of several triggers in one message
+type DigestSender interface {
+	SendDigest(events NotificationEvents, contact ContactData, triggers map[string]TriggerData, throttled bool) error
+}
+
 // ImageStore is the interface for image storage providers
 type ImageStore interface {
 	StoreImage(image []byte) (string, error)
@@ -0,0 +1,28 @@
+package prometheus
+
+import "time"
+
+// Config represents config of Prometheus-compatible HTTP API
+type Config struct {
+	URL        string
+	MetricsTTL time.Duration
+	Timeout    time.Duration
+	Step       time.Duration
+	User       string
+	Password   string
+	Enabled    bool
+}
+
+// isEnabled checks that prometheus config is enabled (url is defined and enabled flag is set)
+func (c *Config) isEnabled() bool {
+	return c.Enabled && c.URL != ""
+}
+
+// getStep returns query resolution step in seconds, one minute is used if step is not configured
+func (c *Config) getStep() int64 {
+	step := int64(c.Step.Seconds())
+	if step <= 0 {
+		return 60 //nolint
+	}
+	return step
+}
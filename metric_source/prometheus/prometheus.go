@@ -0,0 +1,105 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moira-alert/moira"
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/remote"
+)
+
+// ErrPrometheusStorageDisabled is used to prevent prometheus.Fetch calls when prometheus storage is disabled
+var ErrPrometheusStorageDisabled = fmt.Errorf("prometheus storage is not enabled")
+
+// ErrInvalidQuery is used when Prometheus API rejects target as invalid PromQL query
+type ErrInvalidQuery struct {
+	Target  string
+	Message string
+}
+
+// Error is a representation of Error interface method
+func (err ErrInvalidQuery) Error() string {
+	return fmt.Sprintf("invalid PromQL query '%s': %s", err.Target, err.Message)
+}
+
+// Prometheus is implementation of MetricSource interface, which implements fetch metrics method from Prometheus-compatible HTTP API
+type Prometheus struct {
+	config *Config
+	client *http.Client
+}
+
+// Create configures prometheus metric source
+func Create(config *Config) metricSource.MetricSource {
+	return &Prometheus{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Fetch evaluates PromQL query over given time range and converts resulting series to expected format
+func (prometheus *Prometheus) Fetch(target string, from, until int64, allowRealTimeAlerting bool) (metricSource.FetchResult, error) {
+	// Don't fetch intervals larger than metrics TTL to prevent OOM errors
+	from = moira.MaxInt64(from, until-int64(prometheus.config.MetricsTTL.Seconds()))
+	step := prometheus.config.getStep()
+	from = moira.RoundToNearestRetention(from, step)
+	req, err := prometheus.prepareRequest(from, until, step, target)
+	if err != nil {
+		return nil, remote.ErrRemoteTriggerResponse{
+			InternalError: err,
+			Target:        target,
+		}
+	}
+	body, err := prometheus.makeRequest(req)
+	if err != nil {
+		if invalidQuery, ok := err.(ErrInvalidQuery); ok {
+			invalidQuery.Target = target
+			return nil, invalidQuery
+		}
+		return nil, remote.ErrRemoteTriggerResponse{
+			InternalError: err,
+			Target:        target,
+		}
+	}
+	metricsData, err := decodeBody(body, target, from, until, step)
+	if err != nil {
+		return nil, remote.ErrRemoteTriggerResponse{
+			InternalError: err,
+			Target:        target,
+		}
+	}
+	fetchResult := remote.MakeFetchResult(metricsData, allowRealTimeAlerting)
+	return &fetchResult, nil
+}
+
+// GetMetricsTTLSeconds returns maximum time interval that we are allowed to fetch from prometheus
+func (prometheus *Prometheus) GetMetricsTTLSeconds() int64 {
+	return int64(prometheus.config.MetricsTTL.Seconds())
+}
+
+// IsConfigured returns false in cases that user does not properly configure prometheus settings like API URL
+func (prometheus *Prometheus) IsConfigured() (bool, error) {
+	if prometheus.config.isEnabled() {
+		return true, nil
+	}
+	return false, ErrPrometheusStorageDisabled
+}
+
+// IsRemoteAvailable checks if Prometheus API is available and returns 200 response
+func (prometheus *Prometheus) IsRemoteAvailable() (bool, error) {
+	maxRetries := 3
+	until := time.Now().Unix()
+	from := until - 600 //nolint
+	req, err := prometheus.prepareRequest(from, until, prometheus.config.getStep(), "vector(0)")
+	if err != nil {
+		return false, err
+	}
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		_, err = prometheus.makeRequest(req)
+		if err == nil {
+			return true, nil
+		}
+	}
+	return false, err
+}
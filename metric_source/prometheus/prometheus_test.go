@@ -0,0 +1,145 @@
+package prometheus
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metricSource "github.com/moira-alert/moira/metric_source"
+	"github.com/moira-alert/moira/metric_source/remote"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const queryRangeResponse = `{
+	"status": "success",
+	"data": {
+		"resultType": "matrix",
+		"result": [
+			{
+				"metric": {"__name__": "up", "job": "node", "instance": "host:9100"},
+				"values": [[300, "1"], [360, "0"], [480, "NaN"]]
+			},
+			{
+				"metric": {},
+				"values": [[300, "5"], [420, "7.5"], [480, "8"]]
+			}
+		]
+	}
+}`
+
+func TestIsConfigured(t *testing.T) {
+	Convey("Prometheus is not configured", t, func() {
+		prometheus := Create(&Config{URL: "", Enabled: true})
+		isConfigured, err := prometheus.IsConfigured()
+		So(isConfigured, ShouldBeFalse)
+		So(err, ShouldResemble, ErrPrometheusStorageDisabled)
+	})
+
+	Convey("Prometheus is configured", t, func() {
+		prometheus := Create(&Config{URL: "http://host", Enabled: true})
+		isConfigured, err := prometheus.IsConfigured()
+		So(isConfigured, ShouldBeTrue)
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestIsRemoteAvailable(t *testing.T) {
+	Convey("Is available", t, func() {
+		server := createServer([]byte(queryRangeResponse), http.StatusOK)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		isAvailable, err := prometheus.IsRemoteAvailable()
+		So(isAvailable, ShouldBeTrue)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Not available", t, func() {
+		server := createServer([]byte("Some string"), http.StatusServiceUnavailable)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		isAvailable, err := prometheus.IsRemoteAvailable()
+		So(isAvailable, ShouldBeFalse)
+		So(err, ShouldResemble, fmt.Errorf("bad response status %d: %s", http.StatusServiceUnavailable, "Some string"))
+	})
+}
+
+func TestFetch(t *testing.T) {
+	var from int64 = 300
+	var until int64 = 480
+	target := "up"
+	config := &Config{MetricsTTL: time.Hour, Step: time.Minute}
+
+	Convey("Request success", t, func() {
+		server := createServer([]byte(queryRangeResponse), http.StatusOK)
+		config.URL = server.URL
+		prometheus := Prometheus{client: server.Client(), config: config}
+
+		Convey("with real time alerting", func() {
+			result, err := prometheus.Fetch(target, from, until, true)
+			So(err, ShouldBeNil)
+			metricsData := result.GetMetricsData()
+			So(metricsData, ShouldHaveLength, 2)
+			So(metricsData[0].Name, ShouldEqual, "up;instance=host:9100;job=node")
+			So(metricsData[0].StartTime, ShouldEqual, 300)
+			So(metricsData[0].StepTime, ShouldEqual, 60)
+			So(metricsData[0].Values, ShouldHaveLength, 4)
+			So(metricsData[0].Values[:2], ShouldResemble, []float64{1, 0})
+			So(math.IsNaN(metricsData[0].Values[2]), ShouldBeTrue)
+			So(math.IsNaN(metricsData[0].Values[3]), ShouldBeTrue)
+			So(metricsData[1].Name, ShouldEqual, target)
+			So(math.IsNaN(metricsData[1].Values[1]), ShouldBeTrue)
+			So(metricsData[1].Values[2:], ShouldResemble, []float64{7.5, 8})
+		})
+
+		Convey("without real time alerting last value is removed", func() {
+			result, err := prometheus.Fetch(target, from, until, false)
+			So(err, ShouldBeNil)
+			metricsData := result.GetMetricsData()
+			So(metricsData[1].Values, ShouldHaveLength, 3)
+			So(metricsData[1].Values[2], ShouldEqual, 7.5)
+		})
+	})
+
+	Convey("Query is invalid", t, func() {
+		server := createServer([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`), http.StatusBadRequest)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		result, err := prometheus.Fetch("up{", from, until, false)
+		So(result, ShouldBeNil)
+		So(err, ShouldResemble, ErrInvalidQuery{Target: "up{", Message: "parse error"})
+	})
+
+	Convey("Query result is not matrix", t, func() {
+		server := createServer([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`), http.StatusOK)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		result, err := prometheus.Fetch(target, from, until, false)
+		So(result, ShouldBeNil)
+		So(err, ShouldResemble, remote.ErrRemoteTriggerResponse{
+			InternalError: fmt.Errorf("unexpected result type vector, only matrix is supported"),
+			Target:        target,
+		})
+	})
+
+	Convey("Fail request with InternalServerError", t, func() {
+		server := createServer([]byte("Some string"), http.StatusInternalServerError)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		result, err := prometheus.Fetch(target, from, until, false)
+		So(result, ShouldBeNil)
+		So(err.Error(), ShouldResemble, fmt.Sprintf("bad response status %d: %s", http.StatusInternalServerError, "Some string"))
+	})
+
+	Convey("Empty result", t, func() {
+		server := createServer([]byte(`{"status":"success","data":{"resultType":"matrix","result":[]}}`), http.StatusOK)
+		prometheus := Prometheus{client: server.Client(), config: &Config{URL: server.URL}}
+		result, err := prometheus.Fetch(target, from, until, false)
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, &remote.FetchResult{MetricsData: []metricSource.MetricData{}})
+	})
+}
+
+func createServer(body []byte, statusCode int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(statusCode)
+		rw.Write(body) //nolint
+	}))
+}
@@ -0,0 +1,47 @@
+package prometheus
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPrepareRequest(t *testing.T) {
+	var from int64 = 300
+	var until int64 = 500
+	target := "sum(rate(http_requests_total[5m]))"
+
+	Convey("Given valid params", t, func() {
+		prometheus := Prometheus{config: &Config{
+			URL: "http://test/",
+		}}
+		req, err := prometheus.prepareRequest(from, until, 60, target)
+		Convey("url should be encoded correctly without error", func() {
+			So(err, ShouldBeNil)
+			So(req.URL.Path, ShouldEqual, "/api/v1/query_range")
+			So(req.URL.Query().Get("query"), ShouldEqual, target)
+			So(req.URL.Query().Get("start"), ShouldEqual, "300")
+			So(req.URL.Query().Get("end"), ShouldEqual, "500")
+			So(req.URL.Query().Get("step"), ShouldEqual, "60")
+		})
+		Convey("auth header should be empty", func() {
+			So(req.Header.Get("Authorization"), ShouldEqual, "")
+		})
+	})
+
+	Convey("Given valid params with user and password", t, func() {
+		prometheus := Prometheus{config: &Config{
+			URL:      "http://test",
+			User:     "foo",
+			Password: "bar",
+		}}
+		req, err := prometheus.prepareRequest(from, until, 60, target)
+		Convey("auth header should be set without error", func() {
+			u, p, ok := req.BasicAuth()
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(u, ShouldEqual, prometheus.config.User)
+			So(p, ShouldEqual, prometheus.config.Password)
+		})
+	})
+}
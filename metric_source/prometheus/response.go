@@ -0,0 +1,109 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	metricSource "github.com/moira-alert/moira/metric_source"
+)
+
+const (
+	statusSuccess    = "success"
+	errorTypeBadData = "bad_data"
+	resultTypeMatrix = "matrix"
+	metricNameLabel  = "__name__"
+)
+
+type response struct {
+	Status    string       `json:"status"`
+	Data      responseData `json:"data"`
+	ErrorType string       `json:"errorType"`
+	Error     string       `json:"error"`
+}
+
+type responseData struct {
+	ResultType string   `json:"resultType"`
+	Result     []series `json:"result"`
+}
+
+type series struct {
+	Metric map[string]string `json:"metric"`
+	Values []point           `json:"values"`
+}
+
+// point is a pair of unix timestamp and string representation of value as Prometheus API returns it
+type point [2]interface{}
+
+func (p point) parse() (int64, float64, error) {
+	timestamp, ok := p[0].(float64)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid point timestamp: %v", p[0])
+	}
+	valueStr, ok := p[1].(string)
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid point value: %v", p[1])
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return int64(timestamp), value, nil
+}
+
+// decodeBody converts matrix result of query_range into metrics data with values placed on regular step grid starting at from
+func decodeBody(body []byte, target string, from, until, step int64) ([]metricSource.MetricData, error) {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != statusSuccess {
+		return nil, fmt.Errorf("query failed with %s error: %s", resp.ErrorType, resp.Error)
+	}
+	if resp.Data.ResultType != resultTypeMatrix {
+		return nil, fmt.Errorf("unexpected result type %s, only matrix is supported", resp.Data.ResultType)
+	}
+
+	valuesCount := (until-from)/step + 1
+	res := make([]metricSource.MetricData, 0, len(resp.Data.Result))
+	for _, s := range resp.Data.Result {
+		values := make([]float64, valuesCount)
+		for i := range values {
+			values[i] = math.NaN()
+		}
+		for _, p := range s.Values {
+			timestamp, value, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			index := (timestamp - from) / step
+			if index >= 0 && index < valuesCount {
+				values[index] = value
+			}
+		}
+		res = append(res, *metricSource.MakeMetricData(getMetricName(s.Metric, target), values, step, from))
+	}
+	return res, nil
+}
+
+// getMetricName builds graphite-like tagged metric name from series labels: name;label1=value1;label2=value2.
+// Query is used as name of series without labels
+func getMetricName(labels map[string]string, target string) string {
+	if len(labels) == 0 {
+		return target
+	}
+	tags := make([]string, 0, len(labels))
+	for label, value := range labels {
+		if label != metricNameLabel {
+			tags = append(tags, fmt.Sprintf("%s=%s", label, value))
+		}
+	}
+	sort.Strings(tags)
+	if name, ok := labels[metricNameLabel]; ok {
+		tags = append([]string{name}, tags...)
+	}
+	return strings.Join(tags, ";")
+}
@@ -0,0 +1,15 @@
+package prometheus
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetMetricName(t *testing.T) {
+	Convey("Metric name is built from labels", t, func() {
+		So(getMetricName(map[string]string{"__name__": "up", "job": "node", "env": "prod"}, "up"), ShouldEqual, "up;env=prod;job=node")
+		So(getMetricName(map[string]string{"job": "node"}, "sum by (job) (up)"), ShouldEqual, "job=node")
+		So(getMetricName(map[string]string{}, "sum(up)"), ShouldEqual, "sum(up)")
+	})
+}
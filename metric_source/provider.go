@@ -2,6 +2,7 @@ package metricsource
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/moira-alert/moira"
 )
@@ -9,17 +10,34 @@ import (
 // ErrMetricSourceIsNotConfigured is used then metric source return false on IsConfigured method call with nil error
 var ErrMetricSourceIsNotConfigured = fmt.Errorf("metric source is not configured")
 
+// ErrUnknownMetricSource is used when trigger refers to remote metric source which is absent in config
+type ErrUnknownMetricSource struct {
+	Name string
+}
+
+// Error is a representation of Error interface method
+func (err ErrUnknownMetricSource) Error() string {
+	return fmt.Sprintf("unknown remote metric source '%s'", err.Name)
+}
+
 // SourceProvider is a provider for all known metrics sources
 type SourceProvider struct {
-	local  MetricSource
-	remote MetricSource
+	local   MetricSource
+	remotes map[string]MetricSource
 }
 
-// CreateMetricSourceProvider just creates SourceProvider with all known metrics sources
+// CreateMetricSourceProvider just creates SourceProvider with local and default remote metrics sources
 func CreateMetricSourceProvider(local MetricSource, remote MetricSource) *SourceProvider {
+	return CreateMetricSourceProviderWithRemotes(local, map[string]MetricSource{
+		moira.DefaultRemoteSource: remote,
+	})
+}
+
+// CreateMetricSourceProviderWithRemotes creates SourceProvider with local and named remote metrics sources
+func CreateMetricSourceProviderWithRemotes(local MetricSource, remotes map[string]MetricSource) *SourceProvider {
 	return &SourceProvider{
-		remote: remote,
-		local:  local,
+		local:   local,
+		remotes: remotes,
 	}
 }
 
@@ -28,20 +46,41 @@ func (provider *SourceProvider) GetLocal() (MetricSource, error) {
 	return returnSource(provider.local)
 }
 
-// GetRemote gets remote metric source. If it not configured returns not empty error
+// GetRemote gets default remote metric source. If it not configured returns not empty error
 func (provider *SourceProvider) GetRemote() (MetricSource, error) {
-	return returnSource(provider.remote)
+	return provider.GetRemoteSource(moira.DefaultRemoteSource)
+}
+
+// GetRemoteSource gets remote metric source by name. If it unknown or not configured returns not empty error
+func (provider *SourceProvider) GetRemoteSource(name string) (MetricSource, error) {
+	source, ok := provider.remotes[name]
+	if !ok || source == nil {
+		return nil, ErrUnknownMetricSource{Name: name}
+	}
+	return returnSource(source)
+}
+
+// GetRemoteSourceNames returns sorted names of configured remote metric sources
+func (provider *SourceProvider) GetRemoteSourceNames() []string {
+	names := make([]string, 0, len(provider.remotes))
+	for name := range provider.remotes {
+		if _, err := provider.GetRemoteSource(name); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
 }
 
 // GetTriggerMetricSource get metrics source by given trigger. If it not configured returns not empty error
 func (provider *SourceProvider) GetTriggerMetricSource(trigger *moira.Trigger) (MetricSource, error) {
-	return provider.GetMetricSource(trigger.IsRemote)
+	return provider.GetMetricSource(moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source))
 }
 
-// GetMetricSource return metric source depending on trigger flag: is remote trigger or not. GetLocal if not.
-func (provider *SourceProvider) GetMetricSource(isRemote bool) (MetricSource, error) {
-	if isRemote {
-		return provider.GetRemote()
+// GetMetricSource return metric source depending on trigger source name: remote source with given name or local one if name is empty
+func (provider *SourceProvider) GetMetricSource(source string) (MetricSource, error) {
+	if source != "" {
+		return provider.GetRemoteSource(source)
 	}
 	return provider.GetLocal()
 }
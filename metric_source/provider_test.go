@@ -0,0 +1,75 @@
+package metricsource
+
+import (
+	"testing"
+
+	"github.com/moira-alert/moira"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testSource struct {
+	configured bool
+}
+
+func (source testSource) Fetch(target string, from int64, until int64, allowRealTimeAlerting bool) (FetchResult, error) {
+	return nil, nil
+}
+
+func (source testSource) GetMetricsTTLSeconds() int64 {
+	return 0
+}
+
+func (source testSource) IsConfigured() (bool, error) {
+	return source.configured, nil
+}
+
+func TestSourceProvider(t *testing.T) {
+	local := testSource{configured: true}
+	defaultRemote := testSource{configured: true}
+	prometheus := testSource{configured: true}
+	disabled := testSource{configured: false}
+	provider := CreateMetricSourceProviderWithRemotes(local, map[string]MetricSource{
+		moira.DefaultRemoteSource: defaultRemote,
+		"prometheus":              prometheus,
+		"disabled":                disabled,
+	})
+
+	Convey("Get remote source names returns only configured sources", t, func() {
+		So(provider.GetRemoteSourceNames(), ShouldResemble, []string{moira.DefaultRemoteSource, "prometheus"})
+	})
+
+	Convey("Get metric source by name", t, func() {
+		source, err := provider.GetMetricSource("")
+		So(err, ShouldBeNil)
+		So(source, ShouldResemble, local)
+
+		source, err = provider.GetMetricSource("prometheus")
+		So(err, ShouldBeNil)
+		So(source, ShouldResemble, prometheus)
+
+		source, err = provider.GetMetricSource("disabled")
+		So(err, ShouldResemble, ErrMetricSourceIsNotConfigured)
+		So(source, ShouldResemble, disabled)
+
+		source, err = provider.GetMetricSource("unknown")
+		So(err, ShouldResemble, ErrUnknownMetricSource{Name: "unknown"})
+		So(source, ShouldBeNil)
+	})
+
+	Convey("Get trigger metric source", t, func() {
+		source, err := provider.GetTriggerMetricSource(&moira.Trigger{IsRemote: true})
+		So(err, ShouldBeNil)
+		So(source, ShouldResemble, defaultRemote)
+
+		source, err = provider.GetTriggerMetricSource(&moira.Trigger{IsRemote: true, Source: "prometheus"})
+		So(err, ShouldBeNil)
+		So(source, ShouldResemble, prometheus)
+	})
+
+	Convey("Provider without default remote source", t, func() {
+		provider := CreateMetricSourceProvider(local, nil)
+		So(provider.GetRemoteSourceNames(), ShouldBeEmpty)
+		_, err := provider.GetRemote()
+		So(err, ShouldResemble, ErrUnknownMetricSource{Name: moira.DefaultRemoteSource})
+	})
+}
@@ -12,6 +12,23 @@ type FetchResult struct {
 	MetricsData []metricSource.MetricData
 }
 
+// MakeFetchResult creates fetch result of metrics data fetched from remote source.
+// Last value of every metric is removed if real time alerting is not allowed, because it can be incomplete
+func MakeFetchResult(metricsData []metricSource.MetricData, allowRealTimeAlerting bool) FetchResult {
+	if allowRealTimeAlerting {
+		return FetchResult{MetricsData: metricsData}
+	}
+
+	result := make([]metricSource.MetricData, 0, len(metricsData))
+	for _, metricData := range metricsData {
+		if len(metricData.Values) > 0 {
+			metricData.Values = metricData.Values[:len(metricData.Values)-1]
+		}
+		result = append(result, metricData)
+	}
+	return FetchResult{MetricsData: result}
+}
+
 // GetMetricsData return all metrics data from fetch result
 func (fetchResult *FetchResult) GetMetricsData() []metricSource.MetricData {
 	return fetchResult.MetricsData
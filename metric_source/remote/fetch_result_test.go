@@ -34,3 +34,27 @@ func TestFetchResult(t *testing.T) {
 		So(err, ShouldNotBeEmpty)
 	})
 }
+
+func TestMakeFetchResult(t *testing.T) {
+	data := []metricSource.MetricData{*metricSource.MakeMetricData("test", []float64{1, 2, 3}, 20, 0)}
+	Convey("Given data and allowRealTimeAlerting is set", t, func() {
+		fetchResult := MakeFetchResult(data, true)
+		Convey("response should contain last value", func() {
+			So(fetchResult.MetricsData[0].Values, ShouldResemble, []float64{1, 2, 3})
+		})
+	})
+	Convey("Given data and allowRealTimeAlerting is not set", t, func() {
+		fetchResult := MakeFetchResult(data, false)
+		Convey("response should not contain last value", func() {
+			So(fetchResult.MetricsData[0].Values, ShouldResemble, []float64{1, 2})
+		})
+	})
+	Convey("Given data without values and allowRealTimeAlerting is not set", t, func() {
+		empty := []metricSource.MetricData{*metricSource.MakeMetricData("test", []float64{}, 20, 0)}
+		fetchResult := MakeFetchResult(empty, false)
+		Convey("response should contain metric without values", func() {
+			So(fetchResult.MetricsData, ShouldHaveLength, 1)
+			So(fetchResult.MetricsData[0].Values, ShouldBeEmpty)
+		})
+	})
+}
@@ -64,7 +64,7 @@ func (remote *Remote) Fetch(target string, from, until int64, allowRealTimeAlert
 			Target:        target,
 		}
 	}
-	fetchResult := convertResponse(resp, allowRealTimeAlerting)
+	fetchResult := MakeFetchResult(resp, allowRealTimeAlerting)
 	return &fetchResult, nil
 }
 
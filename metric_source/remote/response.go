@@ -12,20 +12,6 @@ type graphiteMetric struct {
 	DataPoints [][2]*float64
 }
 
-func convertResponse(metricsData []metricSource.MetricData, allowRealTimeAlerting bool) FetchResult {
-	if allowRealTimeAlerting {
-		return FetchResult{MetricsData: metricsData}
-	}
-
-	result := make([]metricSource.MetricData, 0, len(metricsData))
-	for _, metricData := range metricsData {
-		// remove last value
-		metricData.Values = metricData.Values[:len(metricData.Values)-1]
-		result = append(result, metricData)
-	}
-	return FetchResult{MetricsData: result}
-}
-
 func decodeBody(body []byte) ([]metricSource.MetricData, error) {
 	var tmp []graphiteMetric
 	err := json.Unmarshal(body, &tmp)
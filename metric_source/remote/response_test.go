@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"testing"
 
-	metricSource "github.com/moira-alert/moira/metric_source"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -57,20 +56,3 @@ func TestDecodeBody(t *testing.T) {
 		})
 	})
 }
-
-func TestConvertResponse(t *testing.T) {
-	d := *metricSource.MakeMetricData("test", []float64{1, 2, 3}, 20, 0)
-	data := []metricSource.MetricData{d}
-	Convey("Given data and allowRealTimeAlerting is set", t, func() {
-		fetchResult := convertResponse(data, true)
-		Convey("response should contain last value", func() {
-			So(fetchResult.MetricsData[0].Values, ShouldResemble, []float64{1, 2, 3})
-		})
-	})
-	Convey("Given data and allowRealTimeAlerting is not set", t, func() {
-		fetchResult := convertResponse(data, false)
-		Convey("response should not contain last value", func() {
-			So(fetchResult.MetricsData[0].Values, ShouldResemble, []float64{1, 2})
-		})
-	})
-}
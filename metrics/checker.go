@@ -5,18 +5,20 @@ import "github.com/moira-alert/moira"
 // CheckerMetrics is a collection of metrics used in checker
 type CheckerMetrics struct {
 	LocalMetrics           *CheckMetrics
-	RemoteMetrics          *CheckMetrics
+	RemoteMetrics          map[string]*CheckMetrics
 	MetricEventsChannelLen Histogram
 	UnusedTriggersCount    Histogram
 	MetricEventsHandleTime Timer
 }
 
-// GetCheckMetrics return check metrics dependent on given trigger type
-func (metrics *CheckerMetrics) GetCheckMetrics(trigger *moira.Trigger) *CheckMetrics {
+// GetCheckMetrics return check metrics dependent on given trigger type.
+// The second value is false if metrics of trigger remote source are not configured
+func (metrics *CheckerMetrics) GetCheckMetrics(trigger *moira.Trigger) (*CheckMetrics, bool) {
 	if trigger.IsRemote {
-		return metrics.RemoteMetrics
+		checkMetrics, ok := metrics.RemoteMetrics[moira.GetRemoteSourceName(trigger.IsRemote, trigger.Source)]
+		return checkMetrics, ok && checkMetrics != nil
 	}
-	return metrics.LocalMetrics
+	return metrics.LocalMetrics, true
 }
 
 // CheckMetrics is a collection of metrics for trigger checks
@@ -27,25 +29,33 @@ type CheckMetrics struct {
 	TriggersToCheckCount Histogram
 }
 
-// ConfigureCheckerMetrics is checker metrics configurator
-func ConfigureCheckerMetrics(registry Registry, remoteEnabled bool) *CheckerMetrics {
+// ConfigureCheckerMetrics is checker metrics configurator, check metrics are configured for every given remote source
+func ConfigureCheckerMetrics(registry Registry, remoteSources []string) *CheckerMetrics {
 	m := &CheckerMetrics{
-		LocalMetrics:           configureCheckMetrics(registry, "local"),
+		LocalMetrics:           configureCheckMetrics(registry, []string{"local"}),
+		RemoteMetrics:          make(map[string]*CheckMetrics, len(remoteSources)),
 		MetricEventsChannelLen: registry.NewHistogram("metricEvents"),
 		MetricEventsHandleTime: registry.NewTimer("metricEventsHandle"),
 		UnusedTriggersCount:    registry.NewHistogram("triggers", "unused"),
 	}
-	if remoteEnabled {
-		m.RemoteMetrics = configureCheckMetrics(registry, "remote")
+	for _, source := range remoteSources {
+		prefix := []string{"remote"}
+		if source != moira.DefaultRemoteSource {
+			prefix = append(prefix, source)
+		}
+		m.RemoteMetrics[source] = configureCheckMetrics(registry, prefix)
 	}
 	return m
 }
 
-func configureCheckMetrics(registry Registry, prefix string) *CheckMetrics {
+func configureCheckMetrics(registry Registry, prefix []string) *CheckMetrics {
+	path := func(names ...string) []string {
+		return append(append(make([]string, 0, len(prefix)+len(names)), prefix...), names...)
+	}
 	return &CheckMetrics{
-		CheckError:           registry.NewMeter(prefix, "errors", "check"),
-		HandleError:          registry.NewMeter(prefix, "errors", "handle"),
-		TriggersCheckTime:    registry.NewTimer(prefix, "triggers"),
-		TriggersToCheckCount: registry.NewHistogram(prefix, "triggersToCheck"),
+		CheckError:           registry.NewMeter(path("errors", "check")...),
+		HandleError:          registry.NewMeter(path("errors", "handle")...),
+		TriggersCheckTime:    registry.NewTimer(path("triggers")...),
+		TriggersToCheckCount: registry.NewHistogram(path("triggersToCheck")...),
 	}
 }
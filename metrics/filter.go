@@ -1,27 +1,65 @@
 package metrics
 
+import "sync"
+
 // FilterMetrics is a collection of metrics used in filter
 type FilterMetrics struct {
-	TotalMetricsReceived    Counter
-	ValidMetricsReceived    Counter
-	MatchingMetricsReceived Counter
-	MatchingTimer           Timer
-	SavingTimer             Timer
-	BuildTreeTimer          Timer
-	MetricChannelLen        Histogram
-	LineChannelLen          Histogram
+	TotalMetricsReceived      Counter
+	ValidMetricsReceived      Counter
+	MatchingMetricsReceived   Counter
+	CardinalityDroppedMetrics Counter
+	RateDroppedMetrics        Counter
+	MatchingTimer             Timer
+	SavingTimer               Timer
+	BuildTreeTimer            Timer
+	MetricChannelLen          Histogram
+	LineChannelLen            Histogram
+	registry                  Registry
+	rewriteRuleHits           map[string]Counter
+	rewriteRuleHitsLock       sync.Mutex
+}
+
+// ListenerMetrics is a collection of metrics used in a single filter metrics listener
+type ListenerMetrics struct {
+	LinesReceived Counter
+	LinesDropped  Counter
 }
 
 // ConfigureFilterMetrics initialize metrics
 func ConfigureFilterMetrics(registry Registry) *FilterMetrics {
 	return &FilterMetrics{
-		TotalMetricsReceived:    registry.NewCounter("received", "total"),
-		ValidMetricsReceived:    registry.NewCounter("received", "valid"),
-		MatchingMetricsReceived: registry.NewCounter("received", "matching"),
-		MatchingTimer:           registry.NewTimer("time", "match"),
-		SavingTimer:             registry.NewTimer("time", "save"),
-		BuildTreeTimer:          registry.NewTimer("time", "buildtree"),
-		MetricChannelLen:        registry.NewHistogram("metricsToSave"),
-		LineChannelLen:          registry.NewHistogram("linesToMatch"),
+		TotalMetricsReceived:      registry.NewCounter("received", "total"),
+		ValidMetricsReceived:      registry.NewCounter("received", "valid"),
+		MatchingMetricsReceived:   registry.NewCounter("received", "matching"),
+		CardinalityDroppedMetrics: registry.NewCounter("dropped", "cardinality"),
+		RateDroppedMetrics:        registry.NewCounter("dropped", "rate"),
+		MatchingTimer:             registry.NewTimer("time", "match"),
+		SavingTimer:               registry.NewTimer("time", "save"),
+		BuildTreeTimer:            registry.NewTimer("time", "buildtree"),
+		MetricChannelLen:          registry.NewHistogram("metricsToSave"),
+		LineChannelLen:            registry.NewHistogram("linesToMatch"),
+		registry:                  registry,
+		rewriteRuleHits:           make(map[string]Counter),
+	}
+}
+
+// ConfigureListenerMetrics initialize metrics of the listener with given name
+func (metrics *FilterMetrics) ConfigureListenerMetrics(name string) *ListenerMetrics {
+	return &ListenerMetrics{
+		LinesReceived: metrics.registry.NewCounter("listeners", name, "received"),
+		LinesDropped:  metrics.registry.NewCounter("listeners", name, "dropped"),
+	}
+}
+
+// GetRewriteRuleHits returns hits counter of the rewrite rule with given name.
+// Counter is created once and reused when rewrite rules are reloaded
+func (metrics *FilterMetrics) GetRewriteRuleHits(name string) Counter {
+	metrics.rewriteRuleHitsLock.Lock()
+	defer metrics.rewriteRuleHitsLock.Unlock()
+	counter, ok := metrics.rewriteRuleHits[name]
+	if !ok {
+		counter = metrics.registry.NewCounter("rewrite", name, "hits")
+		metrics.rewriteRuleHits[name] = counter
 	}
+	return counter
 }
@@ -49,18 +49,33 @@ func (mr *MockDatabaseMockRecorder) AcquireTriggerCheckLock(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTriggerCheckLock", reflect.TypeOf((*MockDatabase)(nil).AcquireTriggerCheckLock), arg0, arg1)
 }
 
+// AddEscalation mocks base method
+func (m *MockDatabase) AddEscalation(arg0 *moira.EscalationData) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEscalation", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddEscalation indicates an expected call of AddEscalation
+func (mr *MockDatabaseMockRecorder) AddEscalation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEscalation", reflect.TypeOf((*MockDatabase)(nil).AddEscalation), arg0)
+}
+
 // AddLocalTriggersToCheck mocks base method
-func (m *MockDatabase) AddLocalTriggersToCheck(arg0 []string) error {
+func (m *MockDatabase) AddLocalTriggersToCheck(arg0 []string, arg1 moira.TriggerPriority) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddLocalTriggersToCheck", arg0)
+	ret := m.ctrl.Call(m, "AddLocalTriggersToCheck", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddLocalTriggersToCheck indicates an expected call of AddLocalTriggersToCheck
-func (mr *MockDatabaseMockRecorder) AddLocalTriggersToCheck(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) AddLocalTriggersToCheck(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLocalTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).AddLocalTriggersToCheck), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLocalTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).AddLocalTriggersToCheck), arg0, arg1)
 }
 
 // AddNotification mocks base method
@@ -77,6 +92,20 @@ func (mr *MockDatabaseMockRecorder) AddNotification(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNotification", reflect.TypeOf((*MockDatabase)(nil).AddNotification), arg0)
 }
 
+// AddNotificationHistory mocks base method
+func (m *MockDatabase) AddNotificationHistory(arg0 *moira.NotificationHistoryItem, arg1 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddNotificationHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddNotificationHistory indicates an expected call of AddNotificationHistory
+func (mr *MockDatabaseMockRecorder) AddNotificationHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddNotificationHistory", reflect.TypeOf((*MockDatabase)(nil).AddNotificationHistory), arg0, arg1)
+}
+
 // AddNotifications mocks base method
 func (m *MockDatabase) AddNotifications(arg0 []*moira.ScheduledNotification, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -105,18 +134,46 @@ func (mr *MockDatabaseMockRecorder) AddPatternMetric(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPatternMetric", reflect.TypeOf((*MockDatabase)(nil).AddPatternMetric), arg0, arg1)
 }
 
+// AddPrefixOffenders mocks base method
+func (m *MockDatabase) AddPrefixOffenders(arg0 []*moira.PrefixOffender) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPrefixOffenders", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPrefixOffenders indicates an expected call of AddPrefixOffenders
+func (mr *MockDatabaseMockRecorder) AddPrefixOffenders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPrefixOffenders", reflect.TypeOf((*MockDatabase)(nil).AddPrefixOffenders), arg0)
+}
+
+// AddRejectedLines mocks base method
+func (m *MockDatabase) AddRejectedLines(arg0 []*moira.RejectedLine, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRejectedLines", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRejectedLines indicates an expected call of AddRejectedLines
+func (mr *MockDatabaseMockRecorder) AddRejectedLines(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRejectedLines", reflect.TypeOf((*MockDatabase)(nil).AddRejectedLines), arg0, arg1)
+}
+
 // AddRemoteTriggersToCheck mocks base method
-func (m *MockDatabase) AddRemoteTriggersToCheck(arg0 []string) error {
+func (m *MockDatabase) AddRemoteTriggersToCheck(arg0 string, arg1 []string, arg2 moira.TriggerPriority) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddRemoteTriggersToCheck", arg0)
+	ret := m.ctrl.Call(m, "AddRemoteTriggersToCheck", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddRemoteTriggersToCheck indicates an expected call of AddRemoteTriggersToCheck
-func (mr *MockDatabaseMockRecorder) AddRemoteTriggersToCheck(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) AddRemoteTriggersToCheck(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemoteTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).AddRemoteTriggersToCheck), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemoteTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).AddRemoteTriggersToCheck), arg0, arg1, arg2)
 }
 
 // AllowStale mocks base method
@@ -429,6 +486,22 @@ func (mr *MockDatabaseMockRecorder) GetNotificationEvents(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEvents", reflect.TypeOf((*MockDatabase)(nil).GetNotificationEvents), arg0, arg1, arg2)
 }
 
+// GetNotificationHistory mocks base method
+func (m *MockDatabase) GetNotificationHistory(arg0, arg1 string, arg2, arg3, arg4, arg5 int64) ([]*moira.NotificationHistoryItem, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationHistory", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]*moira.NotificationHistoryItem)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetNotificationHistory indicates an expected call of GetNotificationHistory
+func (mr *MockDatabaseMockRecorder) GetNotificationHistory(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationHistory", reflect.TypeOf((*MockDatabase)(nil).GetNotificationHistory), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
 // GetNotifications mocks base method
 func (m *MockDatabase) GetNotifications(arg0, arg1 int64) ([]*moira.ScheduledNotification, int64, error) {
 	m.ctrl.T.Helper()
@@ -505,64 +578,94 @@ func (mr *MockDatabaseMockRecorder) GetPatterns() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPatterns", reflect.TypeOf((*MockDatabase)(nil).GetPatterns))
 }
 
+// GetPrefixOffenders mocks base method
+func (m *MockDatabase) GetPrefixOffenders(arg0 int64) ([]*moira.PrefixOffender, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrefixOffenders", arg0)
+	ret0, _ := ret[0].([]*moira.PrefixOffender)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrefixOffenders indicates an expected call of GetPrefixOffenders
+func (mr *MockDatabaseMockRecorder) GetPrefixOffenders(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrefixOffenders", reflect.TypeOf((*MockDatabase)(nil).GetPrefixOffenders), arg0)
+}
+
+// GetRejectedLines mocks base method
+func (m *MockDatabase) GetRejectedLines(arg0 string) ([]*moira.RejectedLine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRejectedLines", arg0)
+	ret0, _ := ret[0].([]*moira.RejectedLine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRejectedLines indicates an expected call of GetRejectedLines
+func (mr *MockDatabaseMockRecorder) GetRejectedLines(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRejectedLines", reflect.TypeOf((*MockDatabase)(nil).GetRejectedLines), arg0)
+}
+
 // GetRemoteChecksUpdatesCount mocks base method
-func (m *MockDatabase) GetRemoteChecksUpdatesCount() (int64, error) {
+func (m *MockDatabase) GetRemoteChecksUpdatesCount(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRemoteChecksUpdatesCount")
+	ret := m.ctrl.Call(m, "GetRemoteChecksUpdatesCount", arg0)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRemoteChecksUpdatesCount indicates an expected call of GetRemoteChecksUpdatesCount
-func (mr *MockDatabaseMockRecorder) GetRemoteChecksUpdatesCount() *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetRemoteChecksUpdatesCount(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteChecksUpdatesCount", reflect.TypeOf((*MockDatabase)(nil).GetRemoteChecksUpdatesCount))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteChecksUpdatesCount", reflect.TypeOf((*MockDatabase)(nil).GetRemoteChecksUpdatesCount), arg0)
 }
 
 // GetRemoteTriggerIDs mocks base method
-func (m *MockDatabase) GetRemoteTriggerIDs() ([]string, error) {
+func (m *MockDatabase) GetRemoteTriggerIDs(arg0 string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRemoteTriggerIDs")
+	ret := m.ctrl.Call(m, "GetRemoteTriggerIDs", arg0)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRemoteTriggerIDs indicates an expected call of GetRemoteTriggerIDs
-func (mr *MockDatabaseMockRecorder) GetRemoteTriggerIDs() *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetRemoteTriggerIDs(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggerIDs", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggerIDs))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggerIDs", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggerIDs), arg0)
 }
 
 // GetRemoteTriggersToCheck mocks base method
-func (m *MockDatabase) GetRemoteTriggersToCheck(arg0 int) ([]string, error) {
+func (m *MockDatabase) GetRemoteTriggersToCheck(arg0 string, arg1 int) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRemoteTriggersToCheck", arg0)
+	ret := m.ctrl.Call(m, "GetRemoteTriggersToCheck", arg0, arg1)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRemoteTriggersToCheck indicates an expected call of GetRemoteTriggersToCheck
-func (mr *MockDatabaseMockRecorder) GetRemoteTriggersToCheck(arg0 interface{}) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetRemoteTriggersToCheck(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggersToCheck), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggersToCheck", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggersToCheck), arg0, arg1)
 }
 
 // GetRemoteTriggersToCheckCount mocks base method
-func (m *MockDatabase) GetRemoteTriggersToCheckCount() (int64, error) {
+func (m *MockDatabase) GetRemoteTriggersToCheckCount(arg0 string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRemoteTriggersToCheckCount")
+	ret := m.ctrl.Call(m, "GetRemoteTriggersToCheckCount", arg0)
 	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetRemoteTriggersToCheckCount indicates an expected call of GetRemoteTriggersToCheckCount
-func (mr *MockDatabaseMockRecorder) GetRemoteTriggersToCheckCount() *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetRemoteTriggersToCheckCount(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggersToCheckCount", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggersToCheckCount))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemoteTriggersToCheckCount", reflect.TypeOf((*MockDatabase)(nil).GetRemoteTriggersToCheckCount), arg0)
 }
 
 // GetSubscription mocks base method
@@ -655,6 +758,21 @@ func (mr *MockDatabaseMockRecorder) GetTrigger(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrigger", reflect.TypeOf((*MockDatabase)(nil).GetTrigger), arg0)
 }
 
+// GetTriggerCheckDiagnostics mocks base method
+func (m *MockDatabase) GetTriggerCheckDiagnostics(arg0 string) ([]*moira.CheckDiagnostics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerCheckDiagnostics", arg0)
+	ret0, _ := ret[0].([]*moira.CheckDiagnostics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerCheckDiagnostics indicates an expected call of GetTriggerCheckDiagnostics
+func (mr *MockDatabaseMockRecorder) GetTriggerCheckDiagnostics(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerCheckDiagnostics", reflect.TypeOf((*MockDatabase)(nil).GetTriggerCheckDiagnostics), arg0)
+}
+
 // GetTriggerChecks mocks base method
 func (m *MockDatabase) GetTriggerChecks(arg0 []string) ([]*moira.TriggerCheck, error) {
 	m.ctrl.T.Helper()
@@ -685,6 +803,21 @@ func (mr *MockDatabaseMockRecorder) GetTriggerLastCheck(arg0 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerLastCheck", reflect.TypeOf((*MockDatabase)(nil).GetTriggerLastCheck), arg0)
 }
 
+// GetTriggerPolicyThrottling mocks base method
+func (m *MockDatabase) GetTriggerPolicyThrottling(arg0, arg1 string) (time.Time, time.Time) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerPolicyThrottling", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(time.Time)
+	return ret0, ret1
+}
+
+// GetTriggerPolicyThrottling indicates an expected call of GetTriggerPolicyThrottling
+func (mr *MockDatabaseMockRecorder) GetTriggerPolicyThrottling(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerPolicyThrottling", reflect.TypeOf((*MockDatabase)(nil).GetTriggerPolicyThrottling), arg0, arg1)
+}
+
 // GetTriggerThrottling mocks base method
 func (m *MockDatabase) GetTriggerThrottling(arg0 string) (time.Time, time.Time) {
 	m.ctrl.T.Helper()
@@ -700,6 +833,21 @@ func (mr *MockDatabaseMockRecorder) GetTriggerThrottling(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerThrottling", reflect.TypeOf((*MockDatabase)(nil).GetTriggerThrottling), arg0)
 }
 
+// GetTriggerThrottlingReason mocks base method
+func (m *MockDatabase) GetTriggerThrottlingReason(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggerThrottlingReason", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerThrottlingReason indicates an expected call of GetTriggerThrottlingReason
+func (mr *MockDatabaseMockRecorder) GetTriggerThrottlingReason(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerThrottlingReason", reflect.TypeOf((*MockDatabase)(nil).GetTriggerThrottlingReason), arg0)
+}
+
 // GetTriggers mocks base method
 func (m *MockDatabase) GetTriggers(arg0 []string) ([]*moira.Trigger, error) {
 	m.ctrl.T.Helper()
@@ -715,6 +863,21 @@ func (mr *MockDatabaseMockRecorder) GetTriggers(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggers", reflect.TypeOf((*MockDatabase)(nil).GetTriggers), arg0)
 }
 
+// GetTriggersCheckSettings mocks base method
+func (m *MockDatabase) GetTriggersCheckSettings() (map[string]moira.TriggerCheckSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTriggersCheckSettings")
+	ret0, _ := ret[0].(map[string]moira.TriggerCheckSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggersCheckSettings indicates an expected call of GetTriggersCheckSettings
+func (mr *MockDatabaseMockRecorder) GetTriggersCheckSettings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggersCheckSettings", reflect.TypeOf((*MockDatabase)(nil).GetTriggersCheckSettings))
+}
+
 // GetTriggersSearchResults mocks base method
 func (m *MockDatabase) GetTriggersSearchResults(arg0 string, arg1, arg2 int64) ([]*moira.SearchResult, int64, error) {
 	m.ctrl.T.Helper()
@@ -776,6 +939,21 @@ func (mr *MockDatabaseMockRecorder) GetUserSubscriptionIDs(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSubscriptionIDs", reflect.TypeOf((*MockDatabase)(nil).GetUserSubscriptionIDs), arg0)
 }
 
+// IsEscalationActive mocks base method
+func (m *MockDatabase) IsEscalationActive(arg0 *moira.EscalationData) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEscalationActive", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEscalationActive indicates an expected call of IsEscalationActive
+func (mr *MockDatabaseMockRecorder) IsEscalationActive(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEscalationActive", reflect.TypeOf((*MockDatabase)(nil).IsEscalationActive), arg0)
+}
+
 // MarkTriggersAsUnused mocks base method
 func (m *MockDatabase) MarkTriggersAsUnused(arg0 ...string) error {
 	m.ctrl.T.Helper()
@@ -882,6 +1060,39 @@ func (mr *MockDatabaseMockRecorder) RemoveContact(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContact", reflect.TypeOf((*MockDatabase)(nil).RemoveContact), arg0)
 }
 
+// RemoveEscalation mocks base method
+func (m *MockDatabase) RemoveEscalation(arg0 *moira.EscalationData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveEscalation", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveEscalation indicates an expected call of RemoveEscalation
+func (mr *MockDatabaseMockRecorder) RemoveEscalation(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveEscalation", reflect.TypeOf((*MockDatabase)(nil).RemoveEscalation), arg0)
+}
+
+// RemoveEscalations mocks base method
+func (m *MockDatabase) RemoveEscalations(arg0 string, arg1 ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RemoveEscalations", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveEscalations indicates an expected call of RemoveEscalations
+func (mr *MockDatabaseMockRecorder) RemoveEscalations(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveEscalations", reflect.TypeOf((*MockDatabase)(nil).RemoveEscalations), varargs...)
+}
+
 // RemoveMetricValues mocks base method
 func (m *MockDatabase) RemoveMetricValues(arg0 string, arg1 int64) error {
 	m.ctrl.T.Helper()
@@ -1135,6 +1346,20 @@ func (mr *MockDatabaseMockRecorder) SaveTrigger(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrigger", reflect.TypeOf((*MockDatabase)(nil).SaveTrigger), arg0, arg1)
 }
 
+// SaveTriggerCheckDiagnostics mocks base method
+func (m *MockDatabase) SaveTriggerCheckDiagnostics(arg0 string, arg1 *moira.CheckDiagnostics, arg2 int, arg3 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTriggerCheckDiagnostics", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTriggerCheckDiagnostics indicates an expected call of SaveTriggerCheckDiagnostics
+func (mr *MockDatabaseMockRecorder) SaveTriggerCheckDiagnostics(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTriggerCheckDiagnostics", reflect.TypeOf((*MockDatabase)(nil).SaveTriggerCheckDiagnostics), arg0, arg1, arg2, arg3)
+}
+
 // SaveTriggersSearchResults mocks base method
 func (m *MockDatabase) SaveTriggersSearchResults(arg0 string, arg1 []*moira.SearchResult) error {
 	m.ctrl.T.Helper()
@@ -1163,6 +1388,20 @@ func (mr *MockDatabaseMockRecorder) SetNotifierState(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotifierState", reflect.TypeOf((*MockDatabase)(nil).SetNotifierState), arg0)
 }
 
+// SetTriggerCheckAck mocks base method
+func (m *MockDatabase) SetTriggerCheckAck(arg0 string, arg1 []string, arg2 *moira.AckData) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTriggerCheckAck", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTriggerCheckAck indicates an expected call of SetTriggerCheckAck
+func (mr *MockDatabaseMockRecorder) SetTriggerCheckAck(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTriggerCheckAck", reflect.TypeOf((*MockDatabase)(nil).SetTriggerCheckAck), arg0, arg1, arg2)
+}
+
 // SetTriggerCheckLock mocks base method
 func (m *MockDatabase) SetTriggerCheckLock(arg0 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1193,7 +1432,7 @@ func (mr *MockDatabaseMockRecorder) SetTriggerCheckMaintenance(arg0, arg1, arg2,
 }
 
 // SetTriggerLastCheck mocks base method
-func (m *MockDatabase) SetTriggerLastCheck(arg0 string, arg1 *moira.CheckData, arg2 bool) error {
+func (m *MockDatabase) SetTriggerLastCheck(arg0 string, arg1 *moira.CheckData, arg2 string) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetTriggerLastCheck", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
@@ -1207,17 +1446,17 @@ func (mr *MockDatabaseMockRecorder) SetTriggerLastCheck(arg0, arg1, arg2 interfa
 }
 
 // SetTriggerThrottling mocks base method
-func (m *MockDatabase) SetTriggerThrottling(arg0 string, arg1 time.Time) error {
+func (m *MockDatabase) SetTriggerThrottling(arg0, arg1 string, arg2 time.Time, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetTriggerThrottling", arg0, arg1)
+	ret := m.ctrl.Call(m, "SetTriggerThrottling", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetTriggerThrottling indicates an expected call of SetTriggerThrottling
-func (mr *MockDatabaseMockRecorder) SetTriggerThrottling(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) SetTriggerThrottling(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTriggerThrottling", reflect.TypeOf((*MockDatabase)(nil).SetTriggerThrottling), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTriggerThrottling", reflect.TypeOf((*MockDatabase)(nil).SetTriggerThrottling), arg0, arg1, arg2, arg3)
 }
 
 // SetUsernameID mocks base method
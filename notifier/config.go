@@ -20,4 +20,27 @@ type Config struct {
 	Location          *time.Location
 	DateTimeFormat    string
 	ReadBatchSize     int64
+	// ThrottlingPolicies are throttling policies by names which subscriptions select
+	ThrottlingPolicies map[string]ThrottlingPolicy
+	// NotificationHistoryTTL is a time to keep history of notification sending attempts, zero disables history
+	NotificationHistoryTTL time.Duration
+}
+
+// DefaultThrottlingPolicy is a name of policy used by subscriptions with throttling enabled which do not select policy
+const DefaultThrottlingPolicy = "default"
+
+// ThrottlingLevel delays next notification of trigger for Delay if trigger switched Count times during Window
+type ThrottlingLevel struct {
+	Window time.Duration
+	Count  int64
+	Delay  time.Duration
+}
+
+// ThrottlingPolicy is a list of throttling levels, levels are checked in order and the first matching one is applied
+type ThrottlingPolicy []ThrottlingLevel
+
+// defaultThrottlingPolicy is used as default policy if config does not define it
+var defaultThrottlingPolicy = ThrottlingPolicy{
+	{Window: 3 * time.Hour, Count: 20, Delay: time.Hour}, //nolint
+	{Window: time.Hour, Count: 10, Delay: time.Hour / 2}, //nolint
 }
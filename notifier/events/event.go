@@ -75,6 +75,11 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 		triggerData   moira.TriggerData
 	)
 
+	if event.IsMuted {
+		worker.Logger.Debugf("Skip muted event of trigger id %s for metric %s", event.TriggerID, event.Metric)
+		return nil
+	}
+
 	if event.State != moira.StateTEST {
 		worker.Logger.Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
 
@@ -110,10 +115,17 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 		subscriptions = []*moira.SubscriptionData{sub}
 	}
 
+	if event.State == moira.StateOK {
+		if err := worker.Database.RemoveEscalations(event.TriggerID, event.Metric); err != nil {
+			worker.Logger.Errorf("Failed to stop escalations of trigger %s metric %s: %s", event.TriggerID, event.Metric, err)
+		}
+	}
+
 	duplications := make(map[string]bool)
 
 	for _, subscription := range subscriptions {
 		if worker.isNotificationRequired(subscription, triggerData, event) {
+			escalationStart := time.Now()
 			for _, contactID := range subscription.Contacts {
 				contact, err := worker.Database.GetContact(contactID)
 				if err != nil {
@@ -123,6 +135,10 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 				event.SubscriptionID = &subscription.ID
 				notification := worker.Scheduler.ScheduleNotification(time.Now(), event, triggerData,
 					contact, subscription.Plotting, false, 0)
+				escalationStart = time.Unix(notification.Timestamp, 0)
+				if event.State != moira.StateTEST {
+					setNotificationDigest(notification, subscription, contact)
+				}
 				key := notification.GetKey()
 				if _, exist := duplications[key]; !exist {
 					if err := worker.Database.AddNotification(notification); err != nil {
@@ -133,11 +149,76 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 					worker.Logger.Debugf("Skip duplicated notification for contact %s", notification.Contact)
 				}
 			}
+			if event.State == moira.StateERROR && subscription.Escalation.IsEnabled() && !event.IsAcknowledged() {
+				event.SubscriptionID = &subscription.ID
+				worker.scheduleEscalation(escalationStart, event, triggerData, subscription)
+			}
 		}
 	}
 	return nil
 }
 
+// scheduleEscalation starts escalation of ERROR event and schedules notifications of all escalation tiers
+// after the first notification of subscription. Notifications are not sent if escalation is stopped
+// by acknowledgement or recovery of trigger metric before, nothing is scheduled if escalation is already in progress
+func (worker *FetchEventsWorker) scheduleEscalation(start time.Time, event moira.NotificationEvent, trigger moira.TriggerData, subscription *moira.SubscriptionData) {
+	escalation := moira.EscalationData{
+		TriggerID:      event.TriggerID,
+		Metric:         event.Metric,
+		SubscriptionID: subscription.ID,
+		Started:        start.Unix(),
+	}
+	started, err := worker.Database.AddEscalation(&escalation)
+	if err != nil {
+		worker.Logger.Errorf("Failed to start escalation of trigger %s metric %s: %s", event.TriggerID, event.Metric, err)
+		return
+	}
+	if !started {
+		worker.Logger.Debugf("Escalation of trigger %s metric %s by subscription %s is already in progress", event.TriggerID, event.Metric, subscription.ID)
+		return
+	}
+
+	tiers := subscription.Escalation.Tiers
+	next := start
+	for i, tier := range tiers {
+		delay := time.Duration(tier.DelayInMinutes) * time.Minute
+		next = next.Add(delay)
+		tierEscalation := escalation
+		tierEscalation.Tier = i
+		if i == len(tiers)-1 {
+			tierEscalation.RepeatInterval = int64(delay.Seconds())
+		}
+		for _, contactID := range tier.Contacts {
+			contact, err := worker.Database.GetContact(contactID)
+			if err != nil {
+				worker.Logger.Warningf("Failed to get escalation contact: %s, skip handling it, error: %v", contactID, err)
+				continue
+			}
+			notification := &moira.ScheduledNotification{
+				Event:      event,
+				Trigger:    trigger,
+				Contact:    contact,
+				Plotting:   subscription.Plotting,
+				Timestamp:  next.Unix(),
+				Escalation: &tierEscalation,
+			}
+			if err := worker.Database.AddNotification(notification); err != nil {
+				worker.Logger.Errorf("Failed to save escalation notification: %s", err)
+			}
+		}
+	}
+}
+
+// setNotificationDigest holds notification till the end of contact or subscription digest window,
+// contact digest combines notifications of all subscriptions so it is preferred
+func setNotificationDigest(notification *moira.ScheduledNotification, subscription *moira.SubscriptionData, contact moira.ContactData) {
+	if contact.DigestWindow > 0 {
+		notification.SetDigest(contact.ID, contact.DigestWindow)
+		return
+	}
+	notification.SetDigest(fmt.Sprintf("%s:%s", subscription.ID, contact.ID), subscription.DigestWindow)
+}
+
 func (worker *FetchEventsWorker) getNotificationSubscriptions(event moira.NotificationEvent) (*moira.SubscriptionData, error) {
 	if event.SubscriptionID != nil {
 		worker.Logger.Debugf("Getting subscriptionID %s for test message", *event.SubscriptionID)
@@ -121,6 +121,7 @@ func TestNoSubscription(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return(make([]*moira.SubscriptionData, 0), nil)
 
 		err := worker.processEvent(event)
@@ -128,6 +129,33 @@ func TestNoSubscription(t *testing.T) {
 	})
 }
 
+func TestMutedEvent(t *testing.T) {
+	Convey("When event is muted, should not read subscriptions and call AddNotification", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		logger, _ := logging.GetLogger("Events")
+
+		worker := FetchEventsWorker{
+			Database:  dataBase,
+			Logger:    logger,
+			Metrics:   notifierMetrics,
+			Scheduler: notifier.NewScheduler(dataBase, logger, notifierMetrics),
+		}
+
+		event := moira.NotificationEvent{
+			Metric:    "generate.event.1",
+			State:     moira.StateERROR,
+			OldState:  moira.StateOK,
+			TriggerID: triggerData.ID,
+			IsMuted:   true,
+		}
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+}
+
 func TestDisabledNotification(t *testing.T) {
 	Convey("When subscription event tags is disabled, should not call AddNotification", t, func() {
 		mockCtrl := gomock.NewController(t)
@@ -150,6 +178,7 @@ func TestDisabledNotification(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&disabledSubscription}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -183,6 +212,7 @@ func TestSubscriptionsManagedToIgnoreEvents(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscriptionToIgnoreWarnings}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -208,6 +238,7 @@ func TestSubscriptionsManagedToIgnoreEvents(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscriptionToIgnoreRecoverings}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -233,6 +264,7 @@ func TestSubscriptionsManagedToIgnoreEvents(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscriptionToIgnoreWarningsAndRecoverings}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -268,6 +300,7 @@ func TestAddNotification(t *testing.T) {
 		emptyNotification := moira.ScheduledNotification{}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscription}, nil)
 		dataBase.EXPECT().GetContact(contact.ID).Times(1).Return(contact, nil)
 		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, contact, emptyNotification.Plotting, false, 0).Times(1).Return(&emptyNotification)
@@ -278,6 +311,166 @@ func TestAddNotification(t *testing.T) {
 	})
 }
 
+func TestAddDigestNotification(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Events")
+	scheduler := mock_scheduler.NewMockScheduler(mockCtrl)
+	worker := FetchEventsWorker{
+		Database:  dataBase,
+		Logger:    logger,
+		Metrics:   notifierMetrics,
+		Scheduler: scheduler,
+	}
+	event := moira.NotificationEvent{
+		Metric:         "generate.event.1",
+		State:          moira.StateOK,
+		OldState:       moira.StateWARN,
+		TriggerID:      triggerData.ID,
+		SubscriptionID: &subscription.ID,
+	}
+
+	Convey("When subscription has digest window, notification should be held till the end of window", t, func() {
+		digestSubscription := subscription
+		digestSubscription.DigestWindow = 300
+		notification := moira.ScheduledNotification{Timestamp: 1000}
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
+		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Return([]*moira.SubscriptionData{&digestSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Return(contact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, contact, digestSubscription.Plotting, false, 0).Return(&notification)
+		dataBase.EXPECT().AddNotification(&moira.ScheduledNotification{
+			Timestamp: 1200,
+			Digest:    fmt.Sprintf("%s:%s", digestSubscription.ID, contact.ID),
+		}).Return(nil)
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+
+	Convey("When contact has digest window, it should be preferred", t, func() {
+		digestSubscription := subscription
+		digestSubscription.DigestWindow = 300
+		digestContact := contact
+		digestContact.DigestWindow = 600
+		notification := moira.ScheduledNotification{Timestamp: 1000}
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
+		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Return([]*moira.SubscriptionData{&digestSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Return(digestContact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, digestContact, digestSubscription.Plotting, false, 0).Return(&notification)
+		dataBase.EXPECT().AddNotification(&moira.ScheduledNotification{
+			Timestamp: 1200,
+			Digest:    contact.ID,
+		}).Return(nil)
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+}
+
+func TestScheduleEscalation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Events")
+	scheduler := mock_scheduler.NewMockScheduler(mockCtrl)
+	worker := FetchEventsWorker{
+		Database:  dataBase,
+		Logger:    logger,
+		Metrics:   notifierMetrics,
+		Scheduler: scheduler,
+	}
+	event := moira.NotificationEvent{
+		Metric:         "generate.event.1",
+		State:          moira.StateERROR,
+		OldState:       moira.StateOK,
+		TriggerID:      triggerData.ID,
+		SubscriptionID: &subscription.ID,
+	}
+	leadContact := moira.ContactData{ID: "ContactID-000000000000002", Type: "email", Value: "lead@example.com"}
+	managerContact := moira.ContactData{ID: "ContactID-000000000000003", Type: "email", Value: "manager@example.com"}
+	escalationSubscription := subscription
+	escalationSubscription.Escalation = &moira.EscalationPolicy{
+		Tiers: []moira.EscalationTier{
+			{Contacts: []string{leadContact.ID}, DelayInMinutes: 10},
+			{Contacts: []string{managerContact.ID}, DelayInMinutes: 30},
+		},
+	}
+	escalation := moira.EscalationData{
+		TriggerID:      triggerData.ID,
+		Metric:         event.Metric,
+		SubscriptionID: subscription.ID,
+		Started:        1000,
+	}
+
+	Convey("ERROR event should start escalation and schedule notifications of all tiers", t, func() {
+		notification := moira.ScheduledNotification{Timestamp: 1000}
+		leadEscalation := escalation
+		managerEscalation := escalation
+		managerEscalation.Tier = 1
+		managerEscalation.RepeatInterval = 1800
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Return([]*moira.SubscriptionData{&escalationSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Return(contact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, contact, escalationSubscription.Plotting, false, 0).Return(&notification)
+		dataBase.EXPECT().AddNotification(&notification).Return(nil)
+		dataBase.EXPECT().AddEscalation(&escalation).Return(true, nil)
+		dataBase.EXPECT().GetContact(leadContact.ID).Return(leadContact, nil)
+		dataBase.EXPECT().AddNotification(&moira.ScheduledNotification{
+			Event:      event,
+			Trigger:    triggerData,
+			Contact:    leadContact,
+			Timestamp:  1600,
+			Escalation: &leadEscalation,
+		}).Return(nil)
+		dataBase.EXPECT().GetContact(managerContact.ID).Return(managerContact, nil)
+		dataBase.EXPECT().AddNotification(&moira.ScheduledNotification{
+			Event:      event,
+			Trigger:    triggerData,
+			Contact:    managerContact,
+			Timestamp:  3400,
+			Escalation: &managerEscalation,
+		}).Return(nil)
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+
+	Convey("ERROR event should not schedule notifications if escalation is in progress", t, func() {
+		notification := moira.ScheduledNotification{Timestamp: 1000}
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Return([]*moira.SubscriptionData{&escalationSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Return(contact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, contact, escalationSubscription.Plotting, false, 0).Return(&notification)
+		dataBase.EXPECT().AddNotification(&notification).Return(nil)
+		dataBase.EXPECT().AddEscalation(&escalation).Return(false, nil)
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+
+	Convey("Acknowledged ERROR event should not start escalation", t, func() {
+		ackedEvent := event
+		ackedEvent.MessageEventInfo = &moira.EventInfo{Ack: &moira.AckData{User: "user"}}
+		notification := moira.ScheduledNotification{Timestamp: 1000}
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Return([]*moira.SubscriptionData{&escalationSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Return(contact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), ackedEvent, triggerData, contact, escalationSubscription.Plotting, false, 0).Return(&notification)
+		dataBase.EXPECT().AddNotification(&notification).Return(nil)
+
+		err := worker.processEvent(ackedEvent)
+		So(err, ShouldBeEmpty)
+	})
+}
+
 func TestAddOneNotificationByTwoSubscriptionsWithSame(t *testing.T) {
 	Convey("When good subscription and create 2 same scheduled notifications, should add one new notification", t, func() {
 		mockCtrl := gomock.NewController(t)
@@ -305,6 +498,7 @@ func TestAddOneNotificationByTwoSubscriptionsWithSame(t *testing.T) {
 		notification2 := moira.ScheduledNotification{}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscription, &subscription4}, nil)
 		dataBase.EXPECT().GetContact(contact.ID).Times(2).Return(contact, nil)
 
@@ -339,6 +533,7 @@ func TestFailReadContact(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscription}, nil)
 		getContactError := fmt.Errorf("Can not get contact")
 		dataBase.EXPECT().GetContact(contact.ID).Times(1).Return(moira.ContactData{}, getContactError)
@@ -373,6 +568,7 @@ func TestEmptySubscriptions(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{{ThrottlingEnabled: true}}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -402,6 +598,7 @@ func TestEmptySubscriptions(t *testing.T) {
 		}
 
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{nil}, nil)
 
 		logger.EXPECT().Debugf("Processing trigger id %s for metric %s == %f, %s -> %s", event.TriggerID, event.Metric, event.GetMetricsValues(), event.OldState, event.State)
@@ -481,6 +678,7 @@ func TestGoRoutine(t *testing.T) {
 			})
 		})
 		dataBase.EXPECT().GetTrigger(event.TriggerID).Times(1).Return(trigger, nil)
+		dataBase.EXPECT().RemoveEscalations(event.TriggerID, event.Metric).Return(nil)
 		dataBase.EXPECT().GetTagsSubscriptions(triggerData.Tags).Times(1).Return([]*moira.SubscriptionData{&subscription}, nil)
 		dataBase.EXPECT().GetContact(contact.ID).Times(1).Return(contact, nil)
 		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, triggerData, contact, emptyNotification.Plotting, false, 0).Times(1).Return(&emptyNotification)
@@ -8,6 +8,7 @@ import (
 	"gopkg.in/tomb.v2"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
 	"github.com/moira-alert/moira/notifier"
 )
 
@@ -67,19 +68,31 @@ func (worker *FetchNotificationsWorker) processScheduledNotifications() error {
 	}
 	notificationPackages := make(map[string]*notifier.NotificationPackage)
 	for _, notification := range notifications {
-		packageKey := fmt.Sprintf("%s:%s:%s", notification.Contact.Type, notification.Contact.Value, notification.Event.TriggerID)
+		if notification.Escalation != nil && !worker.processEscalation(notification) {
+			continue
+		}
+		packageKey := getPackageKey(notification)
 		p, found := notificationPackages[packageKey]
 		if !found {
 			p = &notifier.NotificationPackage{
-				Events:    make([]moira.NotificationEvent, 0, len(notifications)),
-				Trigger:   notification.Trigger,
-				Contact:   notification.Contact,
-				Plotting:  notification.Plotting,
-				Throttled: notification.Throttled,
-				FailCount: notification.SendFail,
+				Events:     make([]moira.NotificationEvent, 0, len(notifications)),
+				Trigger:    notification.Trigger,
+				Contact:    notification.Contact,
+				Plotting:   notification.Plotting,
+				Throttled:  notification.Throttled,
+				FailCount:  notification.SendFail,
+				Digest:     notification.Digest,
+				Escalation: notification.Escalation,
+			}
+			if notification.Digest != "" {
+				p.Trigger = moira.TriggerData{}
+				p.Triggers = make(map[string]moira.TriggerData)
 			}
 		}
 		p.Events = append(p.Events, notification.Event)
+		if p.Digest != "" {
+			addToDigest(p, notification)
+		}
 		notificationPackages[packageKey] = p
 	}
 	var sendingWG sync.WaitGroup
@@ -89,3 +102,109 @@ func (worker *FetchNotificationsWorker) processScheduledNotifications() error {
 	sendingWG.Wait()
 	return nil
 }
+
+// processEscalation returns false if escalation notification is sent by is stopped, notification of the last
+// escalation tier is scheduled again to repeat it until escalation is stopped
+func (worker *FetchNotificationsWorker) processEscalation(notification *moira.ScheduledNotification) bool {
+	escalation := notification.Escalation
+	active, err := worker.Database.IsEscalationActive(escalation)
+	if err != nil {
+		worker.Logger.Warningf("Failed to check escalation of trigger %s, notification is sent: %s", escalation.TriggerID, err.Error())
+		return true
+	}
+	if !active {
+		worker.Logger.Debugf("Skip notification of stopped escalation of trigger %s metric %s", escalation.TriggerID, escalation.Metric)
+		return false
+	}
+	if reason := worker.getEscalationStopReason(notification); reason != "" {
+		worker.Logger.Debugf("Stop escalation of trigger %s metric %s by subscription %s: %s",
+			escalation.TriggerID, escalation.Metric, escalation.SubscriptionID, reason)
+		if err := worker.Database.RemoveEscalation(escalation); err != nil {
+			worker.Logger.Errorf("Failed to stop escalation: %s", err.Error())
+		}
+		return false
+	}
+	if escalation.RepeatInterval > 0 && notification.SendFail == 0 {
+		repeated := *notification
+		repeated.Timestamp = time.Now().Unix() + escalation.RepeatInterval
+		if err := worker.Database.AddNotification(&repeated); err != nil {
+			worker.Logger.Errorf("Failed to save repeated escalation notification: %s", err.Error())
+		}
+	}
+	return true
+}
+
+// getEscalationStopReason returns reason to stop escalation before notifying its next tier, it is empty if escalation goes on.
+// Escalation is stopped if its subscription is removed, disabled or has no escalation anymore and if escalated ERROR state
+// is not actual: trigger or metric is removed, recovered or acknowledged. OK event can be suppressed by maintenance
+// or schedule, so state is checked by last check of trigger
+func (worker *FetchNotificationsWorker) getEscalationStopReason(notification *moira.ScheduledNotification) string {
+	escalation := notification.Escalation
+	subscription, err := worker.Database.GetSubscription(escalation.SubscriptionID)
+	if err != nil {
+		if err == database.ErrNil {
+			return "subscription is removed"
+		}
+		worker.Logger.Warningf("Failed to get subscription %s of escalation: %s", escalation.SubscriptionID, err.Error())
+		return ""
+	}
+	if !subscription.Enabled {
+		return "subscription is disabled"
+	}
+	if !subscription.Escalation.IsEnabled() {
+		return "subscription has no escalation"
+	}
+
+	lastCheck, err := worker.Database.GetTriggerLastCheck(escalation.TriggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return "trigger is removed"
+		}
+		worker.Logger.Warningf("Failed to get last check of trigger %s of escalation: %s", escalation.TriggerID, err.Error())
+		return ""
+	}
+	now := time.Now().Unix()
+	if lastCheck.Ack.IsActive(now) {
+		return "trigger is acknowledged"
+	}
+	if notification.Event.IsTriggerEvent {
+		if lastCheck.State != moira.StateERROR {
+			return fmt.Sprintf("trigger state is %s", lastCheck.State)
+		}
+		return ""
+	}
+	metricState, ok := lastCheck.Metrics[escalation.Metric]
+	if !ok {
+		return "metric is removed"
+	}
+	if metricState.State != moira.StateERROR {
+		return fmt.Sprintf("metric state is %s", metricState.State)
+	}
+	if metricState.Ack.IsActive(now) {
+		return "metric is acknowledged"
+	}
+	return ""
+}
+
+// getPackageKey returns key of package notification is sent in: notifications of one trigger to the same contact
+// are sent together, digest notifications of any triggers with the same digest key are sent together.
+// Escalation notification is sent in its own package, so escalation state is kept if it is resent
+func getPackageKey(notification *moira.ScheduledNotification) string {
+	if escalation := notification.Escalation; escalation != nil {
+		return fmt.Sprintf("%s:%s:%s:escalation:%s:%s", notification.Contact.Type, notification.Contact.Value,
+			escalation.TriggerID, escalation.SubscriptionID, escalation.Metric)
+	}
+	if notification.Digest != "" {
+		return fmt.Sprintf("%s:%s:digest:%s", notification.Contact.Type, notification.Contact.Value, notification.Digest)
+	}
+	return fmt.Sprintf("%s:%s:%s", notification.Contact.Type, notification.Contact.Value, notification.Event.TriggerID)
+}
+
+// addToDigest adds trigger of notification to digest package, digest package is throttled if any of its notifications is throttled
+func addToDigest(pkg *notifier.NotificationPackage, notification *moira.ScheduledNotification) {
+	pkg.Triggers[notification.Trigger.ID] = notification.Trigger
+	pkg.Throttled = pkg.Throttled || notification.Throttled
+	if notification.SendFail > pkg.FailCount {
+		pkg.FailCount = notification.SendFail
+	}
+}
@@ -9,6 +9,7 @@ import (
 	. "github.com/smartystreets/goconvey/convey"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
 	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
 	mock_notifier "github.com/moira-alert/moira/mock/notifier"
 	notifier2 "github.com/moira-alert/moira/notifier"
@@ -120,6 +121,207 @@ func TestProcessScheduledEvent(t *testing.T) {
 		err := worker.processScheduledNotifications()
 		So(err, ShouldBeEmpty)
 	})
+
+	Convey("Digest notifications of different triggers, should send one digest package", t, func() {
+		trigger1 := moira.TriggerData{ID: "triggerID-00000000000001", Name: "First"}
+		trigger2 := moira.TriggerData{ID: "triggerID-00000000000002", Name: "Second"}
+		digest1 := moira.ScheduledNotification{
+			Event:     moira.NotificationEvent{TriggerID: trigger1.ID, State: moira.StateERROR},
+			Trigger:   trigger1,
+			Contact:   contact1,
+			Timestamp: 1441188900,
+			Digest:    contact1.ID,
+		}
+		digest2 := moira.ScheduledNotification{
+			Event:     moira.NotificationEvent{TriggerID: trigger2.ID, State: moira.StateWARN},
+			Trigger:   trigger2,
+			Contact:   contact1,
+			Throttled: true,
+			SendFail:  1,
+			Timestamp: 1441188900,
+			Digest:    contact1.ID,
+		}
+		dataBase.EXPECT().FetchNotifications(gomock.Any(), notifier2.NotificationsLimitUnlimited).Return([]*moira.ScheduledNotification{
+			&digest1,
+			&digest2,
+			&notification1,
+		}, nil)
+
+		digestPkg := notifier2.NotificationPackage{
+			Contact:   contact1,
+			Throttled: true,
+			FailCount: 1,
+			Events:    []moira.NotificationEvent{digest1.Event, digest2.Event},
+			Digest:    contact1.ID,
+			Triggers: map[string]moira.TriggerData{
+				trigger1.ID: trigger1,
+				trigger2.ID: trigger2,
+			},
+		}
+		pkg := notifier2.NotificationPackage{
+			Contact: notification1.Contact,
+			Events:  []moira.NotificationEvent{notification1.Event},
+		}
+
+		notifier.EXPECT().Send(&digestPkg, gomock.Any())
+		notifier.EXPECT().Send(&pkg, gomock.Any())
+		dataBase.EXPECT().GetNotifierState().Return(moira.SelfStateOK, nil)
+		notifier.EXPECT().GetReadBatchSize().Return(notifier2.NotificationsLimitUnlimited)
+		err := worker.processScheduledNotifications()
+		So(err, ShouldBeEmpty)
+	})
+
+	Convey("Escalation notifications, should skip stopped escalation and repeat the last tier", t, func() {
+		trigger := moira.TriggerData{ID: "triggerID-00000000000001", Name: "First"}
+		stopped := moira.ScheduledNotification{
+			Event:      moira.NotificationEvent{TriggerID: trigger.ID, State: moira.StateERROR},
+			Trigger:    trigger,
+			Contact:    contact1,
+			Timestamp:  1441188900,
+			Escalation: &moira.EscalationData{TriggerID: trigger.ID, SubscriptionID: subID5, Started: 1441188000},
+		}
+		lastTier := moira.ScheduledNotification{
+			Event:      moira.NotificationEvent{TriggerID: trigger.ID, Metric: "metric", State: moira.StateERROR},
+			Trigger:    trigger,
+			Contact:    contact2,
+			Timestamp:  1441188900,
+			Escalation: &moira.EscalationData{TriggerID: trigger.ID, Metric: "metric", SubscriptionID: subID7, Started: 1441188000, Tier: 1, RepeatInterval: 600},
+		}
+		dataBase.EXPECT().FetchNotifications(gomock.Any(), notifier2.NotificationsLimitUnlimited).Return([]*moira.ScheduledNotification{
+			&stopped,
+			&lastTier,
+		}, nil)
+
+		pkg := notifier2.NotificationPackage{
+			Trigger:    trigger,
+			Contact:    contact2,
+			Events:     []moira.NotificationEvent{lastTier.Event},
+			Escalation: lastTier.Escalation,
+		}
+
+		dataBase.EXPECT().IsEscalationActive(stopped.Escalation).Return(false, nil)
+		dataBase.EXPECT().IsEscalationActive(lastTier.Escalation).Return(true, nil)
+		dataBase.EXPECT().GetSubscription(subID7).Return(moira.SubscriptionData{
+			ID:         subID7,
+			Enabled:    true,
+			Escalation: &moira.EscalationPolicy{Tiers: []moira.EscalationTier{{Contacts: []string{contact2.ID}, DelayInMinutes: 10}}},
+		}, nil)
+		dataBase.EXPECT().GetTriggerLastCheck(trigger.ID).Return(moira.CheckData{
+			State:   moira.StateERROR,
+			Metrics: map[string]moira.MetricState{"metric": {State: moira.StateERROR}},
+		}, nil)
+		dataBase.EXPECT().AddNotification(gomock.Any()).Do(func(repeated *moira.ScheduledNotification) {
+			So(repeated.Contact, ShouldResemble, contact2)
+			So(repeated.Escalation, ShouldResemble, lastTier.Escalation)
+			So(repeated.Timestamp, ShouldBeGreaterThanOrEqualTo, time.Now().Unix()+600-1)
+		}).Return(nil)
+		notifier.EXPECT().Send(&pkg, gomock.Any())
+		dataBase.EXPECT().GetNotifierState().Return(moira.SelfStateOK, nil)
+		notifier.EXPECT().GetReadBatchSize().Return(notifier2.NotificationsLimitUnlimited)
+		err := worker.processScheduledNotifications()
+		So(err, ShouldBeEmpty)
+	})
+
+	Convey("Resent escalation notification, should be dropped if metric is acknowledged after failed send", t, func() {
+		trigger := moira.TriggerData{ID: "triggerID-00000000000001", Name: "First"}
+		resent := moira.ScheduledNotification{
+			Event:      moira.NotificationEvent{TriggerID: trigger.ID, Metric: "metric", State: moira.StateERROR},
+			Trigger:    trigger,
+			Contact:    contact2,
+			Timestamp:  1441188900,
+			SendFail:   1,
+			Escalation: &moira.EscalationData{TriggerID: trigger.ID, Metric: "metric", SubscriptionID: subID7, Started: 1441188000},
+		}
+		dataBase.EXPECT().FetchNotifications(gomock.Any(), notifier2.NotificationsLimitUnlimited).Return([]*moira.ScheduledNotification{&resent}, nil)
+		dataBase.EXPECT().IsEscalationActive(resent.Escalation).Return(true, nil)
+		dataBase.EXPECT().GetSubscription(subID7).Return(moira.SubscriptionData{
+			ID:         subID7,
+			Enabled:    true,
+			Escalation: &moira.EscalationPolicy{Tiers: []moira.EscalationTier{{Contacts: []string{contact2.ID}, DelayInMinutes: 10}}},
+		}, nil)
+		dataBase.EXPECT().GetTriggerLastCheck(trigger.ID).Return(moira.CheckData{
+			State:   moira.StateERROR,
+			Metrics: map[string]moira.MetricState{"metric": {State: moira.StateERROR, Ack: &moira.AckData{User: "user"}}},
+		}, nil)
+		dataBase.EXPECT().RemoveEscalation(resent.Escalation).Return(nil)
+		dataBase.EXPECT().GetNotifierState().Return(moira.SelfStateOK, nil)
+		notifier.EXPECT().GetReadBatchSize().Return(notifier2.NotificationsLimitUnlimited)
+		err := worker.processScheduledNotifications()
+		So(err, ShouldBeEmpty)
+	})
+}
+
+func TestGetEscalationStopReason(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Notification")
+	worker := &FetchNotificationsWorker{
+		Database: dataBase,
+		Logger:   logger,
+	}
+
+	subscriptionID := "subscriptionID-00000000000001"
+	triggerID := "triggerID-00000000000001"
+	notification := &moira.ScheduledNotification{
+		Event:      moira.NotificationEvent{TriggerID: triggerID, Metric: "metric", State: moira.StateERROR},
+		Escalation: &moira.EscalationData{TriggerID: triggerID, Metric: "metric", SubscriptionID: subscriptionID, Started: 1441188000},
+	}
+	subscription := moira.SubscriptionData{
+		ID:         subscriptionID,
+		Enabled:    true,
+		Escalation: &moira.EscalationPolicy{Tiers: []moira.EscalationTier{{Contacts: []string{"contact"}, DelayInMinutes: 10}}},
+	}
+	lastCheck := moira.CheckData{
+		State:   moira.StateOK,
+		Metrics: map[string]moira.MetricState{"metric": {State: moira.StateERROR}},
+	}
+
+	Convey("Escalation goes on while metric is in ERROR state", t, func() {
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(subscription, nil)
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		So(worker.getEscalationStopReason(notification), ShouldBeEmpty)
+	})
+
+	Convey("Escalation is stopped by changes of subscription", t, func() {
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(moira.SubscriptionData{}, database.ErrNil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "subscription is removed")
+
+		disabled := subscription
+		disabled.Enabled = false
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(disabled, nil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "subscription is disabled")
+
+		withoutEscalation := subscription
+		withoutEscalation.Escalation = nil
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(withoutEscalation, nil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "subscription has no escalation")
+	})
+
+	Convey("Escalation is stopped if ERROR state is not actual", t, func() {
+		dataBase.EXPECT().GetSubscription(subscriptionID).Return(subscription, nil).Times(5)
+
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "trigger is removed")
+
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{Metrics: map[string]moira.MetricState{}}, nil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "metric is removed")
+
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{
+			Metrics: map[string]moira.MetricState{"metric": {State: moira.StateOK}},
+		}, nil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "metric state is OK")
+
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{
+			Metrics: map[string]moira.MetricState{"metric": {State: moira.StateERROR, Ack: &moira.AckData{User: "user", Timestamp: 1441188000}}},
+		}, nil)
+		So(worker.getEscalationStopReason(notification), ShouldEqual, "metric is acknowledged")
+
+		triggerNotification := *notification
+		triggerNotification.Event.IsTriggerEvent = true
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(lastCheck, nil)
+		So(worker.getEscalationStopReason(&triggerNotification), ShouldEqual, "trigger state is OK")
+	})
 }
 
 func TestGoRoutine(t *testing.T) {
@@ -20,6 +20,12 @@ type NotificationPackage struct {
 	FailCount  int
 	Throttled  bool
 	DontResend bool
+	// Digest is a key of digest package which combines events of several triggers, it is empty for package of single trigger
+	Digest string
+	// Triggers are triggers of digest package events by trigger ID
+	Triggers map[string]moira.TriggerData
+	// Escalation is a state of escalation package is sent by, it is nil for package of subscription contacts
+	Escalation *moira.EscalationData
 }
 
 // String returns notification package summary
@@ -60,6 +66,24 @@ func (pkg NotificationPackage) GetMetricNames() []string {
 	return metricNames
 }
 
+// SplitByTriggers splits digest package to packages of single triggers
+func (pkg NotificationPackage) SplitByTriggers() []NotificationPackage {
+	groups := moira.NotificationEvents(pkg.Events).GroupByTriggers(pkg.Triggers)
+	packages := make([]NotificationPackage, 0, len(groups))
+	for _, group := range groups {
+		packages = append(packages, NotificationPackage{
+			Events:     group.Events,
+			Trigger:    group.Trigger,
+			Contact:    pkg.Contact,
+			Plotting:   pkg.Plotting,
+			FailCount:  pkg.FailCount,
+			Throttled:  pkg.Throttled,
+			DontResend: pkg.DontResend,
+		})
+	}
+	return packages
+}
+
 // Notifier implements notification functionality
 type Notifier interface {
 	Send(pkg *NotificationPackage, waitGroup *sync.WaitGroup)
@@ -88,7 +112,7 @@ func NewNotifier(database moira.Database, logger moira.Logger, config Config, me
 		senders:              make(map[string]chan NotificationPackage),
 		logger:               logger,
 		database:             database,
-		scheduler:            NewScheduler(database, logger, metrics),
+		scheduler:            NewSchedulerWithPolicies(database, logger, metrics, config.ThrottlingPolicies),
 		config:               config,
 		metrics:              metrics,
 		metricSourceProvider: metricSourceProvider,
@@ -100,7 +124,9 @@ func NewNotifier(database moira.Database, logger moira.Logger, config Config, me
 func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync.WaitGroup) {
 	ch, found := notifier.senders[pkg.Contact.Type]
 	if !found {
-		notifier.resend(pkg, fmt.Sprintf("Unknown contact type '%s' [%s]", pkg.Contact.Type, pkg))
+		reason := fmt.Sprintf("Unknown contact type '%s' [%s]", pkg.Contact.Type, pkg)
+		notifier.saveHistory(pkg, 0, reason)
+		notifier.resend(pkg, reason)
 		return
 	}
 	waitGroup.Add(1)
@@ -111,7 +137,9 @@ func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync
 		case ch <- *pkg:
 			break
 		case <-time.After(notifier.config.SendingTimeout):
-			notifier.resend(pkg, fmt.Sprintf("Timeout sending %s", pkg))
+			reason := fmt.Sprintf("Timeout sending %s", pkg)
+			notifier.saveHistory(pkg, notifier.config.SendingTimeout, reason)
+			notifier.resend(pkg, reason)
 			break
 		}
 	}(pkg)
@@ -143,9 +171,16 @@ func (notifier *StandardNotifier) resend(pkg *NotificationPackage, reason string
 	if time.Duration(pkg.FailCount)*time.Minute > notifier.config.ResendingTimeout {
 		notifier.logger.Error("Stop resending. Notification interval is timed out")
 	} else {
+		now := time.Now()
 		for _, event := range pkg.Events {
-			notification := notifier.scheduler.ScheduleNotification(time.Now(), event,
-				pkg.Trigger, pkg.Contact, pkg.Plotting, pkg.Throttled, pkg.FailCount+1)
+			trigger := pkg.Trigger
+			if pkg.Digest != "" {
+				trigger = pkg.Triggers[event.TriggerID]
+			}
+			notification := notifier.scheduler.ScheduleNotification(now, event,
+				trigger, pkg.Contact, pkg.Plotting, pkg.Throttled, pkg.FailCount+1)
+			notification.Digest = pkg.Digest
+			notification.Escalation = pkg.Escalation
 			if err := notifier.database.AddNotification(notification); err != nil {
 				notifier.logger.Errorf("Failed to save scheduled notification: %s", err)
 			}
@@ -162,29 +197,93 @@ func (notifier *StandardNotifier) runSender(sender moira.Sender, ch chan Notific
 	defer notifier.waitGroup.Done()
 
 	for pkg := range ch {
-		plots, err := notifier.buildNotificationPackagePlots(pkg)
-		if err != nil {
-			buildErr := fmt.Sprintf("Can't build notification package plot for %s: %s", pkg.Trigger.ID, err.Error())
-			switch err.(type) {
-			case plotting.ErrNoPointsToRender:
-				notifier.logger.Debugf(buildErr)
-			default:
-				notifier.logger.Errorf(buildErr)
-			}
+		if pkg.Digest != "" {
+			notifier.sendDigest(sender, pkg)
+		} else {
+			notifier.sendPackage(sender, pkg)
 		}
+	}
+}
 
-		err = pkg.Trigger.PopulatedDescription(pkg.Events)
-		if err != nil {
-			notifier.logger.Warningf("Error populate description:\n%v", err)
+func (notifier *StandardNotifier) sendPackage(sender moira.Sender, pkg NotificationPackage) {
+	plots, err := notifier.buildNotificationPackagePlots(pkg)
+	if err != nil {
+		buildErr := fmt.Sprintf("Can't build notification package plot for %s: %s", pkg.Trigger.ID, err.Error())
+		switch err.(type) {
+		case plotting.ErrNoPointsToRender:
+			notifier.logger.Debugf(buildErr)
+		default:
+			notifier.logger.Errorf(buildErr)
 		}
+	}
 
-		err = sender.SendEvents(pkg.Events, pkg.Contact, pkg.Trigger, plots, pkg.Throttled)
-		if err == nil {
-			if metric, found := notifier.metrics.SendersOkMetrics.GetRegisteredMeter(pkg.Contact.Type); found {
-				metric.Mark(1)
-			}
-		} else {
-			notifier.resend(&pkg, err.Error())
+	err = pkg.Trigger.PopulatedDescription(pkg.Events)
+	if err != nil {
+		notifier.logger.Warningf("Error populate description:\n%v", err)
+	}
+
+	started := time.Now()
+	err = sender.SendEvents(pkg.Events, pkg.Contact, pkg.Trigger, plots, pkg.Throttled)
+	notifier.handleSendResult(&pkg, err, time.Since(started))
+}
+
+// sendDigest sends events of several triggers in one message if sender supports digests.
+// Otherwise and if digest contains events of single trigger they are sent in usual messages of each trigger
+func (notifier *StandardNotifier) sendDigest(sender moira.Sender, pkg NotificationPackage) {
+	digestSender, ok := sender.(moira.DigestSender)
+	if !ok || len(pkg.Triggers) == 1 {
+		for _, triggerPkg := range pkg.SplitByTriggers() {
+			notifier.sendPackage(sender, triggerPkg)
 		}
+		return
+	}
+	started := time.Now()
+	err := digestSender.SendDigest(pkg.Events, pkg.Contact, pkg.Triggers, pkg.Throttled)
+	notifier.handleSendResult(&pkg, err, time.Since(started))
+}
+
+func (notifier *StandardNotifier) handleSendResult(pkg *NotificationPackage, err error, duration time.Duration) {
+	if err != nil {
+		notifier.saveHistory(pkg, duration, err.Error())
+		notifier.resend(pkg, err.Error())
+		return
+	}
+	notifier.saveHistory(pkg, duration, "")
+	if metric, found := notifier.metrics.SendersOkMetrics.GetRegisteredMeter(pkg.Contact.Type); found {
+		metric.Mark(1)
+	}
+}
+
+// saveHistory records attempt to send package to notification history, empty sendError means successful attempt
+func (notifier *StandardNotifier) saveHistory(pkg *NotificationPackage, duration time.Duration, sendError string) {
+	if notifier.config.NotificationHistoryTTL <= 0 {
+		return
+	}
+	item := &moira.NotificationHistoryItem{
+		Timestamp:    time.Now().Unix(),
+		ContactID:    pkg.Contact.ID,
+		ContactValue: pkg.Contact.Value,
+		SenderType:   pkg.Contact.Type,
+		TriggerIDs:   make([]string, 0, 1),
+		Events:       make([]moira.NotificationHistoryEvent, 0, len(pkg.Events)),
+		Attempt:      pkg.FailCount + 1,
+		Duration:     int64(duration / time.Millisecond),
+		Success:      sendError == "",
+		Error:        sendError,
+	}
+	for _, event := range pkg.Events {
+		if event.TriggerID != "" && !item.HasTrigger(event.TriggerID) {
+			item.TriggerIDs = append(item.TriggerIDs, event.TriggerID)
+		}
+		item.Events = append(item.Events, moira.NotificationHistoryEvent{
+			TriggerID: event.TriggerID,
+			Metric:    event.Metric,
+			OldState:  event.OldState,
+			State:     event.State,
+			Timestamp: event.Timestamp,
+		})
+	}
+	if err := notifier.database.AddNotificationHistory(item, notifier.config.NotificationHistoryTTL); err != nil {
+		notifier.logger.Errorf("Failed to save notification history of %s: %s", pkg, err.Error())
 	}
 }
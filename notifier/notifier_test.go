@@ -73,6 +73,55 @@ func TestGetWindow(t *testing.T) {
 	})
 }
 
+func TestSplitByTriggers(t *testing.T) {
+	Convey("Digest package is split to packages of triggers ordered by name", t, func() {
+		trigger1 := moira.TriggerData{ID: "trigger1", Name: "Zeta"}
+		trigger2 := moira.TriggerData{ID: "trigger2", Name: "Alpha"}
+		event1 := moira.NotificationEvent{TriggerID: trigger1.ID, Metric: "metric1"}
+		event2 := moira.NotificationEvent{TriggerID: trigger2.ID, Metric: "metric2"}
+		event3 := moira.NotificationEvent{TriggerID: trigger1.ID, Metric: "metric3"}
+		contact := moira.ContactData{Type: "test", Value: "contact"}
+		pkg := NotificationPackage{
+			Events:    []moira.NotificationEvent{event1, event2, event3},
+			Contact:   contact,
+			Throttled: true,
+			FailCount: 2,
+			Digest:    "digest",
+			Triggers:  map[string]moira.TriggerData{trigger1.ID: trigger1, trigger2.ID: trigger2},
+		}
+		So(pkg.SplitByTriggers(), ShouldResemble, []NotificationPackage{
+			{Events: []moira.NotificationEvent{event2}, Trigger: trigger2, Contact: contact, Throttled: true, FailCount: 2},
+			{Events: []moira.NotificationEvent{event1, event3}, Trigger: trigger1, Contact: contact, Throttled: true, FailCount: 2},
+		})
+	})
+}
+
+func TestSendDigestWithoutDigestSender(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	trigger1 := moira.TriggerData{ID: "trigger1", Name: "First"}
+	trigger2 := moira.TriggerData{ID: "trigger2", Name: "Second"}
+	event1 := moira.NotificationEvent{TriggerID: trigger1.ID, Metric: "metric1"}
+	event2 := moira.NotificationEvent{TriggerID: trigger2.ID, Metric: "metric2"}
+	pkg := NotificationPackage{
+		Events:   []moira.NotificationEvent{event1, event2},
+		Contact:  moira.ContactData{Type: "test"},
+		Digest:   "digest",
+		Triggers: map[string]moira.TriggerData{trigger1.ID: trigger1, trigger2.ID: trigger2},
+	}
+
+	var sent sync.WaitGroup
+	sent.Add(2)
+	sender.EXPECT().SendEvents(moira.NotificationEvents{event1}, pkg.Contact, trigger1, plots, false).Return(nil).Do(func(f ...interface{}) { sent.Done() })
+	sender.EXPECT().SendEvents(moira.NotificationEvents{event2}, pkg.Contact, trigger2, plots, false).Return(nil).Do(func(f ...interface{}) { sent.Done() })
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	sent.Wait()
+}
+
 func TestUnknownContactType(t *testing.T) {
 	configureNotifier(t)
 	defer afterTest()
@@ -117,6 +166,84 @@ func TestFailSendEvent(t *testing.T) {
 	time.Sleep(time.Second * 2)
 }
 
+func TestFailSendEscalation(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	var eventsData moira.NotificationEvents = []moira.NotificationEvent{event}
+
+	pkg := NotificationPackage{
+		Events: eventsData,
+		Contact: moira.ContactData{
+			Type: "test",
+		},
+		Escalation: &moira.EscalationData{TriggerID: event.TriggerID, Metric: event.Metric, SubscriptionID: "subscriptionID", Tier: 1},
+	}
+	notification := moira.ScheduledNotification{}
+	sender.EXPECT().SendEvents(eventsData, pkg.Contact, pkg.Trigger, plots, pkg.Throttled).Return(fmt.Errorf("Cant't send"))
+	scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg.Trigger, pkg.Contact, pkg.Plotting, pkg.Throttled, pkg.FailCount+1).Return(&notification)
+	dataBase.EXPECT().AddNotification(&notification).Return(nil)
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	time.Sleep(time.Second * 2)
+
+	Convey("Resent notification keeps escalation state", t, func() {
+		So(notification.Escalation, ShouldResemble, pkg.Escalation)
+	})
+}
+
+func TestSaveNotificationHistory(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+	notif.config.NotificationHistoryTTL = time.Hour
+
+	var eventsData moira.NotificationEvents = []moira.NotificationEvent{event}
+
+	pkg := NotificationPackage{
+		Events: eventsData,
+		Contact: moira.ContactData{
+			ID:    "contactID",
+			Type:  "test",
+			Value: "contact",
+		},
+		FailCount: 2,
+	}
+	var item *moira.NotificationHistoryItem
+	done := make(chan struct{})
+	notification := moira.ScheduledNotification{}
+	sender.EXPECT().SendEvents(eventsData, pkg.Contact, pkg.Trigger, plots, pkg.Throttled).Return(fmt.Errorf("can't send"))
+	dataBase.EXPECT().AddNotificationHistory(gomock.Any(), time.Hour).Return(nil).Do(func(historyItem *moira.NotificationHistoryItem, ttl time.Duration) {
+		item = historyItem
+	})
+	scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg.Trigger, pkg.Contact, pkg.Plotting, pkg.Throttled, pkg.FailCount+1).Return(&notification)
+	dataBase.EXPECT().AddNotification(&notification).Return(nil).Do(func(f ...interface{}) { close(done) })
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	<-done
+
+	Convey("Failed attempt is saved to history", t, func() {
+		So(item, ShouldNotBeNil)
+		So(item.Timestamp, ShouldBeGreaterThan, 0)
+		item.Timestamp = 0
+		item.Duration = 0
+		So(item, ShouldResemble, &moira.NotificationHistoryItem{
+			ContactID:    "contactID",
+			ContactValue: "contact",
+			SenderType:   "test",
+			TriggerIDs:   []string{event.TriggerID},
+			Events: []moira.NotificationHistoryEvent{
+				{TriggerID: event.TriggerID, Metric: event.Metric, OldState: event.OldState, State: event.State},
+			},
+			Attempt: 3,
+			Error:   "can't send",
+		})
+	})
+}
+
 func TestTimeout(t *testing.T) {
 	configureNotifier(t)
 	var wg sync.WaitGroup
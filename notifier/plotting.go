@@ -7,6 +7,7 @@ import (
 
 	"github.com/beevee/go-chart"
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	metricSource "github.com/moira-alert/moira/metric_source"
 	"github.com/moira-alert/moira/metric_source/local"
 	"github.com/moira-alert/moira/plotting"
@@ -32,11 +33,15 @@ func (err errFetchAvailableSeriesFailed) Error() string {
 	return fmt.Sprintf("Failed to fetch both realtime and stored data: [realtime]: %s, [stored]: %s", err.realtimeErr, err.storedErr)
 }
 
-// buildTriggerPlots returns bytes slices containing trigger plots
-func buildTriggerPlots(trigger *moira.Trigger, metricsData map[string][]metricSource.MetricData, plotTemplate *plotting.Plot) ([][]byte, error) {
+// buildTriggerPlots returns bytes slices containing trigger plots, bands are drawn on main target plot
+func buildTriggerPlots(trigger *moira.Trigger, metricsData map[string][]metricSource.MetricData, bands []anomaly.BandSeries, plotTemplate *plotting.Plot) ([][]byte, error) {
 	result := make([][]byte, 0)
 	for targetName, metrics := range metricsData {
-		renderable, err := plotTemplate.GetRenderable(targetName, trigger, metrics)
+		var targetBands []anomaly.BandSeries
+		if targetName == "t1" {
+			targetBands = bands
+		}
+		renderable, err := plotTemplate.GetRenderable(targetName, trigger, metrics, targetBands)
 		if err != nil {
 			return nil, err
 		}
@@ -72,10 +77,29 @@ func (notifier *StandardNotifier) buildNotificationPackagePlots(pkg Notification
 	}
 	metricsData = getMetricDataToShow(metricsData, metricsToShow)
 	notifier.logger.Debugf("Build plot for trigger: %s from MetricsData: %v", trigger.ID, metricsData)
-	result, err := buildTriggerPlots(trigger, metricsData, plotTemplate)
+	bands := notifier.getTriggerAnomalyBands(from, to, trigger, metricsData["t1"])
+	result, err := buildTriggerPlots(trigger, metricsData, bands, plotTemplate)
 	return result, err
 }
 
+// getTriggerAnomalyBands returns bands of anomaly trigger, plot is built without bands if they can not be fetched
+func (notifier *StandardNotifier) getTriggerAnomalyBands(from, to int64, trigger *moira.Trigger, metricsData []metricSource.MetricData) []anomaly.BandSeries {
+	if trigger.TriggerType != moira.AnomalyTrigger {
+		return nil
+	}
+	metricsSource, err := notifier.metricSourceProvider.GetTriggerMetricSource(trigger)
+	if err != nil {
+		notifier.logger.Warningf("Failed to get trigger %s metric source to build anomaly bands: %s", trigger.ID, err.Error())
+		return nil
+	}
+	bands, err := anomaly.GetTriggerBands(metricsSource, trigger, from, to, metricsData, false)
+	if err != nil {
+		notifier.logger.Warningf("Failed to fetch trigger %s anomaly bands: %s", trigger.ID, err.Error())
+		return nil
+	}
+	return bands
+}
+
 // resolveMetricsWindow returns from, to parameters depending on trigger type
 func resolveMetricsWindow(logger moira.Logger, trigger moira.TriggerData, pkg NotificationPackage) (int64, int64) {
 	// resolve default realtime window for any case
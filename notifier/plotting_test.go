@@ -253,7 +253,7 @@ func TestBuildTriggerPlots(t *testing.T) {
 
 		Convey("without errors", func() {
 			testMetricsData := generateTestMetricsData()
-			result, err := buildTriggerPlots(&trigger, testMetricsData, plotTemplate)
+			result, err := buildTriggerPlots(&trigger, testMetricsData, nil, plotTemplate)
 			So(len(result), ShouldResemble, 4)
 			So(err, ShouldBeNil)
 		})
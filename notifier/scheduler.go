@@ -16,23 +16,24 @@ type Scheduler interface {
 
 // StandardScheduler represents standard event scheduling
 type StandardScheduler struct {
-	logger   moira.Logger
-	database moira.Database
-	metrics  *metrics.NotifierMetrics
+	logger             moira.Logger
+	database           moira.Database
+	metrics            *metrics.NotifierMetrics
+	throttlingPolicies map[string]ThrottlingPolicy
 }
 
-type throttlingLevel struct {
-	duration time.Duration
-	delay    time.Duration
-	count    int64
+// NewScheduler is initializer for StandardScheduler with default throttling policy only
+func NewScheduler(database moira.Database, logger moira.Logger, metrics *metrics.NotifierMetrics) *StandardScheduler {
+	return NewSchedulerWithPolicies(database, logger, metrics, nil)
 }
 
-// NewScheduler is initializer for StandardScheduler
-func NewScheduler(database moira.Database, logger moira.Logger, metrics *metrics.NotifierMetrics) *StandardScheduler {
+// NewSchedulerWithPolicies is initializer for StandardScheduler with throttling policies which subscriptions select by name
+func NewSchedulerWithPolicies(database moira.Database, logger moira.Logger, metrics *metrics.NotifierMetrics, throttlingPolicies map[string]ThrottlingPolicy) *StandardScheduler {
 	return &StandardScheduler{
-		database: database,
-		logger:   logger,
-		metrics:  metrics,
+		database:           database,
+		logger:             logger,
+		metrics:            metrics,
+		throttlingPolicies: throttlingPolicies,
 	}
 }
 
@@ -72,56 +73,25 @@ func (scheduler *StandardScheduler) ScheduleNotification(now time.Time, event mo
 }
 
 func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *moira.NotificationEvent) (time.Time, bool) {
-	// if trigger switches more than .count times in .length seconds, delay next delivery for .delay seconds
-	// processing stops after first condition matches
-	throttlingLevels := []throttlingLevel{
-		{3 * time.Hour, time.Hour, 20},
-		{time.Hour, time.Hour / 2, 10},
-	}
-
-	alarmFatigue := false
-
-	next, beginning := scheduler.database.GetTriggerThrottling(event.TriggerID)
-
-	if next.After(now) {
-		alarmFatigue = true
-	} else {
-		next = now
-	}
-
 	subscription, err := scheduler.database.GetSubscription(moira.UseString(event.SubscriptionID))
 	if err != nil {
 		scheduler.metrics.SubsMalformed.Mark(1)
 		scheduler.logger.Debugf("Failed get subscription by id: %s. %s", moira.UseString(event.SubscriptionID), err.Error())
-		return next, alarmFatigue
+		next, _ := scheduler.database.GetTriggerThrottling(event.TriggerID)
+		if next.After(now) {
+			return next, true
+		}
+		return now, false
 	}
 
+	next := now
+	alarmFatigue := false
 	if subscription.ThrottlingEnabled {
-		if next.After(now) {
-			scheduler.logger.Debugf("Using existing throttling for trigger %s: %s", event.TriggerID, next)
-		} else {
-			for _, level := range throttlingLevels {
-				from := now.Add(-level.duration)
-				if from.Before(beginning) {
-					from = beginning
-				}
-				count := scheduler.database.GetNotificationEventCount(event.TriggerID, from.Unix())
-				if count >= level.count {
-					next = now.Add(level.delay)
-					scheduler.logger.Debugf("Trigger %s switched %d times in last %s, delaying next notification for %s",
-						event.TriggerID, count, level.duration, level.delay)
-					if err = scheduler.database.SetTriggerThrottling(event.TriggerID, next); err != nil {
-						scheduler.logger.Errorf("Failed to set trigger throttling timestamp: %s", err)
-					}
-					alarmFatigue = true
-					break
-				} else if count == level.count-1 {
-					alarmFatigue = true
-				}
-			}
-		}
+		next, alarmFatigue = scheduler.applyThrottlingPolicy(now, event.TriggerID, subscription.ThrottlingPolicy)
 	} else {
-		next = now
+		// notification is not delayed, but it is marked as throttled if trigger is throttled by other subscriptions
+		throttling, _ := scheduler.database.GetTriggerThrottling(event.TriggerID)
+		alarmFatigue = throttling.After(now)
 	}
 	next, err = calculateNextDelivery(&subscription.Schedule, next)
 	if err != nil {
@@ -130,6 +100,57 @@ func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *
 	return next, alarmFatigue
 }
 
+// applyThrottlingPolicy returns time of next delivery delayed by throttling policy selected in subscription.
+// Throttling is stored per policy, so subscriptions of trigger are delayed only by policies they select
+func (scheduler *StandardScheduler) applyThrottlingPolicy(now time.Time, triggerID, policyName string) (time.Time, bool) {
+	policyName, policy := scheduler.getThrottlingPolicy(policyName)
+	next, beginning := scheduler.database.GetTriggerPolicyThrottling(triggerID, policyName)
+	if next.After(now) {
+		scheduler.logger.Debugf("Using existing throttling for trigger %s by policy '%s': %s", triggerID, policyName, next)
+		return next, true
+	}
+
+	// if trigger switches more than level count times in level window, delay next delivery for level delay
+	// processing stops after first condition matches
+	alarmFatigue := false
+	for _, level := range policy {
+		from := now.Add(-level.Window)
+		if from.Before(beginning) {
+			from = beginning
+		}
+		count := scheduler.database.GetNotificationEventCount(triggerID, from.Unix())
+		if count >= level.Count {
+			next = now.Add(level.Delay)
+			reason := fmt.Sprintf("trigger switched %d times in last %s, notifications are delayed for %s by throttling policy '%s'",
+				count, level.Window, level.Delay, policyName)
+			scheduler.logger.Debugf("Trigger %s: %s", triggerID, reason)
+			if err := scheduler.database.SetTriggerThrottling(triggerID, policyName, next, reason); err != nil {
+				scheduler.logger.Errorf("Failed to set trigger throttling timestamp: %s", err)
+			}
+			return next, true
+		} else if count == level.Count-1 {
+			alarmFatigue = true
+		}
+	}
+	return now, alarmFatigue
+}
+
+// getThrottlingPolicy returns name and levels of throttling policy selected in subscription,
+// unknown policies are replaced with default one
+func (scheduler *StandardScheduler) getThrottlingPolicy(name string) (string, ThrottlingPolicy) {
+	if name == "" {
+		name = DefaultThrottlingPolicy
+	}
+	if policy, ok := scheduler.throttlingPolicies[name]; ok {
+		return name, policy
+	}
+	if name != DefaultThrottlingPolicy {
+		scheduler.logger.Warningf("Unknown throttling policy '%s', default policy is used", name)
+		return scheduler.getThrottlingPolicy(DefaultThrottlingPolicy)
+	}
+	return name, defaultThrottlingPolicy
+}
+
 func calculateNextDelivery(schedule *moira.ScheduleData, nextTime time.Time) (time.Time, error) {
 	if len(schedule.Days) != 0 && len(schedule.Days) != 7 {
 		return nextTime, fmt.Errorf("invalid scheduled settings: %d days defined", len(schedule.Days))
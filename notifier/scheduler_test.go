@@ -183,7 +183,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 		subscription.ThrottlingEnabled = true
 
 		Convey("Has trigger events count slightly less than low throttling level, should next timestamp now minutes, but throttling", func() {
-			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, DefaultThrottlingPolicy).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(13))
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(9))
@@ -194,11 +194,12 @@ func TestSubscriptionSchedule(t *testing.T) {
 		})
 
 		Convey("Has trigger events count event more than low throttling level, should next timestamp in 30 minutes", func() {
-			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, DefaultThrottlingPolicy).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(10))
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(10))
-			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, now.Add(time.Hour/2)).Return(nil)
+			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, DefaultThrottlingPolicy, now.Add(time.Hour/2),
+				"trigger switched 10 times in last 1h0m0s, notifications are delayed for 30m0s by throttling policy 'default'").Return(nil)
 
 			next, throttled := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, time.Unix(1441135800, 0))
@@ -206,18 +207,61 @@ func TestSubscriptionSchedule(t *testing.T) {
 		})
 
 		Convey("Has trigger event more than high throttling level, should next timestamp in 1 hour", func() {
-			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, DefaultThrottlingPolicy).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(20))
-			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, now.Add(time.Hour)).Return(nil)
+			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, DefaultThrottlingPolicy, now.Add(time.Hour),
+				"trigger switched 20 times in last 3h0m0s, notifications are delayed for 1h0m0s by throttling policy 'default'").Return(nil)
 
 			next, throttled := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, now.Add(time.Hour))
 			So(throttled, ShouldBeTrue)
 		})
 
+		Convey("Subscription policy is used instead of default one", func() {
+			scheduler := NewSchedulerWithPolicies(dataBase, logger, notifierMetrics, map[string]ThrottlingPolicy{
+				"strict": {{Window: time.Hour, Count: 3, Delay: 2 * time.Hour}},
+			})
+			strictSubscription := subscription
+			strictSubscription.ThrottlingPolicy = "strict"
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, "strict").Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(strictSubscription, nil)
+			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(3))
+			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, "strict", now.Add(2*time.Hour),
+				"trigger switched 3 times in last 1h0m0s, notifications are delayed for 2h0m0s by throttling policy 'strict'").Return(nil)
+
+			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			So(next, ShouldResemble, now.Add(2*time.Hour))
+			So(throttled, ShouldBeTrue)
+		})
+
+		Convey("Policy without levels does not delay notifications", func() {
+			scheduler := NewSchedulerWithPolicies(dataBase, logger, notifierMetrics, map[string]ThrottlingPolicy{
+				"none": {},
+			})
+			noneSubscription := subscription
+			noneSubscription.ThrottlingPolicy = "none"
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, "none").Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(noneSubscription, nil)
+
+			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			So(next, ShouldResemble, now)
+			So(throttled, ShouldBeFalse)
+		})
+
+		Convey("Throttling of other policy does not delay notifications", func() {
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, DefaultThrottlingPolicy).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
+			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(3))
+			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(3))
+
+			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			So(next, ShouldResemble, now)
+			So(throttled, ShouldBeFalse)
+		})
+
 		Convey("Trigger already alarm fatigue, should has old throttled value", func() {
-			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(1441148000, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetTriggerPolicyThrottling(event.TriggerID, DefaultThrottlingPolicy).Return(time.Unix(1441148000, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
 			next, throttled := scheduler.calculateNextDelivery(now, &event)
@@ -358,3 +402,34 @@ var schedule3 = moira.ScheduleData{
 		{Enabled: true},
 	},
 }
+
+func TestGetThrottlingPolicy(t *testing.T) {
+	logger, _ := logging.GetLogger("Scheduler")
+	notifierMetrics := metrics.ConfigureNotifierMetrics(metrics.NewDummyRegistry(), "notifier")
+	strict := ThrottlingPolicy{{Window: time.Hour, Count: 3, Delay: time.Hour}}
+
+	Convey("Without configured policies default policy is built-in one", t, func() {
+		scheduler := NewScheduler(nil, logger, notifierMetrics)
+		name, policy := scheduler.getThrottlingPolicy("")
+		So(name, ShouldEqual, DefaultThrottlingPolicy)
+		So(policy, ShouldResemble, defaultThrottlingPolicy)
+
+		name, policy = scheduler.getThrottlingPolicy("strict")
+		So(name, ShouldEqual, DefaultThrottlingPolicy)
+		So(policy, ShouldResemble, defaultThrottlingPolicy)
+	})
+
+	Convey("Configured policies are selected by name and can override default one", t, func() {
+		scheduler := NewSchedulerWithPolicies(nil, logger, notifierMetrics, map[string]ThrottlingPolicy{
+			"strict":                strict,
+			DefaultThrottlingPolicy: {},
+		})
+		name, policy := scheduler.getThrottlingPolicy("strict")
+		So(name, ShouldEqual, "strict")
+		So(policy, ShouldResemble, strict)
+
+		name, policy = scheduler.getThrottlingPolicy("unknown")
+		So(name, ShouldEqual, DefaultThrottlingPolicy)
+		So(policy, ShouldResemble, ThrottlingPolicy{})
+	})
+}
@@ -31,8 +31,10 @@ func (selfCheck *SelfCheckWorker) selfStateChecker(stop <-chan struct{}) error {
 		selfCheck.Heartbeats = append(selfCheck.Heartbeats, heartbeat)
 	}
 
-	if heartbeat := heartbeat.GetRemoteChecker(selfCheck.Config.LastRemoteCheckDelaySeconds, selfCheck.Logger, selfCheck.Database); heartbeat != nil && heartbeat.NeedToCheckOthers() {
-		selfCheck.Heartbeats = append(selfCheck.Heartbeats, heartbeat)
+	for _, source := range selfCheck.Config.getRemoteSources() {
+		if heartbeat := heartbeat.GetRemoteChecker(source, selfCheck.Config.LastRemoteCheckDelaySeconds, selfCheck.Logger, selfCheck.Database); heartbeat != nil && heartbeat.NeedToCheckOthers() {
+			selfCheck.Heartbeats = append(selfCheck.Heartbeats, heartbeat)
+		}
 	}
 
 	if heartbeat := heartbeat.GetNotifier(selfCheck.Logger, selfCheck.Database); heartbeat != nil {
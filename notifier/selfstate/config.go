@@ -2,6 +2,8 @@ package selfstate
 
 import (
 	"fmt"
+
+	"github.com/moira-alert/moira"
 )
 
 // Config is representation of self state worker settings like moira admins contacts and threshold values for checked services
@@ -11,6 +13,7 @@ type Config struct {
 	LastMetricReceivedDelaySeconds int64
 	LastCheckDelaySeconds          int64
 	LastRemoteCheckDelaySeconds    int64
+	RemoteSources                  []string
 	NoticeIntervalSeconds          int64
 	Contacts                       []map[string]string
 }
@@ -32,3 +35,14 @@ func (config *Config) checkConfig(senders map[string]bool) error {
 	}
 	return nil
 }
+
+// getRemoteSources returns names of remote sources which checkers are monitored, default remote source is always monitored
+func (config *Config) getRemoteSources() []string {
+	sources := []string{moira.DefaultRemoteSource}
+	for _, source := range config.RemoteSources {
+		if source != moira.DefaultRemoteSource {
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
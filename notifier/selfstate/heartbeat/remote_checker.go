@@ -1,6 +1,7 @@
 package heartbeat
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/moira-alert/moira"
@@ -8,28 +9,32 @@ import (
 
 type remoteChecker struct {
 	heartbeat
-	count int64
+	source string
+	count  int64
 }
 
-func GetRemoteChecker(delay int64, logger moira.Logger, database moira.Database) Heartbeater {
+func GetRemoteChecker(source string, delay int64, logger moira.Logger, database moira.Database) Heartbeater {
 	if delay > 0 {
-		return &remoteChecker{heartbeat: heartbeat{
-			logger:              logger,
-			database:            database,
-			delay:               delay,
-			lastSuccessfulCheck: time.Now().Unix(),
-		}}
+		return &remoteChecker{
+			heartbeat: heartbeat{
+				logger:              logger,
+				database:            database,
+				delay:               delay,
+				lastSuccessfulCheck: time.Now().Unix(),
+			},
+			source: source,
+		}
 	}
 	return nil
 }
 
 func (check *remoteChecker) Check(nowTS int64) (int64, bool, error) {
-	triggerCount, err := check.database.GetRemoteTriggersToCheckCount()
+	triggerCount, err := check.database.GetRemoteTriggersToCheckCount(check.source)
 	if err != nil {
 		return 0, false, err
 	}
 
-	remoteTriggersCount, _ := check.database.GetRemoteChecksUpdatesCount()
+	remoteTriggersCount, _ := check.database.GetRemoteChecksUpdatesCount(check.source)
 	if check.count != remoteTriggersCount || triggerCount == 0 {
 		check.count = remoteTriggersCount
 		check.lastSuccessfulCheck = nowTS
@@ -51,6 +56,9 @@ func (remoteChecker) NeedToCheckOthers() bool {
 	return true
 }
 
-func (remoteChecker) GetErrorMessage() string {
-	return "Moira-Remote-Checker does not check remote triggers"
+func (check remoteChecker) GetErrorMessage() string {
+	if check.source == moira.DefaultRemoteSource {
+		return "Moira-Remote-Checker does not check remote triggers"
+	}
+	return fmt.Sprintf("Moira-Remote-Checker does not check triggers of %s remote source", check.source)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/moira-alert/moira"
 	mock_moira_alert "github.com/moira-alert/moira/mock/moira-alert"
 
 	"github.com/golang/mock/gomock"
@@ -20,13 +21,13 @@ func TestGraphiteRemoteChecker(t *testing.T) {
 		database := check.database.(*mock_moira_alert.MockDatabase)
 
 		Convey("Checking the created graphite remote checker", func() {
-			expected := &remoteChecker{heartbeat: heartbeat{database: check.database, logger: check.logger, delay: 1, lastSuccessfulCheck: now}}
-			So(GetRemoteChecker(0, check.logger, check.database), ShouldBeNil)
-			So(GetRemoteChecker(1, check.logger, check.database), ShouldResemble, expected)
+			expected := &remoteChecker{heartbeat: heartbeat{database: check.database, logger: check.logger, delay: 1, lastSuccessfulCheck: now}, source: moira.DefaultRemoteSource}
+			So(GetRemoteChecker(moira.DefaultRemoteSource, 0, check.logger, check.database), ShouldBeNil)
+			So(GetRemoteChecker(moira.DefaultRemoteSource, 1, check.logger, check.database), ShouldResemble, expected)
 		})
 
 		Convey("GraphiteRemoteChecker error handling test", func() {
-			database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(0), err)
+			database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(0), err)
 
 			value, needSend, errActual := check.Check(now)
 			So(errActual, ShouldEqual, err)
@@ -36,8 +37,8 @@ func TestGraphiteRemoteChecker(t *testing.T) {
 
 		Convey("Test update lastSuccessfulCheck", func() {
 			now += 1000
-			database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(1), nil)
-			database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(1), nil)
+			database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(1), nil)
+			database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(1), nil)
 
 			value, needSend, errActual := check.Check(now)
 			So(errActual, ShouldBeNil)
@@ -49,8 +50,8 @@ func TestGraphiteRemoteChecker(t *testing.T) {
 		Convey("Check for notification", func() {
 			check.lastSuccessfulCheck = now - check.delay - 1
 
-			database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(0), nil)
-			database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(1), nil)
+			database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(0), nil)
+			database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(1), nil)
 
 			value, needSend, errActual := check.Check(now)
 			So(errActual, ShouldBeNil)
@@ -59,8 +60,8 @@ func TestGraphiteRemoteChecker(t *testing.T) {
 		})
 
 		Convey("Exit without action", func() {
-			database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(0), nil)
-			database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(1), nil)
+			database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(0), nil)
+			database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(1), nil)
 
 			value, needSend, errActual := check.Check(now)
 			So(errActual, ShouldBeNil)
@@ -69,11 +70,11 @@ func TestGraphiteRemoteChecker(t *testing.T) {
 		})
 
 		Convey("Test NeedToCheckOthers and NeedTurnOffNotifier", func() {
-			database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(1), nil)
-			database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(0), nil)
+			database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(1), nil)
+			database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(0), nil)
 			So(check.NeedToCheckOthers(), ShouldBeTrue)
 
-			database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(0), nil)
+			database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(0), nil)
 			So(check.NeedToCheckOthers(), ShouldBeTrue)
 
 			So(check.NeedTurnOffNotifier(), ShouldBeFalse)
@@ -85,5 +86,13 @@ func createGraphiteRemoteCheckerTest(t *testing.T) *remoteChecker {
 	mockCtrl := gomock.NewController(t)
 	logger, _ := logging.GetLogger("MetricDelay")
 
-	return GetRemoteChecker(120, logger, mock_moira_alert.NewMockDatabase(mockCtrl)).(*remoteChecker)
+	return GetRemoteChecker(moira.DefaultRemoteSource, 120, logger, mock_moira_alert.NewMockDatabase(mockCtrl)).(*remoteChecker)
+}
+
+func TestRemoteCheckerErrorMessage(t *testing.T) {
+	logger, _ := logging.GetLogger("MetricDelay")
+	Convey("Remote checker error message should contain name of not default source", t, func() {
+		So(GetRemoteChecker(moira.DefaultRemoteSource, 120, logger, nil).GetErrorMessage(), ShouldEqual, "Moira-Remote-Checker does not check remote triggers")
+		So(GetRemoteChecker("prometheus", 120, logger, nil).GetErrorMessage(), ShouldEqual, "Moira-Remote-Checker does not check triggers of prometheus remote source")
+	})
 }
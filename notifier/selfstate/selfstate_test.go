@@ -33,9 +33,9 @@ func TestSelfCheckWorker_selfStateChecker(t *testing.T) {
 		var events []moira.NotificationEvent
 		mock.database.EXPECT().GetChecksUpdatesCount().Return(int64(1), nil).Times(2)
 		mock.database.EXPECT().GetMetricsUpdatesCount().Return(int64(1), nil)
-		mock.database.EXPECT().GetRemoteChecksUpdatesCount().Return(int64(1), nil)
+		mock.database.EXPECT().GetRemoteChecksUpdatesCount(moira.DefaultRemoteSource).Return(int64(1), nil)
 		mock.database.EXPECT().GetNotifierState().Return(moira.SelfStateOK, nil)
-		mock.database.EXPECT().GetRemoteTriggersToCheckCount().Return(int64(1), nil)
+		mock.database.EXPECT().GetRemoteTriggersToCheckCount(moira.DefaultRemoteSource).Return(int64(1), nil)
 		mock.database.EXPECT().GetLocalTriggersToCheckCount().Return(int64(1), nil).Times(2)
 		mock.notif.EXPECT().Send(gomock.Any(), gomock.Any())
 
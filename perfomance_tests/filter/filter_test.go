@@ -51,7 +51,7 @@ func BenchmarkProcessIncomingMetric(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		patternsStorage.ProcessIncomingMetric([]byte(testMetricsLines[i]))
+		patternsStorage.ProcessIncomingMetric([]byte(testMetricsLines[i]), "")
 	}
 }
 
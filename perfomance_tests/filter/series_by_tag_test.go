@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/moira-alert/moira/filter"
+)
+
+func BenchmarkMatchSeriesByTagPatterns(b *testing.B) {
+	for _, patternsCount := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d patterns", patternsCount), func(b *testing.B) {
+			index := filter.NewSeriesByTagPatternIndex(generateSeriesByTagPatterns(patternsCount))
+			names, labels := generateTaggedMetrics(patternsCount, b.N)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				index.MatchPatterns(names[i], labels[i])
+			}
+		})
+	}
+}
+
+// generateSeriesByTagPatterns generates patterns which look like patterns of tagged triggers:
+// most of them have equality specs and some of them have only regex specs
+func generateSeriesByTagPatterns(count int) map[string][]filter.TagSpec {
+	tagSpecsByPattern := make(map[string][]filter.TagSpec, count)
+	for i := 0; i < count; i++ {
+		var pattern string
+		switch i % 10 {
+		case 0:
+			pattern = fmt.Sprintf("seriesByTag('name=~service%d\\.', 'host=~host%d')", i, i%100)
+		case 1:
+			pattern = fmt.Sprintf("seriesByTag('name=service%d.cpu', 'dc!=dc%d')", i, i%3)
+		default:
+			pattern = fmt.Sprintf("seriesByTag('name=service%d.cpu', 'dc=dc%d', 'host=~host%d')", i, i%3, i%100)
+		}
+		tagSpecs, err := filter.ParseSeriesByTag(pattern)
+		if err != nil {
+			panic(fmt.Sprintf("can not parse pattern %s: %s", pattern, err))
+		}
+		tagSpecsByPattern[pattern] = tagSpecs
+	}
+	return tagSpecsByPattern
+}
+
+func generateTaggedMetrics(patternsCount, count int) ([]string, []map[string]string) {
+	names := make([]string, 0, count)
+	labels := make([]map[string]string, 0, count)
+	for i := 0; i < count; i++ {
+		service := rand.Intn(patternsCount * 2)
+		names = append(names, fmt.Sprintf("service%d.cpu", service))
+		labels = append(labels, map[string]string{
+			"dc":   fmt.Sprintf("dc%d", rand.Intn(3)),
+			"host": fmt.Sprintf("host%d", rand.Intn(100)),
+		})
+	}
+	return names, labels
+}
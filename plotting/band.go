@@ -0,0 +1,51 @@
+package plotting
+
+import (
+	"github.com/beevee/go-chart"
+	"github.com/beevee/go-chart/drawing"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
+	metricSource "github.com/moira-alert/moira/metric_source"
+)
+
+// bandDashArray is a dash pattern of anomaly band bounds
+var bandDashArray = []float64{5, 5}
+
+// getBandBounds returns lower and upper bounds of anomaly bands by threshold type
+func getBandBounds(trigger *moira.Trigger, bands []anomaly.BandSeries) map[string][]metricSource.MetricData {
+	bounds := make(map[string][]metricSource.MetricData)
+	if trigger.Anomaly == nil {
+		return bounds
+	}
+	sensitivities := map[string]*float64{
+		"ERROR": trigger.Anomaly.ErrorSensitivity,
+		"WARN":  trigger.Anomaly.WarnSensitivity,
+	}
+	for thresholdType, sensitivity := range sensitivities {
+		if sensitivity == nil {
+			continue
+		}
+		for _, band := range bands {
+			lower, upper := band.GetBounds(*sensitivity)
+			bounds[thresholdType] = append(bounds[thresholdType], lower, upper)
+		}
+	}
+	return bounds
+}
+
+// getBandSeriesList returns dashed curves of anomaly band bounds
+func getBandSeriesList(bounds map[string][]metricSource.MetricData, theme moira.PlotTheme) []chart.Series {
+	bandSeriesList := make([]chart.Series, 0)
+	for _, thresholdType := range []string{"ERROR", "WARN"} {
+		style := theme.GetThresholdStyle(thresholdType)
+		style.FillColor = drawing.ColorTransparent
+		style.StrokeDashArray = bandDashArray
+		for _, bound := range bounds[thresholdType] {
+			for _, curve := range generatePlotCurves(bound, style, style) {
+				curve.Name = thresholdSerie
+				bandSeriesList = append(bandSeriesList, curve)
+			}
+		}
+	}
+	return bandSeriesList
+}
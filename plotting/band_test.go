@@ -0,0 +1,65 @@
+package plotting
+
+import (
+	"math"
+	"testing"
+
+	"github.com/beevee/go-chart"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
+)
+
+// TestGetBandSeriesList tests anomaly band bounds are drawn as dashed threshold curves
+func TestGetBandSeriesList(t *testing.T) {
+	theme, _ := getPlotTheme("light")
+	warnSensitivity := float64(1)
+	errorSensitivity := float64(2)
+	bands := []anomaly.BandSeries{
+		{
+			Name:      "metric",
+			StartTime: 0,
+			StepTime:  60,
+			Expected:  []float64{10, 10, math.NaN()},
+			Deviation: []float64{1, 2, math.NaN()},
+		},
+	}
+
+	Convey("Not anomaly trigger has no band curves", t, func() {
+		bounds := getBandBounds(&moira.Trigger{TriggerType: moira.RisingTrigger}, bands)
+		So(bounds, ShouldBeEmpty)
+		So(getBandSeriesList(bounds, theme), ShouldBeEmpty)
+	})
+
+	Convey("Anomaly trigger with both sensitivities", t, func() {
+		trigger := &moira.Trigger{
+			TriggerType: moira.AnomalyTrigger,
+			Anomaly:     &moira.AnomalySettings{WarnSensitivity: &warnSensitivity, ErrorSensitivity: &errorSensitivity},
+		}
+		bounds := getBandBounds(trigger, bands)
+		So(bounds["WARN"][0].Values[:2], ShouldResemble, []float64{9, 8})
+		So(bounds["WARN"][1].Values[:2], ShouldResemble, []float64{11, 12})
+		So(bounds["ERROR"][0].Values[:2], ShouldResemble, []float64{8, 6})
+		So(bounds["ERROR"][1].Values[:2], ShouldResemble, []float64{12, 14})
+
+		seriesList := getBandSeriesList(bounds, theme)
+		So(seriesList, ShouldHaveLength, 4)
+		for _, series := range seriesList {
+			timeSeries := series.(chart.TimeSeries)
+			So(timeSeries.Name, ShouldEqual, thresholdSerie)
+			So(timeSeries.Style.StrokeDashArray, ShouldResemble, bandDashArray)
+			So(timeSeries.YValues, ShouldHaveLength, 2)
+		}
+	})
+
+	Convey("Anomaly trigger with error sensitivity only", t, func() {
+		trigger := &moira.Trigger{
+			TriggerType: moira.AnomalyTrigger,
+			Anomaly:     &moira.AnomalySettings{ErrorSensitivity: &errorSensitivity},
+		}
+		bounds := getBandBounds(trigger, bands)
+		So(bounds["WARN"], ShouldBeEmpty)
+		So(getBandSeriesList(bounds, theme), ShouldHaveLength, 2)
+	})
+}
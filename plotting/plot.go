@@ -6,6 +6,7 @@ import (
 
 	"github.com/beevee/go-chart"
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/anomaly"
 	metricSource "github.com/moira-alert/moira/metric_source"
 )
 
@@ -48,13 +49,19 @@ func GetPlotTemplate(theme string, location *time.Location) (*Plot, error) {
 	}, nil
 }
 
-// GetRenderable returns go-chart to render
-func (plot *Plot) GetRenderable(targetName string, trigger *moira.Trigger, metricsData []metricSource.MetricData) (chart.Chart, error) {
+// GetRenderable returns go-chart to render.
+// Bands are drawn for anomaly trigger only and can be nil
+func (plot *Plot) GetRenderable(targetName string, trigger *moira.Trigger, metricsData []metricSource.MetricData, bands []anomaly.BandSeries) (chart.Chart, error) {
 	var renderable chart.Chart
 
 	plotSeries := make([]chart.Series, 0)
 
-	limits := resolveLimits(metricsData)
+	bandBounds := getBandBounds(trigger, bands)
+	limitsData := append(make([]metricSource.MetricData, 0, len(metricsData)), metricsData...)
+	for _, bounds := range bandBounds {
+		limitsData = append(limitsData, bounds...)
+	}
+	limits := resolveLimits(limitsData)
 
 	curveSeriesList := getCurveSeriesList(metricsData, plot.theme)
 	if len(curveSeriesList) == 0 {
@@ -67,6 +74,7 @@ func (plot *Plot) GetRenderable(targetName string, trigger *moira.Trigger, metri
 
 	thresholdSeriesList := getThresholdSeriesList(trigger, plot.theme, limits)
 	plotSeries = append(plotSeries, thresholdSeriesList...)
+	plotSeries = append(plotSeries, getBandSeriesList(bandBounds, plot.theme)...)
 
 	gridStyle := plot.theme.GetGridStyle()
 
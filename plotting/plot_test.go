@@ -495,7 +495,7 @@ func renderTestMetricsDataToPNG(trigger moira.Trigger, plotTheme string,
 	if err != nil {
 		return err
 	}
-	renderable, err := plotTemplate.GetRenderable("t1", &trigger, metricsData)
+	renderable, err := plotTemplate.GetRenderable("t1", &trigger, metricsData, nil)
 	if err != nil {
 		return err
 	}
@@ -651,7 +651,7 @@ func TestErrNoPointsToRender_Error(t *testing.T) {
 		}
 		fmt.Printf("MetricsData points: %#v", testMetricsPoints)
 		for _, trigger := range testTriggers {
-			_, err = plotTemplate.GetRenderable("t1", &trigger, testMetricsData)
+			_, err = plotTemplate.GetRenderable("t1", &trigger, testMetricsData, nil)
 			So(err.Error(), ShouldEqual, ErrNoPointsToRender{triggerID: trigger.ID}.Error())
 		}
 	})
@@ -663,7 +663,7 @@ func TestErrNoPointsToRender_Error(t *testing.T) {
 		}
 		fmt.Printf("MetricsData points: %#v", testMetricsPoints)
 		for _, trigger := range testTriggers {
-			_, err = plotTemplate.GetRenderable("t1", &trigger, testMetricsData)
+			_, err = plotTemplate.GetRenderable("t1", &trigger, testMetricsData, nil)
 			So(err, ShouldBeNil)
 		}
 	})
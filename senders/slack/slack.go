@@ -73,6 +73,38 @@ func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.
 	return nil
 }
 
+// SendDigest implements moira.DigestSender, events of several triggers are sent in one message
+func (sender *Sender) SendDigest(events moira.NotificationEvents, contact moira.ContactData, triggers map[string]moira.TriggerData, throttled bool) error {
+	message := sender.buildDigestMessage(events.GroupByTriggers(triggers), events.GetSubjectState(), throttled)
+	emoji := sender.getStateEmoji(events.GetSubjectState())
+	_, _, err := sender.sendMessage(message, contact.Value, "digest", useDirectMessaging(contact.Value), emoji)
+	return err
+}
+
+// buildDigestMessage builds message with events of each trigger under its title,
+// triggers which do not fit in message are only counted
+func (sender *Sender) buildDigestMessage(triggerEvents []moira.TriggerEvents, state moira.State, throttled bool) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("*%s* Digest of %d triggers\n", state, len(triggerEvents)))
+
+	tailString := "\n...and %d more triggers."
+	charsLeft := messageMaxCharacters - len([]rune(message.String())) - len([]rune(tailString))
+	for i, group := range triggerEvents {
+		section := "\n" + sender.buildTitle(group.Events, group.Trigger) + sender.buildEventsString(group.Events, -1, false)
+		if len([]rune(section)) > charsLeft {
+			message.WriteString(fmt.Sprintf(tailString, len(triggerEvents)-i))
+			break
+		}
+		message.WriteString(section)
+		charsLeft -= len([]rune(section))
+	}
+
+	if throttled {
+		message.WriteString("\nPlease, *fix your system or tune these triggers* to generate less events.")
+	}
+	return message.String()
+}
+
 func (sender *Sender) buildMessage(events moira.NotificationEvents, trigger moira.TriggerData, throttled bool) string {
 	var message strings.Builder
 
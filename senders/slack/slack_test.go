@@ -207,6 +207,41 @@ some other text italic text
 	})
 }
 
+func TestBuildDigestMessage(t *testing.T) {
+	location, _ := time.LoadLocation("UTC")
+	sender := Sender{location: location, frontURI: "http://moira.url"}
+
+	Convey("Build digest message", t, func() {
+		trigger1 := moira.TriggerData{ID: "trigger1", Name: "First", Tags: []string{"tag1"}}
+		trigger2 := moira.TriggerData{ID: "trigger2", Name: "Second"}
+		events := moira.NotificationEvents{
+			{TriggerID: trigger2.ID, Values: map[string]float64{"t1": 1}, Timestamp: 150000000, Metric: "Metric2", OldState: moira.StateOK, State: moira.StateWARN},
+			{TriggerID: trigger1.ID, Values: map[string]float64{"t1": 2}, Timestamp: 150000000, Metric: "Metric1", OldState: moira.StateOK, State: moira.StateERROR},
+		}
+		triggerEvents := events.GroupByTriggers(map[string]moira.TriggerData{trigger1.ID: trigger1, trigger2.ID: trigger2})
+
+		Convey("Print events of each trigger", func() {
+			actual := sender.buildDigestMessage(triggerEvents, events.GetSubjectState(), true)
+			expected := "*ERROR* Digest of 2 triggers\n" +
+				"\n*ERROR* <http://moira.url/trigger/trigger1|First> [tag1]\n```\n02:40: Metric1 = 2 (OK to ERROR)```" +
+				"\n*WARN* <http://moira.url/trigger/trigger2|Second>\n```\n02:40: Metric2 = 1 (OK to WARN)```" +
+				"\nPlease, *fix your system or tune these triggers* to generate less events."
+			So(actual, ShouldResemble, expected)
+		})
+
+		Convey("Count triggers which do not fit in message", func() {
+			longEvents := make(moira.NotificationEvents, 0)
+			for i := 0; i < 100; i++ {
+				longEvents = append(longEvents, moira.NotificationEvent{TriggerID: trigger1.ID, Metric: strings.Repeat("metric", 10), State: moira.StateERROR})
+			}
+			longEvents = append(longEvents, events[0])
+			triggerEvents := longEvents.GroupByTriggers(map[string]moira.TriggerData{trigger1.ID: trigger1, trigger2.ID: trigger2})
+			actual := sender.buildDigestMessage(triggerEvents, moira.StateERROR, false)
+			So(actual, ShouldResemble, "*ERROR* Digest of 2 triggers\n\n...and 2 more triggers.")
+		})
+	})
+}
+
 func TestBuildDescription(t *testing.T) {
 	location, _ := time.LoadLocation("UTC")
 	sender := Sender{location: location, frontURI: "http://moira.url"}
@@ -45,6 +45,49 @@ func (sender *Sender) SendEvents(events moira.NotificationEvents, contact moira.
 	return nil
 }
 
+// SendDigest implements moira.DigestSender, events of several triggers are sent in one message
+func (sender *Sender) SendDigest(events moira.NotificationEvents, contact moira.ContactData, triggers map[string]moira.TriggerData, throttled bool) error {
+	message := sender.buildDigestMessage(events.GroupByTriggers(triggers), events.GetSubjectState(), throttled)
+	sender.logger.Debugf("Calling telegram api with chat_id %s and digest message body %s", contact.Value, message)
+	chat, err := sender.getChat(contact.Value)
+	if err != nil {
+		return err
+	}
+	if err := sender.sendAsMessage(chat, message); err != nil {
+		return fmt.Errorf("failed to send digest to telegram contact %s: %s. ", contact.Value, err)
+	}
+	return nil
+}
+
+// buildDigestMessage builds message with title and events count of each trigger,
+// triggers which do not fit in message are only counted
+func (sender *Sender) buildDigestMessage(triggerEvents []moira.TriggerEvents, state moira.State, throttled bool) string {
+	var buffer bytes.Buffer
+	title := fmt.Sprintf("%s%s Digest of %d triggers\n", emojiStates[state], state, len(triggerEvents))
+	buffer.WriteString(title)
+
+	messageCharsCount := len([]rune(title))
+	for i, group := range triggerEvents {
+		groupState := group.Events.GetSubjectState()
+		line := fmt.Sprintf("\n%s%s %s %s (%d)", emojiStates[groupState], groupState, group.Trigger.Name, group.Trigger.GetTags(), len(group.Events))
+		if url := group.Trigger.GetTriggerURI(sender.frontURI); url != "" {
+			line += fmt.Sprintf("\n%s", url)
+		}
+		lineCharsCount := len([]rune(line))
+		if messageCharsCount+lineCharsCount > messageMaxCharacters-additionalInfoCharactersCount {
+			buffer.WriteString(fmt.Sprintf("\n\n...and %d more triggers.", len(triggerEvents)-i))
+			break
+		}
+		buffer.WriteString(line)
+		messageCharsCount += lineCharsCount
+	}
+
+	if throttled {
+		buffer.WriteString("\n\nPlease, fix your system or tune these triggers to generate less events.")
+	}
+	return buffer.String()
+}
+
 func (sender *Sender) buildMessage(events moira.NotificationEvents, trigger moira.TriggerData, throttled bool, maxChars int) string {
 	var buffer bytes.Buffer
 	state := events.GetSubjectState()
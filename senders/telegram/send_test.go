@@ -115,6 +115,30 @@ http://moira.url/trigger/TriggerID
 	})
 }
 
+func TestBuildDigestMessage(t *testing.T) {
+	sender := Sender{frontURI: "http://moira.url"}
+
+	Convey("Build digest message", t, func() {
+		trigger1 := moira.TriggerData{ID: "trigger1", Name: "First", Tags: []string{"tag1"}}
+		trigger2 := moira.TriggerData{ID: "trigger2", Name: "Second"}
+		events := moira.NotificationEvents{
+			{TriggerID: trigger2.ID, Metric: "Metric2", OldState: moira.StateOK, State: moira.StateWARN},
+			{TriggerID: trigger1.ID, Metric: "Metric1", OldState: moira.StateOK, State: moira.StateERROR},
+			{TriggerID: trigger1.ID, Metric: "Metric3", OldState: moira.StateOK, State: moira.StateERROR},
+		}
+		triggerEvents := events.GroupByTriggers(map[string]moira.TriggerData{trigger1.ID: trigger1, trigger2.ID: trigger2})
+
+		actual := sender.buildDigestMessage(triggerEvents, events.GetSubjectState(), false)
+		expected := `⭕ERROR Digest of 2 triggers
+
+⭕ERROR First [tag1] (2)
+http://moira.url/trigger/trigger1
+⚠WARN Second  (1)
+http://moira.url/trigger/trigger2`
+		So(actual, ShouldResemble, expected)
+	})
+}
+
 func TestGetChatUID(t *testing.T) {
 	location, _ := time.LoadLocation("UTC")
 	mockCtrl := gomock.NewController(t)
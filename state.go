@@ -53,6 +53,11 @@ func (state State) String() string {
 	return string(state)
 }
 
+// IsWorseThan returns true if state has higher score than other state
+func (state State) IsWorseThan(other State) bool {
+	return stateScores[state] > stateScores[other]
+}
+
 // ToSelfState converts State to corresponding SelfState
 func (state State) ToSelfState() string {
 	if state != StateOK {
@@ -25,3 +25,14 @@ func TestTtlState_ToTriggerState(t *testing.T) {
 		So(TTLStateNODATA.ToTriggerState(), ShouldResemble, StateNODATA)
 	})
 }
+
+func TestState_IsWorseThan(t *testing.T) {
+	Convey("IsWorseThan test", t, func() {
+		So(StateERROR.IsWorseThan(StateWARN), ShouldBeTrue)
+		So(StateNODATA.IsWorseThan(StateERROR), ShouldBeTrue)
+		So(StateWARN.IsWorseThan(StateOK), ShouldBeTrue)
+		So(StateOK.IsWorseThan(StateWARN), ShouldBeFalse)
+		So(StateERROR.IsWorseThan(StateNODATA), ShouldBeFalse)
+		So(StateOK.IsWorseThan(StateOK), ShouldBeFalse)
+	})
+}